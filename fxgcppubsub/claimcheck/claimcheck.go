@@ -0,0 +1,147 @@
+package claimcheck
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"cloud.google.com/go/pubsub"
+	"github.com/ankorstore/yokai-contrib/fxgcppubsub/message"
+	"github.com/ankorstore/yokai-contrib/fxgcppubsub/subscription"
+	"github.com/ankorstore/yokai-contrib/fxgcppubsub/topic"
+	"github.com/ankorstore/yokai/log"
+	"github.com/google/uuid"
+)
+
+const (
+	// KeyAttribute is the message attribute referencing the BlobStore key of a claim-checked payload.
+	KeyAttribute = "claim_check_key"
+	// SizeAttribute is the message attribute carrying the size in bytes of a claim-checked payload.
+	SizeAttribute = "claim_check_size"
+	// DefaultThreshold is the default payload size in bytes above which payloads are claim-checked.
+	DefaultThreshold = 1024 * 1024
+)
+
+var (
+	_ topic.Middleware        = (*PublishMiddleware)(nil)
+	_ subscription.Middleware = (*SubscribeMiddleware)(nil)
+)
+
+// WithClaimCheck is a topic.PublishOption storing the payloads bigger than threshold bytes (DefaultThreshold if not
+// positive) in a BlobStore, and publishing instead empty messages referencing them with the KeyAttribute.
+//
+// Since the pub/sub server rejects empty messages on topics with a schema, the publications of payloads bigger than
+// threshold bytes on such topics fail.
+//
+// Like all the per publication middlewares, it is not applied on the delayed and outbox publications: register
+// NewPublishMiddleware as a global publish middleware instead.
+func WithClaimCheck(store BlobStore, threshold int) topic.PublishOption {
+	return topic.WithMiddlewares(NewPublishMiddleware(store, threshold))
+}
+
+// PublishMiddleware is a topic.Middleware storing large payloads in a BlobStore, and publishing only their reference.
+type PublishMiddleware struct {
+	store     BlobStore
+	threshold int
+}
+
+// NewPublishMiddleware returns a new PublishMiddleware instance, claim-checking the payloads bigger than threshold
+// bytes (DefaultThreshold if not positive).
+func NewPublishMiddleware(store BlobStore, threshold int) *PublishMiddleware {
+	if threshold <= 0 {
+		threshold = DefaultThreshold
+	}
+
+	return &PublishMiddleware{
+		store:     store,
+		threshold: threshold,
+	}
+}
+
+// Threshold returns the payload size in bytes above which payloads are claim-checked.
+func (mw *PublishMiddleware) Threshold() int {
+	return mw.threshold
+}
+
+// Wrap wraps the next topic.PublishFunc.
+func (mw *PublishMiddleware) Wrap(next topic.PublishFunc) topic.PublishFunc {
+	return func(ctx context.Context, msg *pubsub.Message) (*pubsub.PublishResult, error) {
+		if len(msg.Data) <= mw.threshold {
+			return next(ctx, msg)
+		}
+
+		if schema := topic.CtxTopicSchema(ctx); schema != "" {
+			return nil, fmt.Errorf("cannot claim-check payload on topic %s with schema %s: claim-checked messages have an empty data", topic.CtxTopicID(ctx), schema)
+		}
+
+		key := fmt.Sprintf("%s/%s", topic.CtxTopicID(ctx), uuid.NewString())
+
+		err := mw.store.Put(ctx, key, msg.Data)
+		if err != nil {
+			return nil, fmt.Errorf("cannot claim-check payload: %w", err)
+		}
+
+		if msg.Attributes == nil {
+			msg.Attributes = make(map[string]string)
+		}
+
+		msg.Attributes[KeyAttribute] = key
+		msg.Attributes[SizeAttribute] = strconv.Itoa(len(msg.Data))
+		msg.Data = nil
+
+		return next(ctx, msg)
+	}
+}
+
+// Fetch replaces the data of a claim-checked message by its payload fetched from the BlobStore, to be decoded as usual.
+//
+// Messages without the KeyAttribute are left untouched.
+func Fetch(ctx context.Context, store BlobStore, m *message.Message) error {
+	key, ok := m.Attributes()[KeyAttribute]
+	if !ok {
+		return nil
+	}
+
+	data, err := store.Get(ctx, key)
+	if err != nil {
+		return fmt.Errorf("cannot fetch claim-checked payload: %w", err)
+	}
+
+	m.BaseMessage().Data = data
+
+	return nil
+}
+
+// SubscribeMiddleware is a subscription.Middleware fetching the claim-checked payloads from a BlobStore, before the
+// message handling. The messages which payload cannot be fetched are nacked.
+type SubscribeMiddleware struct {
+	store BlobStore
+}
+
+// NewSubscribeMiddleware returns a new SubscribeMiddleware instance.
+func NewSubscribeMiddleware(store BlobStore) *SubscribeMiddleware {
+	return &SubscribeMiddleware{
+		store: store,
+	}
+}
+
+// Wrap wraps the next subscription.SubscribeFunc.
+func (mw *SubscribeMiddleware) Wrap(next subscription.SubscribeFunc) subscription.SubscribeFunc {
+	return func(ctx context.Context, m *message.Message) {
+		err := Fetch(ctx, mw.store, m)
+		if err != nil {
+			log.CtxLogger(ctx).
+				Error().
+				Err(err).
+				Str("subscriptionID", subscription.CtxSubscriptionID(ctx)).
+				Str("messageID", m.ID()).
+				Msg("pubsub message claim-check error")
+
+			m.Nack()
+
+			return
+		}
+
+		next(ctx, m)
+	}
+}
@@ -0,0 +1,282 @@
+package claimcheck_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/pubsub"
+	"github.com/ankorstore/yokai-contrib/fxgcppubsub"
+	"github.com/ankorstore/yokai-contrib/fxgcppubsub/claimcheck"
+	"github.com/ankorstore/yokai-contrib/fxgcppubsub/codec"
+	"github.com/ankorstore/yokai-contrib/fxgcppubsub/message"
+	"github.com/ankorstore/yokai-contrib/fxgcppubsub/reactor"
+	"github.com/ankorstore/yokai-contrib/fxgcppubsub/reactor/ack"
+	"github.com/ankorstore/yokai-contrib/fxgcppubsub/topic"
+	"github.com/ankorstore/yokai/fxconfig"
+	"github.com/ankorstore/yokai/fxlog"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/fx"
+	"go.uber.org/fx/fxtest"
+)
+
+func TestPublishMiddleware(t *testing.T) {
+	t.Parallel()
+
+	ctx := topic.ContextWithTopicID(context.Background(), "test-topic")
+
+	var published *pubsub.Message
+
+	next := func(ctx context.Context, msg *pubsub.Message) (*pubsub.PublishResult, error) {
+		published = msg
+
+		return nil, nil
+	}
+
+	t.Run("default threshold", func(t *testing.T) {
+		t.Parallel()
+
+		mw := claimcheck.NewPublishMiddleware(claimcheck.NewMemoryBlobStore(), 0)
+
+		assert.Equal(t, claimcheck.DefaultThreshold, mw.Threshold())
+	})
+
+	t.Run("payload below threshold", func(t *testing.T) {
+		store := claimcheck.NewMemoryBlobStore()
+
+		//nolint:errcheck
+		claimcheck.NewPublishMiddleware(store, 4).Wrap(next)(ctx, &pubsub.Message{Data: []byte("test")})
+
+		assert.Equal(t, []byte("test"), published.Data)
+		assert.Nil(t, published.Attributes)
+		assert.Empty(t, store.Keys())
+	})
+
+	t.Run("payload above threshold", func(t *testing.T) {
+		store := claimcheck.NewMemoryBlobStore()
+
+		//nolint:errcheck
+		claimcheck.NewPublishMiddleware(store, 3).Wrap(next)(ctx, &pubsub.Message{
+			Data:       []byte("test"),
+			Attributes: map[string]string{"foo": "bar"},
+		})
+
+		assert.Nil(t, published.Data)
+		assert.Equal(t, "bar", published.Attributes["foo"])
+		assert.Equal(t, "4", published.Attributes[claimcheck.SizeAttribute])
+
+		key := published.Attributes[claimcheck.KeyAttribute]
+		assert.True(t, strings.HasPrefix(key, "test-topic/"))
+		assert.Equal(t, []string{key}, store.Keys())
+
+		data, err := store.Get(ctx, key)
+		assert.NoError(t, err)
+		assert.Equal(t, []byte("test"), data)
+	})
+
+	t.Run("payload below threshold on topic with schema", func(t *testing.T) {
+		store := claimcheck.NewMemoryBlobStore()
+
+		schemaCtx := topic.ContextWithTopicSchema(ctx, "test-schema")
+
+		_, err := claimcheck.NewPublishMiddleware(store, 4).Wrap(next)(schemaCtx, &pubsub.Message{Data: []byte("test")})
+		assert.NoError(t, err)
+
+		assert.Equal(t, []byte("test"), published.Data)
+		assert.Empty(t, store.Keys())
+	})
+
+	t.Run("payload above threshold on topic with schema", func(t *testing.T) {
+		t.Parallel()
+
+		store := claimcheck.NewMemoryBlobStore()
+
+		schemaCtx := topic.ContextWithTopicSchema(ctx, "test-schema")
+
+		_, err := claimcheck.NewPublishMiddleware(store, 3).Wrap(next)(schemaCtx, &pubsub.Message{Data: []byte("test")})
+		assert.Error(t, err)
+		assert.Equal(t, "cannot claim-check payload on topic test-topic with schema test-schema: claim-checked messages have an empty data", err.Error())
+		assert.Empty(t, store.Keys())
+	})
+
+	t.Run("payload above threshold with store error", func(t *testing.T) {
+		t.Parallel()
+
+		store := claimcheck.NewFileSystemBlobStore(string([]byte{0}))
+
+		_, err := claimcheck.NewPublishMiddleware(store, 3).Wrap(next)(ctx, &pubsub.Message{Data: []byte("test")})
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "cannot claim-check payload")
+	})
+}
+
+func TestFetch(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	store := claimcheck.NewMemoryBlobStore()
+	err := store.Put(ctx, "test-topic/test-key", []byte("test"))
+	assert.NoError(t, err)
+
+	t.Run("message without claim-check", func(t *testing.T) {
+		t.Parallel()
+
+		m := message.NewMessage(codec.NewRawCodec(), &pubsub.Message{Data: []byte("data")})
+
+		err := claimcheck.Fetch(ctx, store, m)
+		assert.NoError(t, err)
+		assert.Equal(t, []byte("data"), m.Data())
+	})
+
+	t.Run("message with claim-check", func(t *testing.T) {
+		t.Parallel()
+
+		m := message.NewMessage(codec.NewRawCodec(), &pubsub.Message{
+			Attributes: map[string]string{claimcheck.KeyAttribute: "test-topic/test-key"},
+		})
+
+		err := claimcheck.Fetch(ctx, store, m)
+		assert.NoError(t, err)
+		assert.Equal(t, []byte("test"), m.Data())
+	})
+
+	t.Run("message with missing claim-check", func(t *testing.T) {
+		t.Parallel()
+
+		m := message.NewMessage(codec.NewRawCodec(), &pubsub.Message{
+			Attributes: map[string]string{claimcheck.KeyAttribute: "test-topic/invalid-key"},
+		})
+
+		err := claimcheck.Fetch(ctx, store, m)
+		assert.Error(t, err)
+		assert.Equal(t, "cannot fetch claim-checked payload: cannot get blob test-topic/invalid-key: blob not found", err.Error())
+	})
+}
+
+func TestClaimCheckWithTestServer(t *testing.T) {
+	t.Setenv("APP_ENV", "test")
+	t.Setenv("APP_CONFIG_PATH", "../testdata/config")
+	t.Setenv("GCP_PROJECT_ID", "test-project")
+
+	var publisher fxgcppubsub.Publisher
+	var subscriber fxgcppubsub.Subscriber
+	var supervisor ack.AckSupervisor
+	var client *pubsub.Client
+
+	ctx := context.Background()
+
+	store := claimcheck.NewMemoryBlobStore()
+
+	fxtest.New(
+		t,
+		fx.NopLogger,
+		fxconfig.FxConfigModule,
+		fxlog.FxLogModule,
+		fxgcppubsub.FxGcpPubSubModule,
+		fx.Supply(fx.Annotate(ctx, fx.As(new(context.Context)))),
+		fx.Supply(fx.Annotate(store, fx.As(new(claimcheck.BlobStore)))),
+		fxgcppubsub.PrepareTopicAndSubscription(fxgcppubsub.PrepareTopicAndSubscriptionParams{
+			TopicID:        "claim-check-topic",
+			SubscriptionID: "claim-check-subscription",
+		}),
+		fx.Populate(&publisher, &subscriber, &supervisor, &client),
+	).RequireStart().RequireStop()
+
+	large := strings.Repeat("a", 100)
+
+	t.Run("publish on derived topic with schema", func(t *testing.T) {
+		top := topic.NewTopic(codec.NewRawCodec(), client.Topic("claim-check-topic"), topic.WithSchema("test-schema"))
+
+		_, err := top.WithOptions(claimcheck.WithClaimCheck(store, 10)).Publish(ctx, []byte(large))
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "cannot claim-check payload on topic claim-check-topic with schema test-schema")
+		assert.Empty(t, store.Keys())
+	})
+
+	for _, data := range []string{"small", large} {
+		res, err := publisher.Publish(ctx, "claim-check-topic", []byte(data), claimcheck.WithClaimCheck(store, 10))
+		assert.NoError(t, err)
+
+		_, err = res.Get(ctx)
+		assert.NoError(t, err)
+	}
+
+	assert.Len(t, store.Keys(), 1)
+
+	waiter := supervisor.StartAckWaiter("claim-check-subscription", reactor.WithCount(2))
+
+	subCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	received := make(chan string, 2)
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		//nolint:errcheck
+		subscriber.Subscribe(subCtx, "claim-check-subscription", func(ctx context.Context, m *message.Message) {
+			received <- string(m.Data())
+
+			m.Ack()
+		})
+	}()
+
+	_, err := waiter.WaitMaxDuration(ctx, 5*time.Second)
+	assert.NoError(t, err)
+
+	assert.ElementsMatch(t, []string{"small", large}, []string{<-received, <-received})
+
+	// stop the streaming subscription, to not receive the messages pulled below
+	cancel()
+	<-done
+
+	t.Run("pull claim-checked messages", func(t *testing.T) {
+		res, err := publisher.Publish(ctx, "claim-check-topic", []byte(large), claimcheck.WithClaimCheck(store, 10))
+		assert.NoError(t, err)
+
+		_, err = res.Get(ctx)
+		assert.NoError(t, err)
+
+		var messages []*message.Message
+
+		assert.Eventually(t, func() bool {
+			pulled, err := subscriber.Pull(ctx, "claim-check-subscription", 10, 100*time.Millisecond)
+			assert.NoError(t, err)
+
+			messages = append(messages, pulled...)
+
+			return len(messages) > 0
+		}, 5*time.Second, 10*time.Millisecond)
+
+		assert.Len(t, messages, 1)
+		assert.Equal(t, []byte(large), messages[0].Data())
+
+		messages[0].Ack()
+	})
+
+	t.Run("pull claim-checked messages with missing payload", func(t *testing.T) {
+		res, err := publisher.Publish(ctx, "claim-check-topic", []byte(large), claimcheck.WithClaimCheck(store, 10))
+		assert.NoError(t, err)
+
+		id, err := res.Get(ctx)
+		assert.NoError(t, err)
+
+		for _, key := range store.Keys() {
+			err = store.Delete(ctx, key)
+			assert.NoError(t, err)
+		}
+
+		nackWaiter := supervisor.StartNackWaiter("claim-check-subscription", ack.WithMessageIDs(id))
+		defer nackWaiter.Release()
+
+		messages, err := subscriber.Pull(ctx, "claim-check-subscription", 10, time.Second)
+		assert.NoError(t, err)
+		assert.Empty(t, messages)
+
+		_, err = nackWaiter.WaitMaxDuration(ctx, time.Second)
+		assert.NoError(t, err)
+	})
+}
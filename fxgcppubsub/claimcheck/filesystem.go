@@ -0,0 +1,87 @@
+package claimcheck
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+var _ BlobStore = (*FileSystemBlobStore)(nil)
+
+// FileSystemBlobStore is a BlobStore implementation storing the blobs as files under a directory.
+type FileSystemBlobStore struct {
+	dir string
+}
+
+// NewFileSystemBlobStore returns a new FileSystemBlobStore instance, storing the blobs under the provided directory.
+func NewFileSystemBlobStore(dir string) *FileSystemBlobStore {
+	return &FileSystemBlobStore{
+		dir: dir,
+	}
+}
+
+// Put stores a blob under a key.
+func (s *FileSystemBlobStore) Put(ctx context.Context, key string, data []byte) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+
+	err = os.MkdirAll(filepath.Dir(path), 0o750)
+	if err != nil {
+		return fmt.Errorf("cannot create blob directory: %w", err)
+	}
+
+	err = os.WriteFile(path, data, 0o600)
+	if err != nil {
+		return fmt.Errorf("cannot put blob %s: %w", key, err)
+	}
+
+	return nil
+}
+
+// Get returns the blob stored under a key.
+func (s *FileSystemBlobStore) Get(ctx context.Context, key string) ([]byte, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, fmt.Errorf("cannot get blob %s: %w", key, ErrBlobNotFound)
+		}
+
+		return nil, fmt.Errorf("cannot get blob %s: %w", key, err)
+	}
+
+	return data, nil
+}
+
+// Delete deletes the blob stored under a key, if any.
+func (s *FileSystemBlobStore) Delete(ctx context.Context, key string) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+
+	err = os.Remove(path)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("cannot delete blob %s: %w", key, err)
+	}
+
+	return nil
+}
+
+func (s *FileSystemBlobStore) path(key string) (string, error) {
+	if key == "" || !filepath.IsLocal(filepath.FromSlash(key)) || strings.Contains(key, "\\") {
+		return "", fmt.Errorf("invalid blob key %q", key)
+	}
+
+	return filepath.Join(s.dir, filepath.FromSlash(key)), nil
+}
@@ -0,0 +1,65 @@
+package claimcheck_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ankorstore/yokai-contrib/fxgcppubsub/claimcheck"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFileSystemBlobStore(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	t.Run("put, get and delete", func(t *testing.T) {
+		t.Parallel()
+
+		dir := t.TempDir()
+
+		store := claimcheck.NewFileSystemBlobStore(dir)
+
+		err := store.Put(ctx, "test-topic/test-key", []byte("test"))
+		assert.NoError(t, err)
+
+		content, err := os.ReadFile(filepath.Join(dir, "test-topic", "test-key"))
+		assert.NoError(t, err)
+		assert.Equal(t, []byte("test"), content)
+
+		data, err := store.Get(ctx, "test-topic/test-key")
+		assert.NoError(t, err)
+		assert.Equal(t, []byte("test"), data)
+
+		err = store.Delete(ctx, "test-topic/test-key")
+		assert.NoError(t, err)
+
+		_, err = store.Get(ctx, "test-topic/test-key")
+		assert.Error(t, err)
+		assert.True(t, errors.Is(err, claimcheck.ErrBlobNotFound))
+
+		err = store.Delete(ctx, "test-topic/test-key")
+		assert.NoError(t, err)
+	})
+
+	t.Run("invalid keys", func(t *testing.T) {
+		t.Parallel()
+
+		store := claimcheck.NewFileSystemBlobStore(t.TempDir())
+
+		for _, key := range []string{"", "../test-key", "/test-key", "test-topic/../../test-key", `test-topic\test-key`} {
+			err := store.Put(ctx, key, []byte("test"))
+			assert.Error(t, err)
+			assert.Contains(t, err.Error(), "invalid blob key")
+
+			_, err = store.Get(ctx, key)
+			assert.Error(t, err)
+
+			err = store.Delete(ctx, key)
+			assert.Error(t, err)
+		}
+	})
+}
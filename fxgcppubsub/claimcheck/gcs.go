@@ -0,0 +1,80 @@
+package claimcheck
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"cloud.google.com/go/storage"
+)
+
+var _ BlobStore = (*GCSBlobStore)(nil)
+
+// GCSBlobStore is a BlobStore implementation storing the blobs as Google Cloud Storage objects.
+//
+// The objects are not deleted after consumption: a bucket lifecycle rule should delete them once older than the
+// subscriptions message retention duration.
+type GCSBlobStore struct {
+	bucket *storage.BucketHandle
+	prefix string
+}
+
+// NewGCSBlobStore returns a new GCSBlobStore instance, storing the blobs in the provided bucket, with an objects name prefix.
+func NewGCSBlobStore(bucket *storage.BucketHandle, prefix string) *GCSBlobStore {
+	return &GCSBlobStore{
+		bucket: bucket,
+		prefix: prefix,
+	}
+}
+
+// Put stores a blob under a key.
+func (s *GCSBlobStore) Put(ctx context.Context, key string, data []byte) error {
+	w := s.bucket.Object(s.prefix + key).NewWriter(ctx)
+
+	_, err := w.Write(data)
+	if err != nil {
+		//nolint:errcheck
+		w.Close()
+
+		return fmt.Errorf("cannot put blob %s: %w", key, err)
+	}
+
+	err = w.Close()
+	if err != nil {
+		return fmt.Errorf("cannot put blob %s: %w", key, err)
+	}
+
+	return nil
+}
+
+// Get returns the blob stored under a key.
+func (s *GCSBlobStore) Get(ctx context.Context, key string) ([]byte, error) {
+	r, err := s.bucket.Object(s.prefix + key).NewReader(ctx)
+	if err != nil {
+		if errors.Is(err, storage.ErrObjectNotExist) {
+			return nil, fmt.Errorf("cannot get blob %s: %w", key, ErrBlobNotFound)
+		}
+
+		return nil, fmt.Errorf("cannot get blob %s: %w", key, err)
+	}
+
+	defer r.Close()
+
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("cannot get blob %s: %w", key, err)
+	}
+
+	return data, nil
+}
+
+// Delete deletes the blob stored under a key, if any.
+func (s *GCSBlobStore) Delete(ctx context.Context, key string) error {
+	err := s.bucket.Object(s.prefix + key).Delete(ctx)
+	if err != nil && !errors.Is(err, storage.ErrObjectNotExist) {
+		return fmt.Errorf("cannot delete blob %s: %w", key, err)
+	}
+
+	return nil
+}
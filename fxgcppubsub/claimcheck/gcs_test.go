@@ -0,0 +1,133 @@
+package claimcheck_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"cloud.google.com/go/storage"
+	"github.com/ankorstore/yokai-contrib/fxgcppubsub/claimcheck"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/api/option"
+)
+
+// newGCSTestServer returns a minimal Google Cloud Storage JSON API test server.
+func newGCSTestServer(tb testing.TB) *httptest.Server {
+	tb.Helper()
+
+	var mutex sync.Mutex
+	objects := make(map[string][]byte)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mutex.Lock()
+		defer mutex.Unlock()
+
+		switch {
+		case r.Method == http.MethodPost && strings.HasPrefix(r.URL.Path, "/upload/storage/v1/b/"):
+			_, params, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+			if err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+
+				return
+			}
+
+			reader := multipart.NewReader(r.Body, params["boundary"])
+
+			metadataPart, err := reader.NextPart()
+			if err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+
+				return
+			}
+
+			var metadata map[string]any
+			//nolint:errcheck
+			json.NewDecoder(metadataPart).Decode(&metadata)
+
+			dataPart, err := reader.NextPart()
+			if err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+
+				return
+			}
+
+			//nolint:errcheck
+			data, _ := io.ReadAll(dataPart)
+
+			name, _ := metadata["name"].(string)
+			objects[name] = data
+
+			w.Header().Set("Content-Type", "application/json")
+			//nolint:errcheck
+			json.NewEncoder(w).Encode(map[string]any{"bucket": "test-bucket", "name": name})
+		case strings.HasPrefix(r.URL.Path, "/storage/v1/b/test-bucket/o/"):
+			name := strings.TrimPrefix(r.URL.Path, "/storage/v1/b/test-bucket/o/")
+
+			data, ok := objects[name]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+
+				return
+			}
+
+			if r.Method == http.MethodDelete {
+				delete(objects, name)
+				w.WriteHeader(http.StatusNoContent)
+
+				return
+			}
+
+			//nolint:errcheck
+			w.Write(data)
+		default:
+			w.WriteHeader(http.StatusNotImplemented)
+		}
+	}))
+
+	tb.Cleanup(server.Close)
+
+	return server
+}
+
+func TestGCSBlobStore(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	server := newGCSTestServer(t)
+
+	client, err := storage.NewClient(
+		ctx,
+		option.WithEndpoint(server.URL+"/storage/v1/"),
+		option.WithoutAuthentication(),
+		storage.WithJSONReads(),
+	)
+	assert.NoError(t, err)
+
+	store := claimcheck.NewGCSBlobStore(client.Bucket("test-bucket"), "claims/")
+
+	err = store.Put(ctx, "test-topic/test-key", []byte("test"))
+	assert.NoError(t, err)
+
+	data, err := store.Get(ctx, "test-topic/test-key")
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("test"), data)
+
+	err = store.Delete(ctx, "test-topic/test-key")
+	assert.NoError(t, err)
+
+	_, err = store.Get(ctx, "test-topic/test-key")
+	assert.Error(t, err)
+	assert.True(t, errors.Is(err, claimcheck.ErrBlobNotFound))
+
+	err = store.Delete(ctx, "test-topic/test-key")
+	assert.NoError(t, err)
+}
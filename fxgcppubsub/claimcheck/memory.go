@@ -0,0 +1,71 @@
+package claimcheck
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+)
+
+var _ BlobStore = (*MemoryBlobStore)(nil)
+
+// MemoryBlobStore is an in-memory BlobStore implementation, for testing purposes.
+type MemoryBlobStore struct {
+	blobs map[string][]byte
+	mutex sync.RWMutex
+}
+
+// NewMemoryBlobStore returns a new MemoryBlobStore instance.
+func NewMemoryBlobStore() *MemoryBlobStore {
+	return &MemoryBlobStore{
+		blobs: make(map[string][]byte),
+	}
+}
+
+// Put stores a blob under a key.
+func (s *MemoryBlobStore) Put(ctx context.Context, key string, data []byte) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.blobs[key] = slices.Clone(data)
+
+	return nil
+}
+
+// Get returns the blob stored under a key.
+func (s *MemoryBlobStore) Get(ctx context.Context, key string) ([]byte, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	data, ok := s.blobs[key]
+	if !ok {
+		return nil, fmt.Errorf("cannot get blob %s: %w", key, ErrBlobNotFound)
+	}
+
+	return slices.Clone(data), nil
+}
+
+// Delete deletes the blob stored under a key, if any.
+func (s *MemoryBlobStore) Delete(ctx context.Context, key string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	delete(s.blobs, key)
+
+	return nil
+}
+
+// Keys returns the keys of the stored blobs, sorted.
+func (s *MemoryBlobStore) Keys() []string {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	keys := make([]string, 0, len(s.blobs))
+	for key := range s.blobs {
+		keys = append(keys, key)
+	}
+
+	slices.Sort(keys)
+
+	return keys
+}
@@ -0,0 +1,42 @@
+package claimcheck_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/ankorstore/yokai-contrib/fxgcppubsub/claimcheck"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryBlobStore(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	store := claimcheck.NewMemoryBlobStore()
+
+	data := []byte("test")
+
+	err := store.Put(ctx, "test-topic/test-key", data)
+	assert.NoError(t, err)
+
+	// stored data is a copy
+	data[0] = 'b'
+
+	res, err := store.Get(ctx, "test-topic/test-key")
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("test"), res)
+
+	assert.Equal(t, []string{"test-topic/test-key"}, store.Keys())
+
+	err = store.Delete(ctx, "test-topic/test-key")
+	assert.NoError(t, err)
+
+	_, err = store.Get(ctx, "test-topic/test-key")
+	assert.Error(t, err)
+	assert.True(t, errors.Is(err, claimcheck.ErrBlobNotFound))
+	assert.Equal(t, "cannot get blob test-topic/test-key: blob not found", err.Error())
+
+	assert.Empty(t, store.Keys())
+}
@@ -0,0 +1,16 @@
+package claimcheck
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrBlobNotFound is returned by the BlobStore implementations when a blob cannot be found.
+var ErrBlobNotFound = errors.New("blob not found")
+
+// BlobStore is the interface for the stores of the claim-checked message payloads.
+type BlobStore interface {
+	Put(ctx context.Context, key string, data []byte) error
+	Get(ctx context.Context, key string) ([]byte, error)
+	Delete(ctx context.Context, key string) error
+}
@@ -0,0 +1,56 @@
+package codec
+
+import "context"
+
+// AttributesCodec is the interface for codecs also handling the messages attributes, like the CloudEventsCodec in binary mode.
+type AttributesCodec interface {
+	EncodeWithAttributes(in any) ([]byte, map[string]string, error)
+	DecodeWithAttributes(enc []byte, attributes map[string]string, out any) error
+}
+
+// ContextAttributesCodec is the interface for the AttributesCodec also needing a context, like the encryption.EncryptionCodec
+// delegating to a KMS.
+type ContextAttributesCodec interface {
+	AttributesCodec
+	EncodeWithAttributesContext(ctx context.Context, in any) ([]byte, map[string]string, error)
+	DecodeWithAttributesContext(ctx context.Context, enc []byte, attributes map[string]string, out any) error
+}
+
+// EncodeWithAttributes encodes the provided input, and returns the attributes to add to the message if the Codec is a AttributesCodec.
+func EncodeWithAttributes(c Codec, in any) ([]byte, map[string]string, error) {
+	if attributesCodec, ok := c.(AttributesCodec); ok {
+		return attributesCodec.EncodeWithAttributes(in)
+	}
+
+	enc, err := c.Encode(in)
+
+	return enc, nil, err
+}
+
+// DecodeWithAttributes decodes the provided input with the message attributes if the Codec is a AttributesCodec,
+// or with the schema revision id provided in the attributes if the Codec is a RevisionCodec.
+func DecodeWithAttributes(c Codec, enc []byte, attributes map[string]string, out any) error {
+	if attributesCodec, ok := c.(AttributesCodec); ok {
+		return attributesCodec.DecodeWithAttributes(enc, attributes, out)
+	}
+
+	return DecodeWithRevision(c, attributes[SchemaRevisionIDAttribute], enc, out)
+}
+
+// EncodeWithAttributesContext is like EncodeWithAttributes, providing the context if the Codec is a ContextAttributesCodec.
+func EncodeWithAttributesContext(ctx context.Context, c Codec, in any) ([]byte, map[string]string, error) {
+	if contextCodec, ok := c.(ContextAttributesCodec); ok {
+		return contextCodec.EncodeWithAttributesContext(ctx, in)
+	}
+
+	return EncodeWithAttributes(c, in)
+}
+
+// DecodeWithAttributesContext is like DecodeWithAttributes, providing the context if the Codec is a ContextAttributesCodec.
+func DecodeWithAttributesContext(ctx context.Context, c Codec, enc []byte, attributes map[string]string, out any) error {
+	if contextCodec, ok := c.(ContextAttributesCodec); ok {
+		return contextCodec.DecodeWithAttributesContext(ctx, enc, attributes, out)
+	}
+
+	return DecodeWithAttributes(c, enc, attributes, out)
+}
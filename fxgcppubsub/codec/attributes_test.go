@@ -0,0 +1,47 @@
+package codec_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ankorstore/yokai-contrib/fxgcppubsub/codec"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAttributes(t *testing.T) {
+	t.Parallel()
+
+	t.Run("encoding and decoding with non attributes codec", func(t *testing.T) {
+		t.Parallel()
+
+		jsonCodec := codec.NewJsonCodec()
+
+		enc, attributes, err := codec.EncodeWithAttributes(jsonCodec, testJsonRecord{Name: "test", Age: 12})
+		assert.NoError(t, err)
+		assert.Nil(t, attributes)
+
+		out := testJsonRecord{}
+
+		err = codec.DecodeWithAttributes(jsonCodec, enc, map[string]string{"foo": "bar"}, &out)
+		assert.NoError(t, err)
+
+		assert.Equal(t, testJsonRecord{Name: "test", Age: 12}, out)
+	})
+
+	t.Run("encoding and decoding with context with non context codec", func(t *testing.T) {
+		t.Parallel()
+
+		jsonCodec := codec.NewJsonCodec()
+
+		enc, attributes, err := codec.EncodeWithAttributesContext(context.Background(), jsonCodec, testJsonRecord{Name: "test", Age: 12})
+		assert.NoError(t, err)
+		assert.Nil(t, attributes)
+
+		out := testJsonRecord{}
+
+		err = codec.DecodeWithAttributesContext(context.Background(), jsonCodec, enc, map[string]string{"foo": "bar"}, &out)
+		assert.NoError(t, err)
+
+		assert.Equal(t, testJsonRecord{Name: "test", Age: 12}, out)
+	})
+}
@@ -1,16 +1,18 @@
 package codec
 
 import (
-	"encoding/json"
 	"fmt"
+	"reflect"
 
 	"github.com/hamba/avro/v2"
 	"github.com/linkedin/goavro/v2"
 )
 
 var (
-	_ Codec = (*AvroBinaryCodec)(nil)
-	_ Codec = (*AvroJsonCodec)(nil)
+	_ Codec            = (*AvroBinaryCodec)(nil)
+	_ TypeCheckerCodec = (*AvroBinaryCodec)(nil)
+	_ Codec            = (*AvroJsonCodec)(nil)
+	_ TypeCheckerCodec = (*AvroJsonCodec)(nil)
 )
 
 // AvroBinaryCodec is a Codec implementation for encoding and decoding with avro schema in binary format.
@@ -54,9 +56,24 @@ func (c *AvroBinaryCodec) Decode(enc []byte, out any) error {
 	return nil
 }
 
+// CheckType checks that the provided Go type is compatible with the avro schema.
+func (c *AvroBinaryCodec) CheckType(typ reflect.Type) error {
+	err := avroCheckType(c.schema, typ)
+	if err != nil {
+		return fmt.Errorf("invalid avro type: %w", err)
+	}
+
+	return nil
+}
+
 // AvroJsonCodec is a Codec implementation for encoding and decoding with avro schema in json format.
+//
+// Values are mapped directly to the goavro native representation, following the AvroBinaryCodec Go conventions:
+// nullable unions as pointers, timestamp and date logical types as time.Time, time logical types as time.Duration,
+// decimal logical types as *big.Rat, enums as string and fixed as byte arrays.
 type AvroJsonCodec struct {
-	codec *goavro.Codec
+	codec  *goavro.Codec
+	schema avro.Schema
 }
 
 // NewAvroJsonCodec returns a new AvroJsonCodec instance.
@@ -66,17 +83,25 @@ func NewAvroJsonCodec(schemaDefinition string) (*AvroJsonCodec, error) {
 		return nil, fmt.Errorf("cannot parse avro schema: %w", err)
 	}
 
-	return &AvroJsonCodec{codec: codec}, nil
+	schema, err := avro.ParseBytesWithCache([]byte(schemaDefinition), "", &avro.SchemaCache{})
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse avro schema: %w", err)
+	}
+
+	return &AvroJsonCodec{
+		codec:  codec,
+		schema: schema,
+	}, nil
 }
 
 // Encode encodes in avro json format.
 func (c *AvroJsonCodec) Encode(in any) ([]byte, error) {
-	inMap, err := c.convertStructIntoMap(in)
+	native, err := avroNativeFromGo(c.schema, reflect.ValueOf(in))
 	if err != nil {
-		return nil, fmt.Errorf("cannot convert struct into map: %w", err)
+		return nil, fmt.Errorf("cannot encode avro json: %w", err)
 	}
 
-	out, err := c.codec.TextualFromNative(nil, inMap)
+	out, err := c.codec.TextualFromNative(nil, native)
 	if err != nil {
 		return nil, fmt.Errorf("cannot encode avro json: %w", err)
 	}
@@ -86,49 +111,29 @@ func (c *AvroJsonCodec) Encode(in any) ([]byte, error) {
 
 // Decode decodes from avro json format.
 func (c *AvroJsonCodec) Decode(enc []byte, out any) error {
-	data, _, err := c.codec.NativeFromTextual(enc)
-	if err != nil {
-		return fmt.Errorf("cannot decode avro json: %w", err)
+	rv := reflect.ValueOf(out)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return fmt.Errorf("cannot decode avro json: non nil pointer expected, got %T", out)
 	}
 
-	dataMap, ok := data.(map[string]interface{})
-	if !ok {
-		return fmt.Errorf("cannot convert avro json into map: %w", err)
-	}
-
-	err = c.convertMapIntoStruct(dataMap, out)
-	if err != nil {
-		return fmt.Errorf("cannot convert map into struct: %w", err)
-	}
-
-	return nil
-}
-
-func (c *AvroJsonCodec) convertStructIntoMap(in any) (map[string]interface{}, error) {
-	var out map[string]interface{}
-
-	jsonIn, err := json.Marshal(in)
+	native, _, err := c.codec.NativeFromTextual(enc)
 	if err != nil {
-		return nil, fmt.Errorf("cannot marshal json: %w", err)
+		return fmt.Errorf("cannot decode avro json: %w", err)
 	}
 
-	err = json.Unmarshal(jsonIn, &out)
+	err = avroGoFromNative(c.schema, native, rv.Elem())
 	if err != nil {
-		return nil, fmt.Errorf("cannot unmarshal json: %w", err)
+		return fmt.Errorf("cannot decode avro json: %w", err)
 	}
 
-	return out, nil
+	return nil
 }
 
-func (c *AvroJsonCodec) convertMapIntoStruct(in map[string]interface{}, out any) error {
-	jsonIn, err := json.Marshal(in)
-	if err != nil {
-		return fmt.Errorf("cannot marshal json: %w", err)
-	}
-
-	err = json.Unmarshal(jsonIn, &out)
+// CheckType checks that the provided Go type is compatible with the avro schema.
+func (c *AvroJsonCodec) CheckType(typ reflect.Type) error {
+	err := avroCheckType(c.schema, typ)
 	if err != nil {
-		return fmt.Errorf("cannot unmarshal json: %w", err)
+		return fmt.Errorf("invalid avro type: %w", err)
 	}
 
 	return nil
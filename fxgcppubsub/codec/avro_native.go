@@ -0,0 +1,758 @@
+package codec
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hamba/avro/v2"
+	"github.com/linkedin/goavro/v2"
+)
+
+var (
+	avroTimeType     = reflect.TypeOf(time.Time{})
+	avroDurationType = reflect.TypeOf(time.Duration(0))
+	avroRatType      = reflect.TypeOf(big.Rat{})
+	avroRatPtrType   = reflect.TypeOf(&big.Rat{})
+
+	avroStructFieldsCache sync.Map
+)
+
+// avroNativeFromGo converts a Go value into the goavro native representation of the provided schema:
+//   - records are converted from structs (or maps) into map[string]any
+//   - non-null unions members are wrapped into map[string]any{"member type name": value}
+//   - timestamp-millis, timestamp-micros and date logical types are converted from time.Time
+//   - time-millis and time-micros logical types are converted from time.Duration
+//   - decimal logical types are converted from *big.Rat
+//
+// The Go conventions are the same as the ones used by AvroBinaryCodec.
+//
+//nolint:cyclop
+func avroNativeFromGo(schema avro.Schema, v reflect.Value) (any, error) {
+	schema = avroResolveSchema(schema)
+
+	if union, ok := schema.(*avro.UnionSchema); ok {
+		return avroNativeUnionFromGo(union, v)
+	}
+
+	v, ok := avroIndirect(v)
+	if !ok {
+		if schema.Type() == avro.Null {
+			return nil, nil
+		}
+
+		return nil, fmt.Errorf("cannot convert nil into avro %s", schema.Type())
+	}
+
+	switch s := schema.(type) {
+	case *avro.PrimitiveSchema:
+		return avroNativePrimitiveFromGo(s.Type(), s.Logical(), v)
+	case *avro.FixedSchema:
+		return avroNativePrimitiveFromGo(avro.Fixed, s.Logical(), v)
+	case *avro.EnumSchema:
+		if v.Kind() == reflect.String {
+			return v.String(), nil
+		}
+	case *avro.RecordSchema:
+		return avroNativeRecordFromGo(s, v)
+	case *avro.ArraySchema:
+		if v.Kind() == reflect.Slice || v.Kind() == reflect.Array {
+			out := make([]any, v.Len())
+
+			for i := 0; i < v.Len(); i++ {
+				item, err := avroNativeFromGo(s.Items(), v.Index(i))
+				if err != nil {
+					return nil, fmt.Errorf("item %d: %w", i, err)
+				}
+
+				out[i] = item
+			}
+
+			return out, nil
+		}
+	case *avro.MapSchema:
+		if v.Kind() == reflect.Map && v.Type().Key().Kind() == reflect.String {
+			out := make(map[string]any, v.Len())
+
+			iter := v.MapRange()
+			for iter.Next() {
+				value, err := avroNativeFromGo(s.Values(), iter.Value())
+				if err != nil {
+					return nil, fmt.Errorf("key %s: %w", iter.Key().String(), err)
+				}
+
+				out[iter.Key().String()] = value
+			}
+
+			return out, nil
+		}
+	}
+
+	return nil, fmt.Errorf("cannot convert %s into avro %s", v.Type(), schema.Type())
+}
+
+//nolint:cyclop,exhaustive
+func avroNativePrimitiveFromGo(typ avro.Type, logical avro.LogicalSchema, v reflect.Value) (any, error) {
+	if logical != nil {
+		switch logical.Type() {
+		case avro.TimestampMillis, avro.TimestampMicros, avro.Date:
+			if v.Type() == avroTimeType {
+				return v.Interface(), nil
+			}
+		case avro.TimeMillis, avro.TimeMicros:
+			if v.Type() == avroDurationType {
+				return time.Duration(v.Int()), nil
+			}
+		case avro.Decimal:
+			if v.Type() == avroRatType {
+				return avroRatValue(v), nil
+			}
+		}
+	}
+
+	switch typ {
+	case avro.Boolean:
+		if v.Kind() == reflect.Bool {
+			return v.Bool(), nil
+		}
+	case avro.String:
+		if v.Kind() == reflect.String {
+			return v.String(), nil
+		}
+	case avro.Int:
+		if avroIsInt(v.Kind()) && v.Int() >= math.MinInt32 && v.Int() <= math.MaxInt32 {
+			return int32(v.Int()), nil
+		}
+		if avroIsUint(v.Kind()) && v.Uint() <= math.MaxInt32 {
+			return int32(v.Uint()), nil
+		}
+	case avro.Long:
+		if avroIsInt(v.Kind()) {
+			return v.Int(), nil
+		}
+		if avroIsUint(v.Kind()) && v.Uint() <= math.MaxInt64 {
+			return int64(v.Uint()), nil
+		}
+	case avro.Float:
+		if v.Kind() == reflect.Float32 || v.Kind() == reflect.Float64 {
+			return float32(v.Float()), nil
+		}
+	case avro.Double:
+		if v.Kind() == reflect.Float32 || v.Kind() == reflect.Float64 {
+			return v.Float(), nil
+		}
+	case avro.Bytes, avro.Fixed:
+		if avroIsBytes(v.Type()) {
+			if v.Kind() == reflect.Slice {
+				return v.Bytes(), nil
+			}
+
+			out := make([]byte, v.Len())
+			reflect.Copy(reflect.ValueOf(out), v)
+
+			return out, nil
+		}
+	}
+
+	return nil, fmt.Errorf("cannot convert %s into avro %s", v.Type(), typ)
+}
+
+func avroNativeRecordFromGo(schema *avro.RecordSchema, v reflect.Value) (any, error) {
+	out := make(map[string]any, len(schema.Fields()))
+
+	switch {
+	case v.Kind() == reflect.Struct && v.Type() != avroTimeType && v.Type() != avroRatType:
+		fields := avroStructFields(v.Type())
+
+		for _, field := range schema.Fields() {
+			index, ok := fields[field.Name()]
+			if !ok {
+				// missing fields are handled by goavro, with their default values if any
+				continue
+			}
+
+			value, err := avroNativeFromGo(field.Type(), v.FieldByIndex(index))
+			if err != nil {
+				return nil, fmt.Errorf("field %s: %w", field.Name(), err)
+			}
+
+			out[field.Name()] = value
+		}
+	case v.Kind() == reflect.Map && v.Type().Key().Kind() == reflect.String:
+		for _, field := range schema.Fields() {
+			fieldValue := v.MapIndex(reflect.ValueOf(field.Name()).Convert(v.Type().Key()))
+			if !fieldValue.IsValid() {
+				continue
+			}
+
+			value, err := avroNativeFromGo(field.Type(), fieldValue)
+			if err != nil {
+				return nil, fmt.Errorf("field %s: %w", field.Name(), err)
+			}
+
+			out[field.Name()] = value
+		}
+	default:
+		return nil, fmt.Errorf("cannot convert %s into avro %s", v.Type(), schema.Type())
+	}
+
+	return out, nil
+}
+
+func avroNativeUnionFromGo(schema *avro.UnionSchema, v reflect.Value) (any, error) {
+	v, ok := avroIndirect(v)
+	if !ok {
+		for _, member := range schema.Types() {
+			if member.Type() == avro.Null {
+				return nil, nil
+			}
+		}
+
+		return nil, fmt.Errorf("cannot convert nil into non nullable avro union")
+	}
+
+	// members matching exactly the Go type are preferred
+	for _, member := range schema.Types() {
+		member = avroResolveSchema(member)
+
+		if avroMatches(member, v.Type()) {
+			value, err := avroNativeFromGo(member, v)
+			if err != nil {
+				return nil, err
+			}
+
+			return goavro.Union(avroUnionMemberName(member), value), nil
+		}
+	}
+
+	// then the first member accepting the Go value
+	for _, member := range schema.Types() {
+		member = avroResolveSchema(member)
+
+		if member.Type() == avro.Null {
+			continue
+		}
+
+		value, err := avroNativeFromGo(member, v)
+		if err == nil {
+			return goavro.Union(avroUnionMemberName(member), value), nil
+		}
+	}
+
+	return nil, fmt.Errorf("cannot convert %s into any avro union member", v.Type())
+}
+
+// avroGoFromNative converts a goavro native value of the provided schema into the provided Go value, which must be settable.
+//
+//nolint:cyclop
+func avroGoFromNative(schema avro.Schema, native any, v reflect.Value) error {
+	schema = avroResolveSchema(schema)
+
+	if v.Kind() == reflect.Interface && v.NumMethod() == 0 {
+		if native == nil {
+			v.Set(reflect.Zero(v.Type()))
+
+			return nil
+		}
+
+		v.Set(reflect.ValueOf(avroUnwrapNative(schema, native)))
+
+		return nil
+	}
+
+	if native == nil {
+		v.Set(reflect.Zero(v.Type()))
+
+		return nil
+	}
+
+	if union, ok := schema.(*avro.UnionSchema); ok {
+		return avroGoFromNativeUnion(union, native, v)
+	}
+
+	if v.Type() == avroRatPtrType {
+		if r, ok := native.(*big.Rat); ok {
+			v.Set(reflect.ValueOf(r))
+
+			return nil
+		}
+	}
+
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+
+		return avroGoFromNative(schema, native, v.Elem())
+	}
+
+	switch s := schema.(type) {
+	case *avro.RecordSchema:
+		if record, ok := native.(map[string]any); ok {
+			return avroGoFromNativeRecord(s, record, v)
+		}
+	case *avro.ArraySchema:
+		if items, ok := native.([]any); ok && v.Kind() == reflect.Slice {
+			v.Set(reflect.MakeSlice(v.Type(), len(items), len(items)))
+
+			for i, item := range items {
+				err := avroGoFromNative(s.Items(), item, v.Index(i))
+				if err != nil {
+					return fmt.Errorf("item %d: %w", i, err)
+				}
+			}
+
+			return nil
+		}
+	case *avro.MapSchema:
+		if values, ok := native.(map[string]any); ok && v.Kind() == reflect.Map && v.Type().Key().Kind() == reflect.String {
+			v.Set(reflect.MakeMapWithSize(v.Type(), len(values)))
+
+			for key, value := range values {
+				elem := reflect.New(v.Type().Elem()).Elem()
+
+				err := avroGoFromNative(s.Values(), value, elem)
+				if err != nil {
+					return fmt.Errorf("key %s: %w", key, err)
+				}
+
+				v.SetMapIndex(reflect.ValueOf(key).Convert(v.Type().Key()), elem)
+			}
+
+			return nil
+		}
+	default:
+		return avroGoFromNativeScalar(schema, native, v)
+	}
+
+	return fmt.Errorf("cannot convert avro %s into %s", schema.Type(), v.Type())
+}
+
+func avroGoFromNativeUnion(schema *avro.UnionSchema, native any, v reflect.Value) error {
+	wrapped, ok := native.(map[string]any)
+	if ok && len(wrapped) == 1 {
+		for name, value := range wrapped {
+			for _, member := range schema.Types() {
+				member = avroResolveSchema(member)
+
+				if avroUnionMemberName(member) == name {
+					return avroGoFromNative(member, value, v)
+				}
+			}
+		}
+	}
+
+	return fmt.Errorf("cannot convert avro union into %s", v.Type())
+}
+
+func avroGoFromNativeRecord(schema *avro.RecordSchema, record map[string]any, v reflect.Value) error {
+	switch {
+	case v.Kind() == reflect.Struct:
+		fields := avroStructFields(v.Type())
+
+		for _, field := range schema.Fields() {
+			index, ok := fields[field.Name()]
+			if !ok {
+				continue
+			}
+
+			err := avroGoFromNative(field.Type(), record[field.Name()], v.FieldByIndex(index))
+			if err != nil {
+				return fmt.Errorf("field %s: %w", field.Name(), err)
+			}
+		}
+
+		return nil
+	case v.Kind() == reflect.Map && v.Type().Key().Kind() == reflect.String:
+		if v.IsNil() {
+			v.Set(reflect.MakeMapWithSize(v.Type(), len(schema.Fields())))
+		}
+
+		for _, field := range schema.Fields() {
+			elem := reflect.New(v.Type().Elem()).Elem()
+
+			err := avroGoFromNative(field.Type(), record[field.Name()], elem)
+			if err != nil {
+				return fmt.Errorf("field %s: %w", field.Name(), err)
+			}
+
+			v.SetMapIndex(reflect.ValueOf(field.Name()).Convert(v.Type().Key()), elem)
+		}
+
+		return nil
+	default:
+		return fmt.Errorf("cannot convert avro %s into %s", schema.Type(), v.Type())
+	}
+}
+
+//nolint:cyclop
+func avroGoFromNativeScalar(schema avro.Schema, native any, v reflect.Value) error {
+	nv := reflect.ValueOf(native)
+
+	switch {
+	case nv.Type().AssignableTo(v.Type()):
+		v.Set(nv)
+	case nv.Type() == avroRatPtrType && v.Type() == avroRatType:
+		avroRatValue(v).Set(native.(*big.Rat))
+	case avroIsBytes(nv.Type()) && v.Kind() == reflect.Array && avroIsBytes(v.Type()) && v.Len() == nv.Len():
+		reflect.Copy(v, nv)
+	case nv.Kind() == reflect.String && v.Kind() == reflect.String:
+		v.SetString(nv.String())
+	case nv.Kind() == reflect.Bool && v.Kind() == reflect.Bool:
+		v.SetBool(nv.Bool())
+	case avroIsInt(nv.Kind()) && avroIsInt(v.Kind()) && !v.OverflowInt(nv.Int()):
+		v.SetInt(nv.Int())
+	case avroIsInt(nv.Kind()) && avroIsUint(v.Kind()) && nv.Int() >= 0 && !v.OverflowUint(uint64(nv.Int())):
+		v.SetUint(uint64(nv.Int()))
+	case (nv.Kind() == reflect.Float32 || nv.Kind() == reflect.Float64) && (v.Kind() == reflect.Float32 || v.Kind() == reflect.Float64):
+		v.SetFloat(nv.Float())
+	default:
+		return fmt.Errorf("cannot convert avro %s into %s", schema.Type(), v.Type())
+	}
+
+	return nil
+}
+
+// avroUnwrapNative recursively unwraps the unions members of a goavro native value, for untyped targets.
+func avroUnwrapNative(schema avro.Schema, native any) any {
+	switch s := avroResolveSchema(schema).(type) {
+	case *avro.UnionSchema:
+		if wrapped, ok := native.(map[string]any); ok && len(wrapped) == 1 {
+			for name, value := range wrapped {
+				for _, member := range s.Types() {
+					if avroUnionMemberName(avroResolveSchema(member)) == name {
+						return avroUnwrapNative(member, value)
+					}
+				}
+			}
+		}
+	case *avro.RecordSchema:
+		if record, ok := native.(map[string]any); ok {
+			for _, field := range s.Fields() {
+				if value, ok := record[field.Name()]; ok {
+					record[field.Name()] = avroUnwrapNative(field.Type(), value)
+				}
+			}
+		}
+	case *avro.ArraySchema:
+		if items, ok := native.([]any); ok {
+			for i, item := range items {
+				items[i] = avroUnwrapNative(s.Items(), item)
+			}
+		}
+	case *avro.MapSchema:
+		if values, ok := native.(map[string]any); ok {
+			for key, value := range values {
+				values[key] = avroUnwrapNative(s.Values(), value)
+			}
+		}
+	}
+
+	return native
+}
+
+// avroUnionMemberName returns the name used by goavro to identify a union member.
+//
+//nolint:exhaustive
+func avroUnionMemberName(schema avro.Schema) string {
+	switch s := schema.(type) {
+	case avro.NamedSchema:
+		return s.FullName()
+	case *avro.PrimitiveSchema:
+		if logical := s.Logical(); logical != nil {
+			switch logical.Type() {
+			case avro.TimestampMillis, avro.TimestampMicros, avro.TimeMillis, avro.TimeMicros, avro.Date, avro.Decimal:
+				return string(s.Type()) + "." + string(logical.Type())
+			}
+		}
+	}
+
+	return string(schema.Type())
+}
+
+// avroMatches returns true if the Go type is the natural mapping of the provided schema.
+//
+//nolint:cyclop,exhaustive
+func avroMatches(schema avro.Schema, typ reflect.Type) bool {
+	var logical avro.LogicalType
+
+	if logicalSchema, ok := schema.(avro.LogicalTypeSchema); ok && logicalSchema.Logical() != nil {
+		logical = logicalSchema.Logical().Type()
+	}
+
+	switch typ {
+	case avroTimeType:
+		return logical == avro.TimestampMillis || logical == avro.TimestampMicros || logical == avro.Date
+	case avroDurationType:
+		return logical == avro.TimeMillis || logical == avro.TimeMicros
+	case avroRatType:
+		return logical == avro.Decimal
+	}
+
+	switch schema.Type() {
+	case avro.Boolean:
+		return typ.Kind() == reflect.Bool
+	case avro.String, avro.Enum:
+		return typ.Kind() == reflect.String
+	case avro.Int:
+		return logical == "" && (typ.Kind() == reflect.Int8 || typ.Kind() == reflect.Int16 || typ.Kind() == reflect.Int32)
+	case avro.Long:
+		return logical == "" && (typ.Kind() == reflect.Int || typ.Kind() == reflect.Int64)
+	case avro.Float:
+		return typ.Kind() == reflect.Float32
+	case avro.Double:
+		return typ.Kind() == reflect.Float64
+	case avro.Bytes:
+		return logical == "" && typ.Kind() == reflect.Slice && avroIsBytes(typ)
+	case avro.Fixed:
+		return logical == "" && typ.Kind() == reflect.Array && avroIsBytes(typ)
+	case avro.Record:
+		return typ.Kind() == reflect.Struct
+	case avro.Array:
+		return (typ.Kind() == reflect.Slice || typ.Kind() == reflect.Array) && !avroIsBytes(typ)
+	case avro.Map:
+		return typ.Kind() == reflect.Map
+	default:
+		return false
+	}
+}
+
+type avroCheckedType struct {
+	schema avro.Schema
+	typ    reflect.Type
+}
+
+// avroCheckType checks that the Go type follows, for the provided schema, the Go conventions used by AvroBinaryCodec:
+// struct fields are matched by name, and the schema fields missing from the struct must have a default.
+func avroCheckType(schema avro.Schema, typ reflect.Type) error {
+	return avroCheckTypeSeen(schema, typ, make(map[avroCheckedType]bool))
+}
+
+//nolint:cyclop,exhaustive
+func avroCheckTypeSeen(schema avro.Schema, typ reflect.Type, seen map[avroCheckedType]bool) error {
+	schema = avroResolveSchema(schema)
+
+	if typ.Kind() == reflect.Interface {
+		return nil
+	}
+
+	if union, ok := schema.(*avro.UnionSchema); ok {
+		return avroCheckUnionType(union, typ, seen)
+	}
+
+	for typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+
+	if logicalSchema, ok := schema.(avro.LogicalTypeSchema); ok && logicalSchema.Logical() != nil {
+		switch logicalSchema.Logical().Type() {
+		case avro.TimestampMillis, avro.TimestampMicros, avro.Date:
+			if typ == avroTimeType {
+				return nil
+			}
+		case avro.TimeMillis, avro.TimeMicros:
+			if typ == avroDurationType {
+				return nil
+			}
+		case avro.Decimal:
+			if typ == avroRatType {
+				return nil
+			}
+
+			return fmt.Errorf("cannot use %s as avro decimal, *big.Rat expected", typ)
+		}
+	}
+
+	compatible := false
+
+	switch s := schema.(type) {
+	case *avro.RecordSchema:
+		if typ.Kind() == reflect.Map && typ.Key().Kind() == reflect.String {
+			return nil
+		}
+
+		if typ.Kind() == reflect.Struct && typ != avroTimeType && typ != avroRatType {
+			return avroCheckRecordType(s, typ, seen)
+		}
+	case *avro.ArraySchema:
+		if (typ.Kind() == reflect.Slice || typ.Kind() == reflect.Array) && !avroIsBytes(typ) {
+			err := avroCheckTypeSeen(s.Items(), typ.Elem(), seen)
+			if err != nil {
+				return fmt.Errorf("items: %w", err)
+			}
+
+			return nil
+		}
+	case *avro.MapSchema:
+		if typ.Kind() == reflect.Map && typ.Key().Kind() == reflect.String {
+			err := avroCheckTypeSeen(s.Values(), typ.Elem(), seen)
+			if err != nil {
+				return fmt.Errorf("values: %w", err)
+			}
+
+			return nil
+		}
+	default:
+		switch schema.Type() {
+		case avro.Null:
+			compatible = true
+		case avro.Boolean:
+			compatible = typ.Kind() == reflect.Bool
+		case avro.String, avro.Enum:
+			compatible = typ.Kind() == reflect.String
+		case avro.Int, avro.Long:
+			compatible = avroIsInt(typ.Kind()) || avroIsUint(typ.Kind())
+		case avro.Float, avro.Double:
+			compatible = typ.Kind() == reflect.Float32 || typ.Kind() == reflect.Float64
+		case avro.Bytes, avro.Fixed:
+			compatible = avroIsBytes(typ)
+		}
+	}
+
+	if !compatible {
+		return fmt.Errorf("cannot use %s as avro %s", typ, schema.Type())
+	}
+
+	return nil
+}
+
+func avroCheckRecordType(schema *avro.RecordSchema, typ reflect.Type, seen map[avroCheckedType]bool) error {
+	// recursive records are checked once per Go type
+	key := avroCheckedType{schema: schema, typ: typ}
+	if seen[key] {
+		return nil
+	}
+
+	seen[key] = true
+
+	fields := avroStructFields(typ)
+
+	for _, field := range schema.Fields() {
+		index, ok := fields[field.Name()]
+		if !ok {
+			if field.HasDefault() {
+				continue
+			}
+
+			delete(seen, key)
+
+			return fmt.Errorf("missing field %s in %s", field.Name(), typ)
+		}
+
+		err := avroCheckTypeSeen(field.Type(), typ.FieldByIndex(index).Type, seen)
+		if err != nil {
+			delete(seen, key)
+
+			return fmt.Errorf("field %s: %w", field.Name(), err)
+		}
+	}
+
+	return nil
+}
+
+func avroCheckUnionType(schema *avro.UnionSchema, typ reflect.Type, seen map[avroCheckedType]bool) error {
+	checked := 0
+
+	for _, member := range schema.Types() {
+		if member.Type() == avro.Null {
+			continue
+		}
+
+		checked++
+
+		if avroCheckTypeSeen(member, typ, seen) == nil {
+			return nil
+		}
+	}
+
+	if checked == 0 {
+		return nil
+	}
+
+	return fmt.Errorf("cannot use %s as any avro union member", typ)
+}
+
+// avroStructFields returns the struct fields indexes by avro field name, resolved from the avro tag, then the json tag, then the field name.
+func avroStructFields(typ reflect.Type) map[string][]int {
+	if cached, ok := avroStructFieldsCache.Load(typ); ok {
+		//nolint:forcetypeassert
+		return cached.(map[string][]int)
+	}
+
+	fields := make(map[string][]int, typ.NumField())
+
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		name := field.Name
+
+		if tag, ok := field.Tag.Lookup("avro"); ok && tag != "" && tag != "-" {
+			name = tag
+		} else if tag, ok := field.Tag.Lookup("json"); ok {
+			jsonName, _, _ := strings.Cut(tag, ",")
+			if jsonName == "-" {
+				continue
+			}
+			if jsonName != "" {
+				name = jsonName
+			}
+		}
+
+		fields[name] = field.Index
+	}
+
+	avroStructFieldsCache.Store(typ, fields)
+
+	return fields
+}
+
+func avroResolveSchema(schema avro.Schema) avro.Schema {
+	if ref, ok := schema.(*avro.RefSchema); ok {
+		return ref.Schema()
+	}
+
+	return schema
+}
+
+func avroIndirect(v reflect.Value) (reflect.Value, bool) {
+	for v.IsValid() && (v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface) {
+		if v.IsNil() {
+			return v, false
+		}
+
+		v = v.Elem()
+	}
+
+	return v, v.IsValid()
+}
+
+func avroRatValue(v reflect.Value) *big.Rat {
+	if v.CanAddr() {
+		//nolint:forcetypeassert
+		return v.Addr().Interface().(*big.Rat)
+	}
+
+	//nolint:forcetypeassert
+	r := v.Interface().(big.Rat)
+
+	return &r
+}
+
+func avroIsInt(kind reflect.Kind) bool {
+	return kind >= reflect.Int && kind <= reflect.Int64
+}
+
+func avroIsUint(kind reflect.Kind) bool {
+	return kind >= reflect.Uint && kind <= reflect.Uint64
+}
+
+func avroIsBytes(typ reflect.Type) bool {
+	return (typ.Kind() == reflect.Slice || typ.Kind() == reflect.Array) && typ.Elem().Kind() == reflect.Uint8
+}
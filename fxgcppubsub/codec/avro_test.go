@@ -1,10 +1,15 @@
 package codec_test
 
 import (
+	"encoding/json"
+	"fmt"
+	"math/big"
 	"testing"
+	"time"
 
 	"github.com/ankorstore/yokai-contrib/fxgcppubsub/codec"
 	"github.com/ankorstore/yokai-contrib/fxgcppubsub/testdata/avro"
+	"github.com/linkedin/goavro/v2"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -134,6 +139,46 @@ func TestAvroJsonCodec(t *testing.T) {
 		assert.Contains(t, err.Error(), "cannot decode avro json")
 	})
 
+	t.Run("avro json decoding into non pointer failure", func(t *testing.T) {
+		t.Parallel()
+
+		avroJsonCodec, err := codec.NewAvroJsonCodec(schemaDefinition)
+		assert.NoError(t, err)
+
+		err = avroJsonCodec.Decode([]byte(`{"StringField":"test","FloatField":12.34,"BooleanField":true}`), avro.SimpleRecord{})
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "cannot decode avro json: non nil pointer expected, got avro.SimpleRecord")
+	})
+
+	t.Run("avro json encoding and decoding with maps", func(t *testing.T) {
+		t.Parallel()
+
+		avroJsonCodec, err := codec.NewAvroJsonCodec(schemaDefinition)
+		assert.NoError(t, err)
+
+		enc, err := avroJsonCodec.Encode(map[string]any{
+			"StringField":  "test",
+			"FloatField":   float32(12.34),
+			"BooleanField": true,
+		})
+		assert.NoError(t, err)
+
+		out := map[string]any{}
+
+		err = avroJsonCodec.Decode(enc, &out)
+		assert.NoError(t, err)
+
+		assert.Equal(
+			t,
+			map[string]any{
+				"StringField":  "test",
+				"FloatField":   float32(12.34),
+				"BooleanField": true,
+			},
+			out,
+		)
+	})
+
 	t.Run("avro json invalid schema", func(t *testing.T) {
 		t.Parallel()
 
@@ -143,3 +188,241 @@ func TestAvroJsonCodec(t *testing.T) {
 		assert.Contains(t, err.Error(), "cannot parse avro schema")
 	})
 }
+
+func TestAvroCodecsWithLogicalTypesAndUnions(t *testing.T) {
+	t.Parallel()
+
+	schemaDefinition := avro.GetTestAvroComplexSchemaDefinition(t)
+
+	avroBinaryCodec, err := codec.NewAvroBinaryCodec(schemaDefinition)
+	assert.NoError(t, err)
+
+	avroJsonCodec, err := codec.NewAvroJsonCodec(schemaDefinition)
+	assert.NoError(t, err)
+
+	codecs := map[string]codec.Codec{
+		"binary": avroBinaryCodec,
+		"json":   avroJsonCodec,
+	}
+
+	for name, c := range codecs {
+		t.Run(fmt.Sprintf("avro %s round trip with all values", name), func(t *testing.T) {
+			t.Parallel()
+
+			in := newComplexRecord()
+
+			optionalString := "optional"
+			optionalTimestamp := time.Date(2024, 5, 6, 7, 8, 9, 0, time.UTC)
+
+			in.OptionalStringField = &optionalString
+			in.OptionalTimestampField = &optionalTimestamp
+			in.OptionalNestedField = &avro.ComplexNested{Name: "optional nested"}
+			in.UnionField = int64(42)
+
+			enc, err := c.Encode(in)
+			assert.NoError(t, err)
+
+			out := avro.ComplexRecord{}
+
+			err = c.Decode(enc, &out)
+			assert.NoError(t, err)
+
+			assertComplexRecord(t, in, out)
+		})
+
+		t.Run(fmt.Sprintf("avro %s round trip with null values", name), func(t *testing.T) {
+			t.Parallel()
+
+			in := newComplexRecord()
+
+			enc, err := c.Encode(in)
+			assert.NoError(t, err)
+
+			out := avro.ComplexRecord{}
+
+			err = c.Decode(enc, &out)
+			assert.NoError(t, err)
+
+			assertComplexRecord(t, in, out)
+		})
+	}
+
+	t.Run("avro json encoding wraps unions and logical types", func(t *testing.T) {
+		t.Parallel()
+
+		in := newComplexRecord()
+
+		optionalString := "optional"
+
+		in.OptionalStringField = &optionalString
+		in.UnionField = "union"
+
+		enc, err := avroJsonCodec.Encode(in)
+		assert.NoError(t, err)
+
+		assert.Contains(t, string(enc), `"OptionalStringField":{"string":"optional"}`)
+		assert.Contains(t, string(enc), `"OptionalTimestampField":null`)
+		assert.Contains(t, string(enc), `"TimestampField":1704164645678`)
+		assert.Contains(t, string(enc), `"DateField":19724`)
+		assert.Contains(t, string(enc), `"TimeField":45296000`)
+		assert.Contains(t, string(enc), `"UnionField":{"string":"union"}`)
+	})
+
+	t.Run("avro json decoding into map", func(t *testing.T) {
+		t.Parallel()
+
+		in := newComplexRecord()
+		in.UnionField = "union"
+
+		enc, err := avroJsonCodec.Encode(in)
+		assert.NoError(t, err)
+
+		out := map[string]any{}
+
+		err = avroJsonCodec.Decode(enc, &out)
+		assert.NoError(t, err)
+
+		assert.Equal(t, "test", out["StringField"])
+		assert.Nil(t, out["OptionalStringField"])
+		assert.Equal(t, in.TimestampField, out["TimestampField"])
+		assert.Equal(t, in.TimeField, out["TimeField"])
+		assert.Equal(t, "union", out["UnionField"])
+		assert.Equal(t, map[string]any{"Name": "nested"}, out["NestedField"])
+	})
+
+	t.Run("avro json encoding failure on invalid union value", func(t *testing.T) {
+		t.Parallel()
+
+		in := newComplexRecord()
+		in.UnionField = true
+
+		_, err := avroJsonCodec.Encode(in)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "cannot encode avro json: field UnionField: cannot convert bool into any avro union member")
+	})
+}
+
+func newComplexRecord() avro.ComplexRecord {
+	return avro.ComplexRecord{
+		StringField:    "test",
+		IntField:       12,
+		LongField:      1234567890123,
+		DoubleField:    12.34,
+		BytesField:     []byte("bytes"),
+		TimestampField: time.Date(2024, 1, 2, 3, 4, 5, 678000000, time.UTC),
+		DateField:      time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC),
+		TimeField:      12*time.Hour + 34*time.Minute + 56*time.Second,
+		DecimalField:   big.NewRat(123456, 100),
+		UUIDField:      "6ba7b810-9dad-11d1-80b4-00c04fd430c8",
+		EnumField:      "GREEN",
+		FixedField:     [4]byte{1, 2, 3, 4},
+		ArrayField:     []string{"a", "b"},
+		MapField:       map[string]int64{"a": 1, "b": 2},
+		NestedField:    avro.ComplexNested{Name: "nested"},
+	}
+}
+
+func assertComplexRecord(t *testing.T, expected avro.ComplexRecord, actual avro.ComplexRecord) {
+	t.Helper()
+
+	assert.Equal(t, 0, expected.DecimalField.Cmp(actual.DecimalField))
+
+	expected.DecimalField = nil
+	actual.DecimalField = nil
+
+	assert.True(t, expected.TimestampField.Equal(actual.TimestampField))
+	assert.True(t, expected.DateField.Equal(actual.DateField))
+
+	expected.TimestampField = time.Time{}
+	actual.TimestampField = time.Time{}
+	expected.DateField = time.Time{}
+	actual.DateField = time.Time{}
+
+	if expected.OptionalTimestampField != nil {
+		assert.NotNil(t, actual.OptionalTimestampField)
+		assert.True(t, expected.OptionalTimestampField.Equal(*actual.OptionalTimestampField))
+
+		expected.OptionalTimestampField = nil
+		actual.OptionalTimestampField = nil
+	}
+
+	assert.Equal(t, expected, actual)
+}
+
+func BenchmarkAvroJsonCodec(b *testing.B) {
+	avroJsonCodec, err := codec.NewAvroJsonCodec(avro.GetTestAvroSchemaDefinition(b))
+	assert.NoError(b, err)
+
+	in := avro.SimpleRecord{
+		StringField:  "test",
+		FloatField:   12.34,
+		BooleanField: true,
+	}
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		enc, err := avroJsonCodec.Encode(in)
+		if err != nil {
+			b.Fatal(err)
+		}
+
+		out := avro.SimpleRecord{}
+
+		err = avroJsonCodec.Decode(enc, &out)
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkAvroJsonMapBaseline measures the former AvroJsonCodec path on the same schema, converting values through
+// a map with encoding/json before the goavro textual conversion, as a baseline for BenchmarkAvroJsonCodec.
+func BenchmarkAvroJsonMapBaseline(b *testing.B) {
+	goavroCodec, err := goavro.NewCodec(avro.GetTestAvroSchemaDefinition(b))
+	assert.NoError(b, err)
+
+	in := avro.SimpleRecord{
+		StringField:  "test",
+		FloatField:   12.34,
+		BooleanField: true,
+	}
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		jsonIn, err := json.Marshal(in)
+		if err != nil {
+			b.Fatal(err)
+		}
+
+		var inMap map[string]interface{}
+
+		err = json.Unmarshal(jsonIn, &inMap)
+		if err != nil {
+			b.Fatal(err)
+		}
+
+		enc, err := goavroCodec.TextualFromNative(nil, inMap)
+		if err != nil {
+			b.Fatal(err)
+		}
+
+		native, _, err := goavroCodec.NativeFromTextual(enc)
+		if err != nil {
+			b.Fatal(err)
+		}
+
+		jsonOut, err := json.Marshal(native)
+		if err != nil {
+			b.Fatal(err)
+		}
+
+		out := avro.SimpleRecord{}
+
+		err = json.Unmarshal(jsonOut, &out)
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,373 @@
+package codec
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// CloudEventsSpecVersion is the supported CloudEvents specification version.
+	CloudEventsSpecVersion = "1.0"
+	// CloudEventsContentType is the content type of CloudEvents in structured mode.
+	CloudEventsContentType = "application/cloudevents+json"
+	// CloudEventsAttributePrefix is the prefix of the CloudEvents attributes in binary mode.
+	CloudEventsAttributePrefix = "ce-"
+	// ContentTypeAttribute is the message attribute carrying the content type.
+	ContentTypeAttribute = "Content-Type"
+)
+
+// CloudEventsMode is the CloudEvents content mode, as defined by the CloudEvents Pub/Sub protocol binding.
+type CloudEventsMode string
+
+const (
+	// CloudEventsStructuredMode carries the whole event in the message data.
+	CloudEventsStructuredMode CloudEventsMode = "structured"
+	// CloudEventsBinaryMode carries the event data in the message data, and the event attributes in the message attributes.
+	CloudEventsBinaryMode CloudEventsMode = "binary"
+)
+
+var (
+	_ Codec           = (*CloudEventsCodec)(nil)
+	_ AttributesCodec = (*CloudEventsCodec)(nil)
+)
+
+// CloudEvent represents a CloudEvents event, with json data.
+type CloudEvent struct {
+	ID              string
+	Source          string
+	SpecVersion     string
+	Type            string
+	DataContentType string
+	DataSchema      string
+	Subject         string
+	Time            time.Time
+	Extensions      map[string]string
+	Data            any
+}
+
+// DecodeData decodes the event json data into the provided parameter.
+func (e *CloudEvent) DecodeData(out any) error {
+	data, err := e.data()
+	if err != nil {
+		return err
+	}
+
+	err = json.Unmarshal(data, out)
+	if err != nil {
+		return fmt.Errorf("cannot decode cloudevent data: %w", err)
+	}
+
+	return nil
+}
+
+func (e *CloudEvent) data() ([]byte, error) {
+	switch d := e.Data.(type) {
+	case nil:
+		return nil, nil
+	case json.RawMessage:
+		return d, nil
+	case []byte:
+		return d, nil
+	default:
+		data, err := json.Marshal(d)
+		if err != nil {
+			return nil, fmt.Errorf("cannot encode cloudevent data: %w", err)
+		}
+
+		return data, nil
+	}
+}
+
+// CloudEventsCodec is a Codec implementation for encoding and decoding CloudEvents, in structured or binary mode.
+//
+// It accepts a CloudEvent (or a pointer to it) to encode, or any other value used as event data with the codec default source and type.
+// It decodes into a CloudEvent pointer, or any other value from the event data.
+type CloudEventsCodec struct {
+	mode      CloudEventsMode
+	source    string
+	eventType string
+}
+
+// NewCloudEventsCodec returns a new CloudEventsCodec instance, for a given mode, and default event source and type.
+func NewCloudEventsCodec(mode CloudEventsMode, source string, eventType string) *CloudEventsCodec {
+	return &CloudEventsCodec{
+		mode:      mode,
+		source:    source,
+		eventType: eventType,
+	}
+}
+
+// Encode encodes the provided input as a CloudEvent.
+//
+// In binary mode, the event attributes are lost: use EncodeWithAttributes instead.
+func (c *CloudEventsCodec) Encode(in any) ([]byte, error) {
+	enc, _, err := c.EncodeWithAttributes(in)
+
+	return enc, err
+}
+
+// Decode decodes the provided input as a CloudEvent.
+//
+// In binary mode, the event attributes are required: use DecodeWithAttributes instead.
+func (c *CloudEventsCodec) Decode(enc []byte, out any) error {
+	return c.DecodeWithAttributes(enc, nil, out)
+}
+
+// EncodeWithAttributes encodes the provided input as a CloudEvent, and returns the message attributes.
+func (c *CloudEventsCodec) EncodeWithAttributes(in any) ([]byte, map[string]string, error) {
+	event, err := c.event(in)
+	if err != nil {
+		return nil, nil, fmt.Errorf("cannot encode cloudevent: %w", err)
+	}
+
+	data, err := event.data()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if c.mode == CloudEventsBinaryMode {
+		attributes := map[string]string{
+			CloudEventsAttributePrefix + "id":          event.ID,
+			CloudEventsAttributePrefix + "source":      event.Source,
+			CloudEventsAttributePrefix + "specversion": event.SpecVersion,
+			CloudEventsAttributePrefix + "type":        event.Type,
+			CloudEventsAttributePrefix + "time":        event.Time.Format(time.RFC3339Nano),
+			ContentTypeAttribute:                       event.DataContentType,
+		}
+
+		setIfNotEmpty(attributes, CloudEventsAttributePrefix+"dataschema", event.DataSchema)
+		setIfNotEmpty(attributes, CloudEventsAttributePrefix+"subject", event.Subject)
+
+		for name, value := range event.Extensions {
+			attributes[CloudEventsAttributePrefix+name] = value
+		}
+
+		return data, attributes, nil
+	}
+
+	structured := map[string]any{
+		"id":              event.ID,
+		"source":          event.Source,
+		"specversion":     event.SpecVersion,
+		"type":            event.Type,
+		"time":            event.Time.Format(time.RFC3339Nano),
+		"datacontenttype": event.DataContentType,
+	}
+
+	for name, value := range event.Extensions {
+		structured[name] = value
+	}
+
+	if event.DataSchema != "" {
+		structured["dataschema"] = event.DataSchema
+	}
+
+	if event.Subject != "" {
+		structured["subject"] = event.Subject
+	}
+
+	if data != nil {
+		structured["data"] = json.RawMessage(data)
+	}
+
+	enc, err := json.Marshal(structured)
+	if err != nil {
+		return nil, nil, fmt.Errorf("cannot encode cloudevent: %w", err)
+	}
+
+	return enc, map[string]string{ContentTypeAttribute: CloudEventsContentType}, nil
+}
+
+// DecodeWithAttributes decodes the provided input and message attributes as a CloudEvent.
+func (c *CloudEventsCodec) DecodeWithAttributes(enc []byte, attributes map[string]string, out any) error {
+	var event *CloudEvent
+	var err error
+
+	if c.mode == CloudEventsBinaryMode {
+		event, err = c.decodeBinary(enc, attributes)
+	} else {
+		event, err = c.decodeStructured(enc)
+	}
+
+	if err != nil {
+		return fmt.Errorf("cannot decode cloudevent: %w", err)
+	}
+
+	if eventOut, ok := out.(*CloudEvent); ok {
+		*eventOut = *event
+
+		return nil
+	}
+
+	return event.DecodeData(out)
+}
+
+func (c *CloudEventsCodec) event(in any) (*CloudEvent, error) {
+	var event CloudEvent
+
+	switch e := in.(type) {
+	case CloudEvent:
+		event = e
+	case *CloudEvent:
+		if e == nil {
+			return nil, fmt.Errorf("nil cloudevent")
+		}
+
+		event = *e
+	default:
+		event = CloudEvent{Data: in}
+	}
+
+	if event.ID == "" {
+		event.ID = uuid.NewString()
+	}
+
+	if event.Source == "" {
+		event.Source = c.source
+	}
+
+	if event.Type == "" {
+		event.Type = c.eventType
+	}
+
+	if event.SpecVersion == "" {
+		event.SpecVersion = CloudEventsSpecVersion
+	}
+
+	if event.DataContentType == "" {
+		event.DataContentType = "application/json"
+	}
+
+	if event.Time.IsZero() {
+		event.Time = time.Now().UTC()
+	}
+
+	if event.Source == "" || event.Type == "" {
+		return nil, fmt.Errorf("source and type are required")
+	}
+
+	return &event, nil
+}
+
+func (c *CloudEventsCodec) decodeBinary(enc []byte, attributes map[string]string) (*CloudEvent, error) {
+	event := &CloudEvent{
+		DataContentType: attributes[ContentTypeAttribute],
+		Extensions:      map[string]string{},
+	}
+
+	if len(enc) > 0 {
+		event.Data = json.RawMessage(enc)
+	}
+
+	for name, value := range attributes {
+		attribute, ok := strings.CutPrefix(name, CloudEventsAttributePrefix)
+		if !ok {
+			continue
+		}
+
+		switch attribute {
+		case "id":
+			event.ID = value
+		case "source":
+			event.Source = value
+		case "specversion":
+			event.SpecVersion = value
+		case "type":
+			event.Type = value
+		case "dataschema":
+			event.DataSchema = value
+		case "subject":
+			event.Subject = value
+		case "time":
+			t, err := time.Parse(time.RFC3339Nano, value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid time attribute: %w", err)
+			}
+
+			event.Time = t
+		default:
+			event.Extensions[attribute] = value
+		}
+	}
+
+	return event, c.validate(event)
+}
+
+func (c *CloudEventsCodec) decodeStructured(enc []byte) (*CloudEvent, error) {
+	var structured map[string]json.RawMessage
+
+	err := json.Unmarshal(enc, &structured)
+	if err != nil {
+		return nil, fmt.Errorf("invalid json: %w", err)
+	}
+
+	event := &CloudEvent{
+		Extensions: map[string]string{},
+	}
+
+	for name, raw := range structured {
+		if name == "data" {
+			event.Data = raw
+
+			continue
+		}
+
+		var value string
+
+		err = json.Unmarshal(raw, &value)
+		if err != nil {
+			// extensions can be of any json scalar type
+			value = string(raw)
+		}
+
+		switch name {
+		case "id":
+			event.ID = value
+		case "source":
+			event.Source = value
+		case "specversion":
+			event.SpecVersion = value
+		case "type":
+			event.Type = value
+		case "datacontenttype":
+			event.DataContentType = value
+		case "dataschema":
+			event.DataSchema = value
+		case "subject":
+			event.Subject = value
+		case "time":
+			t, err := time.Parse(time.RFC3339Nano, value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid time: %w", err)
+			}
+
+			event.Time = t
+		default:
+			event.Extensions[name] = value
+		}
+	}
+
+	return event, c.validate(event)
+}
+
+func (c *CloudEventsCodec) validate(event *CloudEvent) error {
+	if event.SpecVersion != CloudEventsSpecVersion {
+		return fmt.Errorf("unsupported specversion %q", event.SpecVersion)
+	}
+
+	if event.ID == "" || event.Source == "" || event.Type == "" {
+		return fmt.Errorf("id, source and type are required")
+	}
+
+	return nil
+}
+
+func setIfNotEmpty(m map[string]string, key string, value string) {
+	if value != "" {
+		m[key] = value
+	}
+}
@@ -0,0 +1,177 @@
+package codec_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/ankorstore/yokai-contrib/fxgcppubsub/codec"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCloudEventsCodec(t *testing.T) {
+	t.Parallel()
+
+	eventTime := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	t.Run("structured mode encoding and decoding of data", func(t *testing.T) {
+		t.Parallel()
+
+		cloudEventsCodec := codec.NewCloudEventsCodec(codec.CloudEventsStructuredMode, "test-source", "test.type")
+
+		in := testJsonRecord{
+			Name: "test",
+			Age:  12,
+		}
+
+		enc, attributes, err := cloudEventsCodec.EncodeWithAttributes(in)
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]string{codec.ContentTypeAttribute: codec.CloudEventsContentType}, attributes)
+
+		var structured map[string]any
+		err = json.Unmarshal(enc, &structured)
+		assert.NoError(t, err)
+
+		assert.Equal(t, "1.0", structured["specversion"])
+		assert.Equal(t, "test-source", structured["source"])
+		assert.Equal(t, "test.type", structured["type"])
+		assert.Equal(t, "application/json", structured["datacontenttype"])
+		assert.NotEmpty(t, structured["id"])
+		assert.NotEmpty(t, structured["time"])
+		assert.Equal(t, map[string]any{"name": "test", "age": float64(12)}, structured["data"])
+
+		out := testJsonRecord{}
+
+		err = cloudEventsCodec.Decode(enc, &out)
+		assert.NoError(t, err)
+
+		assert.Equal(t, in, out)
+	})
+
+	t.Run("structured mode encoding and decoding of event", func(t *testing.T) {
+		t.Parallel()
+
+		cloudEventsCodec := codec.NewCloudEventsCodec(codec.CloudEventsStructuredMode, "test-source", "test.type")
+
+		in := codec.CloudEvent{
+			ID:         "test-id",
+			Source:     "other-source",
+			Type:       "other.type",
+			Subject:    "test-subject",
+			Time:       eventTime,
+			Extensions: map[string]string{"tenant": "test-tenant"},
+			Data:       testJsonRecord{Name: "test", Age: 12},
+		}
+
+		enc, err := cloudEventsCodec.Encode(&in)
+		assert.NoError(t, err)
+
+		out := codec.CloudEvent{}
+
+		err = cloudEventsCodec.Decode(enc, &out)
+		assert.NoError(t, err)
+
+		assert.Equal(t, "test-id", out.ID)
+		assert.Equal(t, "other-source", out.Source)
+		assert.Equal(t, "other.type", out.Type)
+		assert.Equal(t, "1.0", out.SpecVersion)
+		assert.Equal(t, "application/json", out.DataContentType)
+		assert.Equal(t, "test-subject", out.Subject)
+		assert.Equal(t, eventTime, out.Time)
+		assert.Equal(t, map[string]string{"tenant": "test-tenant"}, out.Extensions)
+
+		data := testJsonRecord{}
+
+		err = out.DecodeData(&data)
+		assert.NoError(t, err)
+
+		assert.Equal(t, testJsonRecord{Name: "test", Age: 12}, data)
+	})
+
+	t.Run("binary mode encoding and decoding", func(t *testing.T) {
+		t.Parallel()
+
+		cloudEventsCodec := codec.NewCloudEventsCodec(codec.CloudEventsBinaryMode, "test-source", "test.type")
+
+		in := codec.CloudEvent{
+			ID:         "test-id",
+			Time:       eventTime,
+			Extensions: map[string]string{"tenant": "test-tenant"},
+			Data:       testJsonRecord{Name: "test", Age: 12},
+		}
+
+		enc, attributes, err := codec.EncodeWithAttributes(cloudEventsCodec, in)
+		assert.NoError(t, err)
+
+		assert.Equal(t, `{"name":"test","age":12}`, string(enc))
+		assert.Equal(
+			t,
+			map[string]string{
+				"ce-id":                    "test-id",
+				"ce-source":                "test-source",
+				"ce-specversion":           "1.0",
+				"ce-type":                  "test.type",
+				"ce-time":                  "2024-01-02T03:04:05Z",
+				"ce-tenant":                "test-tenant",
+				codec.ContentTypeAttribute: "application/json",
+			},
+			attributes,
+		)
+
+		out := codec.CloudEvent{}
+
+		err = codec.DecodeWithAttributes(cloudEventsCodec, enc, attributes, &out)
+		assert.NoError(t, err)
+
+		assert.Equal(t, "test-id", out.ID)
+		assert.Equal(t, "test-source", out.Source)
+		assert.Equal(t, "test.type", out.Type)
+		assert.Equal(t, eventTime, out.Time)
+		assert.Equal(t, map[string]string{"tenant": "test-tenant"}, out.Extensions)
+
+		data := testJsonRecord{}
+
+		err = codec.DecodeWithAttributes(cloudEventsCodec, enc, attributes, &data)
+		assert.NoError(t, err)
+
+		assert.Equal(t, testJsonRecord{Name: "test", Age: 12}, data)
+	})
+
+	t.Run("binary mode decoding failure without attributes", func(t *testing.T) {
+		t.Parallel()
+
+		cloudEventsCodec := codec.NewCloudEventsCodec(codec.CloudEventsBinaryMode, "test-source", "test.type")
+
+		out := testJsonRecord{}
+
+		err := cloudEventsCodec.Decode([]byte(`{"name":"test","age":12}`), &out)
+		assert.Error(t, err)
+		assert.Equal(t, `cannot decode cloudevent: unsupported specversion ""`, err.Error())
+	})
+
+	t.Run("encoding failure without source and type", func(t *testing.T) {
+		t.Parallel()
+
+		cloudEventsCodec := codec.NewCloudEventsCodec(codec.CloudEventsStructuredMode, "", "")
+
+		_, err := cloudEventsCodec.Encode(testJsonRecord{Name: "test"})
+		assert.Error(t, err)
+		assert.Equal(t, "cannot encode cloudevent: source and type are required", err.Error())
+	})
+
+	t.Run("structured mode decoding failure", func(t *testing.T) {
+		t.Parallel()
+
+		cloudEventsCodec := codec.NewCloudEventsCodec(codec.CloudEventsStructuredMode, "test-source", "test.type")
+
+		out := codec.CloudEvent{}
+
+		err := cloudEventsCodec.Decode([]byte("invalid"), &out)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "cannot decode cloudevent: invalid json")
+
+		err = cloudEventsCodec.Decode([]byte(`{"specversion":"1.0","id":"test-id"}`), &out)
+		assert.Error(t, err)
+		assert.Equal(t, "cannot decode cloudevent: id, source and type are required", err.Error())
+	})
+}
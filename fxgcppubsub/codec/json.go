@@ -0,0 +1,141 @@
+package codec
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+)
+
+var (
+	_ Codec            = (*JsonCodec)(nil)
+	_ TypeCheckerCodec = (*JsonCodec)(nil)
+
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// JsonCodec is a Codec implementation for encoding and decoding in json, with optional JSON Schema validation.
+type JsonCodec struct {
+	schema *jsonschema.Schema
+}
+
+// NewJsonCodec returns a new JsonCodec instance, without JSON Schema validation.
+func NewJsonCodec() *JsonCodec {
+	return &JsonCodec{}
+}
+
+// NewJsonSchemaCodec returns a new JsonCodec instance, validating encoded and decoded data against the provided JSON Schema.
+func NewJsonSchemaCodec(schemaDefinition string) (*JsonCodec, error) {
+	schema, err := jsonschema.CompileString("schema.json", schemaDefinition)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse json schema: %w", err)
+	}
+
+	return &JsonCodec{schema: schema}, nil
+}
+
+// Encode encodes in json, and validates the result if a JSON Schema is configured.
+func (c *JsonCodec) Encode(in any) ([]byte, error) {
+	out, err := json.Marshal(in)
+	if err != nil {
+		return nil, fmt.Errorf("cannot encode json: %w", err)
+	}
+
+	err = c.validate(out)
+	if err != nil {
+		return nil, fmt.Errorf("cannot encode json: %w", err)
+	}
+
+	return out, nil
+}
+
+// Decode validates the provided input if a JSON Schema is configured, and decodes it from json.
+func (c *JsonCodec) Decode(enc []byte, out any) error {
+	err := c.validate(enc)
+	if err != nil {
+		return fmt.Errorf("cannot decode json: %w", err)
+	}
+
+	err = json.Unmarshal(enc, out)
+	if err != nil {
+		return fmt.Errorf("cannot decode json: %w", err)
+	}
+
+	return nil
+}
+
+// CheckType checks that the provided Go type can be encoded in json.
+//
+// The JSON Schema constraints are validated on the encoded and decoded data only, since they depend on the values.
+func (c *JsonCodec) CheckType(typ reflect.Type) error {
+	err := jsonCheckType(typ, make(map[reflect.Type]bool))
+	if err != nil {
+		return fmt.Errorf("invalid json type: %w", err)
+	}
+
+	return nil
+}
+
+func (c *JsonCodec) validate(data []byte) error {
+	if c.schema == nil {
+		return nil
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var v any
+
+	err := decoder.Decode(&v)
+	if err != nil {
+		return fmt.Errorf("invalid json: %w", err)
+	}
+
+	err = c.schema.Validate(v)
+	if err != nil {
+		return fmt.Errorf("json schema validation failure: %w", err)
+	}
+
+	return nil
+}
+
+//nolint:exhaustive
+func jsonCheckType(typ reflect.Type, seen map[reflect.Type]bool) error {
+	if seen[typ] || typ.Implements(jsonMarshalerType) || reflect.PointerTo(typ).Implements(jsonMarshalerType) {
+		return nil
+	}
+
+	seen[typ] = true
+
+	switch typ.Kind() {
+	case reflect.Chan, reflect.Func, reflect.Complex64, reflect.Complex128, reflect.UnsafePointer:
+		return fmt.Errorf("unsupported type %s", typ)
+	case reflect.Pointer, reflect.Slice, reflect.Array:
+		return jsonCheckType(typ.Elem(), seen)
+	case reflect.Map:
+		key := typ.Key()
+		if key.Kind() != reflect.String && (key.Kind() < reflect.Int || key.Kind() > reflect.Uintptr) && !key.Implements(textMarshalerType) {
+			return fmt.Errorf("unsupported map key type %s", key)
+		}
+
+		return jsonCheckType(typ.Elem(), seen)
+	case reflect.Struct:
+		for i := 0; i < typ.NumField(); i++ {
+			field := typ.Field(i)
+			if !field.IsExported() || field.Tag.Get("json") == "-" {
+				continue
+			}
+
+			err := jsonCheckType(field.Type, seen)
+			if err != nil {
+				return fmt.Errorf("field %s: %w", field.Name, err)
+			}
+		}
+	}
+
+	return nil
+}
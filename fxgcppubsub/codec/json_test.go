@@ -0,0 +1,143 @@
+package codec_test
+
+import (
+	"testing"
+
+	"github.com/ankorstore/yokai-contrib/fxgcppubsub/codec"
+	"github.com/stretchr/testify/assert"
+)
+
+const testJsonSchema = `{
+  "type": "object",
+  "properties": {
+    "name": {"type": "string"},
+    "age": {"type": "integer", "minimum": 0}
+  },
+  "required": ["name"]
+}`
+
+type testJsonRecord struct {
+	Name string `json:"name,omitempty"`
+	Age  int    `json:"age"`
+}
+
+func TestJsonCodec(t *testing.T) {
+	t.Parallel()
+
+	t.Run("json encoding and decoding success", func(t *testing.T) {
+		t.Parallel()
+
+		jsonCodec := codec.NewJsonCodec()
+
+		in := testJsonRecord{
+			Name: "test",
+			Age:  12,
+		}
+
+		enc, err := jsonCodec.Encode(in)
+		assert.NoError(t, err)
+		assert.Equal(t, `{"name":"test","age":12}`, string(enc))
+
+		out := testJsonRecord{}
+
+		err = jsonCodec.Decode(enc, &out)
+		assert.NoError(t, err)
+
+		assert.Equal(t, in, out)
+	})
+
+	t.Run("json encoding failure", func(t *testing.T) {
+		t.Parallel()
+
+		jsonCodec := codec.NewJsonCodec()
+
+		_, err := jsonCodec.Encode(func() {})
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "cannot encode json")
+	})
+
+	t.Run("json decoding failure", func(t *testing.T) {
+		t.Parallel()
+
+		jsonCodec := codec.NewJsonCodec()
+
+		out := testJsonRecord{}
+
+		err := jsonCodec.Decode([]byte("invalid"), &out)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "cannot decode json")
+	})
+}
+
+func TestJsonSchemaCodec(t *testing.T) {
+	t.Parallel()
+
+	t.Run("json schema encoding and decoding success", func(t *testing.T) {
+		t.Parallel()
+
+		jsonCodec, err := codec.NewJsonSchemaCodec(testJsonSchema)
+		assert.NoError(t, err)
+
+		in := testJsonRecord{
+			Name: "test",
+			Age:  12,
+		}
+
+		enc, err := jsonCodec.Encode(in)
+		assert.NoError(t, err)
+
+		out := testJsonRecord{}
+
+		err = jsonCodec.Decode(enc, &out)
+		assert.NoError(t, err)
+
+		assert.Equal(t, in, out)
+	})
+
+	t.Run("json schema encoding validation failure", func(t *testing.T) {
+		t.Parallel()
+
+		jsonCodec, err := codec.NewJsonSchemaCodec(testJsonSchema)
+		assert.NoError(t, err)
+
+		_, err = jsonCodec.Encode(testJsonRecord{Age: 12})
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "cannot encode json: json schema validation failure")
+		assert.Contains(t, err.Error(), "missing properties: 'name'")
+	})
+
+	t.Run("json schema decoding validation failure", func(t *testing.T) {
+		t.Parallel()
+
+		jsonCodec, err := codec.NewJsonSchemaCodec(testJsonSchema)
+		assert.NoError(t, err)
+
+		out := testJsonRecord{}
+
+		err = jsonCodec.Decode([]byte(`{"name":"test","age":-1}`), &out)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "cannot decode json: json schema validation failure")
+	})
+
+	t.Run("json schema decoding invalid json failure", func(t *testing.T) {
+		t.Parallel()
+
+		jsonCodec, err := codec.NewJsonSchemaCodec(testJsonSchema)
+		assert.NoError(t, err)
+
+		out := testJsonRecord{}
+
+		err = jsonCodec.Decode([]byte("invalid"), &out)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "cannot decode json: invalid json")
+	})
+
+	t.Run("json schema invalid schema", func(t *testing.T) {
+		t.Parallel()
+
+		jsonCodec, err := codec.NewJsonSchemaCodec("invalid")
+		assert.Nil(t, jsonCodec)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "cannot parse json schema")
+	})
+}
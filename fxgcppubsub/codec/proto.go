@@ -2,14 +2,19 @@ package codec
 
 import (
 	"fmt"
+	"reflect"
 
 	"google.golang.org/protobuf/encoding/protojson"
 	"google.golang.org/protobuf/proto"
 )
 
 var (
-	_ Codec = (*ProtoBinaryCodec)(nil)
-	_ Codec = (*ProtoJsonCodec)(nil)
+	_ Codec            = (*ProtoBinaryCodec)(nil)
+	_ TypeCheckerCodec = (*ProtoBinaryCodec)(nil)
+	_ Codec            = (*ProtoJsonCodec)(nil)
+	_ TypeCheckerCodec = (*ProtoJsonCodec)(nil)
+
+	protoMessageType = reflect.TypeOf((*proto.Message)(nil)).Elem()
 )
 
 // ProtoBinaryCodec is a Codec implementation for encoding and decoding with protobuf schema in binary format.
@@ -51,6 +56,11 @@ func (c *ProtoBinaryCodec) Decode(enc []byte, out any) error {
 	return nil
 }
 
+// CheckType checks that the provided Go type is a proto message.
+func (c *ProtoBinaryCodec) CheckType(typ reflect.Type) error {
+	return checkProtoType(typ)
+}
+
 // ProtoJsonCodec is a Codec implementation for encoding and decoding with protobuf schema in json format.
 type ProtoJsonCodec struct{}
 
@@ -88,3 +98,16 @@ func (c *ProtoJsonCodec) Decode(enc []byte, out any) error {
 
 	return nil
 }
+
+// CheckType checks that the provided Go type is a proto message.
+func (c *ProtoJsonCodec) CheckType(typ reflect.Type) error {
+	return checkProtoType(typ)
+}
+
+func checkProtoType(typ reflect.Type) error {
+	if !typ.Implements(protoMessageType) {
+		return fmt.Errorf("invalid proto message: %s does not implement proto.Message", typ)
+	}
+
+	return nil
+}
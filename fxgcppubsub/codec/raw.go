@@ -1,11 +1,19 @@
 package codec
 
-import "fmt"
+import (
+	"errors"
+	"fmt"
+)
 
 var (
 	_ Codec = (*RawCodec)(nil)
 )
 
+// ErrNoCodecConfigured is returned when decoding data without schema, for which no decoding codec is configured.
+var ErrNoCodecConfigured = errors.New(
+	"no codec configured to decode data without schema, set modules.gcppubsub.subscriptions.<subscription id>.codec or the subscription codec label",
+)
+
 // RawCodec is a Codec implementation for encoding and decoding without specified schemas.
 type RawCodec struct{}
 
@@ -19,7 +27,7 @@ func (c *RawCodec) Encode(in any) ([]byte, error) {
 	return []byte(fmt.Sprintf("%s", in)), nil
 }
 
-// Decode returns a error.
+// Decode returns ErrNoCodecConfigured, since raw data cannot be decoded.
 func (c *RawCodec) Decode([]byte, any) error {
-	return fmt.Errorf("data without schema cannot be decoded")
+	return ErrNoCodecConfigured
 }
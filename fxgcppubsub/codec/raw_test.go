@@ -42,6 +42,7 @@ func TestRawCodec(t *testing.T) {
 
 		err := rawCodec.Decode([]byte("test"), struct{}{})
 		assert.Error(t, err)
-		assert.Equal(t, "data without schema cannot be decoded", err.Error())
+		assert.ErrorIs(t, err, codec.ErrNoCodecConfigured)
+		assert.Contains(t, err.Error(), "modules.gcppubsub.subscriptions.<subscription id>.codec")
 	})
 }
@@ -0,0 +1,89 @@
+package codec
+
+import (
+	"fmt"
+	"sort"
+)
+
+const (
+	// CodecLabel is the topic or subscription label used to select a named codec from the CodecRegistry.
+	CodecLabel = "codec"
+	// RawCodecName is the name of the built-in RawCodec.
+	RawCodecName = "raw"
+	// JsonCodecName is the name of the built-in JsonCodec, without JSON Schema validation.
+	JsonCodecName = "json"
+	// CloudEventsCodecName is the name of the built-in CloudEventsCodec in structured mode.
+	CloudEventsCodecName = "cloudevents"
+	// CloudEventsBinaryCodecName is the name of the built-in CloudEventsCodec in binary mode.
+	CloudEventsBinaryCodecName = "cloudevents-binary"
+)
+
+var _ CodecRegistry = (*DefaultCodecRegistry)(nil)
+
+// CodecNameFunc returns the name of the codec to use for a topic or subscription id, or an empty string if not configured.
+type CodecNameFunc func(id string) string
+
+// CodecDefinition is a Codec registered by name in a CodecRegistry.
+type CodecDefinition struct {
+	Name  string
+	Codec Codec
+}
+
+// CodecRegistry is the interface for registries of named codecs, used for topics without schema.
+type CodecRegistry interface {
+	Has(name string) bool
+	Get(name string) (Codec, error)
+	Names() []string
+}
+
+// DefaultCodecRegistry is the default CodecRegistry implementation.
+type DefaultCodecRegistry struct {
+	codecs map[string]Codec
+}
+
+// NewDefaultCodecRegistry returns a new DefaultCodecRegistry instance, for a list of CodecDefinition.
+//
+// The raw and json built-in codecs are always registered, but can be overridden by definitions with the same name.
+func NewDefaultCodecRegistry(definitions ...CodecDefinition) *DefaultCodecRegistry {
+	codecs := map[string]Codec{
+		RawCodecName:  NewRawCodec(),
+		JsonCodecName: NewJsonCodec(),
+	}
+
+	for _, definition := range definitions {
+		codecs[definition.Name] = definition.Codec
+	}
+
+	return &DefaultCodecRegistry{
+		codecs: codecs,
+	}
+}
+
+// Has returns true if a codec is registered for the provided name.
+func (r *DefaultCodecRegistry) Has(name string) bool {
+	_, ok := r.codecs[name]
+
+	return ok
+}
+
+// Get returns the codec registered for the provided name.
+func (r *DefaultCodecRegistry) Get(name string) (Codec, error) {
+	c, ok := r.codecs[name]
+	if !ok {
+		return nil, fmt.Errorf("codec %s not found", name)
+	}
+
+	return c, nil
+}
+
+// Names returns the sorted names of the registered codecs.
+func (r *DefaultCodecRegistry) Names() []string {
+	names := make([]string, 0, len(r.codecs))
+	for name := range r.codecs {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
@@ -0,0 +1,65 @@
+package codec_test
+
+import (
+	"testing"
+
+	"github.com/ankorstore/yokai-contrib/fxgcppubsub/codec"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDefaultCodecRegistry(t *testing.T) {
+	t.Parallel()
+
+	t.Run("built-in codecs", func(t *testing.T) {
+		t.Parallel()
+
+		registry := codec.NewDefaultCodecRegistry()
+
+		assert.Equal(t, []string{codec.JsonCodecName, codec.RawCodecName}, registry.Names())
+
+		assert.True(t, registry.Has(codec.RawCodecName))
+		c, err := registry.Get(codec.RawCodecName)
+		assert.NoError(t, err)
+		assert.IsType(t, &codec.RawCodec{}, c)
+
+		assert.True(t, registry.Has(codec.JsonCodecName))
+		c, err = registry.Get(codec.JsonCodecName)
+		assert.NoError(t, err)
+		assert.IsType(t, &codec.JsonCodec{}, c)
+	})
+
+	t.Run("registered codecs", func(t *testing.T) {
+		t.Parallel()
+
+		cloudEventsCodec := codec.NewCloudEventsCodec(codec.CloudEventsStructuredMode, "source", "type")
+		protoCodec := codec.NewProtoJsonCodec()
+
+		registry := codec.NewDefaultCodecRegistry(
+			codec.CodecDefinition{Name: "custom", Codec: cloudEventsCodec},
+			codec.CodecDefinition{Name: codec.JsonCodecName, Codec: protoCodec},
+		)
+
+		assert.Equal(t, []string{"custom", codec.JsonCodecName, codec.RawCodecName}, registry.Names())
+
+		c, err := registry.Get("custom")
+		assert.NoError(t, err)
+		assert.Same(t, cloudEventsCodec, c)
+
+		c, err = registry.Get(codec.JsonCodecName)
+		assert.NoError(t, err)
+		assert.Same(t, protoCodec, c)
+	})
+
+	t.Run("unknown codec", func(t *testing.T) {
+		t.Parallel()
+
+		registry := codec.NewDefaultCodecRegistry()
+
+		assert.False(t, registry.Has("unknown"))
+
+		c, err := registry.Get("unknown")
+		assert.Nil(t, c)
+		assert.Error(t, err)
+		assert.Equal(t, "codec unknown not found", err.Error())
+	})
+}
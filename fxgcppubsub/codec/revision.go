@@ -0,0 +1,132 @@
+package codec
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+
+	"cloud.google.com/go/pubsub"
+)
+
+var (
+	_ RevisionCodec    = (*RevisionAwareCodec)(nil)
+	_ TypeCheckerCodec = (*RevisionAwareCodec)(nil)
+)
+
+// SchemaRevisionIDAttribute is the message attribute set by pub/sub with the schema revision id the message was validated against.
+const SchemaRevisionIDAttribute = "googclient_schemarevisionid"
+
+// RevisionCodec is the interface for codecs able to decode data encoded with a given schema revision.
+type RevisionCodec interface {
+	Codec
+	DecodeRevision(revisionID string, enc []byte, out any) error
+}
+
+// SchemaRevisionFunc returns the schema definition for a provided revisionID, or for the latest revision if empty,
+// with its resolved revision id.
+type SchemaRevisionFunc func(revisionID string) (resolvedRevisionID string, schemaDefinition string, err error)
+
+// RevisionAwareCodec is a RevisionCodec implementation, encoding with the latest schema revision,
+// and decoding with the schema revision data was encoded with.
+type RevisionAwareCodec struct {
+	factory        CodecFactory
+	schemaType     pubsub.SchemaType
+	schemaEncoding pubsub.SchemaEncoding
+	revisionFunc   SchemaRevisionFunc
+	codecs         map[string]Codec
+	mutex          sync.Mutex
+}
+
+// NewRevisionAwareCodec returns a new RevisionAwareCodec instance, checking that a codec can be created for the latest revision.
+func NewRevisionAwareCodec(
+	factory CodecFactory,
+	schemaType pubsub.SchemaType,
+	schemaEncoding pubsub.SchemaEncoding,
+	revisionFunc SchemaRevisionFunc,
+) (*RevisionAwareCodec, error) {
+	c := &RevisionAwareCodec{
+		factory:        factory,
+		schemaType:     schemaType,
+		schemaEncoding: schemaEncoding,
+		revisionFunc:   revisionFunc,
+		codecs:         make(map[string]Codec),
+	}
+
+	_, err := c.Revision("")
+	if err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// Revision returns the Codec for a provided revisionID, or for the latest revision if empty.
+func (c *RevisionAwareCodec) Revision(revisionID string) (Codec, error) {
+	resolvedRevisionID, schemaDefinition, err := c.revisionFunc(revisionID)
+	if err != nil {
+		return nil, fmt.Errorf("cannot resolve schema revision: %w", err)
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if revisionCodec, found := c.codecs[resolvedRevisionID]; found {
+		return revisionCodec, nil
+	}
+
+	revisionCodec, err := c.factory.Create(c.schemaType, c.schemaEncoding, schemaDefinition)
+	if err != nil {
+		return nil, err
+	}
+
+	c.codecs[resolvedRevisionID] = revisionCodec
+
+	return revisionCodec, nil
+}
+
+// Encode encodes the provided input with the latest schema revision.
+func (c *RevisionAwareCodec) Encode(in any) ([]byte, error) {
+	latestCodec, err := c.Revision("")
+	if err != nil {
+		return nil, err
+	}
+
+	return latestCodec.Encode(in)
+}
+
+// Decode decodes the provided input with the latest schema revision.
+func (c *RevisionAwareCodec) Decode(enc []byte, out any) error {
+	return c.DecodeRevision("", enc, out)
+}
+
+// DecodeRevision decodes the provided input with a provided schema revisionID, or with the latest revision if empty.
+func (c *RevisionAwareCodec) DecodeRevision(revisionID string, enc []byte, out any) error {
+	revisionCodec, err := c.Revision(revisionID)
+	if err != nil {
+		return err
+	}
+
+	return revisionCodec.Decode(enc, out)
+}
+
+// CheckType checks that the provided Go type is compatible with the latest schema revision, if its Codec is a TypeCheckerCodec.
+func (c *RevisionAwareCodec) CheckType(typ reflect.Type) error {
+	latestCodec, err := c.Revision("")
+	if err != nil {
+		return err
+	}
+
+	_, err = CheckType(latestCodec, typ)
+
+	return err
+}
+
+// DecodeWithRevision decodes the provided input with a provided schema revisionID if the Codec is a RevisionCodec,
+// or with a regular Decode otherwise.
+func DecodeWithRevision(c Codec, revisionID string, enc []byte, out any) error {
+	if revisionCodec, ok := c.(RevisionCodec); ok && revisionID != "" {
+		return revisionCodec.DecodeRevision(revisionID, enc, out)
+	}
+
+	return c.Decode(enc, out)
+}
@@ -0,0 +1,107 @@
+package codec_test
+
+import (
+	"fmt"
+	"testing"
+
+	"cloud.google.com/go/pubsub"
+	"github.com/ankorstore/yokai-contrib/fxgcppubsub/codec"
+	"github.com/ankorstore/yokai-contrib/fxgcppubsub/testdata/avro"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRevisionAwareCodec(t *testing.T) {
+	t.Parallel()
+
+	revisions := map[string]string{
+		"rev-1": avro.GetTestAvroSchemaDefinition(t),
+		"rev-2": avro.GetTestAvroSchemaV2Definition(t),
+	}
+
+	newRevisionFunc := func(latest string, calls *int) codec.SchemaRevisionFunc {
+		return func(revisionID string) (string, string, error) {
+			*calls++
+
+			if revisionID == "" {
+				revisionID = latest
+			}
+
+			definition, ok := revisions[revisionID]
+			if !ok {
+				return "", "", fmt.Errorf("revision %s not found", revisionID)
+			}
+
+			return revisionID, definition, nil
+		}
+	}
+
+	t.Run("encoding with latest revision and decoding with provided revision", func(t *testing.T) {
+		t.Parallel()
+
+		calls := 0
+
+		v1Codec, err := codec.NewAvroBinaryCodec(revisions["rev-1"])
+		assert.NoError(t, err)
+
+		c, err := codec.NewRevisionAwareCodec(codec.NewDefaultCodecFactory(), pubsub.SchemaAvro, pubsub.EncodingBinary, newRevisionFunc("rev-2", &calls))
+		assert.NoError(t, err)
+
+		// latest revision
+		enc, err := c.Encode(avro.SimpleRecordV2{StringField: "test", IntField: 12})
+		assert.NoError(t, err)
+
+		outV2 := avro.SimpleRecordV2{}
+
+		err = c.Decode(enc, &outV2)
+		assert.NoError(t, err)
+		assert.Equal(t, avro.SimpleRecordV2{StringField: "test", IntField: 12}, outV2)
+
+		// previous revision
+		enc, err = v1Codec.Encode(avro.SimpleRecord{StringField: "test", FloatField: 12.34, BooleanField: true})
+		assert.NoError(t, err)
+
+		outV1 := avro.SimpleRecordV2{}
+
+		err = codec.DecodeWithRevision(c, "rev-1", enc, &outV1)
+		assert.NoError(t, err)
+		assert.Equal(t, avro.SimpleRecordV2{StringField: "test", FloatField: 12.34, BooleanField: true}, outV1)
+
+		// codecs are cached per revision
+		revisionCodec, err := c.Revision("rev-1")
+		assert.NoError(t, err)
+
+		sameRevisionCodec, err := c.Revision("rev-1")
+		assert.NoError(t, err)
+		assert.Same(t, revisionCodec, sameRevisionCodec)
+
+		// unknown revision
+		err = c.DecodeRevision("rev-3", enc, &outV1)
+		assert.Error(t, err)
+		assert.Equal(t, "cannot resolve schema revision: revision rev-3 not found", err.Error())
+	})
+
+	t.Run("creation failure on latest revision", func(t *testing.T) {
+		t.Parallel()
+
+		calls := 0
+
+		_, err := codec.NewRevisionAwareCodec(codec.NewDefaultCodecFactory(), pubsub.SchemaAvro, pubsub.EncodingBinary, newRevisionFunc("rev-3", &calls))
+		assert.Error(t, err)
+		assert.Equal(t, "cannot resolve schema revision: revision rev-3 not found", err.Error())
+
+		_, err = codec.NewRevisionAwareCodec(codec.NewDefaultCodecFactory(), pubsub.SchemaAvro, pubsub.EncodingUnspecified, newRevisionFunc("rev-1", &calls))
+		assert.Error(t, err)
+		assert.Equal(t, "invalid avro encoding", err.Error())
+	})
+
+	t.Run("decoding with non revision codec", func(t *testing.T) {
+		t.Parallel()
+
+		var out []byte
+
+		err := codec.DecodeWithRevision(codec.NewRawCodec(), "rev-1", []byte("test"), &out)
+		assert.Error(t, err)
+		assert.ErrorIs(t, err, codec.ErrNoCodecConfigured)
+		assert.Contains(t, err.Error(), "modules.gcppubsub.subscriptions.<subscription id>.codec")
+	})
+}
@@ -0,0 +1,21 @@
+package codec
+
+import (
+	"reflect"
+)
+
+// TypeCheckerCodec is the interface for codecs able to check, from their schema, that values of a Go type can be
+// encoded and decoded, without encoding any value.
+type TypeCheckerCodec interface {
+	CheckType(typ reflect.Type) error
+}
+
+// CheckType checks that values of the provided Go type can be encoded and decoded with the Codec if it is a
+// TypeCheckerCodec, and returns false otherwise.
+func CheckType(c Codec, typ reflect.Type) (bool, error) {
+	if checker, ok := c.(TypeCheckerCodec); ok {
+		return true, checker.CheckType(typ)
+	}
+
+	return false, nil
+}
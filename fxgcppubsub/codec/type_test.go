@@ -0,0 +1,178 @@
+package codec_test
+
+import (
+	"math/big"
+	"reflect"
+	"testing"
+
+	"cloud.google.com/go/pubsub"
+	"github.com/ankorstore/yokai-contrib/fxgcppubsub/codec"
+	"github.com/ankorstore/yokai-contrib/fxgcppubsub/testdata/avro"
+	"github.com/ankorstore/yokai-contrib/fxgcppubsub/testdata/proto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckType(t *testing.T) {
+	t.Parallel()
+
+	t.Run("avro codecs", func(t *testing.T) {
+		t.Parallel()
+
+		avroBinaryCodec, err := codec.NewAvroBinaryCodec(avro.GetTestAvroComplexSchemaDefinition(t))
+		assert.NoError(t, err)
+
+		avroJsonCodec, err := codec.NewAvroJsonCodec(avro.GetTestAvroComplexSchemaDefinition(t))
+		assert.NoError(t, err)
+
+		type partialRecord struct {
+			StringField string
+		}
+
+		for _, c := range []codec.Codec{avroBinaryCodec, avroJsonCodec} {
+			checked, err := codec.CheckType(c, reflect.TypeOf(avro.ComplexRecord{}))
+			assert.True(t, checked)
+			assert.NoError(t, err)
+
+			_, err = codec.CheckType(c, reflect.TypeOf(&avro.ComplexRecord{}))
+			assert.NoError(t, err)
+
+			_, err = codec.CheckType(c, reflect.TypeOf(map[string]any{}))
+			assert.NoError(t, err)
+
+			_, err = codec.CheckType(c, reflect.TypeOf(partialRecord{}))
+			assert.Error(t, err)
+			assert.Contains(t, err.Error(), "invalid avro type: missing field IntField")
+		}
+	})
+
+	t.Run("avro codecs with decimal", func(t *testing.T) {
+		t.Parallel()
+
+		avroBinaryCodec, err := codec.NewAvroBinaryCodec(`{
+			"type": "record",
+			"name": "Decimal",
+			"fields": [
+				{"name": "DecimalField", "type": {"type": "bytes", "logicalType": "decimal", "precision": 10, "scale": 2}}
+			]
+		}`)
+		assert.NoError(t, err)
+
+		_, err = codec.CheckType(avroBinaryCodec, reflect.TypeOf(struct{ DecimalField *big.Rat }{}))
+		assert.NoError(t, err)
+
+		_, err = codec.CheckType(avroBinaryCodec, reflect.TypeOf(struct{ DecimalField float64 }{}))
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "field DecimalField: cannot use float64 as avro decimal")
+	})
+
+	t.Run("avro codecs with simple schema", func(t *testing.T) {
+		t.Parallel()
+
+		avroBinaryCodec, err := codec.NewAvroBinaryCodec(avro.GetTestAvroSchemaDefinition(t))
+		assert.NoError(t, err)
+
+		_, err = codec.CheckType(avroBinaryCodec, reflect.TypeOf(avro.SimpleRecord{}))
+		assert.NoError(t, err)
+
+		_, err = codec.CheckType(avroBinaryCodec, reflect.TypeOf(avro.InvalidSimpleRecord{}))
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "field StringField: cannot use bool as avro string")
+
+		_, err = codec.CheckType(avroBinaryCodec, reflect.TypeOf(""))
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "cannot use string as avro record")
+	})
+
+	t.Run("avro codecs with recursive schema", func(t *testing.T) {
+		t.Parallel()
+
+		type node struct {
+			Value int64 `avro:"value"`
+			Next  *node `avro:"next"`
+		}
+
+		avroBinaryCodec, err := codec.NewAvroBinaryCodec(`{
+			"type": "record",
+			"name": "Node",
+			"fields": [
+				{"name": "value", "type": "long"},
+				{"name": "next", "type": ["null", "Node"], "default": null}
+			]
+		}`)
+		assert.NoError(t, err)
+
+		_, err = codec.CheckType(avroBinaryCodec, reflect.TypeOf(node{}))
+		assert.NoError(t, err)
+	})
+
+	t.Run("proto codecs", func(t *testing.T) {
+		t.Parallel()
+
+		for _, c := range []codec.Codec{codec.NewProtoBinaryCodec(), codec.NewProtoJsonCodec()} {
+			checked, err := codec.CheckType(c, reflect.TypeOf(&proto.SimpleRecord{}))
+			assert.True(t, checked)
+			assert.NoError(t, err)
+
+			_, err = codec.CheckType(c, reflect.TypeOf(avro.SimpleRecord{}))
+			assert.Error(t, err)
+			assert.Contains(t, err.Error(), "invalid proto message")
+		}
+	})
+
+	t.Run("json codec with schema constraints", func(t *testing.T) {
+		t.Parallel()
+
+		jsonCodec, err := codec.NewJsonSchemaCodec(`{
+			"type": "object",
+			"required": ["StringField"],
+			"properties": {
+				"StringField": {"type": "string", "minLength": 1}
+			}
+		}`)
+		assert.NoError(t, err)
+
+		// the zero value does not validate, but the type is compatible
+		_, err = jsonCodec.Encode(avro.SimpleRecord{})
+		assert.Error(t, err)
+
+		checked, err := codec.CheckType(jsonCodec, reflect.TypeOf(avro.SimpleRecord{}))
+		assert.True(t, checked)
+		assert.NoError(t, err)
+
+		_, err = codec.CheckType(jsonCodec, reflect.TypeOf(big.Rat{}))
+		assert.NoError(t, err)
+
+		_, err = codec.CheckType(jsonCodec, reflect.TypeOf(struct{ Channel chan int }{}))
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid json type: field Channel: unsupported type chan int")
+	})
+
+	t.Run("revision aware codec", func(t *testing.T) {
+		t.Parallel()
+
+		c, err := codec.NewRevisionAwareCodec(
+			codec.NewDefaultCodecFactory(),
+			pubsub.SchemaAvro,
+			pubsub.EncodingBinary,
+			func(string) (string, string, error) {
+				return "rev-1", avro.GetTestAvroSchemaDefinition(t), nil
+			},
+		)
+		assert.NoError(t, err)
+
+		checked, err := codec.CheckType(c, reflect.TypeOf(avro.SimpleRecord{}))
+		assert.True(t, checked)
+		assert.NoError(t, err)
+
+		_, err = codec.CheckType(c, reflect.TypeOf(avro.InvalidSimpleRecord{}))
+		assert.Error(t, err)
+	})
+
+	t.Run("codec without type check", func(t *testing.T) {
+		t.Parallel()
+
+		checked, err := codec.CheckType(codec.NewRawCodec(), reflect.TypeOf(avro.SimpleRecord{}))
+		assert.False(t, checked)
+		assert.NoError(t, err)
+	})
+}
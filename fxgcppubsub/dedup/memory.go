@@ -0,0 +1,112 @@
+package dedup
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+var _ DedupStore = (*MemoryDedupStore)(nil)
+
+// DefaultMemoryEvictionInterval is the default minimum interval between the evictions of all the expired keys.
+const DefaultMemoryEvictionInterval = 1 * time.Minute
+
+type memoryEntry struct {
+	expiration time.Time
+	processed  bool
+}
+
+// MemoryDedupStore is an in memory DedupStore implementation, not shared between application instances.
+//
+// Expired keys are evicted when accessed, and all of them at most once per eviction interval.
+type MemoryDedupStore struct {
+	entries   map[string]memoryEntry
+	interval  time.Duration
+	lastEvict time.Time
+	mutex     sync.Mutex
+}
+
+// NewMemoryDedupStore returns a new MemoryDedupStore instance, evicting all the expired keys at most once per
+// DefaultMemoryEvictionInterval.
+func NewMemoryDedupStore() *MemoryDedupStore {
+	return &MemoryDedupStore{
+		entries:   make(map[string]memoryEntry),
+		interval:  DefaultMemoryEvictionInterval,
+		lastEvict: time.Now(),
+	}
+}
+
+// WithEvictionInterval sets the minimum interval between the evictions of all the expired keys.
+func (s *MemoryDedupStore) WithEvictionInterval(interval time.Duration) *MemoryDedupStore {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.interval = interval
+
+	return s
+}
+
+// Reserve reserves the key for a provided ttl if not existing (or expired), or returns the status of the existing one.
+func (s *MemoryDedupStore) Reserve(_ context.Context, key string, ttl time.Duration) (Status, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	now := time.Now()
+
+	s.evict(now)
+
+	if entry, ok := s.entries[key]; ok && now.Before(entry.expiration) {
+		if entry.processed {
+			return StatusProcessed, nil
+		}
+
+		return StatusInProgress, nil
+	}
+
+	s.entries[key] = memoryEntry{expiration: now.Add(ttl)}
+
+	return StatusReserved, nil
+}
+
+// Save saves the key as processed for a provided ttl.
+func (s *MemoryDedupStore) Save(_ context.Context, key string, ttl time.Duration) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.entries[key] = memoryEntry{expiration: time.Now().Add(ttl), processed: true}
+
+	return nil
+}
+
+// Delete deletes the key.
+func (s *MemoryDedupStore) Delete(_ context.Context, key string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	delete(s.entries, key)
+
+	return nil
+}
+
+// Len returns the number of stored keys, including the expired ones not evicted yet.
+func (s *MemoryDedupStore) Len() int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return len(s.entries)
+}
+
+// evict evicts all the expired keys, if the eviction interval elapsed since the last eviction.
+func (s *MemoryDedupStore) evict(now time.Time) {
+	if now.Sub(s.lastEvict) < s.interval {
+		return
+	}
+
+	for key, entry := range s.entries {
+		if !now.Before(entry.expiration) {
+			delete(s.entries, key)
+		}
+	}
+
+	s.lastEvict = now
+}
@@ -0,0 +1,94 @@
+package dedup_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ankorstore/yokai-contrib/fxgcppubsub/dedup"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryDedupStore(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	t.Run("reserve, save and delete", func(t *testing.T) {
+		t.Parallel()
+
+		store := dedup.NewMemoryDedupStore()
+
+		status, err := store.Reserve(ctx, "key", time.Hour)
+		assert.NoError(t, err)
+		assert.Equal(t, dedup.StatusReserved, status)
+
+		status, err = store.Reserve(ctx, "key", time.Hour)
+		assert.NoError(t, err)
+		assert.Equal(t, dedup.StatusInProgress, status)
+
+		err = store.Save(ctx, "key", time.Hour)
+		assert.NoError(t, err)
+
+		status, err = store.Reserve(ctx, "key", time.Hour)
+		assert.NoError(t, err)
+		assert.Equal(t, dedup.StatusProcessed, status)
+
+		err = store.Delete(ctx, "key")
+		assert.NoError(t, err)
+
+		status, err = store.Reserve(ctx, "key", time.Hour)
+		assert.NoError(t, err)
+		assert.Equal(t, dedup.StatusReserved, status)
+	})
+
+	t.Run("expiration", func(t *testing.T) {
+		t.Parallel()
+
+		store := dedup.NewMemoryDedupStore()
+
+		status, err := store.Reserve(ctx, "reserved-key", time.Millisecond)
+		assert.NoError(t, err)
+		assert.Equal(t, dedup.StatusReserved, status)
+
+		err = store.Save(ctx, "processed-key", time.Millisecond)
+		assert.NoError(t, err)
+
+		time.Sleep(5 * time.Millisecond)
+
+		status, err = store.Reserve(ctx, "reserved-key", time.Hour)
+		assert.NoError(t, err)
+		assert.Equal(t, dedup.StatusReserved, status)
+
+		status, err = store.Reserve(ctx, "processed-key", time.Hour)
+		assert.NoError(t, err)
+		assert.Equal(t, dedup.StatusReserved, status)
+	})
+
+	t.Run("eviction", func(t *testing.T) {
+		t.Parallel()
+
+		store := dedup.NewMemoryDedupStore().WithEvictionInterval(50 * time.Millisecond)
+
+		for _, key := range []string{"key-1", "key-2", "key-3"} {
+			err := store.Save(ctx, key, time.Millisecond)
+			assert.NoError(t, err)
+		}
+
+		err := store.Save(ctx, "key-4", time.Hour)
+		assert.NoError(t, err)
+
+		// expired keys are kept until the eviction interval elapsed
+		status, err := store.Reserve(ctx, "other-key", time.Hour)
+		assert.NoError(t, err)
+		assert.Equal(t, dedup.StatusReserved, status)
+		assert.Equal(t, 5, store.Len())
+
+		time.Sleep(60 * time.Millisecond)
+
+		status, err = store.Reserve(ctx, "another-key", time.Hour)
+		assert.NoError(t, err)
+		assert.Equal(t, dedup.StatusReserved, status)
+		assert.Equal(t, 3, store.Len())
+	})
+}
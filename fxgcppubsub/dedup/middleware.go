@@ -0,0 +1,185 @@
+package dedup
+
+import (
+	"context"
+	"time"
+
+	"github.com/ankorstore/yokai-contrib/fxgcppubsub/message"
+	"github.com/ankorstore/yokai-contrib/fxgcppubsub/subscription"
+	"github.com/ankorstore/yokai/log"
+)
+
+var _ subscription.Middleware = (*DedupMiddleware)(nil)
+
+const (
+	// DefaultTTL is the default duration during which a processed message is considered as duplicated.
+	DefaultTTL = 10 * time.Minute
+	// DefaultReservationTTL is the default duration during which a message being processed is reserved.
+	DefaultReservationTTL = 1 * time.Minute
+)
+
+// Options represents the DedupMiddleware options.
+type Options struct {
+	TTL            time.Duration
+	ReservationTTL time.Duration
+	AttributeKey   string
+}
+
+// DefaultDedupOptions returns the default DedupMiddleware options.
+func DefaultDedupOptions() Options {
+	return Options{
+		TTL:            DefaultTTL,
+		ReservationTTL: DefaultReservationTTL,
+	}
+}
+
+// DedupOption represents the DedupMiddleware functional options.
+type DedupOption func(o *Options)
+
+// WithTTL sets the duration during which a processed message is considered as duplicated.
+func WithTTL(ttl time.Duration) DedupOption {
+	return func(o *Options) {
+		o.TTL = ttl
+	}
+}
+
+// WithReservationTTL sets the duration during which a message being processed is reserved, its duplicates being nacked.
+func WithReservationTTL(ttl time.Duration) DedupOption {
+	return func(o *Options) {
+		o.ReservationTTL = ttl
+	}
+}
+
+// WithAttributeKey sets the message attribute to use as deduplication key, instead of the message id.
+func WithAttributeKey(key string) DedupOption {
+	return func(o *Options) {
+		o.AttributeKey = key
+	}
+}
+
+// DedupMiddleware is a subscription.Middleware acking, without processing them, the messages already processed within a TTL.
+//
+// Before processing, a message key (the message id, or the configured attribute value) is atomically reserved in the
+// DedupStore for the reservation TTL, namespaced by subscription, so concurrent duplicates are nacked to be redelivered.
+// A message is considered as processed once acked: its key is then saved for the TTL, and its duplicates are acked without
+// processing. Messages that are nacked release their reservation, to be redelivered and processed again.
+// On DedupStore errors, messages are processed anyway.
+type DedupMiddleware struct {
+	store   DedupStore
+	logger  *log.Logger
+	options Options
+}
+
+// NewDedupMiddleware returns a new DedupMiddleware instance.
+func NewDedupMiddleware(store DedupStore, logger *log.Logger, options ...DedupOption) *DedupMiddleware {
+	dedupOptions := DefaultDedupOptions()
+	for _, applyOpt := range options {
+		applyOpt(&dedupOptions)
+	}
+
+	if dedupOptions.TTL <= 0 {
+		dedupOptions.TTL = DefaultTTL
+	}
+
+	if dedupOptions.ReservationTTL <= 0 {
+		dedupOptions.ReservationTTL = DefaultReservationTTL
+	}
+
+	return &DedupMiddleware{
+		store:   store,
+		logger:  logger,
+		options: dedupOptions,
+	}
+}
+
+// Options returns the middleware options.
+func (mw *DedupMiddleware) Options() Options {
+	return mw.options
+}
+
+// Wrap wraps the next subscription.SubscribeFunc.
+func (mw *DedupMiddleware) Wrap(next subscription.SubscribeFunc) subscription.SubscribeFunc {
+	return func(ctx context.Context, m *message.Message) {
+		subscriptionID := subscription.CtxSubscriptionID(ctx)
+
+		key := subscriptionID + ":" + mw.messageKey(m)
+
+		status, err := mw.store.Reserve(ctx, key, mw.options.ReservationTTL)
+		if err != nil {
+			mw.logger.
+				Warn().
+				Err(err).
+				Str("subscriptionID", subscriptionID).
+				Str("messageID", m.ID()).
+				Msg("pubsub message dedup reservation error, processing anyway")
+
+			status = StatusReserved
+		}
+
+		switch status {
+		case StatusProcessed:
+			mw.logger.
+				Debug().
+				Str("subscriptionID", subscriptionID).
+				Str("messageID", m.ID()).
+				Str("dedupKey", key).
+				Msg("pubsub message duplicate skipped")
+
+			m.Ack()
+
+			return
+		case StatusInProgress:
+			mw.logger.
+				Debug().
+				Str("subscriptionID", subscriptionID).
+				Str("messageID", m.ID()).
+				Str("dedupKey", key).
+				Msg("pubsub message duplicate in progress, nacked")
+
+			m.Nack()
+
+			return
+		case StatusReserved:
+		}
+
+		storeCtx := context.WithoutCancel(ctx)
+
+		m.OnAcknowledgement(func(ack bool) {
+			if !ack {
+				err := mw.store.Delete(storeCtx, key)
+				if err != nil {
+					mw.logger.
+						Warn().
+						Err(err).
+						Str("subscriptionID", subscriptionID).
+						Str("messageID", m.ID()).
+						Msg("pubsub message dedup release error")
+				}
+
+				return
+			}
+
+			err := mw.store.Save(storeCtx, key, mw.options.TTL)
+			if err != nil {
+				mw.logger.
+					Warn().
+					Err(err).
+					Str("subscriptionID", subscriptionID).
+					Str("messageID", m.ID()).
+					Msg("pubsub message dedup save error")
+			}
+		})
+
+		next(ctx, m)
+	}
+}
+
+func (mw *DedupMiddleware) messageKey(m *message.Message) string {
+	if mw.options.AttributeKey != "" {
+		if value, ok := m.Attributes()[mw.options.AttributeKey]; ok && value != "" {
+			return value
+		}
+	}
+
+	return m.ID()
+}
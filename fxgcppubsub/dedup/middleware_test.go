@@ -0,0 +1,216 @@
+package dedup_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/pubsub"
+	"github.com/ankorstore/yokai-contrib/fxgcppubsub/codec"
+	"github.com/ankorstore/yokai-contrib/fxgcppubsub/dedup"
+	"github.com/ankorstore/yokai-contrib/fxgcppubsub/message"
+	"github.com/ankorstore/yokai-contrib/fxgcppubsub/subscription"
+	"github.com/ankorstore/yokai/log"
+	"github.com/ankorstore/yokai/log/logtest"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+)
+
+type errorDedupStore struct{}
+
+func (s *errorDedupStore) Reserve(context.Context, string, time.Duration) (dedup.Status, error) {
+	return dedup.StatusInProgress, assert.AnError
+}
+
+func (s *errorDedupStore) Save(context.Context, string, time.Duration) error {
+	return assert.AnError
+}
+
+func (s *errorDedupStore) Delete(context.Context, string) error {
+	return assert.AnError
+}
+
+func TestDedupMiddleware(t *testing.T) {
+	t.Parallel()
+
+	ctx := subscription.ContextWithSubscriptionID(context.Background(), "test-subscription")
+
+	newMessage := func(id string, attributes map[string]string) *message.Message {
+		return message.NewMessage(codec.NewRawCodec(), &pubsub.Message{ID: id, Attributes: attributes})
+	}
+
+	t.Run("with defaults", func(t *testing.T) {
+		t.Parallel()
+
+		logBuffer := logtest.NewDefaultTestLogBuffer()
+		logger, err := log.NewDefaultLoggerFactory().Create(
+			log.WithLevel(zerolog.DebugLevel),
+			log.WithOutputWriter(logBuffer),
+		)
+		assert.NoError(t, err)
+
+		mw := dedup.NewDedupMiddleware(dedup.NewMemoryDedupStore(), logger)
+		assert.Equal(t, dedup.DefaultTTL, mw.Options().TTL)
+		assert.Equal(t, dedup.DefaultReservationTTL, mw.Options().ReservationTTL)
+		assert.Equal(t, "", mw.Options().AttributeKey)
+
+		processed := 0
+
+		f := mw.Wrap(func(ctx context.Context, m *message.Message) {
+			processed++
+
+			if m.ID() == "nacked-id" {
+				m.Nack()
+			} else {
+				m.Ack()
+			}
+		})
+
+		// processed once
+		f(ctx, newMessage("test-id", nil))
+		f(ctx, newMessage("test-id", nil))
+		assert.Equal(t, 1, processed)
+
+		// other subscription
+		f(subscription.ContextWithSubscriptionID(context.Background(), "other-subscription"), newMessage("test-id", nil))
+		assert.Equal(t, 2, processed)
+
+		// nacked messages are redelivered
+		f(ctx, newMessage("nacked-id", nil))
+		f(ctx, newMessage("nacked-id", nil))
+		assert.Equal(t, 4, processed)
+
+		logtest.AssertHasLogRecord(t, logBuffer, map[string]interface{}{
+			"level":          "debug",
+			"subscriptionID": "test-subscription",
+			"messageID":      "test-id",
+			"dedupKey":       "test-subscription:test-id",
+			"message":        "pubsub message duplicate skipped",
+		})
+	})
+
+	t.Run("with attribute key and ttl", func(t *testing.T) {
+		t.Parallel()
+
+		logger, err := log.NewDefaultLoggerFactory().Create()
+		assert.NoError(t, err)
+
+		mw := dedup.NewDedupMiddleware(
+			dedup.NewMemoryDedupStore(),
+			logger,
+			dedup.WithAttributeKey("eventID"),
+			dedup.WithTTL(10*time.Millisecond),
+		)
+
+		processed := 0
+
+		f := mw.Wrap(func(ctx context.Context, m *message.Message) {
+			processed++
+
+			m.Ack()
+		})
+
+		f(ctx, newMessage("id-1", map[string]string{"eventID": "event"}))
+		f(ctx, newMessage("id-2", map[string]string{"eventID": "event"}))
+		assert.Equal(t, 1, processed)
+
+		// fallback on message id
+		f(ctx, newMessage("id-3", nil))
+		f(ctx, newMessage("id-3", nil))
+		assert.Equal(t, 2, processed)
+
+		// expired
+		time.Sleep(20 * time.Millisecond)
+
+		f(ctx, newMessage("id-4", map[string]string{"eventID": "event"}))
+		assert.Equal(t, 3, processed)
+	})
+
+	t.Run("with duplicate in progress", func(t *testing.T) {
+		t.Parallel()
+
+		logBuffer := logtest.NewDefaultTestLogBuffer()
+		logger, err := log.NewDefaultLoggerFactory().Create(
+			log.WithLevel(zerolog.DebugLevel),
+			log.WithOutputWriter(logBuffer),
+		)
+		assert.NoError(t, err)
+
+		mw := dedup.NewDedupMiddleware(dedup.NewMemoryDedupStore(), logger, dedup.WithReservationTTL(time.Hour))
+		assert.Equal(t, time.Hour, mw.Options().ReservationTTL)
+
+		processed := 0
+
+		var f subscription.SubscribeFunc
+
+		f = mw.Wrap(func(ctx context.Context, m *message.Message) {
+			processed++
+
+			// concurrent duplicate, while processing
+			duplicate := newMessage("test-id", nil)
+
+			var duplicateAck *bool
+
+			duplicate.OnAcknowledgement(func(ack bool) {
+				duplicateAck = &ack
+			})
+
+			f(ctx, duplicate)
+
+			assert.NotNil(t, duplicateAck)
+			assert.False(t, *duplicateAck)
+
+			m.Ack()
+		})
+
+		f(ctx, newMessage("test-id", nil))
+		assert.Equal(t, 1, processed)
+
+		logtest.AssertHasLogRecord(t, logBuffer, map[string]interface{}{
+			"level":          "debug",
+			"subscriptionID": "test-subscription",
+			"messageID":      "test-id",
+			"dedupKey":       "test-subscription:test-id",
+			"message":        "pubsub message duplicate in progress, nacked",
+		})
+	})
+
+	t.Run("with store errors", func(t *testing.T) {
+		t.Parallel()
+
+		logBuffer := logtest.NewDefaultTestLogBuffer()
+		logger, err := log.NewDefaultLoggerFactory().Create(
+			log.WithLevel(zerolog.DebugLevel),
+			log.WithOutputWriter(logBuffer),
+		)
+		assert.NoError(t, err)
+
+		mw := dedup.NewDedupMiddleware(&errorDedupStore{}, logger)
+
+		processed := 0
+
+		f := mw.Wrap(func(ctx context.Context, m *message.Message) {
+			processed++
+
+			m.Ack()
+		})
+
+		f(ctx, newMessage("test-id", nil))
+		f(ctx, newMessage("test-id", nil))
+		assert.Equal(t, 2, processed)
+
+		logtest.AssertHasLogRecord(t, logBuffer, map[string]interface{}{
+			"level":          "warn",
+			"subscriptionID": "test-subscription",
+			"messageID":      "test-id",
+			"message":        "pubsub message dedup reservation error, processing anyway",
+		})
+
+		logtest.AssertHasLogRecord(t, logBuffer, map[string]interface{}{
+			"level":          "warn",
+			"subscriptionID": "test-subscription",
+			"messageID":      "test-id",
+			"message":        "pubsub message dedup save error",
+		})
+	})
+}
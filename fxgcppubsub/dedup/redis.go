@@ -0,0 +1,88 @@
+package dedup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+var _ DedupStore = (*RedisDedupStore)(nil)
+
+const (
+	// DefaultRedisKeyPrefix is the default prefix of the keys saved by the RedisDedupStore.
+	DefaultRedisKeyPrefix = "gcppubsub:dedup:"
+
+	redisReservedValue  = "reserved"
+	redisProcessedValue = "processed"
+)
+
+// RedisDedupStore is a DedupStore implementation backed by Redis (for example with the fxredis module client).
+//
+// Keys are reserved atomically with SET NX, and their value tells if they are reserved or processed.
+type RedisDedupStore struct {
+	client redis.Cmdable
+	prefix string
+}
+
+// NewRedisDedupStore returns a new RedisDedupStore instance, for a provided keys prefix.
+func NewRedisDedupStore(client redis.Cmdable, prefix string) *RedisDedupStore {
+	if prefix == "" {
+		prefix = DefaultRedisKeyPrefix
+	}
+
+	return &RedisDedupStore{
+		client: client,
+		prefix: prefix,
+	}
+}
+
+// Reserve reserves the key for a provided ttl if not existing (or expired), or returns the status of the existing one.
+func (s *RedisDedupStore) Reserve(ctx context.Context, key string, ttl time.Duration) (Status, error) {
+	reserved, err := s.client.SetNX(ctx, s.prefix+key, redisReservedValue, ttl).Result()
+	if err != nil {
+		return StatusInProgress, fmt.Errorf("cannot reserve dedup key: %w", err)
+	}
+
+	if reserved {
+		return StatusReserved, nil
+	}
+
+	value, err := s.client.Get(ctx, s.prefix+key).Result()
+	if err != nil {
+		// expired in the meantime, considered as in progress to be redelivered
+		if errors.Is(err, redis.Nil) {
+			return StatusInProgress, nil
+		}
+
+		return StatusInProgress, fmt.Errorf("cannot get dedup key: %w", err)
+	}
+
+	if value == redisProcessedValue {
+		return StatusProcessed, nil
+	}
+
+	return StatusInProgress, nil
+}
+
+// Save saves the key as processed for a provided ttl.
+func (s *RedisDedupStore) Save(ctx context.Context, key string, ttl time.Duration) error {
+	err := s.client.Set(ctx, s.prefix+key, redisProcessedValue, ttl).Err()
+	if err != nil {
+		return fmt.Errorf("cannot save dedup key: %w", err)
+	}
+
+	return nil
+}
+
+// Delete deletes the key.
+func (s *RedisDedupStore) Delete(ctx context.Context, key string) error {
+	err := s.client.Del(ctx, s.prefix+key).Err()
+	if err != nil {
+		return fmt.Errorf("cannot delete dedup key: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,92 @@
+package dedup_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ankorstore/yokai-contrib/fxgcppubsub/dedup"
+	"github.com/go-redis/redismock/v9"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRedisDedupStore(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	t.Run("with default prefix", func(t *testing.T) {
+		t.Parallel()
+
+		client, clientMock := redismock.NewClientMock()
+
+		store := dedup.NewRedisDedupStore(client, "")
+
+		clientMock.ExpectSetNX("gcppubsub:dedup:key", "reserved", time.Hour).SetVal(true)
+		clientMock.ExpectSetNX("gcppubsub:dedup:key", "reserved", time.Hour).SetVal(false)
+		clientMock.ExpectGet("gcppubsub:dedup:key").SetVal("reserved")
+		clientMock.ExpectSet("gcppubsub:dedup:key", "processed", time.Hour).SetVal("OK")
+		clientMock.ExpectSetNX("gcppubsub:dedup:key", "reserved", time.Hour).SetVal(false)
+		clientMock.ExpectGet("gcppubsub:dedup:key").SetVal("processed")
+		clientMock.ExpectDel("gcppubsub:dedup:key").SetVal(1)
+		clientMock.ExpectSetNX("gcppubsub:dedup:key", "reserved", time.Hour).SetVal(false)
+		clientMock.ExpectGet("gcppubsub:dedup:key").RedisNil()
+
+		status, err := store.Reserve(ctx, "key", time.Hour)
+		assert.NoError(t, err)
+		assert.Equal(t, dedup.StatusReserved, status)
+
+		status, err = store.Reserve(ctx, "key", time.Hour)
+		assert.NoError(t, err)
+		assert.Equal(t, dedup.StatusInProgress, status)
+
+		err = store.Save(ctx, "key", time.Hour)
+		assert.NoError(t, err)
+
+		status, err = store.Reserve(ctx, "key", time.Hour)
+		assert.NoError(t, err)
+		assert.Equal(t, dedup.StatusProcessed, status)
+
+		err = store.Delete(ctx, "key")
+		assert.NoError(t, err)
+
+		// expired between the reservation and the get
+		status, err = store.Reserve(ctx, "key", time.Hour)
+		assert.NoError(t, err)
+		assert.Equal(t, dedup.StatusInProgress, status)
+
+		assert.NoError(t, clientMock.ExpectationsWereMet())
+	})
+
+	t.Run("with errors", func(t *testing.T) {
+		t.Parallel()
+
+		client, clientMock := redismock.NewClientMock()
+
+		store := dedup.NewRedisDedupStore(client, "custom:")
+
+		clientMock.ExpectSetNX("custom:key", "reserved", time.Hour).SetErr(assert.AnError)
+		clientMock.ExpectSetNX("custom:key", "reserved", time.Hour).SetVal(false)
+		clientMock.ExpectGet("custom:key").SetErr(assert.AnError)
+		clientMock.ExpectSet("custom:key", "processed", time.Hour).SetErr(assert.AnError)
+		clientMock.ExpectDel("custom:key").SetErr(assert.AnError)
+
+		_, err := store.Reserve(ctx, "key", time.Hour)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "cannot reserve dedup key")
+
+		_, err = store.Reserve(ctx, "key", time.Hour)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "cannot get dedup key")
+
+		err = store.Save(ctx, "key", time.Hour)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "cannot save dedup key")
+
+		err = store.Delete(ctx, "key")
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "cannot delete dedup key")
+
+		assert.NoError(t, clientMock.ExpectationsWereMet())
+	})
+}
@@ -0,0 +1,177 @@
+package dedup
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ankorstore/yokai-contrib/fxgcppubsub/internal/sqlstore"
+)
+
+var _ DedupStore = (*SQLDedupStore)(nil)
+
+const (
+	DefaultTableName = "gcppubsub_dedup"
+	DialectMySQL     = sqlstore.DialectMySQL
+	DialectPostgres  = sqlstore.DialectPostgres
+)
+
+// SQLDedupStore is a DedupStore implementation backed by database/sql.
+//
+// Keys are reserved atomically with an insert ignoring conflicts, or with a conditional update of an expired key.
+type SQLDedupStore struct {
+	db    *sql.DB
+	table *sqlstore.Table
+}
+
+// NewSQLDedupStore returns a new SQLDedupStore instance, for a provided table and dialect (mysql or postgres).
+func NewSQLDedupStore(db *sql.DB, table string, dialect string) (*SQLDedupStore, error) {
+	if table == "" {
+		table = DefaultTableName
+	}
+
+	sqlTable, err := sqlstore.NewTable(db, table, dialect)
+	if err != nil {
+		return nil, fmt.Errorf("invalid dedup dialect %q", dialect)
+	}
+
+	return &SQLDedupStore{
+		db:    db,
+		table: sqlTable,
+	}, nil
+}
+
+// CreateTable creates the dedup table, if not existing.
+func (s *SQLDedupStore) CreateTable(ctx context.Context) error {
+	err := s.table.Create(
+		ctx,
+		`CREATE TABLE IF NOT EXISTS %s (
+			dedup_key VARCHAR(255) NOT NULL PRIMARY KEY,
+			processed BOOLEAN NOT NULL DEFAULT FALSE,
+			expires_at DATETIME(6) NOT NULL
+		)`,
+		`CREATE TABLE IF NOT EXISTS %s (
+			dedup_key VARCHAR(255) PRIMARY KEY,
+			processed BOOLEAN NOT NULL DEFAULT FALSE,
+			expires_at TIMESTAMP NOT NULL
+		)`,
+	)
+	if err != nil {
+		return fmt.Errorf("cannot create dedup table: %w", err)
+	}
+
+	return nil
+}
+
+// Reserve reserves the key for a provided ttl if not existing (or expired), or returns the status of the existing one.
+//
+// On MySQL, the reservation relies on the default affected rows count (0 for an existing key): the clientFoundRows
+// connection option must not be enabled.
+func (s *SQLDedupStore) Reserve(ctx context.Context, key string, ttl time.Duration) (Status, error) {
+	now := time.Now().UTC()
+
+	var query string
+
+	switch s.table.Dialect() {
+	case DialectPostgres:
+		query = "INSERT INTO %s (dedup_key, processed, expires_at) VALUES (?, FALSE, ?) ON CONFLICT (dedup_key) DO NOTHING"
+	default:
+		// no-op update instead of INSERT IGNORE, which would downgrade all errors to warnings
+		query = "INSERT INTO %s (dedup_key, processed, expires_at) VALUES (?, FALSE, ?) ON DUPLICATE KEY UPDATE dedup_key = dedup_key"
+	}
+
+	res, err := s.db.ExecContext(ctx, s.table.Query(query), key, now.Add(ttl))
+	if err != nil {
+		return StatusInProgress, fmt.Errorf("cannot reserve dedup key: %w", err)
+	}
+
+	reserved, err := s.affected(res)
+	if err != nil || reserved {
+		return StatusReserved, err
+	}
+
+	// existing key, reserved again if expired
+	res, err = s.db.ExecContext(
+		ctx,
+		s.table.Query("UPDATE %s SET processed = FALSE, expires_at = ? WHERE dedup_key = ? AND expires_at <= ?"),
+		now.Add(ttl),
+		key,
+		now,
+	)
+	if err != nil {
+		return StatusInProgress, fmt.Errorf("cannot reserve dedup key: %w", err)
+	}
+
+	reserved, err = s.affected(res)
+	if err != nil || reserved {
+		return StatusReserved, err
+	}
+
+	var processed bool
+
+	err = s.db.QueryRowContext(ctx, s.table.Query("SELECT processed FROM %s WHERE dedup_key = ?"), key).Scan(&processed)
+	if err != nil {
+		// deleted in the meantime, considered as in progress to be redelivered
+		if errors.Is(err, sql.ErrNoRows) {
+			return StatusInProgress, nil
+		}
+
+		return StatusInProgress, fmt.Errorf("cannot get dedup key: %w", err)
+	}
+
+	if processed {
+		return StatusProcessed, nil
+	}
+
+	return StatusInProgress, nil
+}
+
+// Save saves the key as processed for a provided ttl.
+func (s *SQLDedupStore) Save(ctx context.Context, key string, ttl time.Duration) error {
+	var query string
+
+	switch s.table.Dialect() {
+	case DialectPostgres:
+		query = "INSERT INTO %s (dedup_key, processed, expires_at) VALUES (?, TRUE, ?) ON CONFLICT (dedup_key) DO UPDATE SET processed = TRUE, expires_at = EXCLUDED.expires_at"
+	default:
+		query = "INSERT INTO %s (dedup_key, processed, expires_at) VALUES (?, TRUE, ?) ON DUPLICATE KEY UPDATE processed = TRUE, expires_at = VALUES(expires_at)"
+	}
+
+	_, err := s.db.ExecContext(ctx, s.table.Query(query), key, time.Now().UTC().Add(ttl))
+	if err != nil {
+		return fmt.Errorf("cannot save dedup key: %w", err)
+	}
+
+	return nil
+}
+
+// Delete deletes the key.
+func (s *SQLDedupStore) Delete(ctx context.Context, key string) error {
+	_, err := s.db.ExecContext(ctx, s.table.Query("DELETE FROM %s WHERE dedup_key = ?"), key)
+	if err != nil {
+		return fmt.Errorf("cannot delete dedup key: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteExpired deletes the expired keys, and returns the number of deleted ones.
+func (s *SQLDedupStore) DeleteExpired(ctx context.Context) (int64, error) {
+	res, err := s.db.ExecContext(ctx, s.table.Query("DELETE FROM %s WHERE expires_at <= ?"), time.Now().UTC())
+	if err != nil {
+		return 0, fmt.Errorf("cannot delete expired dedup keys: %w", err)
+	}
+
+	return res.RowsAffected()
+}
+
+func (s *SQLDedupStore) affected(res sql.Result) (bool, error) {
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("cannot reserve dedup key: %w", err)
+	}
+
+	return n > 0, nil
+}
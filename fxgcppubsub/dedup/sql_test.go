@@ -0,0 +1,90 @@
+package dedup_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ankorstore/yokai-contrib/fxgcppubsub/dedup"
+	"github.com/ankorstore/yokai-contrib/fxgcppubsub/testdata/mysql"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewSQLDedupStore(t *testing.T) {
+	t.Parallel()
+
+	store, err := dedup.NewSQLDedupStore(nil, "", "")
+	assert.NoError(t, err)
+	assert.IsType(t, &dedup.SQLDedupStore{}, store)
+
+	_, err = dedup.NewSQLDedupStore(nil, "", dedup.DialectPostgres)
+	assert.NoError(t, err)
+
+	_, err = dedup.NewSQLDedupStore(nil, "", "invalid")
+	assert.Error(t, err)
+	assert.Equal(t, `invalid dedup dialect "invalid"`, err.Error())
+}
+
+func TestSQLDedupStore(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	db := mysql.StartTestServer(t)
+
+	store, err := dedup.NewSQLDedupStore(db, "", dedup.DialectMySQL)
+	assert.NoError(t, err)
+
+	err = store.CreateTable(ctx)
+	assert.NoError(t, err)
+
+	status, err := store.Reserve(ctx, "key", time.Hour)
+	assert.NoError(t, err)
+	assert.Equal(t, dedup.StatusReserved, status)
+
+	status, err = store.Reserve(ctx, "key", time.Hour)
+	assert.NoError(t, err)
+	assert.Equal(t, dedup.StatusInProgress, status)
+
+	err = store.Save(ctx, "key", time.Hour)
+	assert.NoError(t, err)
+
+	// override
+	err = store.Save(ctx, "key", time.Hour)
+	assert.NoError(t, err)
+
+	status, err = store.Reserve(ctx, "key", time.Hour)
+	assert.NoError(t, err)
+	assert.Equal(t, dedup.StatusProcessed, status)
+
+	// delete
+	err = store.Delete(ctx, "key")
+	assert.NoError(t, err)
+
+	status, err = store.Reserve(ctx, "key", time.Hour)
+	assert.NoError(t, err)
+	assert.Equal(t, dedup.StatusReserved, status)
+
+	// expiration
+	err = store.Save(ctx, "expiring-key", time.Millisecond)
+	assert.NoError(t, err)
+
+	err = store.Save(ctx, "expired-key", time.Millisecond)
+	assert.NoError(t, err)
+
+	time.Sleep(5 * time.Millisecond)
+
+	status, err = store.Reserve(ctx, "expiring-key", time.Hour)
+	assert.NoError(t, err)
+	assert.Equal(t, dedup.StatusReserved, status)
+
+	deleted, err := store.DeleteExpired(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), deleted)
+
+	// errors are not ignored
+	status, err = store.Reserve(ctx, strings.Repeat("k", 256), time.Hour)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "cannot reserve dedup key")
+	assert.Equal(t, dedup.StatusInProgress, status)
+}
@@ -0,0 +1,42 @@
+package dedup
+
+import (
+	"context"
+	"time"
+)
+
+// Status represents the status of a deduplication key, returned by a DedupStore reservation.
+type Status int
+
+const (
+	// StatusReserved means that the key was reserved by the reservation call.
+	StatusReserved Status = iota
+	// StatusInProgress means that the key was already reserved, and is not yet saved as processed.
+	StatusInProgress
+	// StatusProcessed means that the key was already saved as processed.
+	StatusProcessed
+)
+
+// String returns a string representation of the Status.
+func (s Status) String() string {
+	switch s {
+	case StatusReserved:
+		return "reserved"
+	case StatusInProgress:
+		return "in progress"
+	case StatusProcessed:
+		return "processed"
+	default:
+		return "unknown"
+	}
+}
+
+// DedupStore is the interface for deduplication stores, keeping track of the already processed messages keys.
+//
+// Reserve atomically reserves a key for ttl if not existing (or expired), or returns the status of the existing one.
+// Save saves a key as processed for ttl, overriding its reservation, and Delete deletes a key, releasing its reservation.
+type DedupStore interface {
+	Reserve(ctx context.Context, key string, ttl time.Duration) (Status, error)
+	Save(ctx context.Context, key string, ttl time.Duration) error
+	Delete(ctx context.Context, key string) error
+}
@@ -0,0 +1,121 @@
+package delay
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"sort"
+	"sync"
+	"time"
+)
+
+var _ Store = (*MemoryStore)(nil)
+
+// MemoryStore is an in memory Store implementation, not shared between application instances, and losing its messages on restart.
+type MemoryStore struct {
+	messages map[string]*Message
+	mutex    sync.Mutex
+}
+
+// NewMemoryStore returns a new MemoryStore instance.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		messages: make(map[string]*Message),
+	}
+}
+
+// Save saves a delayed message.
+func (s *MemoryStore) Save(_ context.Context, message *Message) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.messages[message.ID] = copyMessage(message)
+
+	return nil
+}
+
+// Claim returns, by due time, up to limit due messages, and postpones them for the lease duration.
+func (s *MemoryStore) Claim(_ context.Context, limit int, lease time.Duration) ([]*Message, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	now := time.Now().UTC()
+
+	var due []*Message
+
+	for _, message := range s.messages {
+		if !message.DueAt.IsZero() && !message.DueAt.After(now) {
+			due = append(due, message)
+		}
+	}
+
+	sort.Slice(due, func(i, j int) bool {
+		return due[i].DueAt.Before(due[j].DueAt)
+	})
+
+	if len(due) > limit {
+		due = due[:limit]
+	}
+
+	claimed := make([]*Message, len(due))
+
+	for i, message := range due {
+		claimed[i] = copyMessage(message)
+
+		message.DueAt = now.Add(lease)
+	}
+
+	return claimed, nil
+}
+
+// Complete deletes a published message.
+func (s *MemoryStore) Complete(_ context.Context, id string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	delete(s.messages, id)
+
+	return nil
+}
+
+// Fail records a failed publication, and reschedules the message at retryAt, or parks it if retryAt is zero.
+func (s *MemoryStore) Fail(_ context.Context, id string, failure error, retryAt time.Time) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	message, ok := s.messages[id]
+	if !ok {
+		return fmt.Errorf("cannot find delayed message %s", id)
+	}
+
+	message.Attempts++
+	message.LastError = failure.Error()
+	message.DueAt = retryAt.UTC()
+
+	return nil
+}
+
+// Messages returns all the stored messages, including the parked ones.
+func (s *MemoryStore) Messages() []*Message {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	messages := make([]*Message, 0, len(s.messages))
+
+	for _, message := range s.messages {
+		messages = append(messages, copyMessage(message))
+	}
+
+	sort.Slice(messages, func(i, j int) bool {
+		return messages[i].ID < messages[j].ID
+	})
+
+	return messages
+}
+
+func copyMessage(message *Message) *Message {
+	c := *message
+	c.Attributes = maps.Clone(message.Attributes)
+
+	return &c
+}
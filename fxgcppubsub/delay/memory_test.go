@@ -0,0 +1,117 @@
+package delay_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ankorstore/yokai-contrib/fxgcppubsub/codec"
+	"github.com/ankorstore/yokai-contrib/fxgcppubsub/delay"
+	"github.com/ankorstore/yokai-contrib/fxgcppubsub/topic"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewMessage(t *testing.T) {
+	t.Parallel()
+
+	dueAt := time.Now().Add(time.Hour)
+
+	message, err := delay.NewMessage(
+		context.Background(),
+		"test-topic",
+		codec.NewRawCodec(),
+		[]byte("test"),
+		dueAt,
+		topic.WithMessageAttributes(map[string]string{"foo": "bar"}),
+		topic.WithMessageOrderingKey("key"),
+	)
+	assert.NoError(t, err)
+
+	assert.NotEmpty(t, message.ID)
+	assert.Equal(t, "test-topic", message.TopicID)
+	assert.Equal(t, []byte("test"), message.Data)
+	assert.Equal(t, map[string]string{"foo": "bar"}, message.Attributes)
+	assert.Equal(t, "key", message.OrderingKey)
+	assert.True(t, dueAt.Equal(message.DueAt))
+	assert.Equal(t, 0, message.Attempts)
+
+	_, err = delay.NewMessage(context.Background(), "test-topic", codec.NewProtoBinaryCodec(), "invalid", dueAt)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "cannot encode data")
+}
+
+func TestMemoryStore(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	store := delay.NewMemoryStore()
+
+	now := time.Now()
+
+	for id, dueAt := range map[string]time.Time{
+		"due-2":  now.Add(-time.Second),
+		"due-1":  now.Add(-time.Minute),
+		"future": now.Add(time.Hour),
+	} {
+		err := store.Save(ctx, &delay.Message{
+			ID:         id,
+			TopicID:    "test-topic",
+			Data:       []byte(id),
+			Attributes: map[string]string{"foo": "bar"},
+			DueAt:      dueAt,
+		})
+		assert.NoError(t, err)
+	}
+
+	// claim by due time
+	messages, err := store.Claim(ctx, 1, time.Hour)
+	assert.NoError(t, err)
+	assert.Len(t, messages, 1)
+	assert.Equal(t, "due-1", messages[0].ID)
+	assert.Equal(t, map[string]string{"foo": "bar"}, messages[0].Attributes)
+
+	messages, err = store.Claim(ctx, 10, time.Hour)
+	assert.NoError(t, err)
+	assert.Len(t, messages, 1)
+	assert.Equal(t, "due-2", messages[0].ID)
+
+	// leased
+	messages, err = store.Claim(ctx, 10, time.Hour)
+	assert.NoError(t, err)
+	assert.Len(t, messages, 0)
+
+	// complete
+	err = store.Complete(ctx, "due-1")
+	assert.NoError(t, err)
+
+	// fail and retry
+	err = store.Fail(ctx, "due-2", assert.AnError, now.Add(-time.Millisecond))
+	assert.NoError(t, err)
+
+	messages, err = store.Claim(ctx, 10, time.Hour)
+	assert.NoError(t, err)
+	assert.Len(t, messages, 1)
+	assert.Equal(t, "due-2", messages[0].ID)
+	assert.Equal(t, 1, messages[0].Attempts)
+	assert.Equal(t, assert.AnError.Error(), messages[0].LastError)
+
+	// fail and park
+	err = store.Fail(ctx, "due-2", assert.AnError, time.Time{})
+	assert.NoError(t, err)
+
+	err = store.Fail(ctx, "unknown", assert.AnError, time.Time{})
+	assert.Error(t, err)
+	assert.Equal(t, "cannot find delayed message unknown", err.Error())
+
+	stored := store.Messages()
+	assert.Len(t, stored, 2)
+	assert.Equal(t, "due-2", stored[0].ID)
+	assert.Equal(t, 2, stored[0].Attempts)
+	assert.True(t, stored[0].DueAt.IsZero())
+	assert.Equal(t, "future", stored[1].ID)
+
+	messages, err = store.Claim(ctx, 10, time.Hour)
+	assert.NoError(t, err)
+	assert.Len(t, messages, 0)
+}
@@ -0,0 +1,188 @@
+package delay
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+var _ Store = (*RedisStore)(nil)
+
+// DefaultRedisKeyPrefix is the default prefix of the keys saved by the RedisStore.
+//
+// Its {...} hash tag keeps all the RedisStore keys in the same Redis Cluster slot, as required by its scripts and transactions.
+const DefaultRedisKeyPrefix = "{gcppubsub:delay}:"
+
+// claimScript atomically fetches the due messages ids, postpones them for the lease, and returns their payloads.
+var claimScript = redis.NewScript(`
+local ids = redis.call('ZRANGEBYSCORE', KEYS[1], '-inf', ARGV[1], 'LIMIT', 0, ARGV[2])
+if #ids == 0 then
+	return {}
+end
+for _, id in ipairs(ids) do
+	redis.call('ZADD', KEYS[1], ARGV[3], id)
+end
+return redis.call('HMGET', KEYS[2], unpack(ids))
+`)
+
+// failScript atomically increments the attempts of a message, records its last error and due time, and reschedules it
+// at ARGV[4] (or parks it if empty), returning 0 if the message does not exist.
+var failScript = redis.NewScript(`
+local payload = redis.call('HGET', KEYS[1], ARGV[1])
+if not payload then
+	return 0
+end
+local message = cjson.decode(payload)
+message['Attempts'] = (tonumber(message['Attempts']) or 0) + 1
+message['LastError'] = ARGV[2]
+message['DueAt'] = ARGV[3]
+redis.call('HSET', KEYS[1], ARGV[1], cjson.encode(message))
+if ARGV[4] == '' then
+	redis.call('ZREM', KEYS[2], ARGV[1])
+else
+	redis.call('ZADD', KEYS[2], ARGV[4], ARGV[1])
+end
+return 1
+`)
+
+// RedisStore is a Store implementation backed by Redis (for example with the fxredis module client).
+//
+// Messages are saved in a hash ({prefix}messages), and scheduled in a sorted set by due time ({prefix}due).
+// On Redis Cluster, a custom prefix must contain a {...} hash tag, for both keys to be in the same slot.
+type RedisStore struct {
+	client redis.Cmdable
+	prefix string
+}
+
+// NewRedisStore returns a new RedisStore instance, for a provided keys prefix.
+func NewRedisStore(client redis.Cmdable, prefix string) *RedisStore {
+	if prefix == "" {
+		prefix = DefaultRedisKeyPrefix
+	}
+
+	return &RedisStore{
+		client: client,
+		prefix: prefix,
+	}
+}
+
+// Save saves a delayed message.
+func (s *RedisStore) Save(ctx context.Context, message *Message) error {
+	payload, err := json.Marshal(message)
+	if err != nil {
+		return fmt.Errorf("cannot marshal delayed message: %w", err)
+	}
+
+	_, err = s.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.HSet(ctx, s.messagesKey(), message.ID, payload)
+		pipe.ZAdd(ctx, s.dueKey(), redis.Z{Score: score(message.DueAt), Member: message.ID})
+
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("cannot save delayed message: %w", err)
+	}
+
+	return nil
+}
+
+// Claim returns, by due time, up to limit due messages, and postpones them for the lease duration.
+func (s *RedisStore) Claim(ctx context.Context, limit int, lease time.Duration) ([]*Message, error) {
+	now := time.Now().UTC()
+
+	payloads, err := claimScript.Run(
+		ctx,
+		s.client,
+		[]string{s.dueKey(), s.messagesKey()},
+		strconv.FormatInt(now.UnixMilli(), 10),
+		limit,
+		strconv.FormatInt(now.Add(lease).UnixMilli(), 10),
+	).Slice()
+	if err != nil {
+		return nil, fmt.Errorf("cannot claim delayed messages: %w", err)
+	}
+
+	messages := make([]*Message, 0, len(payloads))
+
+	for _, payload := range payloads {
+		// completed in the meantime
+		str, ok := payload.(string)
+		if !ok {
+			continue
+		}
+
+		message := &Message{}
+
+		err = json.Unmarshal([]byte(str), message)
+		if err != nil {
+			return nil, fmt.Errorf("cannot unmarshal delayed message: %w", err)
+		}
+
+		messages = append(messages, message)
+	}
+
+	return messages, nil
+}
+
+// Complete deletes a published message.
+func (s *RedisStore) Complete(ctx context.Context, id string) error {
+	_, err := s.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.ZRem(ctx, s.dueKey(), id)
+		pipe.HDel(ctx, s.messagesKey(), id)
+
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("cannot complete delayed message: %w", err)
+	}
+
+	return nil
+}
+
+// Fail records a failed publication, and reschedules the message at retryAt, or parks it if retryAt is zero.
+func (s *RedisStore) Fail(ctx context.Context, id string, failure error, retryAt time.Time) error {
+	dueAt, err := retryAt.UTC().MarshalText()
+	if err != nil {
+		return fmt.Errorf("cannot marshal delayed message due time: %w", err)
+	}
+
+	var retryScore string
+	if !retryAt.IsZero() {
+		retryScore = strconv.FormatInt(retryAt.UnixMilli(), 10)
+	}
+
+	found, err := failScript.Run(
+		ctx,
+		s.client,
+		[]string{s.messagesKey(), s.dueKey()},
+		id,
+		failure.Error(),
+		string(dueAt),
+		retryScore,
+	).Int()
+	if err != nil {
+		return fmt.Errorf("cannot fail delayed message: %w", err)
+	}
+
+	if found == 0 {
+		return fmt.Errorf("cannot find delayed message %s", id)
+	}
+
+	return nil
+}
+
+func (s *RedisStore) messagesKey() string {
+	return s.prefix + "messages"
+}
+
+func (s *RedisStore) dueKey() string {
+	return s.prefix + "due"
+}
+
+func score(t time.Time) float64 {
+	return float64(t.UnixMilli())
+}
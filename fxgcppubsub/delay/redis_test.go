@@ -0,0 +1,137 @@
+package delay_test
+
+import (
+	"context"
+	"encoding/json"
+	"regexp"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/ankorstore/yokai-contrib/fxgcppubsub/delay"
+	"github.com/go-redis/redismock/v9"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRedisStore(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	dueAt := time.UnixMilli(time.Now().Add(-time.Minute).UnixMilli()).UTC()
+
+	message := &delay.Message{
+		ID:          "id",
+		TopicID:     "test-topic",
+		Data:        []byte("test"),
+		Attributes:  map[string]string{"foo": "bar"},
+		OrderingKey: "key",
+		DueAt:       dueAt,
+	}
+
+	payload, err := json.Marshal(message)
+	assert.NoError(t, err)
+
+	t.Run("with default prefix", func(t *testing.T) {
+		t.Parallel()
+
+		client, clientMock := redismock.NewClientMock()
+		clientMock.MatchExpectationsInOrder(true)
+
+		store := delay.NewRedisStore(client, "")
+
+		// save
+		clientMock.ExpectTxPipeline()
+		clientMock.ExpectHSet("{gcppubsub:delay}:messages", "id", payload).SetVal(1)
+		clientMock.ExpectZAdd("{gcppubsub:delay}:due", redis.Z{Score: float64(dueAt.UnixMilli()), Member: "id"}).SetVal(1)
+		clientMock.ExpectTxPipelineExec()
+
+		err := store.Save(ctx, message)
+		assert.NoError(t, err)
+
+		// claim
+		clientMock.Regexp().
+			ExpectEvalSha(".*", []string{regexp.QuoteMeta("{gcppubsub:delay}:due"), regexp.QuoteMeta("{gcppubsub:delay}:messages")}, ".*", ".*", ".*").
+			SetVal([]any{string(payload), nil})
+
+		messages, err := store.Claim(ctx, 10, time.Minute)
+		assert.NoError(t, err)
+		assert.Len(t, messages, 1)
+		assert.Equal(t, message, messages[0])
+
+		// fail and park
+		clientMock.Regexp().
+			ExpectEvalSha(
+				".*",
+				[]string{regexp.QuoteMeta("{gcppubsub:delay}:messages"), regexp.QuoteMeta("{gcppubsub:delay}:due")},
+				"id",
+				regexp.QuoteMeta(assert.AnError.Error()),
+				regexp.QuoteMeta("0001-01-01T00:00:00Z"),
+				"^$",
+			).
+			SetVal(int64(1))
+
+		err = store.Fail(ctx, "id", assert.AnError, time.Time{})
+		assert.NoError(t, err)
+
+		// fail and retry
+		retryAt := time.Now().Add(time.Minute)
+
+		clientMock.Regexp().
+			ExpectEvalSha(
+				".*",
+				[]string{regexp.QuoteMeta("{gcppubsub:delay}:messages"), regexp.QuoteMeta("{gcppubsub:delay}:due")},
+				"id",
+				".*",
+				".*",
+				strconv.FormatInt(retryAt.UnixMilli(), 10),
+			).
+			SetVal(int64(1))
+
+		err = store.Fail(ctx, "id", assert.AnError, retryAt)
+		assert.NoError(t, err)
+
+		// complete
+		clientMock.ExpectTxPipeline()
+		clientMock.ExpectZRem("{gcppubsub:delay}:due", "id").SetVal(0)
+		clientMock.ExpectHDel("{gcppubsub:delay}:messages", "id").SetVal(1)
+		clientMock.ExpectTxPipelineExec()
+
+		err = store.Complete(ctx, "id")
+		assert.NoError(t, err)
+
+		assert.NoError(t, clientMock.ExpectationsWereMet())
+	})
+
+	t.Run("with errors", func(t *testing.T) {
+		t.Parallel()
+
+		client, clientMock := redismock.NewClientMock()
+
+		store := delay.NewRedisStore(client, "custom:")
+
+		clientMock.ExpectTxPipeline()
+		clientMock.ExpectHSet("custom:messages", "id", payload).SetErr(assert.AnError)
+
+		err := store.Save(ctx, message)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "cannot save delayed message")
+
+		clientMock.Regexp().
+			ExpectEvalSha(".*", []string{"custom:messages", "custom:due"}, "unknown", ".*", ".*", ".*").
+			SetVal(int64(0))
+
+		err = store.Fail(ctx, "unknown", assert.AnError, time.Now())
+		assert.Error(t, err)
+		assert.Equal(t, "cannot find delayed message unknown", err.Error())
+
+		clientMock.Regexp().
+			ExpectEvalSha(".*", []string{"custom:messages", "custom:due"}, "id", ".*", ".*", ".*").
+			SetErr(assert.AnError)
+
+		err = store.Fail(ctx, "id", assert.AnError, time.Now())
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "cannot fail delayed message")
+	})
+}
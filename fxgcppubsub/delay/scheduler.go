@@ -0,0 +1,222 @@
+package delay
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"sync"
+	"time"
+
+	"cloud.google.com/go/pubsub"
+	"github.com/ankorstore/yokai-contrib/fxgcppubsub/topic"
+	"github.com/ankorstore/yokai/log"
+)
+
+const (
+	DefaultSchedulerInterval    = 1 * time.Second
+	DefaultSchedulerBatchSize   = 100
+	DefaultSchedulerLease       = 1 * time.Minute
+	DefaultSchedulerMaxAttempts = 10
+	DefaultSchedulerRetryDelay  = 10 * time.Second
+)
+
+// Publisher is the interface for the publishers used by the scheduler.
+type Publisher interface {
+	PublishEncoded(ctx context.Context, topicID string, data []byte, options ...topic.PublishOption) (*pubsub.PublishResult, error)
+}
+
+// SchedulerOptions represents the Scheduler options.
+type SchedulerOptions struct {
+	Interval    time.Duration
+	BatchSize   int
+	Lease       time.Duration
+	MaxAttempts int
+	RetryDelay  time.Duration
+}
+
+// DefaultSchedulerOptions returns the default SchedulerOptions.
+func DefaultSchedulerOptions() SchedulerOptions {
+	return SchedulerOptions{
+		Interval:    DefaultSchedulerInterval,
+		BatchSize:   DefaultSchedulerBatchSize,
+		Lease:       DefaultSchedulerLease,
+		MaxAttempts: DefaultSchedulerMaxAttempts,
+		RetryDelay:  DefaultSchedulerRetryDelay,
+	}
+}
+
+// Scheduler publishes in background the due delayed messages, and completes them.
+//
+// Messages are claimed for SchedulerOptions.Lease, so concurrent schedulers (for example across application instances)
+// do not publish them twice. Messages failing to be published are retried after SchedulerOptions.RetryDelay, and parked
+// after SchedulerOptions.MaxAttempts. Since a message can be published but not completed (for example on shutdown or
+// on lease expiration), the delivery is at least once: messages are published with the DelayedMessageIDAttribute,
+// to be deduplicated on consumption.
+type Scheduler struct {
+	store     Store
+	publisher Publisher
+	logger    *log.Logger
+	options   SchedulerOptions
+	cancel    context.CancelFunc
+	wg        sync.WaitGroup
+	mutex     sync.Mutex
+}
+
+// NewScheduler returns a new Scheduler instance.
+func NewScheduler(store Store, publisher Publisher, logger *log.Logger, options SchedulerOptions) *Scheduler {
+	defaults := DefaultSchedulerOptions()
+
+	if options.Interval <= 0 {
+		options.Interval = defaults.Interval
+	}
+
+	if options.BatchSize <= 0 {
+		options.BatchSize = defaults.BatchSize
+	}
+
+	if options.Lease <= 0 {
+		options.Lease = defaults.Lease
+	}
+
+	if options.MaxAttempts <= 0 {
+		options.MaxAttempts = defaults.MaxAttempts
+	}
+
+	if options.RetryDelay <= 0 {
+		options.RetryDelay = defaults.RetryDelay
+	}
+
+	return &Scheduler{
+		store:     store,
+		publisher: publisher,
+		logger:    logger,
+		options:   options,
+	}
+}
+
+// Options returns the scheduler options.
+func (s *Scheduler) Options() SchedulerOptions {
+	return s.options
+}
+
+// Start starts the scheduler in background, running every SchedulerOptions.Interval until Stop is called.
+func (s *Scheduler) Start(ctx context.Context) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.cancel != nil {
+		return
+	}
+
+	ctx, s.cancel = context.WithCancel(ctx)
+
+	s.wg.Add(1)
+
+	go func() {
+		defer s.wg.Done()
+
+		ticker := time.NewTicker(s.options.Interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				_, err := s.Run(ctx)
+				if err != nil && ctx.Err() == nil {
+					s.logger.Error().Err(err).Msg("pubsub delay scheduler error")
+				}
+			}
+		}
+	}()
+}
+
+// Stop stops the scheduler, and waits for the current run to complete.
+func (s *Scheduler) Stop() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.cancel == nil {
+		return
+	}
+
+	s.cancel()
+	s.wg.Wait()
+
+	s.cancel = nil
+}
+
+// Run performs a single scheduler run: it publishes a batch of due messages, and returns the number of published ones.
+func (s *Scheduler) Run(ctx context.Context) (int, error) {
+	messages, err := s.store.Claim(ctx, s.options.BatchSize, s.options.Lease)
+	if err != nil {
+		return 0, err
+	}
+
+	// publish
+	results := make([]*pubsub.PublishResult, len(messages))
+	errs := make([]error, len(messages))
+
+	for i, message := range messages {
+		attributes := maps.Clone(message.Attributes)
+		if attributes == nil {
+			attributes = make(map[string]string, 1)
+		}
+
+		attributes[DelayedMessageIDAttribute] = message.ID
+
+		results[i], errs[i] = s.publisher.PublishEncoded(
+			ctx,
+			message.TopicID,
+			message.Data,
+			topic.WithMessageAttributes(attributes),
+			topic.WithMessageOrderingKey(message.OrderingKey),
+		)
+	}
+
+	// complete
+	published := 0
+
+	for i, message := range messages {
+		if errs[i] == nil {
+			_, errs[i] = results[i].Get(ctx)
+		}
+
+		if errs[i] != nil {
+			var retryAt time.Time
+			if message.Attempts+1 < s.options.MaxAttempts {
+				retryAt = time.Now().Add(s.options.RetryDelay)
+			}
+
+			s.logger.
+				Warn().
+				Err(errs[i]).
+				Str("delayedMessageID", message.ID).
+				Str("topicID", message.TopicID).
+				Int("attempt", message.Attempts+1).
+				Bool("parked", retryAt.IsZero()).
+				Msg("pubsub delayed message publication error")
+
+			err = s.store.Fail(ctx, message.ID, errs[i], retryAt)
+			if err != nil {
+				return published, err
+			}
+
+			continue
+		}
+
+		err = s.store.Complete(ctx, message.ID)
+		if err != nil {
+			return published, fmt.Errorf("delayed message %s published but not completed: %w", message.ID, err)
+		}
+
+		published++
+	}
+
+	if published > 0 {
+		s.logger.Debug().Int("published", published).Msg("pubsub delayed messages published")
+	}
+
+	return published, nil
+}
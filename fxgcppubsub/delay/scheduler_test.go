@@ -0,0 +1,161 @@
+package delay_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/pubsub"
+	"github.com/ankorstore/yokai-contrib/fxgcppubsub"
+	"github.com/ankorstore/yokai-contrib/fxgcppubsub/delay"
+	"github.com/ankorstore/yokai/fxconfig"
+	"github.com/ankorstore/yokai/fxlog"
+	"github.com/ankorstore/yokai/log"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/fx"
+	"go.uber.org/fx/fxtest"
+)
+
+func TestScheduler(t *testing.T) {
+	t.Setenv("APP_ENV", "test")
+	t.Setenv("APP_CONFIG_PATH", "../testdata/config")
+	t.Setenv("GCP_PROJECT_ID", "test-project")
+
+	ctx := context.Background()
+
+	var publisher delay.Publisher
+	var client *pubsub.Client
+	var logger *log.Logger
+
+	fxtest.New(
+		t,
+		fx.NopLogger,
+		fxconfig.FxConfigModule,
+		fxlog.FxLogModule,
+		fxgcppubsub.FxGcpPubSubModule,
+		fx.Supply(fx.Annotate(ctx, fx.As(new(context.Context)))),
+		fxgcppubsub.PrepareTopicAndSubscription(fxgcppubsub.PrepareTopicAndSubscriptionParams{
+			TopicID:        "delay-topic",
+			SubscriptionID: "delay-subscription",
+		}),
+		fx.Populate(&publisher, &client, &logger),
+	).RequireStart().RequireStop()
+
+	store := delay.NewMemoryStore()
+	scheduler := delay.NewScheduler(store, publisher, logger, delay.SchedulerOptions{MaxAttempts: 2})
+
+	assert.Equal(t, delay.DefaultSchedulerInterval, scheduler.Options().Interval)
+	assert.Equal(t, delay.DefaultSchedulerBatchSize, scheduler.Options().BatchSize)
+	assert.Equal(t, delay.DefaultSchedulerLease, scheduler.Options().Lease)
+	assert.Equal(t, 2, scheduler.Options().MaxAttempts)
+	assert.Equal(t, delay.DefaultSchedulerRetryDelay, scheduler.Options().RetryDelay)
+
+	t.Run("message not yet due is not published", func(t *testing.T) {
+		err := store.Save(ctx, &delay.Message{
+			ID:      "future",
+			TopicID: "delay-topic",
+			Data:    []byte("future"),
+			DueAt:   time.Now().Add(time.Hour),
+		})
+		assert.NoError(t, err)
+
+		published, err := scheduler.Run(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, 0, published)
+	})
+
+	t.Run("due message is published", func(t *testing.T) {
+		err := store.Save(ctx, &delay.Message{
+			ID:         "due",
+			TopicID:    "delay-topic",
+			Data:       []byte("due"),
+			Attributes: map[string]string{"foo": "bar"},
+			DueAt:      time.Now(),
+		})
+		assert.NoError(t, err)
+
+		published, err := scheduler.Run(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, published)
+
+		// already published
+		published, err = scheduler.Run(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, 0, published)
+
+		// received
+		receiveCtx, cancel := context.WithTimeout(ctx, 2*time.Second)
+		defer cancel()
+
+		var data []byte
+		var attributes map[string]string
+
+		err = client.Subscription("delay-subscription").Receive(receiveCtx, func(_ context.Context, m *pubsub.Message) {
+			data = m.Data
+			attributes = m.Attributes
+
+			m.Ack()
+			cancel()
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, []byte("due"), data)
+		assert.Equal(t, map[string]string{"foo": "bar", delay.DelayedMessageIDAttribute: "due"}, attributes)
+	})
+
+	t.Run("failing message is retried until max attempts, then parked", func(t *testing.T) {
+		failingStore := delay.NewMemoryStore()
+		failingScheduler := delay.NewScheduler(failingStore, publisher, logger, delay.SchedulerOptions{
+			MaxAttempts: 2,
+			RetryDelay:  time.Millisecond,
+		})
+
+		err := failingStore.Save(ctx, &delay.Message{
+			ID:      "failing",
+			TopicID: "invalid-topic",
+			Data:    []byte("test"),
+			DueAt:   time.Now(),
+		})
+		assert.NoError(t, err)
+
+		for i := 1; i <= 2; i++ {
+			time.Sleep(5 * time.Millisecond)
+
+			published, err := failingScheduler.Run(ctx)
+			assert.NoError(t, err)
+			assert.Equal(t, 0, published)
+
+			messages := failingStore.Messages()
+			assert.Len(t, messages, 1)
+			assert.Equal(t, i, messages[0].Attempts)
+			assert.Contains(t, messages[0].LastError, "cannot create topic")
+		}
+
+		assert.True(t, failingStore.Messages()[0].DueAt.IsZero())
+
+		published, err := failingScheduler.Run(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, 0, published)
+	})
+}
+
+func TestSchedulerStartAndStop(t *testing.T) {
+	t.Parallel()
+
+	logger, err := log.NewDefaultLoggerFactory().Create()
+	assert.NoError(t, err)
+
+	scheduler := delay.NewScheduler(delay.NewMemoryStore(), nil, logger, delay.SchedulerOptions{
+		Interval: 10 * time.Millisecond,
+	})
+
+	// stop before start is a no-op
+	scheduler.Stop()
+
+	scheduler.Start(context.Background())
+	scheduler.Start(context.Background())
+
+	time.Sleep(50 * time.Millisecond)
+
+	scheduler.Stop()
+	scheduler.Stop()
+}
@@ -0,0 +1,221 @@
+package delay
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/ankorstore/yokai-contrib/fxgcppubsub/internal/sqlstore"
+)
+
+var _ Store = (*SQLStore)(nil)
+
+const (
+	DefaultTableName = "gcppubsub_delayed"
+	DialectMySQL     = sqlstore.DialectMySQL
+	DialectPostgres  = sqlstore.DialectPostgres
+)
+
+// SQLStore is a Store implementation backed by database/sql.
+//
+// Due times are stored as unix milliseconds, and parked messages have a NULL due time.
+type SQLStore struct {
+	db    *sql.DB
+	table *sqlstore.Table
+}
+
+// NewSQLStore returns a new SQLStore instance, for a provided table and dialect (mysql or postgres).
+func NewSQLStore(db *sql.DB, table string, dialect string) (*SQLStore, error) {
+	if table == "" {
+		table = DefaultTableName
+	}
+
+	sqlTable, err := sqlstore.NewTable(db, table, dialect)
+	if err != nil {
+		return nil, fmt.Errorf("invalid delay dialect %q", dialect)
+	}
+
+	return &SQLStore{
+		db:    db,
+		table: sqlTable,
+	}, nil
+}
+
+// CreateTable creates the delayed messages table, if not existing.
+func (s *SQLStore) CreateTable(ctx context.Context) error {
+	err := s.table.Create(
+		ctx,
+		`CREATE TABLE IF NOT EXISTS %s (
+			id VARCHAR(36) NOT NULL PRIMARY KEY,
+			topic_id VARCHAR(255) NOT NULL,
+			data LONGBLOB NOT NULL,
+			attributes TEXT NOT NULL,
+			ordering_key VARCHAR(255) NOT NULL,
+			due_at BIGINT NULL,
+			attempts INT NOT NULL DEFAULT 0,
+			last_error TEXT NULL
+		)`,
+		`CREATE TABLE IF NOT EXISTS %s (
+			id VARCHAR(36) PRIMARY KEY,
+			topic_id VARCHAR(255) NOT NULL,
+			data BYTEA NOT NULL,
+			attributes TEXT NOT NULL,
+			ordering_key VARCHAR(255) NOT NULL,
+			due_at BIGINT NULL,
+			attempts INTEGER NOT NULL DEFAULT 0,
+			last_error TEXT NULL
+		)`,
+	)
+	if err != nil {
+		return fmt.Errorf("cannot create delay table: %w", err)
+	}
+
+	return nil
+}
+
+// Save saves a delayed message.
+func (s *SQLStore) Save(ctx context.Context, message *Message) error {
+	attributes, err := json.Marshal(message.Attributes)
+	if err != nil {
+		return fmt.Errorf("cannot marshal delayed message attributes: %w", err)
+	}
+
+	_, err = s.db.ExecContext(
+		ctx,
+		s.table.Query("INSERT INTO %s (id, topic_id, data, attributes, ordering_key, due_at, attempts) VALUES (?, ?, ?, ?, ?, ?, ?)"),
+		message.ID,
+		message.TopicID,
+		message.Data,
+		string(attributes),
+		message.OrderingKey,
+		message.DueAt.UnixMilli(),
+		message.Attempts,
+	)
+	if err != nil {
+		return fmt.Errorf("cannot save delayed message: %w", err)
+	}
+
+	return nil
+}
+
+// Claim returns, by due time, up to limit due messages, and postpones them for the lease duration.
+//
+// Each message is claimed with a conditional update on its due time, so concurrent schedulers cannot claim the same message.
+func (s *SQLStore) Claim(ctx context.Context, limit int, lease time.Duration) ([]*Message, error) {
+	now := time.Now().UTC()
+
+	rows, err := s.db.QueryContext(
+		ctx,
+		s.table.Query("SELECT id, topic_id, data, attributes, ordering_key, due_at, attempts, last_error FROM %s WHERE due_at IS NOT NULL AND due_at <= ? ORDER BY due_at LIMIT ?"),
+		now.UnixMilli(),
+		limit,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("cannot fetch delayed messages: %w", err)
+	}
+
+	defer rows.Close()
+
+	var candidates []*Message
+
+	for rows.Next() {
+		var attributes string
+		var dueAt int64
+		var lastError sql.NullString
+
+		message := &Message{}
+
+		err = rows.Scan(
+			&message.ID,
+			&message.TopicID,
+			&message.Data,
+			&attributes,
+			&message.OrderingKey,
+			&dueAt,
+			&message.Attempts,
+			&lastError,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("cannot scan delayed message: %w", err)
+		}
+
+		err = json.Unmarshal([]byte(attributes), &message.Attributes)
+		if err != nil {
+			return nil, fmt.Errorf("cannot unmarshal delayed message attributes: %w", err)
+		}
+
+		message.DueAt = time.UnixMilli(dueAt).UTC()
+		message.LastError = lastError.String
+
+		candidates = append(candidates, message)
+	}
+
+	err = rows.Err()
+	if err != nil {
+		return nil, fmt.Errorf("cannot fetch delayed messages: %w", err)
+	}
+
+	leaseAt := now.Add(lease).UnixMilli()
+
+	var messages []*Message
+
+	for _, message := range candidates {
+		res, err := s.db.ExecContext(
+			ctx,
+			s.table.Query("UPDATE %s SET due_at = ? WHERE id = ? AND due_at = ?"),
+			leaseAt,
+			message.ID,
+			message.DueAt.UnixMilli(),
+		)
+		if err != nil {
+			return nil, fmt.Errorf("cannot claim delayed message: %w", err)
+		}
+
+		n, err := res.RowsAffected()
+		if err != nil {
+			return nil, fmt.Errorf("cannot claim delayed message: %w", err)
+		}
+
+		// claimed by another scheduler in the meantime
+		if n == 0 {
+			continue
+		}
+
+		messages = append(messages, message)
+	}
+
+	return messages, nil
+}
+
+// Complete deletes a published message.
+func (s *SQLStore) Complete(ctx context.Context, id string) error {
+	_, err := s.db.ExecContext(ctx, s.table.Query("DELETE FROM %s WHERE id = ?"), id)
+	if err != nil {
+		return fmt.Errorf("cannot complete delayed message: %w", err)
+	}
+
+	return nil
+}
+
+// Fail records a failed publication, and reschedules the message at retryAt, or parks it if retryAt is zero.
+func (s *SQLStore) Fail(ctx context.Context, id string, failure error, retryAt time.Time) error {
+	var dueAt sql.NullInt64
+	if !retryAt.IsZero() {
+		dueAt = sql.NullInt64{Int64: retryAt.UnixMilli(), Valid: true}
+	}
+
+	_, err := s.db.ExecContext(
+		ctx,
+		s.table.Query("UPDATE %s SET attempts = attempts + 1, last_error = ?, due_at = ? WHERE id = ?"),
+		failure.Error(),
+		dueAt,
+		id,
+	)
+	if err != nil {
+		return fmt.Errorf("cannot fail delayed message: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,130 @@
+package delay_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ankorstore/yokai-contrib/fxgcppubsub/delay"
+	"github.com/ankorstore/yokai-contrib/fxgcppubsub/testdata/mysql"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewSQLStore(t *testing.T) {
+	t.Parallel()
+
+	t.Run("with defaults", func(t *testing.T) {
+		t.Parallel()
+
+		store, err := delay.NewSQLStore(nil, "", "")
+		assert.NoError(t, err)
+		assert.IsType(t, &delay.SQLStore{}, store)
+		assert.Implements(t, (*delay.Store)(nil), store)
+	})
+
+	t.Run("with postgres dialect", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := delay.NewSQLStore(nil, "custom_delayed", delay.DialectPostgres)
+		assert.NoError(t, err)
+	})
+
+	t.Run("with invalid dialect", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := delay.NewSQLStore(nil, "", "invalid")
+		assert.Error(t, err)
+		assert.Equal(t, `invalid delay dialect "invalid"`, err.Error())
+	})
+}
+
+func TestSQLStore(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	db := mysql.StartTestServer(t)
+
+	store, err := delay.NewSQLStore(db, "", delay.DialectMySQL)
+	assert.NoError(t, err)
+
+	err = store.CreateTable(ctx)
+	assert.NoError(t, err)
+
+	// idempotent
+	err = store.CreateTable(ctx)
+	assert.NoError(t, err)
+
+	// save
+	now := time.Now()
+
+	for id, dueAt := range map[string]time.Time{
+		"due-2":  now.Add(-time.Second),
+		"due-1":  now.Add(-time.Minute),
+		"future": now.Add(time.Hour),
+	} {
+		err = store.Save(ctx, &delay.Message{
+			ID:          id,
+			TopicID:     "test-topic",
+			Data:        []byte(id),
+			Attributes:  map[string]string{"foo": "bar"},
+			OrderingKey: "key",
+			DueAt:       dueAt,
+		})
+		assert.NoError(t, err)
+	}
+
+	// claim by due time
+	messages, err := store.Claim(ctx, 1, time.Hour)
+	assert.NoError(t, err)
+	assert.Len(t, messages, 1)
+
+	assert.Equal(t, "due-1", messages[0].ID)
+	assert.Equal(t, "test-topic", messages[0].TopicID)
+	assert.Equal(t, []byte("due-1"), messages[0].Data)
+	assert.Equal(t, map[string]string{"foo": "bar"}, messages[0].Attributes)
+	assert.Equal(t, "key", messages[0].OrderingKey)
+	assert.Equal(t, now.Add(-time.Minute).UnixMilli(), messages[0].DueAt.UnixMilli())
+
+	messages, err = store.Claim(ctx, 10, time.Hour)
+	assert.NoError(t, err)
+	assert.Len(t, messages, 1)
+	assert.Equal(t, "due-2", messages[0].ID)
+
+	// leased
+	messages, err = store.Claim(ctx, 10, time.Hour)
+	assert.NoError(t, err)
+	assert.Len(t, messages, 0)
+
+	// complete
+	err = store.Complete(ctx, "due-1")
+	assert.NoError(t, err)
+
+	// fail and retry
+	err = store.Fail(ctx, "due-2", assert.AnError, now.Add(-time.Millisecond))
+	assert.NoError(t, err)
+
+	messages, err = store.Claim(ctx, 10, time.Hour)
+	assert.NoError(t, err)
+	assert.Len(t, messages, 1)
+	assert.Equal(t, "due-2", messages[0].ID)
+	assert.Equal(t, 1, messages[0].Attempts)
+	assert.Equal(t, assert.AnError.Error(), messages[0].LastError)
+
+	// fail and park
+	err = store.Fail(ctx, "due-2", assert.AnError, time.Time{})
+	assert.NoError(t, err)
+
+	messages, err = store.Claim(ctx, 10, time.Hour)
+	assert.NoError(t, err)
+	assert.Len(t, messages, 0)
+
+	var count, attempts int
+
+	err = db.QueryRowContext(ctx, "SELECT COUNT(*) FROM gcppubsub_delayed").Scan(&count)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, count)
+
+	err = db.QueryRowContext(ctx, "SELECT attempts FROM gcppubsub_delayed WHERE id = 'due-2' AND due_at IS NULL").Scan(&attempts)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, attempts)
+}
@@ -0,0 +1,62 @@
+package delay
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ankorstore/yokai-contrib/fxgcppubsub/codec"
+	"github.com/ankorstore/yokai-contrib/fxgcppubsub/topic"
+	"github.com/google/uuid"
+)
+
+// DelayedMessageIDAttribute is the attribute carrying the delayed message id, on its publication.
+const DelayedMessageIDAttribute = "delayed_message_id"
+
+// Message represents a delayed message, holding an encoded message to publish on a topic once due.
+type Message struct {
+	ID          string
+	TopicID     string
+	Data        []byte
+	Attributes  map[string]string
+	OrderingKey string
+	DueAt       time.Time
+	Attempts    int
+	LastError   string
+}
+
+// NewMessage returns a new Message for a topicID, due at dueAt, encoding data with the topic codec, and with the message options (attributes and ordering key).
+func NewMessage(ctx context.Context, topicID string, cdc codec.Codec, data any, dueAt time.Time, options ...topic.PublishOption) (*Message, error) {
+	// encode
+	encodedData, encodedAttributes, err := codec.EncodeWithAttributesContext(ctx, cdc, data)
+	if err != nil {
+		return nil, fmt.Errorf("cannot encode data: %w", err)
+	}
+
+	// resolve message options
+	publishOptions := topic.DefaultPublishOptions()
+	for _, applyOpt := range options {
+		applyOpt(publishOptions)
+	}
+
+	return &Message{
+		ID:          uuid.NewString(),
+		TopicID:     topicID,
+		Data:        encodedData,
+		Attributes:  publishOptions.MessageSettings.MergeAttributes(encodedAttributes),
+		OrderingKey: publishOptions.MessageSettings.OrderingKey,
+		DueAt:       dueAt.UTC(),
+	}, nil
+}
+
+// Store is the interface for delayed messages stores.
+//
+// Claim returns up to limit due messages, and postpones them for the lease duration: while leased, a message cannot be
+// claimed again, and it's claimed again after the lease expiration if not completed (or failed) in the meantime.
+// Fail records a failed publication, and reschedules the message at retryAt, or parks it if retryAt is zero.
+type Store interface {
+	Save(ctx context.Context, message *Message) error
+	Claim(ctx context.Context, limit int, lease time.Duration) ([]*Message, error)
+	Complete(ctx context.Context, id string) error
+	Fail(ctx context.Context, id string, failure error, retryAt time.Time) error
+}
@@ -0,0 +1,175 @@
+package encryption
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"maps"
+	"reflect"
+
+	"github.com/ankorstore/yokai-contrib/fxgcppubsub/codec"
+)
+
+const (
+	// KeyIDAttribute is the message attribute carrying the id of the key the data key was wrapped with.
+	KeyIDAttribute = "encryption_key_id"
+	// DataKeyAttribute is the message attribute carrying the base64 encoded wrapped data key.
+	DataKeyAttribute = "encryption_data_key"
+	// CodecNamePrefix is the prefix of the encrypted variants of the codecs registered in the codec.CodecRegistry.
+	CodecNamePrefix = "encrypted-"
+	// DataKeySize is the size in bytes of the generated AES-256 data keys.
+	DataKeySize = 32
+)
+
+var (
+	_ codec.Codec                  = (*EncryptionCodec)(nil)
+	_ codec.AttributesCodec        = (*EncryptionCodec)(nil)
+	_ codec.ContextAttributesCodec = (*EncryptionCodec)(nil)
+	_ codec.TypeCheckerCodec       = (*EncryptionCodec)(nil)
+)
+
+// EncryptionCodec is a codec.Codec decorator encrypting the messages data with envelope encryption.
+//
+// Each message data is encrypted with AES-GCM using a random data key, itself wrapped by the KeyProvider:
+// the wrapped data key and the id of the key it was wrapped with are written in the message attributes.
+type EncryptionCodec struct {
+	codec    codec.Codec
+	provider KeyProvider
+}
+
+// NewEncryptionCodec returns a new EncryptionCodec instance, decorating the provided codec.Codec.
+func NewEncryptionCodec(c codec.Codec, provider KeyProvider) *EncryptionCodec {
+	return &EncryptionCodec{
+		codec:    c,
+		provider: provider,
+	}
+}
+
+// Codec returns the decorated codec.Codec.
+func (c *EncryptionCodec) Codec() codec.Codec {
+	return c.codec
+}
+
+// Encode encodes and encrypts the provided input.
+//
+// The encryption attributes are lost: use EncodeWithAttributes instead.
+func (c *EncryptionCodec) Encode(in any) ([]byte, error) {
+	enc, _, err := c.EncodeWithAttributes(in)
+
+	return enc, err
+}
+
+// Decode decrypts and decodes the provided input.
+//
+// The encryption attributes are required: use DecodeWithAttributes instead.
+func (c *EncryptionCodec) Decode(enc []byte, out any) error {
+	return c.DecodeWithAttributes(enc, nil, out)
+}
+
+// EncodeWithAttributes encodes the provided input with the decorated codec, encrypts it, and returns the message
+// attributes with the encryption ones.
+//
+// The KeyProvider is called without context: use EncodeWithAttributesContext instead.
+func (c *EncryptionCodec) EncodeWithAttributes(in any) ([]byte, map[string]string, error) {
+	return c.EncodeWithAttributesContext(context.Background(), in)
+}
+
+// EncodeWithAttributesContext encodes the provided input with the decorated codec, encrypts it, and returns the message
+// attributes with the encryption ones. The provided context is passed to the KeyProvider.
+func (c *EncryptionCodec) EncodeWithAttributesContext(ctx context.Context, in any) ([]byte, map[string]string, error) {
+	enc, encAttributes, err := codec.EncodeWithAttributesContext(ctx, c.codec, in)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	dataKey := make([]byte, DataKeySize)
+
+	_, err = rand.Read(dataKey)
+	if err != nil {
+		return nil, nil, fmt.Errorf("cannot generate data key: %w", err)
+	}
+
+	encrypted, err := seal(dataKey, enc)
+	if err != nil {
+		return nil, nil, fmt.Errorf("cannot encrypt data: %w", err)
+	}
+
+	keyID, wrappedKey, err := c.provider.Wrap(ctx, dataKey)
+	if err != nil {
+		return nil, nil, fmt.Errorf("cannot encrypt data: %w", err)
+	}
+
+	attributes := make(map[string]string, len(encAttributes)+2)
+	maps.Copy(attributes, encAttributes)
+	attributes[KeyIDAttribute] = keyID
+	attributes[DataKeyAttribute] = base64.StdEncoding.EncodeToString(wrappedKey)
+
+	return encrypted, attributes, nil
+}
+
+// DecodeWithAttributes decrypts the provided input with the data key found in the message attributes,
+// and decodes it with the decorated codec.
+//
+// The KeyProvider is called without context: use DecodeWithAttributesContext instead.
+func (c *EncryptionCodec) DecodeWithAttributes(enc []byte, attributes map[string]string, out any) error {
+	return c.DecodeWithAttributesContext(context.Background(), enc, attributes, out)
+}
+
+// DecodeWithAttributesContext decrypts the provided input with the data key found in the message attributes,
+// and decodes it with the decorated codec. The provided context is passed to the KeyProvider.
+func (c *EncryptionCodec) DecodeWithAttributesContext(ctx context.Context, enc []byte, attributes map[string]string, out any) error {
+	keyID, ok := attributes[KeyIDAttribute]
+	if !ok {
+		return fmt.Errorf("cannot decrypt data: missing %s attribute", KeyIDAttribute)
+	}
+
+	encodedKey, ok := attributes[DataKeyAttribute]
+	if !ok {
+		return fmt.Errorf("cannot decrypt data: missing %s attribute", DataKeyAttribute)
+	}
+
+	wrappedKey, err := base64.StdEncoding.DecodeString(encodedKey)
+	if err != nil {
+		return fmt.Errorf("cannot decrypt data: %w", err)
+	}
+
+	dataKey, err := c.provider.Unwrap(ctx, keyID, wrappedKey)
+	if err != nil {
+		return fmt.Errorf("cannot decrypt data: %w", err)
+	}
+
+	decrypted, err := open(dataKey, enc)
+	if err != nil {
+		return fmt.Errorf("cannot decrypt data: %w", err)
+	}
+
+	return codec.DecodeWithAttributesContext(ctx, c.codec, decrypted, attributes, out)
+}
+
+// CheckType checks that the provided Go type is compatible with the decorated codec, if it is a codec.TypeCheckerCodec.
+func (c *EncryptionCodec) CheckType(typ reflect.Type) error {
+	_, err := codec.CheckType(c.codec, typ)
+
+	return err
+}
+
+// EncryptedDefinitions returns, for each codec of the codec.CodecRegistry, a codec.CodecDefinition of its encrypted
+// variant named with the CodecNamePrefix.
+func EncryptedDefinitions(registry codec.CodecRegistry, provider KeyProvider) ([]codec.CodecDefinition, error) {
+	definitions := make([]codec.CodecDefinition, 0, len(registry.Names()))
+
+	for _, name := range registry.Names() {
+		c, err := registry.Get(name)
+		if err != nil {
+			return nil, err
+		}
+
+		definitions = append(definitions, codec.CodecDefinition{
+			Name:  CodecNamePrefix + name,
+			Codec: NewEncryptionCodec(c, provider),
+		})
+	}
+
+	return definitions, nil
+}
@@ -0,0 +1,257 @@
+package encryption_test
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"testing"
+
+	"github.com/ankorstore/yokai-contrib/fxgcppubsub/codec"
+	"github.com/ankorstore/yokai-contrib/fxgcppubsub/encryption"
+	"github.com/stretchr/testify/assert"
+)
+
+type person struct {
+	Name string `json:"name"`
+	Age  int    `json:"age"`
+}
+
+type errorKeyProvider struct{}
+
+func (p *errorKeyProvider) Wrap(context.Context, []byte) (string, []byte, error) {
+	return "", nil, fmt.Errorf("wrap error")
+}
+
+func (p *errorKeyProvider) Unwrap(context.Context, string, []byte) ([]byte, error) {
+	return nil, fmt.Errorf("unwrap error")
+}
+
+type ctxKey struct{}
+
+// contextKeyProvider records the context values the wrap and unwrap calls are made with.
+type contextKeyProvider struct {
+	encryption.KeyProvider
+	values []any
+}
+
+func (p *contextKeyProvider) Wrap(ctx context.Context, dataKey []byte) (string, []byte, error) {
+	p.values = append(p.values, ctx.Value(ctxKey{}))
+
+	return p.KeyProvider.Wrap(ctx, dataKey)
+}
+
+func (p *contextKeyProvider) Unwrap(ctx context.Context, keyID string, wrappedKey []byte) ([]byte, error) {
+	p.values = append(p.values, ctx.Value(ctxKey{}))
+
+	return p.KeyProvider.Unwrap(ctx, keyID, wrappedKey)
+}
+
+func newProvider(t *testing.T, currentKeyID string) *encryption.LocalKeyProvider {
+	t.Helper()
+
+	provider, err := encryption.NewLocalKeyProvider(currentKeyID, map[string][]byte{
+		"key-1": []byte("0123456789abcdef0123456789abcdef"),
+		"key-2": []byte("fedcba9876543210fedcba9876543210"),
+	})
+	assert.NoError(t, err)
+
+	return provider
+}
+
+func TestEncryptionCodec(t *testing.T) {
+	t.Parallel()
+
+	t.Run("encode and decode", func(t *testing.T) {
+		t.Parallel()
+
+		jsonCodec := codec.NewJsonCodec()
+		c := encryption.NewEncryptionCodec(jsonCodec, newProvider(t, "key-1"))
+		assert.Equal(t, jsonCodec, c.Codec())
+
+		enc, attributes, err := c.EncodeWithAttributes(person{Name: "test", Age: 12})
+		assert.NoError(t, err)
+		assert.NotContains(t, string(enc), "test")
+		assert.Equal(t, "key-1", attributes[encryption.KeyIDAttribute])
+		assert.NotEmpty(t, attributes[encryption.DataKeyAttribute])
+
+		var out person
+
+		err = c.DecodeWithAttributes(enc, attributes, &out)
+		assert.NoError(t, err)
+		assert.Equal(t, person{Name: "test", Age: 12}, out)
+	})
+
+	t.Run("random data keys", func(t *testing.T) {
+		t.Parallel()
+
+		c := encryption.NewEncryptionCodec(codec.NewRawCodec(), newProvider(t, "key-1"))
+
+		firstEnc, firstAttributes, err := c.EncodeWithAttributes([]byte("test"))
+		assert.NoError(t, err)
+
+		secondEnc, secondAttributes, err := c.EncodeWithAttributes([]byte("test"))
+		assert.NoError(t, err)
+
+		assert.NotEqual(t, firstEnc, secondEnc)
+		assert.NotEqual(t, firstAttributes[encryption.DataKeyAttribute], secondAttributes[encryption.DataKeyAttribute])
+	})
+
+	t.Run("decode after rotation", func(t *testing.T) {
+		t.Parallel()
+
+		enc, attributes, err := encryption.NewEncryptionCodec(codec.NewJsonCodec(), newProvider(t, "key-1")).
+			EncodeWithAttributes(person{Name: "test", Age: 12})
+		assert.NoError(t, err)
+
+		rotated := encryption.NewEncryptionCodec(codec.NewJsonCodec(), newProvider(t, "key-2"))
+
+		var out person
+
+		err = rotated.DecodeWithAttributes(enc, attributes, &out)
+		assert.NoError(t, err)
+		assert.Equal(t, person{Name: "test", Age: 12}, out)
+
+		_, attributes, err = rotated.EncodeWithAttributes(person{Name: "test", Age: 12})
+		assert.NoError(t, err)
+		assert.Equal(t, "key-2", attributes[encryption.KeyIDAttribute])
+	})
+
+	t.Run("decorated codec attributes", func(t *testing.T) {
+		t.Parallel()
+
+		c := encryption.NewEncryptionCodec(
+			codec.NewCloudEventsCodec(codec.CloudEventsBinaryMode, "test-source", "test.event"),
+			newProvider(t, "key-1"),
+		)
+
+		enc, attributes, err := c.EncodeWithAttributes(person{Name: "test", Age: 12})
+		assert.NoError(t, err)
+		assert.Equal(t, "test-source", attributes["ce-source"])
+		assert.Equal(t, "key-1", attributes[encryption.KeyIDAttribute])
+
+		var event codec.CloudEvent
+
+		err = c.DecodeWithAttributes(enc, attributes, &event)
+		assert.NoError(t, err)
+		assert.Equal(t, "test.event", event.Type)
+
+		var out person
+
+		err = event.DecodeData(&out)
+		assert.NoError(t, err)
+		assert.Equal(t, person{Name: "test", Age: 12}, out)
+	})
+
+	t.Run("encode and decode without attributes", func(t *testing.T) {
+		t.Parallel()
+
+		c := encryption.NewEncryptionCodec(codec.NewJsonCodec(), newProvider(t, "key-1"))
+
+		enc, err := c.Encode(person{Name: "test", Age: 12})
+		assert.NoError(t, err)
+
+		var out person
+
+		err = c.Decode(enc, &out)
+		assert.Error(t, err)
+		assert.Equal(t, "cannot decrypt data: missing encryption_key_id attribute", err.Error())
+	})
+
+	t.Run("decode errors", func(t *testing.T) {
+		t.Parallel()
+
+		c := encryption.NewEncryptionCodec(codec.NewJsonCodec(), newProvider(t, "key-1"))
+
+		enc, attributes, err := c.EncodeWithAttributes(person{Name: "test", Age: 12})
+		assert.NoError(t, err)
+
+		var out person
+
+		err = c.DecodeWithAttributes(enc, map[string]string{encryption.KeyIDAttribute: "key-1"}, &out)
+		assert.Error(t, err)
+		assert.Equal(t, "cannot decrypt data: missing encryption_data_key attribute", err.Error())
+
+		err = c.DecodeWithAttributes(enc, map[string]string{
+			encryption.KeyIDAttribute:   "key-1",
+			encryption.DataKeyAttribute: "!invalid",
+		}, &out)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "cannot decrypt data: illegal base64 data")
+
+		err = c.DecodeWithAttributes(enc, map[string]string{
+			encryption.KeyIDAttribute:   "key-2",
+			encryption.DataKeyAttribute: attributes[encryption.DataKeyAttribute],
+		}, &out)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "cannot decrypt data: cannot unwrap data key")
+
+		tampered := append([]byte(nil), enc...)
+		tampered[len(tampered)-1] ^= 0xff
+
+		err = c.DecodeWithAttributes(tampered, attributes, &out)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "cannot decrypt data: cannot open ciphertext")
+	})
+
+	t.Run("key provider context", func(t *testing.T) {
+		t.Parallel()
+
+		provider := &contextKeyProvider{KeyProvider: newProvider(t, "key-1")}
+		c := encryption.NewEncryptionCodec(codec.NewJsonCodec(), provider)
+
+		ctx := context.WithValue(context.Background(), ctxKey{}, "test")
+
+		enc, attributes, err := codec.EncodeWithAttributesContext(ctx, c, person{Name: "test", Age: 12})
+		assert.NoError(t, err)
+
+		var out person
+
+		err = codec.DecodeWithAttributesContext(ctx, c, enc, attributes, &out)
+		assert.NoError(t, err)
+		assert.Equal(t, person{Name: "test", Age: 12}, out)
+
+		assert.Equal(t, []any{"test", "test"}, provider.values)
+	})
+
+	t.Run("key provider errors", func(t *testing.T) {
+		t.Parallel()
+
+		c := encryption.NewEncryptionCodec(codec.NewJsonCodec(), &errorKeyProvider{})
+
+		_, _, err := c.EncodeWithAttributes(person{Name: "test", Age: 12})
+		assert.Error(t, err)
+		assert.Equal(t, "cannot encrypt data: wrap error", err.Error())
+
+		err = c.DecodeWithAttributes([]byte("test"), map[string]string{
+			encryption.KeyIDAttribute:   "key-1",
+			encryption.DataKeyAttribute: base64.StdEncoding.EncodeToString([]byte("test")),
+		}, &person{})
+		assert.Error(t, err)
+		assert.Equal(t, "cannot decrypt data: unwrap error", err.Error())
+	})
+
+	t.Run("decorated codec encode error", func(t *testing.T) {
+		t.Parallel()
+
+		c := encryption.NewEncryptionCodec(codec.NewJsonCodec(), newProvider(t, "key-1"))
+
+		_, _, err := c.EncodeWithAttributes(func() {})
+		assert.Error(t, err)
+	})
+}
+
+func TestEncryptedDefinitions(t *testing.T) {
+	t.Parallel()
+
+	registry := codec.NewDefaultCodecRegistry()
+
+	definitions, err := encryption.EncryptedDefinitions(registry, newProvider(t, "key-1"))
+	assert.NoError(t, err)
+	assert.Len(t, definitions, 2)
+
+	assert.Equal(t, "encrypted-json", definitions[0].Name)
+	assert.IsType(t, &encryption.EncryptionCodec{}, definitions[0].Codec)
+
+	assert.Equal(t, "encrypted-raw", definitions[1].Name)
+	assert.IsType(t, &encryption.EncryptionCodec{}, definitions[1].Codec)
+}
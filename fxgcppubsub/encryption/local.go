@@ -0,0 +1,117 @@
+package encryption
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+var _ KeyProvider = (*LocalKeyProvider)(nil)
+
+// LocalKeyProvider is a KeyProvider implementation wrapping the data keys with local AES keys, mostly for testing purposes.
+//
+// The data keys are wrapped with the current key, and unwrapped with the key they were wrapped with: to rotate keys,
+// add a new key as current, and keep the previous ones until their messages are consumed.
+//
+// The key ids are case-insensitive, and normalized to lower case, like the keys of the configuration maps.
+type LocalKeyProvider struct {
+	currentKeyID string
+	keys         map[string][]byte
+}
+
+// NewLocalKeyProvider returns a new LocalKeyProvider instance, for the current key id and a map of AES keys
+// (of 16, 24 or 32 bytes) by key id.
+func NewLocalKeyProvider(currentKeyID string, keys map[string][]byte) (*LocalKeyProvider, error) {
+	copied := make(map[string][]byte, len(keys))
+
+	for keyID, key := range keys {
+		normalizedKeyID := normalizeKeyID(keyID)
+
+		if _, ok := copied[normalizedKeyID]; ok {
+			return nil, fmt.Errorf("duplicate key %s", normalizedKeyID)
+		}
+
+		switch len(key) {
+		case 16, 24, 32:
+			copied[normalizedKeyID] = append([]byte(nil), key...)
+		default:
+			return nil, fmt.Errorf("invalid key %s size %d, expected 16, 24 or 32 bytes", keyID, len(key))
+		}
+	}
+
+	normalizedCurrentKeyID := normalizeKeyID(currentKeyID)
+
+	if _, ok := copied[normalizedCurrentKeyID]; !ok {
+		return nil, fmt.Errorf("cannot find current key %s", currentKeyID)
+	}
+
+	return &LocalKeyProvider{
+		currentKeyID: normalizedCurrentKeyID,
+		keys:         copied,
+	}, nil
+}
+
+// NewLocalKeyProviderFromBase64 returns a new LocalKeyProvider instance, for the current key id and a map of base64
+// encoded AES keys by key id.
+func NewLocalKeyProviderFromBase64(currentKeyID string, encodedKeys map[string]string) (*LocalKeyProvider, error) {
+	keys := make(map[string][]byte, len(encodedKeys))
+
+	for keyID, encodedKey := range encodedKeys {
+		key, err := base64.StdEncoding.DecodeString(encodedKey)
+		if err != nil {
+			return nil, fmt.Errorf("cannot decode key %s: %w", keyID, err)
+		}
+
+		keys[keyID] = key
+	}
+
+	return NewLocalKeyProvider(currentKeyID, keys)
+}
+
+// CurrentKeyID returns the id of the key used to wrap the data keys.
+func (p *LocalKeyProvider) CurrentKeyID() string {
+	return p.currentKeyID
+}
+
+// KeyIDs returns the sorted ids of the keys available to unwrap the data keys.
+func (p *LocalKeyProvider) KeyIDs() []string {
+	keyIDs := make([]string, 0, len(p.keys))
+	for keyID := range p.keys {
+		keyIDs = append(keyIDs, keyID)
+	}
+
+	sort.Strings(keyIDs)
+
+	return keyIDs
+}
+
+// Wrap encrypts the provided data key with the current key.
+func (p *LocalKeyProvider) Wrap(ctx context.Context, dataKey []byte) (string, []byte, error) {
+	wrappedKey, err := seal(p.keys[p.currentKeyID], dataKey)
+	if err != nil {
+		return "", nil, fmt.Errorf("cannot wrap data key: %w", err)
+	}
+
+	return p.currentKeyID, wrappedKey, nil
+}
+
+// Unwrap decrypts the provided wrapped data key with the key identified by keyID.
+func (p *LocalKeyProvider) Unwrap(ctx context.Context, keyID string, wrappedKey []byte) ([]byte, error) {
+	key, ok := p.keys[normalizeKeyID(keyID)]
+	if !ok {
+		return nil, fmt.Errorf("cannot find key %s", keyID)
+	}
+
+	dataKey, err := open(key, wrappedKey)
+	if err != nil {
+		return nil, fmt.Errorf("cannot unwrap data key: %w", err)
+	}
+
+	return dataKey, nil
+}
+
+func normalizeKeyID(keyID string) string {
+	return strings.ToLower(keyID)
+}
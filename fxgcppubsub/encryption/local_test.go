@@ -0,0 +1,125 @@
+package encryption_test
+
+import (
+	"context"
+	"encoding/base64"
+	"testing"
+
+	"github.com/ankorstore/yokai-contrib/fxgcppubsub/encryption"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLocalKeyProvider(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	oldKey := []byte("0123456789abcdef0123456789abcdef")
+	newKey := []byte("fedcba9876543210")
+
+	t.Run("wrap and unwrap", func(t *testing.T) {
+		t.Parallel()
+
+		provider, err := encryption.NewLocalKeyProvider("new", map[string][]byte{"old": oldKey, "new": newKey})
+		assert.NoError(t, err)
+
+		assert.Equal(t, "new", provider.CurrentKeyID())
+		assert.Equal(t, []string{"new", "old"}, provider.KeyIDs())
+
+		keyID, wrappedKey, err := provider.Wrap(ctx, []byte("data-key"))
+		assert.NoError(t, err)
+		assert.Equal(t, "new", keyID)
+		assert.NotEqual(t, []byte("data-key"), wrappedKey)
+
+		dataKey, err := provider.Unwrap(ctx, keyID, wrappedKey)
+		assert.NoError(t, err)
+		assert.Equal(t, []byte("data-key"), dataKey)
+	})
+
+	t.Run("unwrap after rotation", func(t *testing.T) {
+		t.Parallel()
+
+		oldProvider, err := encryption.NewLocalKeyProvider("old", map[string][]byte{"old": oldKey})
+		assert.NoError(t, err)
+
+		keyID, wrappedKey, err := oldProvider.Wrap(ctx, []byte("data-key"))
+		assert.NoError(t, err)
+
+		rotatedProvider, err := encryption.NewLocalKeyProvider("new", map[string][]byte{"old": oldKey, "new": newKey})
+		assert.NoError(t, err)
+
+		dataKey, err := rotatedProvider.Unwrap(ctx, keyID, wrappedKey)
+		assert.NoError(t, err)
+		assert.Equal(t, []byte("data-key"), dataKey)
+	})
+
+	t.Run("unwrap errors", func(t *testing.T) {
+		t.Parallel()
+
+		provider, err := encryption.NewLocalKeyProvider("old", map[string][]byte{"old": oldKey, "new": newKey})
+		assert.NoError(t, err)
+
+		_, wrappedKey, err := provider.Wrap(ctx, []byte("data-key"))
+		assert.NoError(t, err)
+
+		_, err = provider.Unwrap(ctx, "unknown", wrappedKey)
+		assert.Error(t, err)
+		assert.Equal(t, "cannot find key unknown", err.Error())
+
+		_, err = provider.Unwrap(ctx, "new", wrappedKey)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "cannot unwrap data key")
+
+		_, err = provider.Unwrap(ctx, "old", []byte("short"))
+		assert.Error(t, err)
+		assert.Equal(t, "cannot unwrap data key: ciphertext too short", err.Error())
+	})
+
+	t.Run("case-insensitive key ids", func(t *testing.T) {
+		t.Parallel()
+
+		provider, err := encryption.NewLocalKeyProvider("New", map[string][]byte{"Old": oldKey, "NEW": newKey})
+		assert.NoError(t, err)
+
+		assert.Equal(t, "new", provider.CurrentKeyID())
+		assert.Equal(t, []string{"new", "old"}, provider.KeyIDs())
+
+		keyID, wrappedKey, err := provider.Wrap(ctx, []byte("data-key"))
+		assert.NoError(t, err)
+		assert.Equal(t, "new", keyID)
+
+		dataKey, err := provider.Unwrap(ctx, "New", wrappedKey)
+		assert.NoError(t, err)
+		assert.Equal(t, []byte("data-key"), dataKey)
+
+		_, err = encryption.NewLocalKeyProvider("key", map[string][]byte{"key": oldKey, "KEY": newKey})
+		assert.Error(t, err)
+		assert.Equal(t, "duplicate key key", err.Error())
+	})
+
+	t.Run("invalid keys", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := encryption.NewLocalKeyProvider("missing", map[string][]byte{"old": oldKey})
+		assert.Error(t, err)
+		assert.Equal(t, "cannot find current key missing", err.Error())
+
+		_, err = encryption.NewLocalKeyProvider("invalid", map[string][]byte{"invalid": []byte("short")})
+		assert.Error(t, err)
+		assert.Equal(t, "invalid key invalid size 5, expected 16, 24 or 32 bytes", err.Error())
+	})
+
+	t.Run("from base64", func(t *testing.T) {
+		t.Parallel()
+
+		provider, err := encryption.NewLocalKeyProviderFromBase64("old", map[string]string{
+			"old": base64.StdEncoding.EncodeToString(oldKey),
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"old"}, provider.KeyIDs())
+
+		_, err = encryption.NewLocalKeyProviderFromBase64("old", map[string]string{"old": "!invalid"})
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "cannot decode key old")
+	})
+}
@@ -0,0 +1,11 @@
+package encryption
+
+import "context"
+
+// KeyProvider is the interface for key providers in charge to wrap and unwrap the data keys encrypting the messages data.
+//
+// Implementations can keep the key encryption keys locally, like the LocalKeyProvider, or delegate to a KMS.
+type KeyProvider interface {
+	Wrap(ctx context.Context, dataKey []byte) (keyID string, wrappedKey []byte, err error)
+	Unwrap(ctx context.Context, keyID string, wrappedKey []byte) ([]byte, error)
+}
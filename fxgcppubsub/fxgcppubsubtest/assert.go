@@ -0,0 +1,125 @@
+package fxgcppubsubtest
+
+import (
+	"bytes"
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/proto"
+)
+
+// AssertPublished allows to assert if a message matching the provided payload (compared decoded with the topic codec)
+// and containing the provided attributes was published on a topicID.
+func AssertPublished(tb testing.TB, inspector *Inspector, topicID string, expectedPayload any, expectedAttributes map[string]string) bool {
+	tb.Helper()
+
+	messages := inspector.PublishedMessages(topicID)
+
+	for _, m := range messages {
+		if containsAttributes(m.Attributes, expectedAttributes) && matchesPayload(inspector, m, expectedPayload) {
+			return true
+		}
+	}
+
+	tb.Errorf(
+		"cannot find message published on topic %s with matching payload %+v and attributes %+v, among %d published messages",
+		topicID,
+		expectedPayload,
+		expectedAttributes,
+		len(messages),
+	)
+
+	return false
+}
+
+// AssertNotPublished allows to assert if no message matching the provided payload (compared decoded with the topic codec)
+// and containing the provided attributes was published on a topicID.
+func AssertNotPublished(tb testing.TB, inspector *Inspector, topicID string, expectedPayload any, expectedAttributes map[string]string) bool {
+	tb.Helper()
+
+	for _, m := range inspector.PublishedMessages(topicID) {
+		if containsAttributes(m.Attributes, expectedAttributes) && matchesPayload(inspector, m, expectedPayload) {
+			tb.Errorf(
+				"can find message %s published on topic %s with matching payload %+v and attributes %+v",
+				m.ID,
+				topicID,
+				expectedPayload,
+				expectedAttributes,
+			)
+
+			return false
+		}
+	}
+
+	return true
+}
+
+// AssertNoUnackedMessages allows to assert if all the messages delivered at least once were acked.
+func AssertNoUnackedMessages(tb testing.TB, inspector *Inspector) bool {
+	tb.Helper()
+
+	unacked := inspector.UnackedMessages()
+	if len(unacked) > 0 {
+		ids := make([]string, len(unacked))
+		for i, m := range unacked {
+			ids[i] = m.ID
+		}
+
+		tb.Errorf("found %d delivered but unacked messages: %v", len(unacked), ids)
+
+		return false
+	}
+
+	return true
+}
+
+func containsAttributes(attributes map[string]string, expectedAttributes map[string]string) bool {
+	for k, v := range expectedAttributes {
+		if value, ok := attributes[k]; !ok || value != v {
+			return false
+		}
+	}
+
+	return true
+}
+
+func matchesPayload(inspector *Inspector, m *PublishedMessage, expectedPayload any) bool {
+	ctx := context.Background()
+
+	// same encoded data, for example with the raw codec, not supporting decoding
+	enc, err := inspector.Encode(ctx, m.TopicID, expectedPayload)
+	if err == nil && bytes.Equal(enc, m.Data) {
+		return true
+	}
+
+	// same decoded data
+	expectedType := reflect.TypeOf(expectedPayload)
+	if expectedType == nil {
+		return false
+	}
+
+	var out reflect.Value
+	if expectedType.Kind() == reflect.Pointer {
+		out = reflect.New(expectedType.Elem())
+	} else {
+		out = reflect.New(expectedType)
+	}
+
+	err = inspector.Decode(ctx, m, out.Interface())
+	if err != nil {
+		return false
+	}
+
+	if expectedMessage, ok := expectedPayload.(proto.Message); ok {
+		//nolint:forcetypeassert
+		return proto.Equal(expectedMessage, out.Interface().(proto.Message))
+	}
+
+	if expectedType.Kind() == reflect.Pointer {
+		return assert.ObjectsAreEqual(expectedPayload, out.Interface())
+	}
+
+	return assert.ObjectsAreEqual(expectedPayload, out.Elem().Interface())
+}
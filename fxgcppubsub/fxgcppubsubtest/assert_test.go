@@ -0,0 +1,118 @@
+package fxgcppubsubtest_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ankorstore/yokai-contrib/fxgcppubsub"
+	"github.com/ankorstore/yokai-contrib/fxgcppubsub/fxgcppubsubtest"
+	"github.com/ankorstore/yokai-contrib/fxgcppubsub/topic"
+	"github.com/ankorstore/yokai/fxconfig"
+	"github.com/ankorstore/yokai/fxlog"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/fx"
+	"go.uber.org/fx/fxtest"
+)
+
+func TestAssertions(t *testing.T) {
+	t.Setenv("APP_ENV", "test")
+	t.Setenv("APP_CONFIG_PATH", "../testdata/config")
+	t.Setenv("GCP_PROJECT_ID", "test-project")
+
+	var publisher fxgcppubsub.Publisher
+	var subscriber fxgcppubsub.Subscriber
+	var inspector *fxgcppubsubtest.Inspector
+
+	ctx := context.Background()
+
+	fxtest.New(
+		t,
+		fx.NopLogger,
+		fxconfig.FxConfigModule,
+		fxlog.FxLogModule,
+		fxgcppubsub.FxGcpPubSubModule,
+		fxgcppubsubtest.FxGcpPubSubTestModule,
+		fx.Supply(fx.Annotate(ctx, fx.As(new(context.Context)))),
+		fxgcppubsub.PrepareTopicAndSubscription(fxgcppubsub.PrepareTopicAndSubscriptionParams{
+			TopicID:        "test-topic",
+			SubscriptionID: "test-subscription",
+		}),
+		fxgcppubsub.PrepareTopicAndSubscription(fxgcppubsub.PrepareTopicAndSubscriptionParams{
+			TopicID:        "codec-topic",
+			SubscriptionID: "codec-subscription",
+		}),
+		fx.Populate(&publisher, &subscriber, &inspector),
+	).RequireStart().RequireStop()
+
+	publish := func(tb testing.TB, topicID string, data any, options ...topic.PublishOption) {
+		tb.Helper()
+
+		res, err := publisher.Publish(ctx, topicID, data, options...)
+		assert.NoError(tb, err)
+
+		_, err = res.Get(ctx)
+		assert.NoError(tb, err)
+	}
+
+	t.Run("assert published with raw codec", func(t *testing.T) {
+		inspector.Reset()
+
+		publish(t, "test-topic", []byte("test"), topic.WithMessageAttributes(map[string]string{"foo": "bar", "baz": "qux"}))
+
+		assert.True(t, fxgcppubsubtest.AssertPublished(t, inspector, "test-topic", []byte("test"), nil))
+		assert.True(t, fxgcppubsubtest.AssertPublished(t, inspector, "test-topic", "test", map[string]string{"foo": "bar"}))
+		assert.True(t, fxgcppubsubtest.AssertNotPublished(t, inspector, "test-topic", []byte("other"), nil))
+		assert.True(t, fxgcppubsubtest.AssertNotPublished(t, inspector, "test-topic", []byte("test"), map[string]string{"foo": "other"}))
+
+		mt := new(testing.T)
+		assert.False(t, fxgcppubsubtest.AssertPublished(mt, inspector, "test-topic", []byte("other"), nil))
+		assert.True(t, mt.Failed())
+
+		mt = new(testing.T)
+		assert.False(t, fxgcppubsubtest.AssertPublished(mt, inspector, "test-topic", []byte("test"), map[string]string{"invalid": "bar"}))
+		assert.True(t, mt.Failed())
+
+		mt = new(testing.T)
+		assert.False(t, fxgcppubsubtest.AssertNotPublished(mt, inspector, "test-topic", []byte("test"), nil))
+		assert.True(t, mt.Failed())
+	})
+
+	t.Run("assert published with decoding codec", func(t *testing.T) {
+		inspector.Reset()
+
+		publish(t, "codec-topic", &testEvent{Name: "test"})
+
+		assert.True(t, fxgcppubsubtest.AssertPublished(t, inspector, "codec-topic", &testEvent{Name: "test"}, nil))
+		assert.True(t, fxgcppubsubtest.AssertPublished(t, inspector, "codec-topic", testEvent{Name: "test"}, map[string]string{
+			"ce-type": "test.event",
+		}))
+		assert.True(t, fxgcppubsubtest.AssertNotPublished(t, inspector, "codec-topic", &testEvent{Name: "other"}, nil))
+
+		mt := new(testing.T)
+		assert.False(t, fxgcppubsubtest.AssertPublished(mt, inspector, "codec-topic", &testEvent{Name: "other"}, nil))
+		assert.True(t, mt.Failed())
+	})
+
+	t.Run("assert no unacked messages", func(t *testing.T) {
+		inspector.Reset()
+
+		publish(t, "test-topic", []byte("test"))
+
+		assert.True(t, fxgcppubsubtest.AssertNoUnackedMessages(t, inspector))
+
+		messages, err := subscriber.Pull(ctx, "test-subscription", 1, time.Second)
+		assert.NoError(t, err)
+		assert.Len(t, messages, 1)
+
+		mt := new(testing.T)
+		assert.False(t, fxgcppubsubtest.AssertNoUnackedMessages(mt, inspector))
+		assert.True(t, mt.Failed())
+
+		messages[0].Ack()
+
+		assert.Eventually(t, func() bool {
+			return fxgcppubsubtest.AssertNoUnackedMessages(new(testing.T), inspector)
+		}, time.Second, 10*time.Millisecond)
+	})
+}
@@ -0,0 +1,181 @@
+package fxgcppubsubtest
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"cloud.google.com/go/pubsub/pstest"
+	"github.com/ankorstore/yokai-contrib/fxgcppubsub/codec"
+	"github.com/ankorstore/yokai-contrib/fxgcppubsub/subscription"
+	"github.com/ankorstore/yokai-contrib/fxgcppubsub/topic"
+)
+
+// DefaultWaitInterval is the default interval between checks when waiting for messages.
+const DefaultWaitInterval = 10 * time.Millisecond
+
+// TopicResolver is the interface for the components resolving topics, like the fxgcppubsub.DefaultPublisher.
+type TopicResolver interface {
+	Topic(ctx context.Context, topicID string) (*topic.Topic, error)
+}
+
+// PublishedMessage represents a message published on a topic, and accepted by the pub/sub test server.
+type PublishedMessage struct {
+	ID          string
+	TopicID     string
+	Data        []byte
+	Attributes  map[string]string
+	OrderingKey string
+	PublishTime time.Time
+	Deliveries  int
+	Acks        int
+}
+
+// DeadLetteredMessage represents a message published on a dead-letter topic by the fxgcppubsub.Subscriber.
+type DeadLetteredMessage struct {
+	*PublishedMessage
+	Error             string
+	SubscriptionID    string
+	OriginalMessageID string
+	DeliveryAttempt   int
+}
+
+// Inspector allows to inspect the messages of the pub/sub test server.
+type Inspector struct {
+	server   *pstest.Server
+	recorder *PublishRecorder
+	resolver TopicResolver
+}
+
+// NewInspector returns a new Inspector instance.
+func NewInspector(server *pstest.Server, recorder *PublishRecorder, resolver TopicResolver) *Inspector {
+	return &Inspector{
+		server:   server,
+		recorder: recorder,
+		resolver: resolver,
+	}
+}
+
+// PublishedMessages returns the messages published on a topicID, in publication order.
+func (i *Inspector) PublishedMessages(topicID string) []*PublishedMessage {
+	// the recorded messages are fetched before the server ones: since they are recorded and given their id while the
+	// server lock is held, fetching the server messages afterward waits for their ids to be assigned
+	recordedMessages := i.recorder.Messages(topicID)
+
+	serverMessages := make(map[string]*pstest.Message)
+	for _, m := range i.server.Messages() {
+		serverMessages[m.ID] = m
+	}
+
+	var messages []*PublishedMessage
+
+	for _, recorded := range recordedMessages {
+		// refused by the server, or cleared
+		serverMessage, ok := serverMessages[recorded.MessageId]
+		if !ok {
+			continue
+		}
+
+		messages = append(messages, &PublishedMessage{
+			ID:          serverMessage.ID,
+			TopicID:     topicID,
+			Data:        serverMessage.Data,
+			Attributes:  serverMessage.Attributes,
+			OrderingKey: serverMessage.OrderingKey,
+			PublishTime: serverMessage.PublishTime,
+			Deliveries:  serverMessage.Deliveries,
+			Acks:        serverMessage.Acks,
+		})
+	}
+
+	return messages
+}
+
+// WaitForMessages waits, for a maximum duration, until at least n messages were published on a topicID, and returns them.
+func (i *Inspector) WaitForMessages(ctx context.Context, topicID string, n int, duration time.Duration) ([]*PublishedMessage, error) {
+	ctx, cancel := context.WithTimeout(ctx, duration)
+	defer cancel()
+
+	ticker := time.NewTicker(DefaultWaitInterval)
+	defer ticker.Stop()
+
+	for {
+		messages := i.PublishedMessages(topicID)
+		if len(messages) >= n {
+			return messages, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return messages, fmt.Errorf("cannot wait for %d messages on topic %s, got %d: %w", n, topicID, len(messages), ctx.Err())
+		case <-ticker.C:
+		}
+	}
+}
+
+// DeadLetteredMessages returns the messages published on a dead-letter topicID by the fxgcppubsub.Subscriber.
+func (i *Inspector) DeadLetteredMessages(topicID string) []*DeadLetteredMessage {
+	var messages []*DeadLetteredMessage
+
+	for _, m := range i.PublishedMessages(topicID) {
+		subscriptionID, ok := m.Attributes[subscription.DeadLetterSubscriptionAttribute]
+		if !ok {
+			continue
+		}
+
+		//nolint:errcheck
+		deliveryAttempt, _ := strconv.Atoi(m.Attributes[subscription.DeadLetterDeliveryAttemptAttribute])
+
+		messages = append(messages, &DeadLetteredMessage{
+			PublishedMessage:  m,
+			Error:             m.Attributes[subscription.DeadLetterErrorAttribute],
+			SubscriptionID:    subscriptionID,
+			OriginalMessageID: m.Attributes[subscription.DeadLetterMessageIDAttribute],
+			DeliveryAttempt:   deliveryAttempt,
+		})
+	}
+
+	return messages
+}
+
+// UnackedMessages returns the messages delivered at least once, but never acked.
+func (i *Inspector) UnackedMessages() []*pstest.Message {
+	var messages []*pstest.Message
+
+	for _, m := range i.server.Messages() {
+		if m.Deliveries > 0 && m.Acks == 0 {
+			messages = append(messages, m)
+		}
+	}
+
+	return messages
+}
+
+// Decode decodes a message published on a topicID into the provided parameter, with the topic codec.
+func (i *Inspector) Decode(ctx context.Context, m *PublishedMessage, out any) error {
+	top, err := i.resolver.Topic(ctx, m.TopicID)
+	if err != nil {
+		return fmt.Errorf("cannot resolve topic: %w", err)
+	}
+
+	return codec.DecodeWithAttributesContext(ctx, top.Codec(), m.Data, m.Attributes, out)
+}
+
+// Encode encodes the provided input with the codec of a topicID.
+func (i *Inspector) Encode(ctx context.Context, topicID string, in any) ([]byte, error) {
+	top, err := i.resolver.Topic(ctx, topicID)
+	if err != nil {
+		return nil, fmt.Errorf("cannot resolve topic: %w", err)
+	}
+
+	enc, _, err := codec.EncodeWithAttributesContext(ctx, top.Codec(), in)
+
+	return enc, err
+}
+
+// Reset resets the server messages, and the recorded publications, for example between subtests.
+func (i *Inspector) Reset() {
+	i.server.ClearMessages()
+	i.recorder.Reset()
+}
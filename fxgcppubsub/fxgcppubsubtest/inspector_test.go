@@ -0,0 +1,212 @@
+package fxgcppubsubtest_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/ankorstore/yokai-contrib/fxgcppubsub"
+	"github.com/ankorstore/yokai-contrib/fxgcppubsub/fxgcppubsubtest"
+	"github.com/ankorstore/yokai-contrib/fxgcppubsub/message"
+	"github.com/ankorstore/yokai-contrib/fxgcppubsub/topic"
+	"github.com/ankorstore/yokai/fxconfig"
+	"github.com/ankorstore/yokai/fxlog"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/fx"
+	"go.uber.org/fx/fxtest"
+)
+
+type testEvent struct {
+	Name string `json:"name"`
+}
+
+func TestInspector(t *testing.T) {
+	t.Setenv("APP_ENV", "test")
+	t.Setenv("APP_CONFIG_PATH", "../testdata/config")
+	t.Setenv("GCP_PROJECT_ID", "test-project")
+
+	var publisher fxgcppubsub.Publisher
+	var subscriber fxgcppubsub.Subscriber
+	var inspector *fxgcppubsubtest.Inspector
+
+	ctx := context.Background()
+
+	fxtest.New(
+		t,
+		fx.NopLogger,
+		fxconfig.FxConfigModule,
+		fxlog.FxLogModule,
+		fxgcppubsub.FxGcpPubSubModule,
+		fxgcppubsubtest.FxGcpPubSubTestModule,
+		fx.Supply(fx.Annotate(ctx, fx.As(new(context.Context)))),
+		fxgcppubsub.PrepareTopicAndSubscription(fxgcppubsub.PrepareTopicAndSubscriptionParams{
+			TopicID:        "test-topic",
+			SubscriptionID: "test-subscription",
+		}),
+		fxgcppubsub.PrepareTopicAndSubscription(fxgcppubsub.PrepareTopicAndSubscriptionParams{
+			TopicID:        "codec-topic",
+			SubscriptionID: "codec-subscription",
+		}),
+		fxgcppubsub.PrepareTopicAndSubscription(fxgcppubsub.PrepareTopicAndSubscriptionParams{
+			TopicID:        "retry-topic",
+			SubscriptionID: "retry-subscription",
+		}),
+		fxgcppubsub.PrepareTopicAndSubscription(fxgcppubsub.PrepareTopicAndSubscriptionParams{
+			TopicID:        "dead-letter-topic",
+			SubscriptionID: "dead-letter-subscription",
+		}),
+		fx.Populate(&publisher, &subscriber, &inspector),
+	).RequireStart().RequireStop()
+
+	t.Run("published messages", func(t *testing.T) {
+		inspector.Reset()
+
+		for _, data := range []string{"first", "second"} {
+			res, err := publisher.Publish(ctx, "test-topic", []byte(data), topic.WithMessageAttributes(map[string]string{"foo": "bar"}))
+			assert.NoError(t, err)
+
+			_, err = res.Get(ctx)
+			assert.NoError(t, err)
+		}
+
+		messages := inspector.PublishedMessages("test-topic")
+		assert.Len(t, messages, 2)
+
+		assert.NotEmpty(t, messages[0].ID)
+		assert.Equal(t, "test-topic", messages[0].TopicID)
+		assert.Equal(t, []byte("first"), messages[0].Data)
+		assert.Equal(t, map[string]string{"foo": "bar"}, messages[0].Attributes)
+		assert.False(t, messages[0].PublishTime.IsZero())
+		assert.Equal(t, []byte("second"), messages[1].Data)
+
+		assert.Empty(t, inspector.PublishedMessages("codec-topic"))
+	})
+
+	t.Run("decode and encode published messages with the topic codec", func(t *testing.T) {
+		inspector.Reset()
+
+		res, err := publisher.Publish(ctx, "codec-topic", &testEvent{Name: "test"})
+		assert.NoError(t, err)
+
+		_, err = res.Get(ctx)
+		assert.NoError(t, err)
+
+		messages := inspector.PublishedMessages("codec-topic")
+		assert.Len(t, messages, 1)
+
+		var event testEvent
+		err = inspector.Decode(ctx, messages[0], &event)
+		assert.NoError(t, err)
+		assert.Equal(t, testEvent{Name: "test"}, event)
+
+		enc, err := inspector.Encode(ctx, "codec-topic", &testEvent{Name: "test"})
+		assert.NoError(t, err)
+		assert.JSONEq(t, `{"name":"test"}`, string(enc))
+	})
+
+	t.Run("wait for published messages", func(t *testing.T) {
+		inspector.Reset()
+
+		go func() {
+			for i := 0; i < 3; i++ {
+				time.Sleep(10 * time.Millisecond)
+
+				//nolint:errcheck
+				publisher.Publish(ctx, "test-topic", []byte(fmt.Sprintf("message %d", i)))
+			}
+		}()
+
+		messages, err := inspector.WaitForMessages(ctx, "test-topic", 3, 2*time.Second)
+		assert.NoError(t, err)
+		assert.Len(t, messages, 3)
+	})
+
+	t.Run("wait for published messages timeout", func(t *testing.T) {
+		inspector.Reset()
+
+		res, err := publisher.Publish(ctx, "test-topic", []byte("test"))
+		assert.NoError(t, err)
+
+		_, err = res.Get(ctx)
+		assert.NoError(t, err)
+
+		messages, err := inspector.WaitForMessages(ctx, "test-topic", 2, 50*time.Millisecond)
+		assert.Error(t, err)
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.Contains(t, err.Error(), "cannot wait for 2 messages on topic test-topic, got 1")
+		assert.Len(t, messages, 1)
+	})
+
+	t.Run("dead-lettered messages", func(t *testing.T) {
+		inspector.Reset()
+
+		res, err := publisher.Publish(ctx, "retry-topic", []byte("test"))
+		assert.NoError(t, err)
+
+		_, err = res.Get(ctx)
+		assert.NoError(t, err)
+
+		subCtx, cancel := context.WithCancel(ctx)
+		defer cancel()
+
+		//nolint:errcheck
+		go subscriber.SubscribeWithRetry(subCtx, "retry-subscription", func(ctx context.Context, m *message.Message) error {
+			return fmt.Errorf("test error")
+		})
+
+		_, err = inspector.WaitForMessages(ctx, "dead-letter-topic", 1, 2*time.Second)
+		assert.NoError(t, err)
+
+		messages := inspector.DeadLetteredMessages("dead-letter-topic")
+		assert.Len(t, messages, 1)
+
+		assert.Equal(t, []byte("test"), messages[0].Data)
+		assert.Equal(t, "test error", messages[0].Error)
+		assert.Equal(t, "retry-subscription", messages[0].SubscriptionID)
+		assert.Equal(t, inspector.PublishedMessages("retry-topic")[0].ID, messages[0].OriginalMessageID)
+		assert.Equal(t, 3, messages[0].DeliveryAttempt)
+
+		assert.Empty(t, inspector.DeadLetteredMessages("test-topic"))
+	})
+
+	t.Run("unacked messages", func(t *testing.T) {
+		inspector.Reset()
+
+		res, err := publisher.Publish(ctx, "test-topic", []byte("test"))
+		assert.NoError(t, err)
+
+		id, err := res.Get(ctx)
+		assert.NoError(t, err)
+
+		assert.Empty(t, inspector.UnackedMessages())
+
+		messages, err := subscriber.Pull(ctx, "test-subscription", 1, time.Second)
+		assert.NoError(t, err)
+		assert.Len(t, messages, 1)
+
+		unacked := inspector.UnackedMessages()
+		assert.Len(t, unacked, 1)
+		assert.Equal(t, id, unacked[0].ID)
+
+		messages[0].Ack()
+
+		assert.Eventually(t, func() bool {
+			return len(inspector.UnackedMessages()) == 0
+		}, time.Second, 10*time.Millisecond)
+	})
+
+	t.Run("reset", func(t *testing.T) {
+		res, err := publisher.Publish(ctx, "test-topic", []byte("test"))
+		assert.NoError(t, err)
+
+		_, err = res.Get(ctx)
+		assert.NoError(t, err)
+
+		assert.NotEmpty(t, inspector.PublishedMessages("test-topic"))
+
+		inspector.Reset()
+
+		assert.Empty(t, inspector.PublishedMessages("test-topic"))
+	})
+}
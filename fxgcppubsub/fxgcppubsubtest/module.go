@@ -0,0 +1,39 @@
+package fxgcppubsubtest
+
+import (
+	"cloud.google.com/go/pubsub/pstest"
+	"github.com/ankorstore/yokai-contrib/fxgcppubsub"
+	"go.uber.org/fx"
+)
+
+// ModuleName is the module name.
+const ModuleName = "gcppubsub-test"
+
+// FxGcpPubSubTestModule is the [Fx] GCP pubsub test helpers module, to be used with [fxgcppubsub.FxGcpPubSubModule] in test mode.
+//
+// It records the publications on the pub/sub test server, and provides an [Inspector] to inspect and assert its messages.
+//
+// [Fx]: https://github.com/uber-go/fx
+var FxGcpPubSubTestModule = fx.Module(
+	ModuleName,
+	fx.Provide(
+		NewPublishRecorder,
+		NewFxGcpPubSubInspector,
+	),
+	fxgcppubsub.AsPubSubTestServerReactor(func(recorder *PublishRecorder) *PublishRecorder {
+		return recorder
+	}),
+)
+
+// FxGcpPubSubInspectorParam allows injection of the required dependencies in [NewFxGcpPubSubInspector].
+type FxGcpPubSubInspectorParam struct {
+	fx.In
+	Server    *pstest.Server
+	Recorder  *PublishRecorder
+	Publisher *fxgcppubsub.DefaultPublisher
+}
+
+// NewFxGcpPubSubInspector returns a new [Inspector] instance.
+func NewFxGcpPubSubInspector(p FxGcpPubSubInspectorParam) *Inspector {
+	return NewInspector(p.Server, p.Recorder, p.Publisher)
+}
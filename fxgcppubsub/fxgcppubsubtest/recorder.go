@@ -0,0 +1,71 @@
+package fxgcppubsubtest
+
+import (
+	"strings"
+	"sync"
+
+	"cloud.google.com/go/pubsub/apiv1/pubsubpb"
+	"github.com/ankorstore/yokai-contrib/fxgcppubsub"
+)
+
+var _ fxgcppubsub.Reactor = (*PublishRecorder)(nil)
+
+// PublishRecorder is a pub/sub test server reactor recording the publication requests per topic.
+type PublishRecorder struct {
+	messages map[string][]*pubsubpb.PubsubMessage
+	mutex    sync.Mutex
+}
+
+// NewPublishRecorder returns a new PublishRecorder instance.
+func NewPublishRecorder() *PublishRecorder {
+	return &PublishRecorder{
+		messages: make(map[string][]*pubsubpb.PubsubMessage),
+	}
+}
+
+// FuncNames returns the list of function names this reactor will react to.
+func (r *PublishRecorder) FuncNames() []string {
+	return []string{
+		"Publish",
+	}
+}
+
+// React is the reactor logic.
+func (r *PublishRecorder) React(req any) (bool, any, error) {
+	if publishReq, ok := req.(*pubsubpb.PublishRequest); ok {
+		r.mutex.Lock()
+		defer r.mutex.Unlock()
+
+		topicID := topicID(publishReq.Topic)
+
+		r.messages[topicID] = append(r.messages[topicID], publishReq.Messages...)
+	}
+
+	return false, nil, nil
+}
+
+// Messages returns the recorded messages of a topicID, in publication order.
+//
+// The message ids are assigned by the server after recording, and are empty for publications refused by the server.
+func (r *PublishRecorder) Messages(topicID string) []*pubsubpb.PubsubMessage {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	return append([]*pubsubpb.PubsubMessage(nil), r.messages[topicID]...)
+}
+
+// Reset resets the recorded messages.
+func (r *PublishRecorder) Reset() {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.messages = make(map[string][]*pubsubpb.PubsubMessage)
+}
+
+func topicID(topicName string) string {
+	if i := strings.LastIndex(topicName, "/topics/"); i >= 0 {
+		return topicName[i+len("/topics/"):]
+	}
+
+	return topicName
+}
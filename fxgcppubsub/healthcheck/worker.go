@@ -0,0 +1,113 @@
+package healthcheck
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ankorstore/yokai-contrib/fxgcppubsub/worker"
+	"github.com/ankorstore/yokai/config"
+	"github.com/ankorstore/yokai/healthcheck"
+)
+
+// WorkersProbeName is the name of the GCP pub/sub workers probe.
+const WorkersProbeName = "gcppubsub-workers"
+
+// GcpPubSubWorkersProbe is a probe compatible with the [healthcheck] module.
+//
+// [healthcheck]: https://github.com/ankorstore/yokai/tree/main/healthcheck
+type GcpPubSubWorkersProbe struct {
+	config     *config.Config
+	supervisor worker.WorkerSupervisor
+}
+
+// NewGcpPubSubWorkersProbe returns a new [GcpPubSubWorkersProbe].
+func NewGcpPubSubWorkersProbe(config *config.Config, supervisor worker.WorkerSupervisor) *GcpPubSubWorkersProbe {
+	return &GcpPubSubWorkersProbe{
+		config:     config,
+		supervisor: supervisor,
+	}
+}
+
+// Name returns the name of the [GcpPubSubWorkersProbe].
+func (p *GcpPubSubWorkersProbe) Name() string {
+	return WorkersProbeName
+}
+
+// Check returns a successful [healthcheck.CheckerProbeResult] if no worker is failed or stopped, if the workers with a
+// modules.gcppubsub.healthcheck.workers.max_idle.<subscription> duration did not stay idle longer than it, and if the
+// workers have an error rate not over modules.gcppubsub.healthcheck.workers.max_error_rate.
+//
+// A restarting worker does not fail the probe, since the supervisor is already recovering it.
+func (p *GcpPubSubWorkersProbe) Check(ctx context.Context) *healthcheck.CheckerProbeResult {
+	maxIdles := p.config.GetStringMapString("modules.gcppubsub.healthcheck.workers.max_idle")
+	maxErrorRate := p.config.GetFloat64("modules.gcppubsub.healthcheck.workers.max_error_rate")
+
+	success := true
+	var messages []string
+
+	for _, status := range p.supervisor.Statuses() {
+		var problems []string
+
+		if status.Status == worker.StatusFailed || status.Status == worker.StatusStopped {
+			problems = append(problems, describeStatus(status))
+		}
+
+		if maxIdle, ok := maxIdles[strings.ToLower(status.SubscriptionID)]; ok {
+			problems = append(problems, checkIdle(status, maxIdle)...)
+		}
+
+		if maxErrorRate > 0 && status.ErrorRate > maxErrorRate {
+			problems = append(problems, fmt.Sprintf("has an error rate of %.2f over %.2f", status.ErrorRate, maxErrorRate))
+		}
+
+		if len(problems) > 0 {
+			success = false
+			messages = append(messages, fmt.Sprintf("worker %s %s", status.SubscriptionID, strings.Join(problems, " and ")))
+
+			continue
+		}
+
+		switch {
+		case status.Status != worker.StatusRunning:
+			messages = append(messages, fmt.Sprintf("worker %s %s", status.SubscriptionID, describeStatus(status)))
+		case status.LastMessageAt.IsZero():
+			messages = append(messages, fmt.Sprintf("worker %s is running, no message processed yet", status.SubscriptionID))
+		default:
+			messages = append(messages, fmt.Sprintf(
+				"worker %s is running, last message processed at %s",
+				status.SubscriptionID,
+				status.LastMessageAt.Format(time.RFC3339),
+			))
+		}
+	}
+
+	return healthcheck.NewCheckerProbeResult(success, strings.Join(messages, ", "))
+}
+
+func describeStatus(status worker.WorkerStatus) string {
+	if status.LastError != nil {
+		return fmt.Sprintf("is %s (last error: %v)", status.Status, status.LastError)
+	}
+
+	return fmt.Sprintf("is %s", status.Status)
+}
+
+func checkIdle(status worker.WorkerStatus, maxIdle string) []string {
+	duration, err := time.ParseDuration(maxIdle)
+	if err != nil {
+		return []string{fmt.Sprintf("has an invalid max idle %q", maxIdle)}
+	}
+
+	lastActivity := status.LastMessageAt
+	if lastActivity.IsZero() {
+		lastActivity = status.StartedAt
+	}
+
+	if duration > 0 && !lastActivity.IsZero() && time.Since(lastActivity) > duration {
+		return []string{fmt.Sprintf("is idle since %s", lastActivity.Format(time.RFC3339))}
+	}
+
+	return nil
+}
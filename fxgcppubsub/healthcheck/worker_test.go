@@ -0,0 +1,164 @@
+package healthcheck_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ankorstore/yokai-contrib/fxgcppubsub/healthcheck"
+	"github.com/ankorstore/yokai-contrib/fxgcppubsub/worker"
+	"github.com/ankorstore/yokai/config"
+	"github.com/stretchr/testify/assert"
+)
+
+type workerSupervisorStub struct {
+	statuses []worker.WorkerStatus
+}
+
+func (s *workerSupervisorStub) Start(context.Context) {}
+
+func (s *workerSupervisorStub) Stop(context.Context) error {
+	return nil
+}
+
+func (s *workerSupervisorStub) Workers() []*worker.Worker {
+	return nil
+}
+
+func (s *workerSupervisorStub) Statuses() []worker.WorkerStatus {
+	return s.statuses
+}
+
+func TestGcpPubSubWorkersProbe(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	now := time.Now()
+	lastMessageAt := now.Add(-time.Minute)
+
+	newConfig := func(t *testing.T, values map[string]any) *config.Config {
+		t.Helper()
+
+		cfg, err := config.NewDefaultConfigFactory().Create(config.WithFilePaths("../testdata/config"))
+		assert.NoError(t, err)
+
+		for k, v := range values {
+			cfg.Set(k, v)
+		}
+
+		return cfg
+	}
+
+	t.Run("probe name", func(t *testing.T) {
+		t.Parallel()
+
+		probe := &healthcheck.GcpPubSubWorkersProbe{}
+
+		assert.Equal(t, "gcppubsub-workers", probe.Name())
+	})
+
+	t.Run("probe success when workers are running", func(t *testing.T) {
+		t.Parallel()
+
+		probe := healthcheck.NewGcpPubSubWorkersProbe(
+			newConfig(t, nil),
+			&workerSupervisorStub{
+				statuses: []worker.WorkerStatus{
+					{SubscriptionID: "sub-1", Status: worker.StatusRunning, StartedAt: now},
+					{SubscriptionID: "sub-2", Status: worker.StatusRunning, StartedAt: now, LastMessageAt: lastMessageAt, ErrorRate: 1},
+				},
+			},
+		)
+
+		res := probe.Check(ctx)
+		assert.True(t, res.Success)
+		assert.Equal(
+			t,
+			"worker sub-1 is running, no message processed yet, worker sub-2 is running, last message processed at "+lastMessageAt.Format(time.RFC3339),
+			res.Message,
+		)
+	})
+
+	t.Run("probe failure when workers are not running", func(t *testing.T) {
+		t.Parallel()
+
+		probe := healthcheck.NewGcpPubSubWorkersProbe(
+			newConfig(t, nil),
+			&workerSupervisorStub{
+				statuses: []worker.WorkerStatus{
+					{SubscriptionID: "sub-1", Status: worker.StatusRunning, StartedAt: now},
+					{SubscriptionID: "sub-2", Status: worker.StatusFailed, LastError: assert.AnError},
+					{SubscriptionID: "sub-3", Status: worker.StatusStopped},
+				},
+			},
+		)
+
+		res := probe.Check(ctx)
+		assert.False(t, res.Success)
+		assert.Equal(
+			t,
+			"worker sub-1 is running, no message processed yet, worker sub-2 is failed (last error: "+assert.AnError.Error()+"), worker sub-3 is stopped",
+			res.Message,
+		)
+	})
+
+	t.Run("probe success when workers are restarting or idle", func(t *testing.T) {
+		t.Parallel()
+
+		probe := healthcheck.NewGcpPubSubWorkersProbe(
+			newConfig(t, nil),
+			&workerSupervisorStub{
+				statuses: []worker.WorkerStatus{
+					{SubscriptionID: "sub-1", Status: worker.StatusRestarting, Restarts: 2, LastError: assert.AnError},
+					{SubscriptionID: "sub-2", Status: worker.StatusIdle},
+				},
+			},
+		)
+
+		res := probe.Check(ctx)
+		assert.True(t, res.Success)
+		assert.Equal(
+			t,
+			"worker sub-1 is restarting (last error: "+assert.AnError.Error()+"), worker sub-2 is idle",
+			res.Message,
+		)
+	})
+
+	t.Run("probe failure when workers are idle or over error rate", func(t *testing.T) {
+		t.Parallel()
+
+		probe := healthcheck.NewGcpPubSubWorkersProbe(
+			newConfig(t, map[string]any{
+				"modules.gcppubsub.healthcheck.workers.max_idle": map[string]any{
+					"sub-1": "30s",
+					"sub-2": "30s",
+					"sub-3": "30s",
+					"sub-5": "invalid",
+				},
+				"modules.gcppubsub.healthcheck.workers.max_error_rate": 0.5,
+			}),
+			&workerSupervisorStub{
+				statuses: []worker.WorkerStatus{
+					{SubscriptionID: "sub-1", Status: worker.StatusRunning, StartedAt: now, LastMessageAt: now, ErrorRate: 0.5},
+					{SubscriptionID: "Sub-2", Status: worker.StatusRunning, StartedAt: lastMessageAt},
+					{SubscriptionID: "sub-3", Status: worker.StatusRunning, StartedAt: lastMessageAt, LastMessageAt: lastMessageAt, ErrorRate: 0.75},
+					{SubscriptionID: "sub-4", Status: worker.StatusRunning, StartedAt: lastMessageAt, LastMessageAt: lastMessageAt},
+					{SubscriptionID: "sub-5", Status: worker.StatusRunning, StartedAt: now},
+				},
+			},
+		)
+
+		res := probe.Check(ctx)
+		assert.False(t, res.Success)
+		assert.Equal(
+			t,
+			"worker sub-1 is running, last message processed at "+now.Format(time.RFC3339)+
+				", worker Sub-2 is idle since "+lastMessageAt.Format(time.RFC3339)+
+				", worker sub-3 is idle since "+lastMessageAt.Format(time.RFC3339)+" and has an error rate of 0.75 over 0.50"+
+				", worker sub-4 is running, last message processed at "+lastMessageAt.Format(time.RFC3339)+
+				`, worker sub-5 has an invalid max idle "invalid"`,
+			res.Message,
+		)
+	})
+}
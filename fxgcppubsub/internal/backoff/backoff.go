@@ -0,0 +1,25 @@
+package backoff
+
+import (
+	"math"
+	"time"
+)
+
+// Exponential returns the exponential backoff to wait after a provided attempt (starting at 1), from minBackoff
+// multiplied by multiplier (at least 1) for each following attempt, and capped to maxBackoff if positive.
+func Exponential(attempt int, minBackoff time.Duration, maxBackoff time.Duration, multiplier float64) time.Duration {
+	if attempt < 1 {
+		attempt = 1
+	}
+
+	if multiplier < 1 {
+		multiplier = 1
+	}
+
+	backoff := float64(minBackoff) * math.Pow(multiplier, float64(attempt-1))
+	if maxBackoff > 0 && backoff > float64(maxBackoff) {
+		return maxBackoff
+	}
+
+	return time.Duration(backoff)
+}
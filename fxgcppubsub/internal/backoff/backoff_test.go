@@ -0,0 +1,35 @@
+package backoff_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ankorstore/yokai-contrib/fxgcppubsub/internal/backoff"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExponential(t *testing.T) {
+	t.Parallel()
+
+	t.Run("exponential with max", func(t *testing.T) {
+		t.Parallel()
+
+		assert.Equal(t, 10*time.Millisecond, backoff.Exponential(0, 10*time.Millisecond, 50*time.Millisecond, 2))
+		assert.Equal(t, 10*time.Millisecond, backoff.Exponential(1, 10*time.Millisecond, 50*time.Millisecond, 2))
+		assert.Equal(t, 20*time.Millisecond, backoff.Exponential(2, 10*time.Millisecond, 50*time.Millisecond, 2))
+		assert.Equal(t, 40*time.Millisecond, backoff.Exponential(3, 10*time.Millisecond, 50*time.Millisecond, 2))
+		assert.Equal(t, 50*time.Millisecond, backoff.Exponential(4, 10*time.Millisecond, 50*time.Millisecond, 2))
+	})
+
+	t.Run("constant without multiplier", func(t *testing.T) {
+		t.Parallel()
+
+		assert.Equal(t, 10*time.Millisecond, backoff.Exponential(5, 10*time.Millisecond, 0, 0))
+	})
+
+	t.Run("unbounded without max", func(t *testing.T) {
+		t.Parallel()
+
+		assert.Equal(t, 160*time.Millisecond, backoff.Exponential(5, 10*time.Millisecond, 0, 2))
+	})
+}
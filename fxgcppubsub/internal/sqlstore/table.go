@@ -0,0 +1,87 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const (
+	DialectMySQL    = "mysql"
+	DialectPostgres = "postgres"
+)
+
+// ErrInvalidDialect is returned for an unsupported SQL dialect.
+var ErrInvalidDialect = errors.New("invalid dialect")
+
+// Table represents the table of a database/sql backed store, with its SQL dialect (mysql or postgres).
+type Table struct {
+	db      *sql.DB
+	name    string
+	dialect string
+}
+
+// NewTable returns a new Table instance, defaulting to the mysql dialect.
+func NewTable(db *sql.DB, name string, dialect string) (*Table, error) {
+	if dialect == "" {
+		dialect = DialectMySQL
+	}
+
+	if dialect != DialectMySQL && dialect != DialectPostgres {
+		return nil, fmt.Errorf("%w %q", ErrInvalidDialect, dialect)
+	}
+
+	return &Table{
+		db:      db,
+		name:    name,
+		dialect: dialect,
+	}, nil
+}
+
+// Name returns the table name.
+func (t *Table) Name() string {
+	return t.name
+}
+
+// Dialect returns the table SQL dialect.
+func (t *Table) Dialect() string {
+	return t.dialect
+}
+
+// Create creates the table if not existing, with the DDL of its dialect, in which %s is replaced by the table name.
+func (t *Table) Create(ctx context.Context, mysqlDDL string, postgresDDL string) error {
+	ddl := mysqlDDL
+	if t.dialect == DialectPostgres {
+		ddl = postgresDDL
+	}
+
+	_, err := t.db.ExecContext(ctx, fmt.Sprintf(ddl, t.name))
+
+	return err
+}
+
+// Query returns the provided query with %s replaced by the table name (or %[1]s if referenced several times),
+// and with the ? placeholders rebound as $n for the postgres dialect.
+func (t *Table) Query(query string) string {
+	query = fmt.Sprintf(query, t.name)
+
+	if t.dialect != DialectPostgres {
+		return query
+	}
+
+	var builder strings.Builder
+
+	n := 0
+	for _, r := range query {
+		if r == '?' {
+			n++
+			builder.WriteString(fmt.Sprintf("$%d", n))
+		} else {
+			builder.WriteRune(r)
+		}
+	}
+
+	return builder.String()
+}
@@ -0,0 +1,85 @@
+package sqlstore_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ankorstore/yokai-contrib/fxgcppubsub/internal/sqlstore"
+	"github.com/ankorstore/yokai-contrib/fxgcppubsub/testdata/mysql"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewTable(t *testing.T) {
+	t.Parallel()
+
+	t.Run("with defaults", func(t *testing.T) {
+		t.Parallel()
+
+		table, err := sqlstore.NewTable(nil, "test", "")
+		assert.NoError(t, err)
+		assert.Equal(t, "test", table.Name())
+		assert.Equal(t, sqlstore.DialectMySQL, table.Dialect())
+	})
+
+	t.Run("with postgres dialect", func(t *testing.T) {
+		t.Parallel()
+
+		table, err := sqlstore.NewTable(nil, "test", sqlstore.DialectPostgres)
+		assert.NoError(t, err)
+		assert.Equal(t, sqlstore.DialectPostgres, table.Dialect())
+	})
+
+	t.Run("with invalid dialect", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := sqlstore.NewTable(nil, "test", "invalid")
+		assert.ErrorIs(t, err, sqlstore.ErrInvalidDialect)
+		assert.Equal(t, `invalid dialect "invalid"`, err.Error())
+	})
+}
+
+func TestTableQuery(t *testing.T) {
+	t.Parallel()
+
+	query := "SELECT id FROM %[1]s o WHERE o.a = ? AND NOT EXISTS (SELECT 1 FROM %[1]s p WHERE p.b = ?)"
+
+	mysqlTable, err := sqlstore.NewTable(nil, "test", sqlstore.DialectMySQL)
+	assert.NoError(t, err)
+
+	assert.Equal(
+		t,
+		"SELECT id FROM test o WHERE o.a = ? AND NOT EXISTS (SELECT 1 FROM test p WHERE p.b = ?)",
+		mysqlTable.Query(query),
+	)
+
+	postgresTable, err := sqlstore.NewTable(nil, "test", sqlstore.DialectPostgres)
+	assert.NoError(t, err)
+
+	assert.Equal(
+		t,
+		"SELECT id FROM test o WHERE o.a = $1 AND NOT EXISTS (SELECT 1 FROM test p WHERE p.b = $2)",
+		postgresTable.Query(query),
+	)
+}
+
+func TestTableCreate(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	db := mysql.StartTestServer(t)
+
+	table, err := sqlstore.NewTable(db, "test", sqlstore.DialectMySQL)
+	assert.NoError(t, err)
+
+	for i := 0; i < 2; i++ {
+		err = table.Create(
+			ctx,
+			"CREATE TABLE IF NOT EXISTS %s (id BIGINT NOT NULL PRIMARY KEY)",
+			"CREATE TABLE IF NOT EXISTS %s (id BIGSERIAL PRIMARY KEY)",
+		)
+		assert.NoError(t, err)
+	}
+
+	_, err = db.ExecContext(ctx, table.Query("INSERT INTO %s (id) VALUES (?)"), 1)
+	assert.NoError(t, err)
+}
@@ -1,14 +1,34 @@
 package message
 
 import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
 	"cloud.google.com/go/pubsub"
 	"github.com/ankorstore/yokai-contrib/fxgcppubsub/codec"
 )
 
+// AcknowledgementFunc represents a callback executed on the first message Ack (ack=true) or Nack (ack=false).
+type AcknowledgementFunc func(ack bool)
+
+// AckHandler is the interface for the acknowledgement handlers of messages not received by streaming pull
+// (for example by unary pull), for which the base message acknowledgement has no effect.
+type AckHandler interface {
+	OnAck()
+	OnNack()
+	OnModifyAckDeadline(ctx context.Context, deadline time.Duration) error
+}
+
 // Message represents a pub/sub message with an associated codec.Codec.
 type Message struct {
 	codec   codec.Codec
 	message *pubsub.Message
+	ackh    AckHandler
+	funcs   []AcknowledgementFunc
+	once    sync.Once
+	mutex   sync.Mutex
 }
 
 // NewMessage returns a new Message instance.
@@ -19,6 +39,15 @@ func NewMessage(codec codec.Codec, message *pubsub.Message) *Message {
 	}
 }
 
+// NewMessageWithAckHandler returns a new Message instance, acknowledged with the provided AckHandler.
+func NewMessageWithAckHandler(codec codec.Codec, message *pubsub.Message, ackh AckHandler) *Message {
+	return &Message{
+		codec:   codec,
+		message: message,
+		ackh:    ackh,
+	}
+}
+
 // Codec returns the associated codec.Codec.
 func (m *Message) Codec() codec.Codec {
 	return m.codec
@@ -30,8 +59,21 @@ func (m *Message) BaseMessage() *pubsub.Message {
 }
 
 // Decode decodes the message content into the provided parameter.
+//
+// If the codec is a codec.AttributesCodec, the message attributes are used for decoding. If the message carries
+// a schema revision id attribute, and the codec is a codec.RevisionCodec, the content is decoded with the schema revision it was encoded with.
 func (m *Message) Decode(out any) error {
-	return m.codec.Decode(m.message.Data, out)
+	return m.DecodeContext(context.Background(), out)
+}
+
+// DecodeContext is like Decode, providing the context to the codecs needing it, like the encryption.EncryptionCodec.
+func (m *Message) DecodeContext(ctx context.Context, out any) error {
+	return codec.DecodeWithAttributesContext(ctx, m.codec, m.message.Data, m.message.Attributes, out)
+}
+
+// SchemaRevisionID returns the schema revision id the message was validated against, if provided by the server.
+func (m *Message) SchemaRevisionID() string {
+	return m.message.Attributes[codec.SchemaRevisionIDAttribute]
 }
 
 // ID returns the base message id.
@@ -49,14 +91,64 @@ func (m *Message) Attributes() map[string]string {
 	return m.message.Attributes
 }
 
+// OrderingKey returns the base message ordering key.
+func (m *Message) OrderingKey() string {
+	return m.message.OrderingKey
+}
+
+// DeliveryAttempt returns the base message delivery attempt, if provided by the server.
+func (m *Message) DeliveryAttempt() *int {
+	return m.message.DeliveryAttempt
+}
+
+// OnAcknowledgement registers a AcknowledgementFunc, executed on the first call to Ack or Nack.
+func (m *Message) OnAcknowledgement(f AcknowledgementFunc) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.funcs = append(m.funcs, f)
+}
+
 // Ack indicates the successful message processing.
 // Calls to Ack or Nack have no effect after the first call.
 func (m *Message) Ack() {
 	m.message.Ack()
+	m.acknowledge(true)
 }
 
 // Nack indicates that the client will not or cannot process the message.
 // Calls to Ack or Nack have no effect after the first call.
 func (m *Message) Nack() {
 	m.message.Nack()
+	m.acknowledge(false)
+}
+
+// ModifyAckDeadline extends (or shortens) the message ack deadline, from now.
+//
+// It's only supported by messages with an AckHandler: the ack deadline of streamed messages is managed by the client.
+func (m *Message) ModifyAckDeadline(ctx context.Context, deadline time.Duration) error {
+	if m.ackh == nil {
+		return errors.New("cannot modify the ack deadline of a streamed message")
+	}
+
+	return m.ackh.OnModifyAckDeadline(ctx, deadline)
+}
+
+func (m *Message) acknowledge(ack bool) {
+	m.once.Do(func() {
+		if m.ackh != nil {
+			if ack {
+				m.ackh.OnAck()
+			} else {
+				m.ackh.OnNack()
+			}
+		}
+
+		m.mutex.Lock()
+		defer m.mutex.Unlock()
+
+		for _, f := range m.funcs {
+			f(ack)
+		}
+	})
 }
@@ -1,7 +1,9 @@
 package message_test
 
 import (
+	"context"
 	"testing"
+	"time"
 
 	"cloud.google.com/go/pubsub"
 	"github.com/ankorstore/yokai-contrib/fxgcppubsub/codec"
@@ -26,6 +28,72 @@ func TestMessage(t *testing.T) {
 		assert.Equal(t, "foo", msg.ID())
 		assert.Equal(t, []byte("bar"), msg.Data())
 		assert.Equal(t, map[string]string{"baz": "baz"}, msg.Attributes())
+		assert.Equal(t, "", msg.OrderingKey())
+		assert.Nil(t, msg.DeliveryAttempt())
+	})
+
+	t.Run("message schema revision id", func(t *testing.T) {
+		t.Parallel()
+
+		msg := message.NewMessage(codec.NewRawCodec(), createTestBaseMessage())
+		assert.Equal(t, "", msg.SchemaRevisionID())
+
+		msg = message.NewMessage(codec.NewRawCodec(), &pubsub.Message{
+			Attributes: map[string]string{codec.SchemaRevisionIDAttribute: "rev-1"},
+		})
+		assert.Equal(t, "rev-1", msg.SchemaRevisionID())
+	})
+
+	t.Run("message acknowledgement funcs", func(t *testing.T) {
+		t.Parallel()
+
+		msg := message.NewMessage(codec.NewRawCodec(), createTestBaseMessage())
+
+		var acks []bool
+
+		msg.OnAcknowledgement(func(ack bool) {
+			acks = append(acks, ack)
+		})
+
+		msg.Nack()
+		msg.Ack()
+		msg.Nack()
+
+		assert.Equal(t, []bool{false}, acks)
+	})
+
+	t.Run("message acknowledgement with ack handler", func(t *testing.T) {
+		t.Parallel()
+
+		ackh := &testAckHandler{}
+
+		msg := message.NewMessageWithAckHandler(codec.NewRawCodec(), createTestBaseMessage(), ackh)
+
+		var acks []bool
+
+		msg.OnAcknowledgement(func(ack bool) {
+			acks = append(acks, ack)
+		})
+
+		err := msg.ModifyAckDeadline(context.Background(), time.Minute)
+		assert.NoError(t, err)
+
+		msg.Ack()
+		msg.Nack()
+		msg.Ack()
+
+		assert.Equal(t, []string{"deadline:1m0s", "ack"}, ackh.calls)
+		assert.Equal(t, []bool{true}, acks)
+	})
+
+	t.Run("message ack deadline modification without ack handler", func(t *testing.T) {
+		t.Parallel()
+
+		msg := message.NewMessage(codec.NewRawCodec(), createTestBaseMessage())
+
+		err := msg.ModifyAckDeadline(context.Background(), time.Minute)
+		assert.Error(t, err)
+		assert.Equal(t, "cannot modify the ack deadline of a streamed message", err.Error())
 	})
 
 	t.Run("message decoding failure without schema", func(t *testing.T) {
@@ -39,10 +107,43 @@ func TestMessage(t *testing.T) {
 		var out []byte
 		err := msg.Decode(&out)
 		assert.Error(t, err)
-		assert.Equal(t, "data without schema cannot be decoded", err.Error())
+		assert.ErrorIs(t, err, codec.ErrNoCodecConfigured)
+		assert.Contains(t, err.Error(), "modules.gcppubsub.subscriptions.<subscription id>.codec")
+	})
+
+	t.Run("message decoding with context", func(t *testing.T) {
+		t.Parallel()
+
+		baseMsg := createTestBaseMessage()
+		baseMsg.Data = []byte(`"test"`)
+
+		msg := message.NewMessage(codec.NewJsonCodec(), baseMsg)
+
+		var out string
+		err := msg.DecodeContext(context.Background(), &out)
+		assert.NoError(t, err)
+		assert.Equal(t, "test", out)
 	})
 }
 
+type testAckHandler struct {
+	calls []string
+}
+
+func (h *testAckHandler) OnAck() {
+	h.calls = append(h.calls, "ack")
+}
+
+func (h *testAckHandler) OnNack() {
+	h.calls = append(h.calls, "nack")
+}
+
+func (h *testAckHandler) OnModifyAckDeadline(_ context.Context, deadline time.Duration) error {
+	h.calls = append(h.calls, "deadline:"+deadline.String())
+
+	return nil
+}
+
 func createTestBaseMessage() *pubsub.Message {
 	return &pubsub.Message{
 		ID:         "foo",
@@ -0,0 +1,137 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// DefaultSubsystem is the default metrics subsystem.
+const DefaultSubsystem = "gcppubsub"
+
+var _ prometheus.Collector = (*Metrics)(nil)
+
+// Metrics holds the pub/sub publications and subscriptions metrics, and is a [prometheus.Collector].
+type Metrics struct {
+	PublishedMessages   *prometheus.CounterVec
+	PublishErrors       *prometheus.CounterVec
+	PublishDuration     *prometheus.HistogramVec
+	ReceivedMessages    *prometheus.CounterVec
+	AckedMessages       *prometheus.CounterVec
+	NackedMessages      *prometheus.CounterVec
+	HandlerDuration     *prometheus.HistogramVec
+	OutstandingMessages *prometheus.GaugeVec
+}
+
+// NewMetrics returns a new Metrics instance, for a provided namespace, subsystem and histograms buckets.
+func NewMetrics(namespace string, subsystem string, buckets []float64) *Metrics {
+	if subsystem == "" {
+		subsystem = DefaultSubsystem
+	}
+
+	if len(buckets) == 0 {
+		buckets = prometheus.DefBuckets
+	}
+
+	return &Metrics{
+		PublishedMessages: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: namespace,
+				Subsystem: subsystem,
+				Name:      "published_messages_total",
+				Help:      "Number of messages published per topic",
+			},
+			[]string{"topic"},
+		),
+		PublishErrors: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: namespace,
+				Subsystem: subsystem,
+				Name:      "publish_errors_total",
+				Help:      "Number of messages publication errors per topic",
+			},
+			[]string{"topic"},
+		),
+		PublishDuration: prometheus.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Namespace: namespace,
+				Subsystem: subsystem,
+				Name:      "publish_duration_seconds",
+				Help:      "Duration of messages publications per topic",
+				Buckets:   buckets,
+			},
+			[]string{"topic"},
+		),
+		ReceivedMessages: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: namespace,
+				Subsystem: subsystem,
+				Name:      "received_messages_total",
+				Help:      "Number of messages received per subscription",
+			},
+			[]string{"subscription"},
+		),
+		AckedMessages: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: namespace,
+				Subsystem: subsystem,
+				Name:      "acked_messages_total",
+				Help:      "Number of messages acked per subscription",
+			},
+			[]string{"subscription"},
+		),
+		NackedMessages: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: namespace,
+				Subsystem: subsystem,
+				Name:      "nacked_messages_total",
+				Help:      "Number of messages nacked per subscription",
+			},
+			[]string{"subscription"},
+		),
+		HandlerDuration: prometheus.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Namespace: namespace,
+				Subsystem: subsystem,
+				Name:      "handler_duration_seconds",
+				Help:      "Duration of messages handling per subscription",
+				Buckets:   buckets,
+			},
+			[]string{"subscription"},
+		),
+		OutstandingMessages: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Subsystem: subsystem,
+				Name:      "outstanding_messages",
+				Help:      "Number of messages received but not yet acked or nacked per subscription",
+			},
+			[]string{"subscription"},
+		),
+	}
+}
+
+// Describe implements [prometheus.Collector].
+func (m *Metrics) Describe(ch chan<- *prometheus.Desc) {
+	for _, c := range m.collectors() {
+		c.Describe(ch)
+	}
+}
+
+// Collect implements [prometheus.Collector].
+func (m *Metrics) Collect(ch chan<- prometheus.Metric) {
+	for _, c := range m.collectors() {
+		c.Collect(ch)
+	}
+}
+
+func (m *Metrics) collectors() []prometheus.Collector {
+	return []prometheus.Collector{
+		m.PublishedMessages,
+		m.PublishErrors,
+		m.PublishDuration,
+		m.ReceivedMessages,
+		m.AckedMessages,
+		m.NackedMessages,
+		m.HandlerDuration,
+		m.OutstandingMessages,
+	}
+}
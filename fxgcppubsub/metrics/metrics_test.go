@@ -0,0 +1,64 @@
+package metrics_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ankorstore/yokai-contrib/fxgcppubsub/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMetrics(t *testing.T) {
+	t.Parallel()
+
+	t.Run("default subsystem", func(t *testing.T) {
+		t.Parallel()
+
+		m := metrics.NewMetrics("", "", nil)
+
+		registry := prometheus.NewRegistry()
+		assert.NoError(t, registry.Register(m))
+
+		m.PublishedMessages.WithLabelValues("test-topic").Inc()
+
+		expected := `
+			# HELP gcppubsub_published_messages_total Number of messages published per topic
+			# TYPE gcppubsub_published_messages_total counter
+			gcppubsub_published_messages_total{topic="test-topic"} 1
+		`
+
+		err := testutil.GatherAndCompare(registry, strings.NewReader(expected), "gcppubsub_published_messages_total")
+		assert.NoError(t, err)
+	})
+
+	t.Run("custom namespace and subsystem", func(t *testing.T) {
+		t.Parallel()
+
+		m := metrics.NewMetrics("foo", "bar", []float64{1, 2})
+
+		registry := prometheus.NewRegistry()
+		assert.NoError(t, registry.Register(m))
+
+		m.ReceivedMessages.WithLabelValues("test-subscription").Inc()
+		m.OutstandingMessages.WithLabelValues("test-subscription").Inc()
+
+		expected := `
+			# HELP foo_bar_received_messages_total Number of messages received per subscription
+			# TYPE foo_bar_received_messages_total counter
+			foo_bar_received_messages_total{subscription="test-subscription"} 1
+			# HELP foo_bar_outstanding_messages Number of messages received but not yet acked or nacked per subscription
+			# TYPE foo_bar_outstanding_messages gauge
+			foo_bar_outstanding_messages{subscription="test-subscription"} 1
+		`
+
+		err := testutil.GatherAndCompare(
+			registry,
+			strings.NewReader(expected),
+			"foo_bar_received_messages_total",
+			"foo_bar_outstanding_messages",
+		)
+		assert.NoError(t, err)
+	})
+}
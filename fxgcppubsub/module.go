@@ -2,19 +2,35 @@ package fxgcppubsub
 
 import (
 	"context"
+	"database/sql"
 	"fmt"
+	"strconv"
+	"strings"
 
 	"cloud.google.com/go/pubsub"
+	pubsubapi "cloud.google.com/go/pubsub/apiv1"
 	"cloud.google.com/go/pubsub/pstest"
+	"github.com/ankorstore/yokai-contrib/fxgcppubsub/claimcheck"
 	"github.com/ankorstore/yokai-contrib/fxgcppubsub/client"
 	"github.com/ankorstore/yokai-contrib/fxgcppubsub/codec"
+	"github.com/ankorstore/yokai-contrib/fxgcppubsub/dedup"
+	"github.com/ankorstore/yokai-contrib/fxgcppubsub/delay"
+	"github.com/ankorstore/yokai-contrib/fxgcppubsub/encryption"
+	"github.com/ankorstore/yokai-contrib/fxgcppubsub/metrics"
+	"github.com/ankorstore/yokai-contrib/fxgcppubsub/outbox"
 	"github.com/ankorstore/yokai-contrib/fxgcppubsub/reactor"
 	"github.com/ankorstore/yokai-contrib/fxgcppubsub/reactor/ack"
 	"github.com/ankorstore/yokai-contrib/fxgcppubsub/schema"
 	"github.com/ankorstore/yokai-contrib/fxgcppubsub/subscription"
+	subscriptionmiddleware "github.com/ankorstore/yokai-contrib/fxgcppubsub/subscription/middleware"
 	"github.com/ankorstore/yokai-contrib/fxgcppubsub/topic"
+	topicmiddleware "github.com/ankorstore/yokai-contrib/fxgcppubsub/topic/middleware"
+	"github.com/ankorstore/yokai-contrib/fxgcppubsub/topology"
+	"github.com/ankorstore/yokai-contrib/fxgcppubsub/worker"
 	"github.com/ankorstore/yokai/config"
 	"github.com/ankorstore/yokai/log"
+	"github.com/prometheus/client_golang/prometheus"
+	oteltrace "go.opentelemetry.io/otel/trace"
 	"go.uber.org/fx"
 	"google.golang.org/api/option"
 	"google.golang.org/api/option/internaloption"
@@ -34,6 +50,8 @@ var FxGcpPubSubModule = fx.Module(
 		NewFxGcpPubSubTestServer,
 		NewFxGcpPubSubClient,
 		NewFxGcpPubSubSchemaClient,
+		NewFxGcpPubSubSubscriberClient,
+		NewFxGcpPubSubMetrics,
 		fx.Annotate(
 			NewFxGcpPubSubDefaultClientFactory,
 			fx.As(new(client.ClientFactory)),
@@ -46,12 +64,20 @@ var FxGcpPubSubModule = fx.Module(
 			codec.NewDefaultCodecFactory,
 			fx.As(new(codec.CodecFactory)),
 		),
+		fx.Annotate(
+			NewFxGcpPubSubCodecRegistry,
+			fx.As(new(codec.CodecRegistry)),
+		),
 		fx.Annotate(
 			schema.NewDefaultSchemaConfigRegistry,
 			fx.As(new(schema.SchemaConfigRegistry)),
 		),
 		fx.Annotate(
-			topic.NewDefaultTopicFactory,
+			schema.NewDefaultSchemaRevisionManager,
+			fx.As(new(schema.SchemaRevisionManager)),
+		),
+		fx.Annotate(
+			NewFxGcpPubSubTopicFactory,
 			fx.As(new(topic.TopicFactory)),
 		),
 		fx.Annotate(
@@ -59,7 +85,7 @@ var FxGcpPubSubModule = fx.Module(
 			fx.As(new(topic.TopicRegistry)),
 		),
 		fx.Annotate(
-			subscription.NewDefaultSubscriptionFactory,
+			NewFxGcpPubSubSubscriptionFactory,
 			fx.As(new(subscription.SubscriptionFactory)),
 		),
 		fx.Annotate(
@@ -72,16 +98,79 @@ var FxGcpPubSubModule = fx.Module(
 		),
 		fx.Annotate(
 			NewFxGcpPubSubPublisher,
+			fx.As(fx.Self()),
 			fx.As(new(Publisher)),
+			fx.As(new(outbox.Publisher)),
+			fx.As(new(delay.Publisher)),
 		),
 		fx.Annotate(
 			NewFxGcpPubSubSubscriber,
 			fx.As(new(Subscriber)),
 		),
+		fx.Annotate(
+			topology.NewDefaultTopologyReconciler,
+			fx.As(new(topology.TopologyReconciler)),
+		),
+		fx.Annotate(
+			dedup.NewMemoryDedupStore,
+			fx.As(new(dedup.DedupStore)),
+		),
+		NewFxGcpPubSubDedupMiddleware,
 	),
+	fx.Invoke(ReconcileFxGcpPubSubTopology),
 	AsPubSubTestServerReactor(ack.NewAckReactor),
 )
 
+// FxGcpPubSubOutboxModule is the [Fx] GCP pubsub transactional outbox module, to be used with [FxGcpPubSubModule].
+//
+// It requires a *sql.DB to be provided, and starts the outbox relay with the application.
+//
+// [Fx]: https://github.com/uber-go/fx
+var FxGcpPubSubOutboxModule = fx.Module(
+	ModuleName+"-outbox",
+	fx.Provide(
+		fx.Annotate(
+			NewFxGcpPubSubOutboxStore,
+			fx.As(new(outbox.Store)),
+		),
+		fx.Annotate(
+			outbox.NewDefaultOutbox,
+			fx.As(new(outbox.Outbox)),
+		),
+		NewFxGcpPubSubOutboxRelay,
+	),
+	fx.Invoke(func(*outbox.Relay) {}),
+)
+
+// FxGcpPubSubDelayModule is the [Fx] GCP pubsub delayed publishing module, to be used with [FxGcpPubSubModule].
+//
+// It requires a delay.Store to be provided, and starts the delay scheduler with the application.
+//
+// [Fx]: https://github.com/uber-go/fx
+var FxGcpPubSubDelayModule = fx.Module(
+	ModuleName+"-delay",
+	fx.Provide(
+		NewFxGcpPubSubDelayScheduler,
+	),
+	fx.Invoke(func(*delay.Scheduler) {}),
+)
+
+// FxGcpPubSubWorkerModule is the [Fx] GCP pubsub workers module, to be used with [FxGcpPubSubModule].
+//
+// It runs the subscription handlers registered with [AsSubscriptionHandler] in background with the application.
+//
+// [Fx]: https://github.com/uber-go/fx
+var FxGcpPubSubWorkerModule = fx.Module(
+	ModuleName+"-worker",
+	fx.Provide(
+		fx.Annotate(
+			NewFxGcpPubSubWorkerSupervisor,
+			fx.As(new(worker.WorkerSupervisor)),
+		),
+	),
+	fx.Invoke(func(worker.WorkerSupervisor) {}),
+)
+
 // FxGcpPubSubTestServerParam allows injection of the required dependencies in [NewFxGcpPubSubTestServer].
 type FxGcpPubSubTestServerParam struct {
 	fx.In
@@ -216,18 +305,151 @@ func NewFxGcpPubSubSchemaClient(p FxGcpPubSubSchemaClientParam) (*pubsub.SchemaC
 	return client, nil
 }
 
-// FxGcpPubSubPublisherParam allows injection of the required dependencies in [NewFxGcpPubSubPublisher].
-type FxGcpPubSubPublisherParam struct {
+// FxGcpPubSubSubscriberClientParam allows injection of the required dependencies in [NewFxGcpPubSubSubscriberClient].
+//
+//nolint:containedctx
+type FxGcpPubSubSubscriberClientParam struct {
 	fx.In
 	LifeCycle fx.Lifecycle
+	Context   context.Context
 	Config    *config.Config
-	Factory   topic.TopicFactory
-	Registry  topic.TopicRegistry
+	Server    *pstest.Server
+}
+
+// NewFxGcpPubSubSubscriberClient returns a low level [pubsubapi.SubscriberClient], used for unary pulls.
+func NewFxGcpPubSubSubscriberClient(p FxGcpPubSubSubscriberClientParam) (*pubsubapi.SubscriberClient, error) {
+	if p.Config.IsTestEnv() {
+		client, err := pubsubapi.NewSubscriberClient(
+			p.Context,
+			option.WithEndpoint(p.Server.Addr),
+			option.WithoutAuthentication(),
+			option.WithGRPCDialOption(grpc.WithTransportCredentials(insecure.NewCredentials())),
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create test pubsub subscriber client: %w", err)
+		}
+
+		return client, nil
+	}
+
+	var subscriberClientOptions []option.ClientOption
+	if emulatorHost := p.Config.GetEnvVar("PUBSUB_EMULATOR_HOST"); emulatorHost != "" {
+		subscriberClientOptions = []option.ClientOption{
+			option.WithEndpoint(emulatorHost),
+			option.WithoutAuthentication(),
+			option.WithTelemetryDisabled(),
+			internaloption.SkipDialSettingsValidation(),
+			option.WithGRPCDialOption(grpc.WithTransportCredentials(insecure.NewCredentials())),
+		}
+	}
+
+	client, err := pubsubapi.NewSubscriberClient(p.Context, subscriberClientOptions...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create pubsub subscriber client: %w", err)
+	}
+
+	p.LifeCycle.Append(fx.Hook{
+		OnStop: func(context.Context) error {
+			return client.Close()
+		},
+	})
+
+	return client, nil
+}
+
+// FxGcpPubSubTopologyParam allows injection of the required dependencies in [ReconcileFxGcpPubSubTopology].
+//
+//nolint:containedctx
+type FxGcpPubSubTopologyParam struct {
+	fx.In
+	Context    context.Context
+	Config     *config.Config
+	Reconciler topology.TopologyReconciler
+}
+
+// ReconcileFxGcpPubSubTopology reconciles the [topology.Topology] declared in modules.gcppubsub.topology, if enabled.
+func ReconcileFxGcpPubSubTopology(p FxGcpPubSubTopologyParam) error {
+	top, err := topology.NewTopologyFromConfig(p.Config)
+	if err != nil {
+		return fmt.Errorf("failed to load pubsub topology: %w", err)
+	}
+
+	if !top.Enabled {
+		return nil
+	}
+
+	_, err = p.Reconciler.Reconcile(p.Context, top)
+	if err != nil {
+		return fmt.Errorf("failed to reconcile pubsub topology: %w", err)
+	}
+
+	return nil
+}
+
+// FxGcpPubSubMetricsParam allows injection of the required dependencies in [NewFxGcpPubSubMetrics].
+type FxGcpPubSubMetricsParam struct {
+	fx.In
+	Config   *config.Config
+	Registry *prometheus.Registry `optional:"true"`
+}
+
+// NewFxGcpPubSubMetrics returns a [metrics.Metrics], registered in the metrics registry if enabled.
+func NewFxGcpPubSubMetrics(p FxGcpPubSubMetricsParam) (*metrics.Metrics, error) {
+	var buckets []float64
+	if bucketsConfig := p.Config.GetString("modules.gcppubsub.metrics.buckets"); bucketsConfig != "" {
+		for _, bucketConfig := range strings.Split(bucketsConfig, ",") {
+			bucket, err := strconv.ParseFloat(strings.TrimSpace(bucketConfig), 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid metrics bucket %q: %w", bucketConfig, err)
+			}
+
+			buckets = append(buckets, bucket)
+		}
+	}
+
+	psMetrics := metrics.NewMetrics(
+		p.Config.GetString("modules.gcppubsub.metrics.namespace"),
+		p.Config.GetString("modules.gcppubsub.metrics.subsystem"),
+		buckets,
+	)
+
+	if p.Config.GetBool("modules.gcppubsub.metrics.enabled") && p.Registry != nil {
+		err := p.Registry.Register(psMetrics)
+		if err != nil {
+			return nil, fmt.Errorf("failed to register pubsub metrics: %w", err)
+		}
+	}
+
+	return psMetrics, nil
+}
+
+// FxGcpPubSubPublisherParam allows injection of the required dependencies in [NewFxGcpPubSubPublisher].
+type FxGcpPubSubPublisherParam struct {
+	fx.In
+	LifeCycle      fx.Lifecycle
+	Config         *config.Config
+	Factory        topic.TopicFactory
+	Registry       topic.TopicRegistry
+	Middlewares    []topic.Middleware       `group:"gcppubsub-publish-middlewares"`
+	TracerProvider oteltrace.TracerProvider `optional:"true"`
+	Metrics        *metrics.Metrics
+	DelayStore     delay.Store `optional:"true"`
 }
 
 // NewFxGcpPubSubPublisher returns a [Publisher].
 func NewFxGcpPubSubPublisher(p FxGcpPubSubPublisherParam) *DefaultPublisher {
-	publisher := NewDefaultPublisher(p.Factory, p.Registry)
+	var middlewares []topic.Middleware
+	if p.Config.GetBool("modules.gcppubsub.trace.enabled") {
+		middlewares = append(middlewares, topicmiddleware.NewTraceMiddleware(p.TracerProvider))
+	}
+
+	if p.Config.GetBool("modules.gcppubsub.metrics.enabled") {
+		middlewares = append(middlewares, topicmiddleware.NewMetricsMiddleware(p.Metrics))
+	}
+
+	middlewares = append(middlewares, p.Middlewares...)
+
+	publisher := NewDefaultPublisher(p.Factory, p.Registry, middlewares...).WithDelayStore(p.DelayStore)
 
 	if !p.Config.IsTestEnv() {
 		p.LifeCycle.Append(fx.Hook{
@@ -245,11 +467,348 @@ func NewFxGcpPubSubPublisher(p FxGcpPubSubPublisherParam) *DefaultPublisher {
 // FxGcpPubSubSubscriberParam allows injection of the required dependencies in [NewFxGcpPubSubPublisher].
 type FxGcpPubSubSubscriberParam struct {
 	fx.In
-	Factory  subscription.SubscriptionFactory
-	Registry subscription.SubscriptionRegistry
+	Factory        subscription.SubscriptionFactory
+	Registry       subscription.SubscriptionRegistry
+	Publisher      Publisher
+	Config         *config.Config
+	Middlewares    []subscription.Middleware `group:"gcppubsub-subscribe-middlewares"`
+	TracerProvider oteltrace.TracerProvider  `optional:"true"`
+	Metrics        *metrics.Metrics
+	PullClient     *pubsubapi.SubscriberClient
+	BlobStore      claimcheck.BlobStore `optional:"true"`
+	LifeCycle      fx.Lifecycle
 }
 
 // NewFxGcpPubSubSubscriber returns a [Subscriber].
 func NewFxGcpPubSubSubscriber(p FxGcpPubSubSubscriberParam) *DefaultSubscriber {
-	return NewDefaultSubscriber(p.Factory, p.Registry)
+	var middlewares []subscription.Middleware
+	if p.Config.GetBool("modules.gcppubsub.trace.enabled") {
+		middlewares = append(middlewares, subscriptionmiddleware.NewTraceMiddleware(p.TracerProvider))
+	}
+
+	if p.Config.GetBool("modules.gcppubsub.metrics.enabled") {
+		middlewares = append(middlewares, subscriptionmiddleware.NewMetricsMiddleware(p.Metrics))
+	}
+
+	middlewares = append(middlewares, p.Middlewares...)
+
+	subscriber := NewDefaultSubscriber(p.Factory, p.Registry, middlewares...).
+		WithPublisher(p.Publisher).
+		WithConfig(p.Config).
+		WithPullClient(p.PullClient)
+
+	if p.BlobStore != nil {
+		subscriber.WithBlobStore(p.BlobStore)
+	}
+
+	// send the pending acks of the pulled messages before closing the pull client
+	p.LifeCycle.Append(fx.Hook{
+		OnStop: subscriber.Flush,
+	})
+
+	return subscriber
+}
+
+// FxGcpPubSubWorkerSupervisorParam allows injection of the required dependencies in [NewFxGcpPubSubWorkerSupervisor].
+//
+//nolint:containedctx
+type FxGcpPubSubWorkerSupervisorParam struct {
+	fx.In
+	LifeCycle   fx.Lifecycle
+	Context     context.Context
+	Config      *config.Config
+	Subscriber  Subscriber
+	Logger      *log.Logger
+	Definitions []worker.SubscriptionHandlerDefinition `group:"gcppubsub-subscription-handlers"`
+}
+
+// NewFxGcpPubSubWorkerSupervisor returns a [worker.DefaultWorkerSupervisor], running the registered subscription handlers
+// from the application start, and draining them on the application stop.
+func NewFxGcpPubSubWorkerSupervisor(p FxGcpPubSubWorkerSupervisorParam) (*worker.DefaultWorkerSupervisor, error) {
+	workers := make([]*worker.Worker, len(p.Definitions))
+
+	for i, definition := range p.Definitions {
+		w, err := worker.NewWorkerFromDefinition(p.Subscriber, definition)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create pubsub worker: %w", err)
+		}
+
+		workers[i] = w
+	}
+
+	supervisor := worker.NewDefaultWorkerSupervisor(
+		p.Logger,
+		worker.SupervisorOptions{
+			MinBackoff:        p.Config.GetDuration("modules.gcppubsub.workers.backoff.min"),
+			MaxBackoff:        p.Config.GetDuration("modules.gcppubsub.workers.backoff.max"),
+			BackoffMultiplier: p.Config.GetFloat64("modules.gcppubsub.workers.backoff.multiplier"),
+			MaxRestarts:       p.Config.GetInt("modules.gcppubsub.workers.max_restarts"),
+			StablePeriod:      p.Config.GetDuration("modules.gcppubsub.workers.stable_period"),
+		},
+		workers...,
+	)
+
+	p.LifeCycle.Append(fx.Hook{
+		OnStart: func(context.Context) error {
+			supervisor.Start(p.Context)
+
+			return nil
+		},
+		OnStop: func(ctx context.Context) error {
+			return supervisor.Stop(ctx)
+		},
+	})
+
+	return supervisor, nil
+}
+
+// FxGcpPubSubDedupMiddlewareParam allows injection of the required dependencies in [NewFxGcpPubSubDedupMiddleware].
+type FxGcpPubSubDedupMiddlewareParam struct {
+	fx.In
+	Config *config.Config
+	Store  dedup.DedupStore
+	Logger *log.Logger
+}
+
+// NewFxGcpPubSubDedupMiddleware returns a [dedup.DedupMiddleware], configured from modules.gcppubsub.dedup.
+func NewFxGcpPubSubDedupMiddleware(p FxGcpPubSubDedupMiddlewareParam) *dedup.DedupMiddleware {
+	return dedup.NewDedupMiddleware(
+		p.Store,
+		p.Logger,
+		dedup.WithTTL(p.Config.GetDuration("modules.gcppubsub.dedup.ttl")),
+		dedup.WithReservationTTL(p.Config.GetDuration("modules.gcppubsub.dedup.reservation_ttl")),
+		dedup.WithAttributeKey(p.Config.GetString("modules.gcppubsub.dedup.attribute")),
+	)
+}
+
+// FxGcpPubSubCodecRegistryParam allows injection of the required dependencies in [NewFxGcpPubSubCodecRegistry].
+type FxGcpPubSubCodecRegistryParam struct {
+	fx.In
+	Config      *config.Config
+	Definitions []codec.CodecDefinition `group:"gcppubsub-codecs"`
+	KeyProvider encryption.KeyProvider  `optional:"true"`
+}
+
+// NewFxGcpPubSubCodecRegistry returns a [codec.DefaultCodecRegistry], with the built-in codecs and the registered ones.
+//
+// The built-in CloudEvents codecs default source and type are configured from modules.gcppubsub.codecs.cloudevents.
+//
+// If an [encryption.KeyProvider] is provided, or if local keys are configured in modules.gcppubsub.codecs.encryption,
+// an encrypted variant of each codec is also registered, named with the [encryption.CodecNamePrefix].
+func NewFxGcpPubSubCodecRegistry(p FxGcpPubSubCodecRegistryParam) (*codec.DefaultCodecRegistry, error) {
+	source := p.Config.GetString("modules.gcppubsub.codecs.cloudevents.source")
+	if source == "" {
+		source = p.Config.AppName()
+	}
+
+	eventType := p.Config.GetString("modules.gcppubsub.codecs.cloudevents.type")
+
+	definitions := []codec.CodecDefinition{
+		{
+			Name:  codec.CloudEventsCodecName,
+			Codec: codec.NewCloudEventsCodec(codec.CloudEventsStructuredMode, source, eventType),
+		},
+		{
+			Name:  codec.CloudEventsBinaryCodecName,
+			Codec: codec.NewCloudEventsCodec(codec.CloudEventsBinaryMode, source, eventType),
+		},
+	}
+
+	definitions = append(definitions, p.Definitions...)
+
+	provider := p.KeyProvider
+	if provider == nil {
+		keys := p.Config.GetStringMapString("modules.gcppubsub.codecs.encryption.keys")
+		if len(keys) == 0 {
+			return codec.NewDefaultCodecRegistry(definitions...), nil
+		}
+
+		localProvider, err := encryption.NewLocalKeyProviderFromBase64(
+			p.Config.GetString("modules.gcppubsub.codecs.encryption.key_id"),
+			keys,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("cannot create encryption key provider: %w", err)
+		}
+
+		provider = localProvider
+	}
+
+	encryptedDefinitions, err := encryption.EncryptedDefinitions(codec.NewDefaultCodecRegistry(definitions...), provider)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create encrypted codecs: %w", err)
+	}
+
+	return codec.NewDefaultCodecRegistry(append(definitions, encryptedDefinitions...)...), nil
+}
+
+// FxGcpPubSubTopicFactoryParam allows injection of the required dependencies in [NewFxGcpPubSubTopicFactory].
+type FxGcpPubSubTopicFactoryParam struct {
+	fx.In
+	Config         *config.Config
+	Client         *pubsub.Client
+	SchemaRegistry schema.SchemaConfigRegistry
+	CodecFactory   codec.CodecFactory
+	CodecRegistry  codec.CodecRegistry
+}
+
+// NewFxGcpPubSubTopicFactory returns a [topic.DefaultTopicFactory], selecting the codecs of topics without schema
+// from modules.gcppubsub.topics.<topic id>.codec, or from the topic codec label, the publish settings from
+// modules.gcppubsub.topics.<topic id>.publish, and the ordering keys resume policies from modules.gcppubsub.topics.<topic id>.ordering.resume.
+func NewFxGcpPubSubTopicFactory(p FxGcpPubSubTopicFactoryParam) *topic.DefaultTopicFactory {
+	return topic.NewDefaultTopicFactory(
+		p.Client,
+		p.SchemaRegistry,
+		p.CodecFactory,
+		topic.WithCodecRegistry(p.CodecRegistry, func(topicID string) string {
+			return p.Config.GetString(fmt.Sprintf("modules.gcppubsub.topics.%s.codec", topicID))
+		}),
+		topic.WithPublishSettingsFunc(func(topicID string) pubsub.PublishSettings {
+			return topic.NewPublishSettingsFromConfig(p.Config, topicID)
+		}),
+		topic.WithResumePolicyFunc(func(topicID string) topic.ResumePolicy {
+			return topic.NewResumePolicyFromConfig(p.Config, topicID)
+		}),
+	)
+}
+
+// FxGcpPubSubSubscriptionFactoryParam allows injection of the required dependencies in [NewFxGcpPubSubSubscriptionFactory].
+type FxGcpPubSubSubscriptionFactoryParam struct {
+	fx.In
+	Config         *config.Config
+	Client         *pubsub.Client
+	SchemaRegistry schema.SchemaConfigRegistry
+	CodecFactory   codec.CodecFactory
+	CodecRegistry  codec.CodecRegistry
+}
+
+// NewFxGcpPubSubSubscriptionFactory returns a [subscription.DefaultSubscriptionFactory], selecting the codecs of subscriptions
+// on topics without schema from modules.gcppubsub.subscriptions.<subscription id>.codec, or from the subscription (or its topic) codec label.
+func NewFxGcpPubSubSubscriptionFactory(p FxGcpPubSubSubscriptionFactoryParam) *subscription.DefaultSubscriptionFactory {
+	return subscription.NewDefaultSubscriptionFactory(
+		p.Client,
+		p.SchemaRegistry,
+		p.CodecFactory,
+		subscription.WithCodecRegistry(p.CodecRegistry, func(subscriptionID string) string {
+			return p.Config.GetString(fmt.Sprintf("modules.gcppubsub.subscriptions.%s.codec", subscriptionID))
+		}),
+	)
+}
+
+// FxGcpPubSubDelaySchedulerParam allows injection of the required dependencies in [NewFxGcpPubSubDelayScheduler].
+//
+//nolint:containedctx
+type FxGcpPubSubDelaySchedulerParam struct {
+	fx.In
+	LifeCycle fx.Lifecycle
+	Context   context.Context
+	Config    *config.Config
+	Store     delay.Store
+	Publisher delay.Publisher
+	Logger    *log.Logger
+}
+
+// NewFxGcpPubSubDelayScheduler returns a [delay.Scheduler], started and stopped with the application.
+func NewFxGcpPubSubDelayScheduler(p FxGcpPubSubDelaySchedulerParam) *delay.Scheduler {
+	scheduler := delay.NewScheduler(
+		p.Store,
+		p.Publisher,
+		p.Logger,
+		delay.SchedulerOptions{
+			Interval:    p.Config.GetDuration("modules.gcppubsub.delay.scheduler.interval"),
+			BatchSize:   p.Config.GetInt("modules.gcppubsub.delay.scheduler.batch_size"),
+			Lease:       p.Config.GetDuration("modules.gcppubsub.delay.scheduler.lease"),
+			MaxAttempts: p.Config.GetInt("modules.gcppubsub.delay.scheduler.max_attempts"),
+			RetryDelay:  p.Config.GetDuration("modules.gcppubsub.delay.scheduler.retry_delay"),
+		},
+	)
+
+	p.LifeCycle.Append(fx.Hook{
+		OnStart: func(context.Context) error {
+			scheduler.Start(p.Context)
+
+			return nil
+		},
+		OnStop: func(context.Context) error {
+			scheduler.Stop()
+
+			return nil
+		},
+	})
+
+	return scheduler
+}
+
+// FxGcpPubSubOutboxStoreParam allows injection of the required dependencies in [NewFxGcpPubSubOutboxStore].
+//
+//nolint:containedctx
+type FxGcpPubSubOutboxStoreParam struct {
+	fx.In
+	Context context.Context
+	Config  *config.Config
+	DB      *sql.DB
+}
+
+// NewFxGcpPubSubOutboxStore returns a [outbox.SQLStore], creating its table if modules.gcppubsub.outbox.create_table is enabled.
+func NewFxGcpPubSubOutboxStore(p FxGcpPubSubOutboxStoreParam) (*outbox.SQLStore, error) {
+	store, err := outbox.NewSQLStore(
+		p.DB,
+		p.Config.GetString("modules.gcppubsub.outbox.table"),
+		p.Config.GetString("modules.gcppubsub.outbox.dialect"),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create pubsub outbox store: %w", err)
+	}
+
+	if p.Config.GetBool("modules.gcppubsub.outbox.create_table") {
+		err = store.CreateTable(p.Context)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create pubsub outbox table: %w", err)
+		}
+	}
+
+	return store, nil
+}
+
+// FxGcpPubSubOutboxRelayParam allows injection of the required dependencies in [NewFxGcpPubSubOutboxRelay].
+//
+//nolint:containedctx
+type FxGcpPubSubOutboxRelayParam struct {
+	fx.In
+	LifeCycle fx.Lifecycle
+	Context   context.Context
+	Config    *config.Config
+	Store     outbox.Store
+	Publisher outbox.Publisher
+	Logger    *log.Logger
+}
+
+// NewFxGcpPubSubOutboxRelay returns a [outbox.Relay], started and stopped with the application.
+func NewFxGcpPubSubOutboxRelay(p FxGcpPubSubOutboxRelayParam) *outbox.Relay {
+	relay := outbox.NewRelay(
+		p.Store,
+		p.Publisher,
+		p.Logger,
+		outbox.RelayOptions{
+			Interval:    p.Config.GetDuration("modules.gcppubsub.outbox.relay.interval"),
+			BatchSize:   p.Config.GetInt("modules.gcppubsub.outbox.relay.batch_size"),
+			MaxAttempts: p.Config.GetInt("modules.gcppubsub.outbox.relay.max_attempts"),
+			Lease:       p.Config.GetDuration("modules.gcppubsub.outbox.relay.lease"),
+		},
+	)
+
+	p.LifeCycle.Append(fx.Hook{
+		OnStart: func(context.Context) error {
+			relay.Start(p.Context)
+
+			return nil
+		},
+		OnStop: func(context.Context) error {
+			relay.Stop()
+
+			return nil
+		},
+	})
+
+	return relay
 }
@@ -2,18 +2,41 @@ package fxgcppubsub_test
 
 import (
 	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"cloud.google.com/go/pubsub"
+	"cloud.google.com/go/pubsub/pstest"
 	"github.com/ankorstore/yokai-contrib/fxgcppubsub"
+	"github.com/ankorstore/yokai-contrib/fxgcppubsub/codec"
+	"github.com/ankorstore/yokai-contrib/fxgcppubsub/dedup"
+	"github.com/ankorstore/yokai-contrib/fxgcppubsub/delay"
+	"github.com/ankorstore/yokai-contrib/fxgcppubsub/encryption"
 	"github.com/ankorstore/yokai-contrib/fxgcppubsub/message"
+	"github.com/ankorstore/yokai-contrib/fxgcppubsub/outbox"
 	"github.com/ankorstore/yokai-contrib/fxgcppubsub/reactor/ack"
+	"github.com/ankorstore/yokai-contrib/fxgcppubsub/schema"
+	"github.com/ankorstore/yokai-contrib/fxgcppubsub/subscription"
 	"github.com/ankorstore/yokai-contrib/fxgcppubsub/testdata/avro"
+	"github.com/ankorstore/yokai-contrib/fxgcppubsub/testdata/mysql"
 	"github.com/ankorstore/yokai-contrib/fxgcppubsub/testdata/proto"
+	"github.com/ankorstore/yokai-contrib/fxgcppubsub/topic"
+	"github.com/ankorstore/yokai-contrib/fxgcppubsub/worker"
 	"github.com/ankorstore/yokai/fxconfig"
 	"github.com/ankorstore/yokai/fxlog"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	oteltrace "go.opentelemetry.io/otel/trace"
 	"go.uber.org/fx"
 	"go.uber.org/fx/fxtest"
 )
@@ -243,3 +266,1094 @@ func TestFxGcpPubSubModule(t *testing.T) {
 		assert.NoError(t, err)
 	})
 }
+
+func TestFxGcpPubSubModuleWithTrace(t *testing.T) {
+	t.Setenv("APP_ENV", "test")
+	t.Setenv("APP_CONFIG_PATH", "testdata/config")
+	t.Setenv("GCP_PROJECT_ID", "test-project")
+	t.Setenv("MODULES_GCPPUBSUB_TRACE_ENABLED", "true")
+
+	// global propagator, as configured by the fxtrace module
+	propagator := otel.GetTextMapPropagator()
+	t.Cleanup(func() {
+		otel.SetTextMapPropagator(propagator)
+	})
+
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	var publisher fxgcppubsub.Publisher
+	var subscriber fxgcppubsub.Subscriber
+	var supervisor ack.AckSupervisor
+
+	ctx := context.Background()
+
+	recorder := tracetest.NewSpanRecorder()
+	tracerProvider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+	fxtest.New(
+		t,
+		fx.NopLogger,
+		fxconfig.FxConfigModule,
+		fxlog.FxLogModule,
+		fxgcppubsub.FxGcpPubSubModule,
+		fx.Supply(fx.Annotate(ctx, fx.As(new(context.Context)))),
+		fx.Supply(fx.Annotate(tracerProvider, fx.As(new(oteltrace.TracerProvider)))),
+		fxgcppubsub.PrepareTopicAndSubscription(fxgcppubsub.PrepareTopicAndSubscriptionParams{
+			TopicID:        "test-topic",
+			SubscriptionID: "test-subscription",
+		}),
+		fx.Populate(&publisher, &subscriber, &supervisor),
+	).RequireStart().RequireStop()
+
+	parentCtx, parentSpan := tracerProvider.Tracer("test").Start(ctx, "parent")
+
+	res, err := publisher.Publish(parentCtx, "test-topic", []byte("test"))
+	assert.NoError(t, err)
+
+	_, err = res.Get(ctx)
+	assert.NoError(t, err)
+
+	parentSpan.End()
+
+	waiter := supervisor.StartAckWaiter("test-subscription")
+
+	var handlerSpanContext oteltrace.SpanContext
+
+	//nolint:errcheck
+	go subscriber.Subscribe(ctx, "test-subscription", func(ctx context.Context, m *message.Message) {
+		handlerSpanContext = oteltrace.SpanContextFromContext(ctx)
+
+		m.Ack()
+	})
+
+	_, err = waiter.WaitMaxDuration(ctx, 2*time.Second)
+	assert.NoError(t, err)
+
+	assert.Equal(t, parentSpan.SpanContext().TraceID(), handlerSpanContext.TraceID())
+
+	var producerSpan sdktrace.ReadOnlySpan
+	for _, span := range recorder.Ended() {
+		if span.Name() == "pubsub publish test-topic" {
+			producerSpan = span
+		}
+	}
+
+	assert.NotNil(t, producerSpan)
+	assert.Equal(t, parentSpan.SpanContext().SpanID(), producerSpan.Parent().SpanID())
+}
+
+func TestFxGcpPubSubModuleWithMetrics(t *testing.T) {
+	t.Setenv("APP_ENV", "test")
+	t.Setenv("APP_CONFIG_PATH", "testdata/config")
+	t.Setenv("GCP_PROJECT_ID", "test-project")
+	t.Setenv("MODULES_GCPPUBSUB_METRICS_ENABLED", "true")
+
+	var publisher fxgcppubsub.Publisher
+	var subscriber fxgcppubsub.Subscriber
+	var supervisor ack.AckSupervisor
+
+	ctx := context.Background()
+	registry := prometheus.NewRegistry()
+
+	fxtest.New(
+		t,
+		fx.NopLogger,
+		fxconfig.FxConfigModule,
+		fxlog.FxLogModule,
+		fxgcppubsub.FxGcpPubSubModule,
+		fx.Supply(fx.Annotate(ctx, fx.As(new(context.Context)))),
+		fx.Supply(registry),
+		fxgcppubsub.PrepareTopicAndSubscription(fxgcppubsub.PrepareTopicAndSubscriptionParams{
+			TopicID:        "test-topic",
+			SubscriptionID: "test-subscription",
+		}),
+		fx.Populate(&publisher, &subscriber, &supervisor),
+	).RequireStart().RequireStop()
+
+	res, err := publisher.Publish(ctx, "test-topic", []byte("test"))
+	assert.NoError(t, err)
+
+	_, err = res.Get(ctx)
+	assert.NoError(t, err)
+
+	waiter := supervisor.StartAckWaiter("test-subscription")
+
+	//nolint:errcheck
+	go subscriber.Subscribe(ctx, "test-subscription", func(ctx context.Context, m *message.Message) {
+		m.Ack()
+	})
+
+	_, err = waiter.WaitMaxDuration(ctx, 2*time.Second)
+	assert.NoError(t, err)
+
+	assert.Eventually(t, func() bool {
+		expected := `
+			# HELP gcppubsub_published_messages_total Number of messages published per topic
+			# TYPE gcppubsub_published_messages_total counter
+			gcppubsub_published_messages_total{topic="test-topic"} 1
+			# HELP gcppubsub_received_messages_total Number of messages received per subscription
+			# TYPE gcppubsub_received_messages_total counter
+			gcppubsub_received_messages_total{subscription="test-subscription"} 1
+			# HELP gcppubsub_acked_messages_total Number of messages acked per subscription
+			# TYPE gcppubsub_acked_messages_total counter
+			gcppubsub_acked_messages_total{subscription="test-subscription"} 1
+			# HELP gcppubsub_outstanding_messages Number of messages received but not yet acked or nacked per subscription
+			# TYPE gcppubsub_outstanding_messages gauge
+			gcppubsub_outstanding_messages{subscription="test-subscription"} 0
+		`
+
+		err := testutil.GatherAndCompare(
+			registry,
+			strings.NewReader(expected),
+			"gcppubsub_published_messages_total",
+			"gcppubsub_received_messages_total",
+			"gcppubsub_acked_messages_total",
+			"gcppubsub_outstanding_messages",
+		)
+
+		return err == nil
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestFxGcpPubSubModuleWithInvalidMetricsBuckets(t *testing.T) {
+	t.Setenv("APP_ENV", "test")
+	t.Setenv("APP_CONFIG_PATH", "testdata/config")
+	t.Setenv("GCP_PROJECT_ID", "test-project")
+	t.Setenv("MODULES_GCPPUBSUB_METRICS_BUCKETS", "1, invalid")
+
+	var publisher fxgcppubsub.Publisher
+
+	app := fx.New(
+		fx.NopLogger,
+		fxconfig.FxConfigModule,
+		fxlog.FxLogModule,
+		fxgcppubsub.FxGcpPubSubModule,
+		fx.Supply(fx.Annotate(context.Background(), fx.As(new(context.Context)))),
+		fx.Populate(&publisher),
+	)
+
+	assert.Error(t, app.Err())
+	assert.Contains(t, app.Err().Error(), `invalid metrics bucket " invalid"`)
+}
+
+func TestFxGcpPubSubModuleWithTopology(t *testing.T) {
+	t.Setenv("APP_ENV", "test")
+	t.Setenv("APP_CONFIG_PATH", "testdata/topology")
+	t.Setenv("GCP_PROJECT_ID", "test-project")
+
+	var publisher *fxgcppubsub.TypedPublisher[avro.SimpleRecord]
+	var subscriber fxgcppubsub.Subscriber
+	var supervisor ack.AckSupervisor
+
+	ctx := context.Background()
+
+	fxtest.New(
+		t,
+		fx.NopLogger,
+		fxconfig.FxConfigModule,
+		fxlog.FxLogModule,
+		fxgcppubsub.FxGcpPubSubModule,
+		fx.Supply(fx.Annotate(ctx, fx.As(new(context.Context)))),
+		fxgcppubsub.AsTypedPublisher[avro.SimpleRecord]("topology-topic"),
+		fx.Populate(&publisher, &subscriber, &supervisor),
+	).RequireStart().RequireStop()
+
+	_, err := publisher.Publish(
+		ctx,
+		avro.SimpleRecord{
+			StringField:  "test",
+			FloatField:   12.34,
+			BooleanField: true,
+		},
+		topic.WithMessageAttributes(map[string]string{"type": "test"}),
+	)
+	assert.NoError(t, err)
+
+	waiter := supervisor.StartAckWaiter("topology-subscription")
+
+	//nolint:errcheck
+	go subscriber.Subscribe(ctx, "topology-subscription", func(ctx context.Context, m *message.Message) {
+		m.Ack()
+	})
+
+	_, err = waiter.WaitMaxDuration(ctx, 2*time.Second)
+	assert.NoError(t, err)
+}
+
+type errorReactor struct {
+	funcNames []string
+}
+
+func (r *errorReactor) FuncNames() []string {
+	return r.funcNames
+}
+
+func (r *errorReactor) React(any) (bool, any, error) {
+	return true, nil, fmt.Errorf("test error")
+}
+
+func TestFxGcpPubSubModuleWithTopologyError(t *testing.T) {
+	t.Setenv("APP_ENV", "test")
+	t.Setenv("APP_CONFIG_PATH", "testdata/topology")
+	t.Setenv("GCP_PROJECT_ID", "test-project")
+
+	var subscriber fxgcppubsub.Subscriber
+
+	app := fx.New(
+		fx.NopLogger,
+		fxconfig.FxConfigModule,
+		fxlog.FxLogModule,
+		fxgcppubsub.FxGcpPubSubModule,
+		fx.Supply(fx.Annotate(context.Background(), fx.As(new(context.Context)))),
+		fxgcppubsub.AsPubSubTestServerReactor(func() *errorReactor {
+			return &errorReactor{funcNames: []string{"CreateTopic"}}
+		}),
+		fx.Populate(&subscriber),
+	)
+
+	assert.Error(t, app.Err())
+	assert.Contains(t, app.Err().Error(), "failed to reconcile pubsub topology: cannot reconcile topic topology-topic")
+}
+
+func TestFxGcpPubSubOutboxModule(t *testing.T) {
+	t.Setenv("APP_ENV", "test")
+	t.Setenv("APP_CONFIG_PATH", "testdata/config")
+	t.Setenv("GCP_PROJECT_ID", "test-project")
+	t.Setenv("MODULES_GCPPUBSUB_OUTBOX_CREATE_TABLE", "true")
+	t.Setenv("MODULES_GCPPUBSUB_OUTBOX_RELAY_INTERVAL", "10ms")
+
+	var box outbox.Outbox
+	var subscriber fxgcppubsub.Subscriber
+	var supervisor ack.AckSupervisor
+
+	ctx := context.Background()
+	db := mysql.StartTestServer(t)
+
+	app := fxtest.New(
+		t,
+		fx.NopLogger,
+		fxconfig.FxConfigModule,
+		fxlog.FxLogModule,
+		fxgcppubsub.FxGcpPubSubModule,
+		fxgcppubsub.FxGcpPubSubOutboxModule,
+		fx.Supply(fx.Annotate(ctx, fx.As(new(context.Context)))),
+		fx.Supply(db),
+		fxgcppubsub.PrepareTopicAndSubscription(fxgcppubsub.PrepareTopicAndSubscriptionParams{
+			TopicID:        "test-topic",
+			SubscriptionID: "test-subscription",
+		}),
+		fx.Populate(&box, &subscriber, &supervisor),
+	).RequireStart()
+
+	tx, err := db.BeginTx(ctx, nil)
+	assert.NoError(t, err)
+
+	err = box.Write(ctx, tx, "test-topic", []byte("test"))
+	assert.NoError(t, err)
+
+	assert.NoError(t, tx.Commit())
+
+	waiter := supervisor.StartAckWaiter("test-subscription")
+
+	//nolint:errcheck
+	go subscriber.Subscribe(ctx, "test-subscription", func(ctx context.Context, m *message.Message) {
+		assert.Equal(t, []byte("test"), m.Data())
+
+		m.Ack()
+	})
+
+	_, err = waiter.WaitMaxDuration(ctx, 2*time.Second)
+	assert.NoError(t, err)
+
+	app.RequireStop()
+}
+
+func TestFxGcpPubSubOutboxModuleWithInvalidDialect(t *testing.T) {
+	t.Setenv("APP_ENV", "test")
+	t.Setenv("APP_CONFIG_PATH", "testdata/config")
+	t.Setenv("GCP_PROJECT_ID", "test-project")
+	t.Setenv("MODULES_GCPPUBSUB_OUTBOX_DIALECT", "invalid")
+
+	app := fx.New(
+		fx.NopLogger,
+		fxconfig.FxConfigModule,
+		fxlog.FxLogModule,
+		fxgcppubsub.FxGcpPubSubModule,
+		fxgcppubsub.FxGcpPubSubOutboxModule,
+		fx.Supply(fx.Annotate(context.Background(), fx.As(new(context.Context)))),
+		fx.Supply(&sql.DB{}),
+	)
+
+	assert.Error(t, app.Err())
+	assert.Contains(t, app.Err().Error(), `failed to create pubsub outbox store: invalid outbox dialect "invalid"`)
+}
+
+func TestFxGcpPubSubModuleWithDedup(t *testing.T) {
+	t.Setenv("APP_ENV", "test")
+	t.Setenv("APP_CONFIG_PATH", "testdata/config")
+	t.Setenv("GCP_PROJECT_ID", "test-project")
+	t.Setenv("MODULES_GCPPUBSUB_DEDUP_ATTRIBUTE", "eventID")
+
+	var publisher fxgcppubsub.Publisher
+	var subscriber fxgcppubsub.Subscriber
+	var dedupMiddleware *dedup.DedupMiddleware
+	var server *pstest.Server
+
+	ctx := context.Background()
+
+	fxtest.New(
+		t,
+		fx.NopLogger,
+		fxconfig.FxConfigModule,
+		fxlog.FxLogModule,
+		fxgcppubsub.FxGcpPubSubModule,
+		fx.Supply(fx.Annotate(ctx, fx.As(new(context.Context)))),
+		fxgcppubsub.PrepareTopicAndSubscription(fxgcppubsub.PrepareTopicAndSubscriptionParams{
+			TopicID:        "test-topic",
+			SubscriptionID: "test-subscription",
+		}),
+		fx.Populate(&publisher, &subscriber, &dedupMiddleware, &server),
+	).RequireStart().RequireStop()
+
+	assert.Equal(t, "eventID", dedupMiddleware.Options().AttributeKey)
+	assert.Equal(t, dedup.DefaultTTL, dedupMiddleware.Options().TTL)
+
+	for i := 0; i < 2; i++ {
+		res, err := publisher.Publish(ctx, "test-topic", []byte("test"), topic.WithMessageAttributes(map[string]string{"eventID": "event"}))
+		assert.NoError(t, err)
+
+		_, err = res.Get(ctx)
+		assert.NoError(t, err)
+	}
+
+	var processed atomic.Int32
+
+	subscribeCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	//nolint:errcheck
+	go subscriber.Subscribe(
+		subscribeCtx,
+		"test-subscription",
+		func(ctx context.Context, m *message.Message) {
+			processed.Add(1)
+
+			m.Ack()
+		},
+		subscription.WithNumGoroutines(1),
+		subscription.WithMaxOutstandingMessages(1),
+		subscription.WithMiddlewares(dedupMiddleware),
+	)
+
+	assert.Eventually(t, func() bool {
+		acks := 0
+		for _, m := range server.Messages() {
+			acks += m.Acks
+		}
+
+		return acks == 2
+	}, 2*time.Second, 10*time.Millisecond)
+
+	assert.Equal(t, int32(1), processed.Load())
+}
+
+type testSubscriptionHandler struct {
+	supervisor ack.AckSupervisor
+}
+
+func newTestSubscriptionHandler(supervisor ack.AckSupervisor) *testSubscriptionHandler {
+	return &testSubscriptionHandler{
+		supervisor: supervisor,
+	}
+}
+
+func (h *testSubscriptionHandler) Handle(ctx context.Context, m *message.Message) error {
+	return nil
+}
+
+func TestFxGcpPubSubModuleWithSubscriptionHandlers(t *testing.T) {
+	t.Setenv("APP_ENV", "test")
+	t.Setenv("APP_CONFIG_PATH", "testdata/config")
+	t.Setenv("GCP_PROJECT_ID", "test-project")
+
+	var publisher fxgcppubsub.Publisher
+	var supervisor ack.AckSupervisor
+	var workerSupervisor worker.WorkerSupervisor
+
+	ctx := context.Background()
+
+	var received atomic.Int32
+
+	app := fxtest.New(
+		t,
+		fx.NopLogger,
+		fxconfig.FxConfigModule,
+		fxlog.FxLogModule,
+		fxgcppubsub.FxGcpPubSubModule,
+		fxgcppubsub.FxGcpPubSubWorkerModule,
+		fx.Supply(fx.Annotate(ctx, fx.As(new(context.Context)))),
+		fxgcppubsub.PrepareTopicAndSubscription(fxgcppubsub.PrepareTopicAndSubscriptionParams{
+			TopicID:        "test-topic",
+			SubscriptionID: "test-subscription",
+		}),
+		fxgcppubsub.PrepareTopicAndSubscription(fxgcppubsub.PrepareTopicAndSubscriptionParams{
+			TopicID:        "other-topic",
+			SubscriptionID: "other-subscription",
+		}),
+		fxgcppubsub.AsSubscriptionHandler("test-subscription", func(ctx context.Context, m *message.Message) {
+			received.Add(1)
+
+			m.Ack()
+		}),
+		fxgcppubsub.AsSubscriptionHandler("other-subscription", newTestSubscriptionHandler),
+		fxgcppubsub.AsSubscriptionHandler("missing-subscription", func(ctx context.Context, m *message.Message) error {
+			return nil
+		}),
+		fx.Populate(&publisher, &supervisor, &workerSupervisor),
+	).RequireStart()
+
+	for _, topicID := range []string{"test-topic", "other-topic"} {
+		waiter := supervisor.StartAckWaiter(strings.Replace(topicID, "topic", "subscription", 1))
+
+		_, err := publisher.Publish(ctx, topicID, []byte("test"))
+		assert.NoError(t, err)
+
+		_, err = waiter.WaitMaxDuration(ctx, 2*time.Second)
+		assert.NoError(t, err)
+	}
+
+	assert.Equal(t, int32(1), received.Load())
+
+	statuses := map[string]worker.WorkerStatus{}
+
+	assert.Eventually(t, func() bool {
+		for _, st := range workerSupervisor.Statuses() {
+			statuses[st.SubscriptionID] = st
+		}
+
+		return statuses["missing-subscription"].Status == worker.StatusFailed
+	}, 2*time.Second, 10*time.Millisecond)
+
+	for _, subscriptionID := range []string{"test-subscription", "other-subscription"} {
+		assert.Equal(t, worker.StatusRunning, statuses[subscriptionID].Status)
+		assert.Equal(t, 1, statuses[subscriptionID].Processed)
+		assert.Equal(t, 0, statuses[subscriptionID].Failed)
+		assert.False(t, statuses[subscriptionID].LastMessageAt.IsZero())
+	}
+
+	app.RequireStop()
+
+	for _, st := range workerSupervisor.Statuses() {
+		if st.SubscriptionID != "missing-subscription" {
+			assert.Equal(t, worker.StatusStopped, st.Status)
+		}
+	}
+}
+
+func TestFxGcpPubSubModuleWithSubscriptionHandlersOnSameSubscription(t *testing.T) {
+	t.Setenv("APP_ENV", "test")
+	t.Setenv("APP_CONFIG_PATH", "testdata/config")
+	t.Setenv("GCP_PROJECT_ID", "test-project")
+
+	var workerSupervisor worker.WorkerSupervisor
+
+	app := fxtest.New(
+		t,
+		fx.NopLogger,
+		fxconfig.FxConfigModule,
+		fxlog.FxLogModule,
+		fxgcppubsub.FxGcpPubSubModule,
+		fxgcppubsub.FxGcpPubSubWorkerModule,
+		fx.Supply(fx.Annotate(context.Background(), fx.As(new(context.Context)))),
+		fxgcppubsub.PrepareTopicAndSubscription(fxgcppubsub.PrepareTopicAndSubscriptionParams{
+			TopicID:        "test-topic",
+			SubscriptionID: "test-subscription",
+		}),
+		fxgcppubsub.AsSubscriptionHandler("test-subscription", newTestSubscriptionHandler),
+		fxgcppubsub.AsSubscriptionHandler("test-subscription", newTestSubscriptionHandler),
+		fx.Populate(&workerSupervisor),
+	).RequireStart()
+
+	assert.Len(t, workerSupervisor.Workers(), 2)
+
+	for _, w := range workerSupervisor.Workers() {
+		assert.Equal(t, "test-subscription", w.SubscriptionID())
+	}
+
+	app.RequireStop()
+}
+
+func TestFxGcpPubSubModuleWithInvalidSubscriptionHandler(t *testing.T) {
+	t.Setenv("APP_ENV", "test")
+	t.Setenv("APP_CONFIG_PATH", "testdata/config")
+	t.Setenv("GCP_PROJECT_ID", "test-project")
+
+	app := fx.New(
+		fx.NopLogger,
+		fxconfig.FxConfigModule,
+		fxlog.FxLogModule,
+		fxgcppubsub.FxGcpPubSubModule,
+		fxgcppubsub.FxGcpPubSubWorkerModule,
+		fx.Supply(fx.Annotate(context.Background(), fx.As(new(context.Context)))),
+		fxgcppubsub.AsSubscriptionHandler("test-subscription", func() string {
+			return "invalid"
+		}),
+	)
+
+	assert.Error(t, app.Err())
+}
+
+func TestFxGcpPubSubModuleWithSchemaRevisions(t *testing.T) {
+	t.Setenv("APP_ENV", "test")
+	t.Setenv("APP_CONFIG_PATH", "testdata/config")
+	t.Setenv("GCP_PROJECT_ID", "test-project")
+
+	var publisher fxgcppubsub.Publisher
+	var subscriber fxgcppubsub.Subscriber
+	var manager schema.SchemaRevisionManager
+	var schemaClient *pubsub.SchemaClient
+	var client *pubsub.Client
+
+	ctx := context.Background()
+
+	fxtest.New(
+		t,
+		fx.NopLogger,
+		fxconfig.FxConfigModule,
+		fxlog.FxLogModule,
+		fxgcppubsub.FxGcpPubSubModule,
+		fx.Supply(fx.Annotate(ctx, fx.As(new(context.Context)))),
+		fxgcppubsub.PrepareTopicAndSubscriptionWithSchema(fxgcppubsub.PrepareTopicAndSubscriptionWithSchemaParams{
+			TopicID:        "avro-topic",
+			SubscriptionID: "avro-subscription",
+			SchemaID:       "avro-schema",
+			SchemaConfig: pubsub.SchemaConfig{
+				Type:       pubsub.SchemaAvro,
+				Definition: avro.GetTestAvroSchemaDefinition(t),
+			},
+			SchemaEncoding: pubsub.EncodingBinary,
+		}),
+		fx.Populate(&publisher, &subscriber, &manager, &schemaClient, &client),
+	).RequireStart().RequireStop()
+
+	v1, err := schemaClient.Schema(ctx, "avro-schema", pubsub.SchemaViewFull)
+	assert.NoError(t, err)
+
+	// publish with the first revision
+	res, err := publisher.Publish(
+		ctx,
+		"avro-topic",
+		avro.SimpleRecord{StringField: "v1", FloatField: 12.34, BooleanField: true},
+		topic.WithMessageAttributes(map[string]string{codec.SchemaRevisionIDAttribute: v1.RevisionID}),
+	)
+	assert.NoError(t, err)
+
+	_, err = res.Get(ctx)
+	assert.NoError(t, err)
+
+	// commit a new revision, and publish with it
+	v2, err := manager.Commit(ctx, "avro-schema", avro.GetTestAvroSchemaV2Definition(t), schema.CompatibilityBackward)
+	assert.NoError(t, err)
+
+	res, err = publisher.Publish(
+		ctx,
+		"avro-topic",
+		avro.SimpleRecordV2{StringField: "v2", FloatField: 56.78, BooleanField: false, IntField: 12},
+		topic.WithMessageAttributes(map[string]string{codec.SchemaRevisionIDAttribute: v2.RevisionID}),
+	)
+	assert.NoError(t, err)
+
+	_, err = res.Get(ctx)
+	assert.NoError(t, err)
+
+	// consume both revisions
+	var mutex sync.Mutex
+	received := map[string]avro.SimpleRecordV2{}
+
+	subscribeCtx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+
+	err = subscriber.Subscribe(subscribeCtx, "avro-subscription", func(ctx context.Context, m *message.Message) {
+		var out avro.SimpleRecordV2
+
+		err := m.Decode(&out)
+		assert.NoError(t, err)
+
+		m.Ack()
+
+		mutex.Lock()
+		defer mutex.Unlock()
+
+		received[m.SchemaRevisionID()] = out
+
+		if len(received) == 2 {
+			cancel()
+		}
+	})
+	assert.NoError(t, err)
+
+	assert.Equal(t, avro.SimpleRecordV2{StringField: "v1", FloatField: 12.34, BooleanField: true}, received[v1.RevisionID])
+	assert.Equal(t, avro.SimpleRecordV2{StringField: "v2", FloatField: 56.78, BooleanField: false, IntField: 12}, received[v2.RevisionID])
+}
+
+func TestFxGcpPubSubModuleWithCodecs(t *testing.T) {
+	t.Setenv("APP_ENV", "test")
+	t.Setenv("APP_CONFIG_PATH", "testdata/config")
+	t.Setenv("GCP_PROJECT_ID", "test-project")
+
+	type person struct {
+		Name string `json:"name"`
+		Age  int    `json:"age"`
+	}
+
+	var publisher fxgcppubsub.Publisher
+	var subscriber fxgcppubsub.Subscriber
+
+	ctx := context.Background()
+
+	fxtest.New(
+		t,
+		fx.NopLogger,
+		fxconfig.FxConfigModule,
+		fxlog.FxLogModule,
+		fxgcppubsub.FxGcpPubSubModule,
+		fx.Supply(fx.Annotate(ctx, fx.As(new(context.Context)))),
+		fxgcppubsub.AsCodec("person-json", func() (*codec.JsonCodec, error) {
+			return codec.NewJsonSchemaCodec(`{"type":"object","properties":{"age":{"type":"integer","minimum":0}}}`)
+		}),
+		fxgcppubsub.PrepareTopicAndSubscription(fxgcppubsub.PrepareTopicAndSubscriptionParams{
+			TopicID: "person-topic",
+			TopicConfig: pubsub.TopicConfig{
+				Labels: map[string]string{codec.CodecLabel: "person-json"},
+			},
+			SubscriptionID: "person-subscription",
+		}),
+		fxgcppubsub.PrepareTopicAndSubscription(fxgcppubsub.PrepareTopicAndSubscriptionParams{
+			TopicID:        "codec-topic",
+			SubscriptionID: "codec-subscription",
+		}),
+		fx.Populate(&publisher, &subscriber),
+	).RequireStart().RequireStop()
+
+	t.Run("json codec selected by topic label", func(t *testing.T) {
+		_, err := publisher.Publish(ctx, "person-topic", person{Name: "invalid", Age: -1})
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "cannot encode json: json schema validation failure")
+
+		res, err := publisher.Publish(ctx, "person-topic", person{Name: "test", Age: 12})
+		assert.NoError(t, err)
+
+		_, err = res.Get(ctx)
+		assert.NoError(t, err)
+
+		received := false
+
+		subscribeCtx, cancel := context.WithTimeout(ctx, 2*time.Second)
+		defer cancel()
+
+		err = subscriber.Subscribe(subscribeCtx, "person-subscription", func(ctx context.Context, m *message.Message) {
+			var out person
+
+			err := m.Decode(&out)
+			assert.NoError(t, err)
+			assert.Equal(t, person{Name: "test", Age: 12}, out)
+
+			m.Ack()
+			received = true
+			cancel()
+		})
+		assert.NoError(t, err)
+		assert.True(t, received)
+	})
+
+	t.Run("cloudevents binary codec selected by config", func(t *testing.T) {
+		res, err := publisher.Publish(ctx, "codec-topic", person{Name: "test", Age: 12})
+		assert.NoError(t, err)
+
+		_, err = res.Get(ctx)
+		assert.NoError(t, err)
+
+		received := false
+
+		subscribeCtx, cancel := context.WithTimeout(ctx, 2*time.Second)
+		defer cancel()
+
+		err = subscriber.Subscribe(subscribeCtx, "codec-subscription", func(ctx context.Context, m *message.Message) {
+			assert.Equal(t, "test-app", m.Attributes()["ce-source"])
+			assert.Equal(t, "test.event", m.Attributes()["ce-type"])
+			assert.Equal(t, `{"name":"test","age":12}`, string(m.Data()))
+
+			var event codec.CloudEvent
+
+			err := m.Decode(&event)
+			assert.NoError(t, err)
+			assert.Equal(t, "test-app", event.Source)
+			assert.Equal(t, "test.event", event.Type)
+
+			var out person
+
+			err = event.DecodeData(&out)
+			assert.NoError(t, err)
+			assert.Equal(t, person{Name: "test", Age: 12}, out)
+
+			m.Ack()
+			received = true
+			cancel()
+		})
+		assert.NoError(t, err)
+		assert.True(t, received)
+	})
+}
+
+func TestFxGcpPubSubModuleWithEncryption(t *testing.T) {
+	t.Setenv("APP_ENV", "test")
+	t.Setenv("APP_CONFIG_PATH", "testdata/config")
+	t.Setenv("GCP_PROJECT_ID", "test-project")
+
+	type person struct {
+		Name string `json:"name"`
+		Age  int    `json:"age"`
+	}
+
+	var publisher fxgcppubsub.Publisher
+	var subscriber fxgcppubsub.Subscriber
+	var registry codec.CodecRegistry
+
+	ctx := context.Background()
+
+	fxtest.New(
+		t,
+		fx.NopLogger,
+		fxconfig.FxConfigModule,
+		fxlog.FxLogModule,
+		fxgcppubsub.FxGcpPubSubModule,
+		fx.Supply(fx.Annotate(ctx, fx.As(new(context.Context)))),
+		fxgcppubsub.PrepareTopicAndSubscription(fxgcppubsub.PrepareTopicAndSubscriptionParams{
+			TopicID:        "encrypted-topic",
+			SubscriptionID: "encrypted-subscription",
+		}),
+		fx.Populate(&publisher, &subscriber, &registry),
+	).RequireStart().RequireStop()
+
+	assert.True(t, registry.Has("encrypted-json"))
+	assert.True(t, registry.Has("encrypted-cloudevents-binary"))
+
+	res, err := publisher.Publish(ctx, "encrypted-topic", person{Name: "test", Age: 12})
+	assert.NoError(t, err)
+
+	_, err = res.Get(ctx)
+	assert.NoError(t, err)
+
+	received := false
+
+	subscribeCtx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+
+	err = subscriber.Subscribe(subscribeCtx, "encrypted-subscription", func(ctx context.Context, m *message.Message) {
+		assert.Equal(t, "key-2", m.Attributes()[encryption.KeyIDAttribute])
+		assert.NotEmpty(t, m.Attributes()[encryption.DataKeyAttribute])
+		assert.NotContains(t, string(m.Data()), "test")
+
+		var out person
+
+		err := m.Decode(&out)
+		assert.NoError(t, err)
+		assert.Equal(t, person{Name: "test", Age: 12}, out)
+
+		m.Ack()
+		received = true
+		cancel()
+	})
+	assert.NoError(t, err)
+	assert.True(t, received)
+}
+
+func TestFxGcpPubSubModuleWithEncryptionKeyProvider(t *testing.T) {
+	t.Setenv("APP_ENV", "test")
+	t.Setenv("APP_CONFIG_PATH", "testdata/config")
+	t.Setenv("GCP_PROJECT_ID", "test-project")
+
+	var registry codec.CodecRegistry
+
+	ctx := context.Background()
+
+	fxtest.New(
+		t,
+		fx.NopLogger,
+		fxconfig.FxConfigModule,
+		fxlog.FxLogModule,
+		fxgcppubsub.FxGcpPubSubModule,
+		fx.Supply(fx.Annotate(ctx, fx.As(new(context.Context)))),
+		fx.Provide(
+			fx.Annotate(
+				func() (*encryption.LocalKeyProvider, error) {
+					return encryption.NewLocalKeyProvider("custom-key", map[string][]byte{
+						"custom-key": []byte("0123456789abcdef"),
+					})
+				},
+				fx.As(new(encryption.KeyProvider)),
+			),
+		),
+		fx.Populate(&registry),
+	).RequireStart().RequireStop()
+
+	c, err := registry.Get("encrypted-json")
+	assert.NoError(t, err)
+
+	_, attributes, err := codec.EncodeWithAttributes(c, map[string]string{"foo": "bar"})
+	assert.NoError(t, err)
+	assert.Equal(t, "custom-key", attributes[encryption.KeyIDAttribute])
+}
+
+func TestFxGcpPubSubModuleWithOrderingKeys(t *testing.T) {
+	t.Setenv("APP_ENV", "test")
+	t.Setenv("APP_CONFIG_PATH", "testdata/config")
+	t.Setenv("GCP_PROJECT_ID", "test-project")
+
+	var publisher fxgcppubsub.Publisher
+	var subscriber fxgcppubsub.Subscriber
+	var client *pubsub.Client
+
+	ctx := context.Background()
+
+	fxtest.New(
+		t,
+		fx.NopLogger,
+		fxconfig.FxConfigModule,
+		fxlog.FxLogModule,
+		fxgcppubsub.FxGcpPubSubModule,
+		fx.Supply(fx.Annotate(ctx, fx.As(new(context.Context)))),
+		fxgcppubsub.PrepareTopicAndSubscription(fxgcppubsub.PrepareTopicAndSubscriptionParams{
+			TopicID:        "ordered-topic",
+			SubscriptionID: "ordered-subscription",
+			SubscriptionConfig: pubsub.SubscriptionConfig{
+				EnableMessageOrdering: true,
+			},
+		}),
+		fxgcppubsub.PrepareTopic(fxgcppubsub.PrepareTopicParams{
+			TopicID: "deleted-topic",
+		}),
+		fx.Populate(&publisher, &subscriber, &client),
+	).RequireStart().RequireStop()
+
+	t.Run("ordered handling per ordering key", func(t *testing.T) {
+		for i := 1; i <= 5; i++ {
+			for _, orderingKey := range []string{"a", "b"} {
+				res, err := publisher.Publish(
+					ctx,
+					"ordered-topic",
+					[]byte(fmt.Sprintf("%s-%d", orderingKey, i)),
+					topic.WithMessageOrderingKey(orderingKey),
+				)
+				assert.NoError(t, err)
+
+				_, err = res.Get(ctx)
+				assert.NoError(t, err)
+			}
+		}
+
+		var mutex sync.Mutex
+		var running, maxRunning int
+		handled := map[string][]string{}
+
+		subscribeCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+		defer cancel()
+
+		//nolint:errcheck
+		go subscriber.Subscribe(subscribeCtx, "ordered-subscription", func(ctx context.Context, m *message.Message) {
+			mutex.Lock()
+			running++
+			maxRunning = max(maxRunning, running)
+			mutex.Unlock()
+
+			time.Sleep(10 * time.Millisecond)
+
+			mutex.Lock()
+			running--
+			handled[m.OrderingKey()] = append(handled[m.OrderingKey()], string(m.Data()))
+			mutex.Unlock()
+
+			m.Ack()
+		})
+
+		assert.Eventually(t, func() bool {
+			mutex.Lock()
+			defer mutex.Unlock()
+
+			return len(handled["a"]) == 5 && len(handled["b"]) == 5
+		}, 5*time.Second, 10*time.Millisecond)
+
+		mutex.Lock()
+		defer mutex.Unlock()
+
+		assert.Equal(t, []string{"a-1", "a-2", "a-3", "a-4", "a-5"}, handled["a"])
+		assert.Equal(t, []string{"b-1", "b-2", "b-3", "b-4", "b-5"}, handled["b"])
+		assert.LessOrEqual(t, maxRunning, 2)
+	})
+
+	t.Run("paused ordering keys", func(t *testing.T) {
+		keys, err := publisher.PausedOrderingKeys(ctx, "deleted-topic")
+		assert.NoError(t, err)
+		assert.Empty(t, keys)
+
+		err = client.Topic("deleted-topic").Delete(ctx)
+		assert.NoError(t, err)
+
+		res, err := publisher.Publish(ctx, "deleted-topic", []byte("test"), topic.WithMessageOrderingKey("key"))
+		assert.NoError(t, err)
+
+		_, err = res.Get(ctx)
+		assert.Error(t, err)
+
+		assert.Eventually(t, func() bool {
+			keys, err = publisher.PausedOrderingKeys(ctx, "deleted-topic")
+
+			return err == nil && len(keys) == 1
+		}, 2*time.Second, 10*time.Millisecond)
+
+		assert.Equal(t, "key", keys[0].OrderingKey)
+		assert.Equal(t, 1, keys[0].Failures)
+
+		err = publisher.ResumePublish(ctx, "deleted-topic", "key")
+		assert.NoError(t, err)
+
+		keys, err = publisher.PausedOrderingKeys(ctx, "deleted-topic")
+		assert.NoError(t, err)
+		assert.Empty(t, keys)
+	})
+
+	t.Run("paused ordering keys of an unknown topic", func(t *testing.T) {
+		_, err := publisher.PausedOrderingKeys(ctx, "unknown-topic")
+		assert.Error(t, err)
+
+		err = publisher.ResumePublish(ctx, "unknown-topic", "key")
+		assert.Error(t, err)
+	})
+}
+
+func TestFxGcpPubSubModuleWithDelayedPublishing(t *testing.T) {
+	t.Setenv("APP_ENV", "test")
+	t.Setenv("APP_CONFIG_PATH", "testdata/config")
+	t.Setenv("GCP_PROJECT_ID", "test-project")
+
+	var publisher fxgcppubsub.Publisher
+	var subscriber fxgcppubsub.Subscriber
+	var store delay.Store
+	var scheduler *delay.Scheduler
+
+	ctx := context.Background()
+
+	app := fxtest.New(
+		t,
+		fx.NopLogger,
+		fxconfig.FxConfigModule,
+		fxlog.FxLogModule,
+		fxgcppubsub.FxGcpPubSubModule,
+		fxgcppubsub.FxGcpPubSubDelayModule,
+		fx.Provide(fx.Annotate(delay.NewMemoryStore, fx.As(new(delay.Store)))),
+		fx.Supply(fx.Annotate(ctx, fx.As(new(context.Context)))),
+		fxgcppubsub.PrepareTopicAndSubscription(fxgcppubsub.PrepareTopicAndSubscriptionParams{
+			TopicID:        "delay-topic",
+			SubscriptionID: "delay-subscription",
+		}),
+		fx.Populate(&publisher, &subscriber, &store, &scheduler),
+	).RequireStart()
+
+	// the scheduler runs while the application is started
+	defer app.RequireStop()
+
+	assert.IsType(t, &delay.MemoryStore{}, store)
+	assert.Equal(t, 50*time.Millisecond, scheduler.Options().Interval)
+	assert.Equal(t, 50*time.Millisecond, scheduler.Options().RetryDelay)
+	assert.Equal(t, delay.DefaultSchedulerLease, scheduler.Options().Lease)
+
+	t.Run("delayed publications are received once due", func(t *testing.T) {
+		start := time.Now()
+
+		laterID, err := publisher.PublishAfter(
+			ctx,
+			"delay-topic",
+			[]byte("later"),
+			300*time.Millisecond,
+			topic.WithMessageAttributes(map[string]string{"foo": "bar"}),
+		)
+		assert.NoError(t, err)
+
+		soonID, err := publisher.PublishAt(ctx, "delay-topic", []byte("soon"), start.Add(100*time.Millisecond))
+		assert.NoError(t, err)
+
+		var mutex sync.Mutex
+		received := map[string]time.Time{}
+		attributes := map[string]map[string]string{}
+
+		subscribeCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+		defer cancel()
+
+		//nolint:errcheck
+		go subscriber.Subscribe(subscribeCtx, "delay-subscription", func(ctx context.Context, m *message.Message) {
+			mutex.Lock()
+			received[string(m.Data())] = time.Now()
+			attributes[string(m.Data())] = m.Attributes()
+			mutex.Unlock()
+
+			m.Ack()
+		})
+
+		assert.Eventually(t, func() bool {
+			mutex.Lock()
+			defer mutex.Unlock()
+
+			return len(received) == 2
+		}, 5*time.Second, 10*time.Millisecond)
+
+		mutex.Lock()
+		defer mutex.Unlock()
+
+		assert.True(t, received["soon"].Sub(start) >= 100*time.Millisecond)
+		assert.True(t, received["later"].Sub(start) >= 300*time.Millisecond)
+		assert.True(t, received["soon"].Before(received["later"]))
+
+		assert.Equal(t, soonID, attributes["soon"][delay.DelayedMessageIDAttribute])
+		assert.Equal(t, laterID, attributes["later"][delay.DelayedMessageIDAttribute])
+		assert.Equal(t, "bar", attributes["later"]["foo"])
+
+		// completed once published
+		assert.Eventually(t, func() bool {
+			//nolint:forcetypeassert
+			return len(store.(*delay.MemoryStore).Messages()) == 0
+		}, 2*time.Second, 10*time.Millisecond)
+	})
+
+	t.Run("delayed publication on an unknown topic", func(t *testing.T) {
+		_, err := publisher.PublishAfter(ctx, "unknown-topic", []byte("test"), time.Second)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "cannot create topic")
+	})
+}
+
+func TestFxGcpPubSubModuleWithoutDelayStore(t *testing.T) {
+	t.Setenv("APP_ENV", "test")
+	t.Setenv("APP_CONFIG_PATH", "testdata/config")
+	t.Setenv("GCP_PROJECT_ID", "test-project")
+
+	var publisher fxgcppubsub.Publisher
+
+	ctx := context.Background()
+
+	fxtest.New(
+		t,
+		fx.NopLogger,
+		fxconfig.FxConfigModule,
+		fxlog.FxLogModule,
+		fxgcppubsub.FxGcpPubSubModule,
+		fx.Supply(fx.Annotate(ctx, fx.As(new(context.Context)))),
+		fxgcppubsub.PrepareTopic(fxgcppubsub.PrepareTopicParams{
+			TopicID: "delay-topic",
+		}),
+		fx.Populate(&publisher),
+	).RequireStart().RequireStop()
+
+	_, err := publisher.PublishAfter(ctx, "delay-topic", []byte("test"), time.Minute)
+	assert.Error(t, err)
+	assert.Equal(t, "cannot schedule publication: no delay store configured", err.Error())
+}
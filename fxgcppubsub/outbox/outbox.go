@@ -0,0 +1,70 @@
+package outbox
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"cloud.google.com/go/pubsub"
+	"github.com/ankorstore/yokai-contrib/fxgcppubsub/codec"
+	"github.com/ankorstore/yokai-contrib/fxgcppubsub/topic"
+)
+
+var _ Outbox = (*DefaultOutbox)(nil)
+
+// Publisher is the interface for the publishers used by the outbox.
+type Publisher interface {
+	Topic(ctx context.Context, topicID string) (*topic.Topic, error)
+	PublishEncoded(ctx context.Context, topicID string, data []byte, options ...topic.PublishOption) (*pubsub.PublishResult, error)
+}
+
+// Outbox is the interface for transactional outboxes.
+type Outbox interface {
+	Write(ctx context.Context, tx *sql.Tx, topicID string, data any, options ...topic.PublishOption) error
+}
+
+// DefaultOutbox is the default Outbox implementation.
+type DefaultOutbox struct {
+	publisher Publisher
+	store     Store
+}
+
+// NewDefaultOutbox returns a new DefaultOutbox instance.
+func NewDefaultOutbox(publisher Publisher, store Store) *DefaultOutbox {
+	return &DefaultOutbox{
+		publisher: publisher,
+		store:     store,
+	}
+}
+
+// Write encodes data with the topicID codec, and writes it with the message options (attributes and ordering key)
+// into the outbox, within the provided transaction. It will be published by the Relay once the transaction is committed.
+//
+// The per publication middlewares are not saved: only the registered global middlewares are applied by the Relay.
+func (o *DefaultOutbox) Write(ctx context.Context, tx *sql.Tx, topicID string, data any, options ...topic.PublishOption) error {
+	// retrieve topic
+	top, err := o.publisher.Topic(ctx, topicID)
+	if err != nil {
+		return err
+	}
+
+	// encode
+	encodedData, encodedAttributes, err := codec.EncodeWithAttributesContext(ctx, top.Codec(), data)
+	if err != nil {
+		return fmt.Errorf("cannot encode data: %w", err)
+	}
+
+	// resolve message options
+	publishOptions := topic.DefaultPublishOptions()
+	for _, applyOpt := range options {
+		applyOpt(publishOptions)
+	}
+
+	// write
+	return o.store.Insert(ctx, tx, &Record{
+		TopicID:     topicID,
+		Data:        encodedData,
+		Attributes:  publishOptions.MessageSettings.MergeAttributes(encodedAttributes),
+		OrderingKey: publishOptions.MessageSettings.OrderingKey,
+	})
+}
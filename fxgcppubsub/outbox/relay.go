@@ -0,0 +1,227 @@
+package outbox
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"cloud.google.com/go/pubsub"
+	"github.com/ankorstore/yokai-contrib/fxgcppubsub/topic"
+	"github.com/ankorstore/yokai/log"
+)
+
+const (
+	DefaultRelayInterval    = 1 * time.Second
+	DefaultRelayBatchSize   = 100
+	DefaultRelayMaxAttempts = 10
+	DefaultRelayLease       = 1 * time.Minute
+)
+
+// RelayOptions represents the Relay options.
+type RelayOptions struct {
+	Interval    time.Duration
+	BatchSize   int
+	MaxAttempts int
+	Lease       time.Duration
+}
+
+// DefaultRelayOptions returns the default RelayOptions.
+func DefaultRelayOptions() RelayOptions {
+	return RelayOptions{
+		Interval:    DefaultRelayInterval,
+		BatchSize:   DefaultRelayBatchSize,
+		MaxAttempts: DefaultRelayMaxAttempts,
+		Lease:       DefaultRelayLease,
+	}
+}
+
+// Relay publishes in background the pending outbox records, and marks them as sent.
+//
+// Records are claimed for RelayOptions.Lease, so concurrent relays (for example across application instances) do not
+// publish them concurrently. Records failing to be published are retried on the next runs, until RelayOptions.MaxAttempts,
+// and the following records of their ordering key are not published while they are failing.
+// Since a record can be published but not marked as sent (for example on shutdown, or on lease expiration), the delivery
+// is at least once.
+type Relay struct {
+	store     Store
+	publisher Publisher
+	logger    *log.Logger
+	options   RelayOptions
+	cancel    context.CancelFunc
+	wg        sync.WaitGroup
+	mutex     sync.Mutex
+}
+
+// NewRelay returns a new Relay instance.
+func NewRelay(store Store, publisher Publisher, logger *log.Logger, options RelayOptions) *Relay {
+	defaults := DefaultRelayOptions()
+
+	if options.Interval <= 0 {
+		options.Interval = defaults.Interval
+	}
+
+	if options.BatchSize <= 0 {
+		options.BatchSize = defaults.BatchSize
+	}
+
+	if options.MaxAttempts <= 0 {
+		options.MaxAttempts = defaults.MaxAttempts
+	}
+
+	if options.Lease <= 0 {
+		options.Lease = defaults.Lease
+	}
+
+	return &Relay{
+		store:     store,
+		publisher: publisher,
+		logger:    logger,
+		options:   options,
+	}
+}
+
+// Start starts the relay in background, running every RelayOptions.Interval until Stop is called.
+func (r *Relay) Start(ctx context.Context) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if r.cancel != nil {
+		return
+	}
+
+	ctx, r.cancel = context.WithCancel(ctx)
+
+	r.wg.Add(1)
+
+	go func() {
+		defer r.wg.Done()
+
+		ticker := time.NewTicker(r.options.Interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				_, err := r.Run(ctx)
+				if err != nil && ctx.Err() == nil {
+					r.logger.Error().Err(err).Msg("pubsub outbox relay error")
+				}
+			}
+		}
+	}()
+}
+
+// Stop stops the relay, and waits for the current run to complete.
+func (r *Relay) Stop() {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if r.cancel == nil {
+		return
+	}
+
+	r.cancel()
+	r.wg.Wait()
+
+	r.cancel = nil
+}
+
+// Run performs a single relay run: it publishes a batch of claimed records, and returns the number of sent ones.
+func (r *Relay) Run(ctx context.Context) (int, error) {
+	records, err := r.store.Claim(ctx, r.options.BatchSize, r.options.MaxAttempts, r.options.Lease)
+	if err != nil {
+		return 0, err
+	}
+
+	// publish
+	results := make([]*pubsub.PublishResult, len(records))
+	errs := make([]error, len(records))
+
+	for i, record := range records {
+		// the topic paused the ordering key on the previous failure
+		if record.OrderingKey != "" && record.Attempts > 0 {
+			errs[i] = r.resume(ctx, record)
+			if errs[i] != nil {
+				continue
+			}
+		}
+
+		results[i], errs[i] = r.publisher.PublishEncoded(
+			ctx,
+			record.TopicID,
+			record.Data,
+			topic.WithMessageAttributes(record.Attributes),
+			topic.WithMessageOrderingKey(record.OrderingKey),
+		)
+	}
+
+	// mark
+	sent := 0
+	failed := make(map[string]bool)
+
+	for i, record := range records {
+		if errs[i] == nil {
+			_, errs[i] = results[i].Get(ctx)
+		}
+
+		if errs[i] != nil {
+			key := orderingKey(record)
+
+			// refused because of an earlier failing record of the ordering key, not accounted as an attempt
+			if key != "" && failed[key] {
+				err = r.store.Release(ctx, record.ID)
+				if err != nil {
+					return sent, err
+				}
+
+				continue
+			}
+
+			if key != "" {
+				failed[key] = true
+			}
+
+			r.logger.
+				Warn().
+				Err(errs[i]).
+				Int64("recordID", record.ID).
+				Str("topicID", record.TopicID).
+				Int("attempt", record.Attempts+1).
+				Msg("pubsub outbox record publication error")
+
+			err = r.store.MarkFailed(ctx, record.ID, errs[i])
+			if err != nil {
+				return sent, err
+			}
+
+			continue
+		}
+
+		err = r.store.MarkSent(ctx, record.ID)
+		if err != nil {
+			return sent, fmt.Errorf("record %d published but not marked as sent: %w", record.ID, err)
+		}
+
+		sent++
+	}
+
+	if sent > 0 {
+		r.logger.Debug().Int("sent", sent).Msg("pubsub outbox records sent")
+	}
+
+	return sent, nil
+}
+
+func (r *Relay) resume(ctx context.Context, record *Record) error {
+	top, err := r.publisher.Topic(ctx, record.TopicID)
+	if err != nil {
+		return err
+	}
+
+	top.ResumePublish(record.OrderingKey)
+
+	return nil
+}
@@ -0,0 +1,222 @@
+package outbox_test
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/pubsub"
+	"github.com/ankorstore/yokai-contrib/fxgcppubsub"
+	"github.com/ankorstore/yokai-contrib/fxgcppubsub/outbox"
+	"github.com/ankorstore/yokai-contrib/fxgcppubsub/testdata/mysql"
+	"github.com/ankorstore/yokai-contrib/fxgcppubsub/topic"
+	"github.com/ankorstore/yokai/fxconfig"
+	"github.com/ankorstore/yokai/fxlog"
+	"github.com/ankorstore/yokai/log"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/fx"
+	"go.uber.org/fx/fxtest"
+)
+
+func TestRelay(t *testing.T) {
+	t.Setenv("APP_ENV", "test")
+	t.Setenv("APP_CONFIG_PATH", "../testdata/config")
+	t.Setenv("GCP_PROJECT_ID", "test-project")
+
+	ctx := context.Background()
+	db := mysql.StartTestServer(t)
+
+	var publisher outbox.Publisher
+	var client *pubsub.Client
+	var logger *log.Logger
+
+	fxtest.New(
+		t,
+		fx.NopLogger,
+		fxconfig.FxConfigModule,
+		fxlog.FxLogModule,
+		fxgcppubsub.FxGcpPubSubModule,
+		fx.Supply(fx.Annotate(ctx, fx.As(new(context.Context)))),
+		fxgcppubsub.PrepareTopicAndSubscription(fxgcppubsub.PrepareTopicAndSubscriptionParams{
+			TopicID:        "outbox-topic",
+			SubscriptionID: "outbox-subscription",
+		}),
+		fx.Populate(&publisher, &client, &logger),
+	).RequireStart().RequireStop()
+
+	store, err := outbox.NewSQLStore(db, "", outbox.DialectMySQL)
+	assert.NoError(t, err)
+
+	err = store.CreateTable(ctx)
+	assert.NoError(t, err)
+
+	box := outbox.NewDefaultOutbox(publisher, store)
+	relay := outbox.NewRelay(store, publisher, logger, outbox.RelayOptions{MaxAttempts: 2})
+
+	write := func(topicID string, data string, commit bool) error {
+		tx, err := db.BeginTx(ctx, nil)
+		assert.NoError(t, err)
+
+		err = box.Write(ctx, tx, topicID, []byte(data), topic.WithMessageAttributes(map[string]string{"foo": "bar"}))
+		if err != nil {
+			assert.NoError(t, tx.Rollback())
+
+			return err
+		}
+
+		if commit {
+			return tx.Commit()
+		}
+
+		return tx.Rollback()
+	}
+
+	t.Run("write on invalid topic", func(t *testing.T) {
+		err := write("invalid-topic", "test", true)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "cannot create topic")
+	})
+
+	t.Run("rollbacked write is not published", func(t *testing.T) {
+		err := write("outbox-topic", "rollbacked", false)
+		assert.NoError(t, err)
+
+		sent, err := relay.Run(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, 0, sent)
+	})
+
+	t.Run("committed write is published", func(t *testing.T) {
+		err := write("outbox-topic", "committed", true)
+		assert.NoError(t, err)
+
+		sent, err := relay.Run(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, sent)
+
+		// already sent
+		sent, err = relay.Run(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, 0, sent)
+
+		// received
+		receiveCtx, cancel := context.WithTimeout(ctx, 2*time.Second)
+		defer cancel()
+
+		var data []byte
+		var attributes map[string]string
+
+		err = client.Subscription("outbox-subscription").Receive(receiveCtx, func(_ context.Context, m *pubsub.Message) {
+			data = m.Data
+			attributes = m.Attributes
+
+			m.Ack()
+			cancel()
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, []byte("committed"), data)
+		assert.Equal(t, map[string]string{"foo": "bar"}, attributes)
+	})
+
+	t.Run("failing record is retried until max attempts", func(t *testing.T) {
+		tx, err := db.BeginTx(ctx, nil)
+		assert.NoError(t, err)
+
+		err = store.Insert(ctx, tx, &outbox.Record{TopicID: "invalid-topic", Data: []byte("test")})
+		assert.NoError(t, err)
+		assert.NoError(t, tx.Commit())
+
+		for i := 1; i <= 2; i++ {
+			sent, err := relay.Run(ctx)
+			assert.NoError(t, err)
+			assert.Equal(t, 0, sent)
+		}
+
+		records, err := store.Claim(ctx, 10, 2, time.Minute)
+		assert.NoError(t, err)
+		assert.Len(t, records, 0)
+
+		var lastError sql.NullString
+
+		err = db.QueryRowContext(ctx, "SELECT last_error FROM gcppubsub_outbox WHERE topic_id = 'invalid-topic'").Scan(&lastError)
+		assert.NoError(t, err)
+		assert.True(t, lastError.Valid)
+		assert.Contains(t, lastError.String, "cannot create topic")
+	})
+
+	t.Run("following records of a failing ordering key are not published", func(t *testing.T) {
+		for _, data := range []string{"first", "second"} {
+			tx, err := db.BeginTx(ctx, nil)
+			assert.NoError(t, err)
+
+			err = store.Insert(ctx, tx, &outbox.Record{TopicID: "invalid-ordered-topic", Data: []byte(data), OrderingKey: "key"})
+			assert.NoError(t, err)
+			assert.NoError(t, tx.Commit())
+		}
+
+		attempts := func() map[string]int {
+			rows, err := db.QueryContext(ctx, "SELECT data, attempts FROM gcppubsub_outbox WHERE topic_id = 'invalid-ordered-topic'")
+			assert.NoError(t, err)
+
+			defer rows.Close()
+
+			res := map[string]int{}
+
+			for rows.Next() {
+				var data string
+				var attempts int
+
+				assert.NoError(t, rows.Scan(&data, &attempts))
+
+				res[data] = attempts
+			}
+
+			assert.NoError(t, rows.Err())
+
+			return res
+		}
+
+		// the second record is released, without attempt
+		sent, err := relay.Run(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, 0, sent)
+		assert.Equal(t, map[string]int{"first": 1, "second": 0}, attempts())
+
+		// the second record is not claimed while the first one is failing
+		for i := 1; i <= 2; i++ {
+			sent, err = relay.Run(ctx)
+			assert.NoError(t, err)
+			assert.Equal(t, 0, sent)
+		}
+
+		assert.Equal(t, map[string]int{"first": 2, "second": 0}, attempts())
+	})
+}
+
+func TestRelayStartAndStop(t *testing.T) {
+	t.Parallel()
+
+	db := mysql.StartTestServer(t)
+
+	store, err := outbox.NewSQLStore(db, "", outbox.DialectMySQL)
+	assert.NoError(t, err)
+
+	logger, err := log.NewDefaultLoggerFactory().Create()
+	assert.NoError(t, err)
+
+	relay := outbox.NewRelay(store, nil, logger, outbox.RelayOptions{
+		Interval: 10 * time.Millisecond,
+	})
+
+	// stop before start is a no-op
+	relay.Stop()
+
+	relay.Start(context.Background())
+	relay.Start(context.Background())
+
+	time.Sleep(50 * time.Millisecond)
+
+	relay.Stop()
+	relay.Stop()
+}
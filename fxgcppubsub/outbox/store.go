@@ -0,0 +1,273 @@
+package outbox
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/ankorstore/yokai-contrib/fxgcppubsub/internal/sqlstore"
+)
+
+var _ Store = (*SQLStore)(nil)
+
+const (
+	DefaultTableName = "gcppubsub_outbox"
+	DialectMySQL     = sqlstore.DialectMySQL
+	DialectPostgres  = sqlstore.DialectPostgres
+)
+
+// Record represents an outbox record, holding an encoded message to publish on a topic.
+type Record struct {
+	ID          int64
+	TopicID     string
+	Data        []byte
+	Attributes  map[string]string
+	OrderingKey string
+	Attempts    int
+}
+
+// Store is the interface for outbox records stores.
+//
+// Claim returns, by insertion order, up to limit pending records with less than maxAttempts failed attempts, and locks
+// them for the lease duration: while locked, a record cannot be claimed again. A record is not claimed while an earlier
+// record of its ordering key is failing (even after maxAttempts) or locked.
+// MarkSent, MarkFailed and Release unlock the record.
+type Store interface {
+	Insert(ctx context.Context, tx *sql.Tx, record *Record) error
+	Claim(ctx context.Context, limit int, maxAttempts int, lease time.Duration) ([]*Record, error)
+	MarkSent(ctx context.Context, id int64) error
+	MarkFailed(ctx context.Context, id int64, err error) error
+	Release(ctx context.Context, id int64) error
+}
+
+// SQLStore is a Store implementation backed by database/sql.
+//
+// Lock expirations are stored as unix milliseconds, and unlocked records have a NULL lock expiration.
+type SQLStore struct {
+	db    *sql.DB
+	table *sqlstore.Table
+}
+
+// NewSQLStore returns a new SQLStore instance, for a provided table and dialect (mysql or postgres).
+func NewSQLStore(db *sql.DB, table string, dialect string) (*SQLStore, error) {
+	if table == "" {
+		table = DefaultTableName
+	}
+
+	sqlTable, err := sqlstore.NewTable(db, table, dialect)
+	if err != nil {
+		return nil, fmt.Errorf("invalid outbox dialect %q", dialect)
+	}
+
+	return &SQLStore{
+		db:    db,
+		table: sqlTable,
+	}, nil
+}
+
+// CreateTable creates the outbox table, if not existing.
+func (s *SQLStore) CreateTable(ctx context.Context) error {
+	err := s.table.Create(
+		ctx,
+		`CREATE TABLE IF NOT EXISTS %s (
+			id BIGINT NOT NULL AUTO_INCREMENT PRIMARY KEY,
+			topic_id VARCHAR(255) NOT NULL,
+			data LONGBLOB NOT NULL,
+			attributes TEXT NOT NULL,
+			ordering_key VARCHAR(255) NOT NULL,
+			attempts INT NOT NULL DEFAULT 0,
+			last_error TEXT NULL,
+			locked_until BIGINT NULL,
+			created_at DATETIME(6) NOT NULL,
+			sent_at DATETIME(6) NULL
+		)`,
+		`CREATE TABLE IF NOT EXISTS %s (
+			id BIGSERIAL PRIMARY KEY,
+			topic_id VARCHAR(255) NOT NULL,
+			data BYTEA NOT NULL,
+			attributes TEXT NOT NULL,
+			ordering_key VARCHAR(255) NOT NULL,
+			attempts INTEGER NOT NULL DEFAULT 0,
+			last_error TEXT NULL,
+			locked_until BIGINT NULL,
+			created_at TIMESTAMP NOT NULL,
+			sent_at TIMESTAMP NULL
+		)`,
+	)
+	if err != nil {
+		return fmt.Errorf("cannot create outbox table: %w", err)
+	}
+
+	return nil
+}
+
+// Insert inserts a Record within the provided transaction.
+func (s *SQLStore) Insert(ctx context.Context, tx *sql.Tx, record *Record) error {
+	attributes, err := json.Marshal(record.Attributes)
+	if err != nil {
+		return fmt.Errorf("cannot marshal outbox record attributes: %w", err)
+	}
+
+	_, err = tx.ExecContext(
+		ctx,
+		s.table.Query("INSERT INTO %s (topic_id, data, attributes, ordering_key, attempts, created_at) VALUES (?, ?, ?, ?, 0, ?)"),
+		record.TopicID,
+		record.Data,
+		string(attributes),
+		record.OrderingKey,
+		time.Now().UTC(),
+	)
+	if err != nil {
+		return fmt.Errorf("cannot insert outbox record: %w", err)
+	}
+
+	return nil
+}
+
+// Claim returns, by insertion order, up to limit pending records with less than maxAttempts failed attempts, and locks
+// them for the lease duration.
+//
+// Each record is locked with a conditional update on its lock expiration, so concurrent relays cannot claim the same
+// record. Once a record cannot be locked, the following ones of its ordering key are not claimed either.
+func (s *SQLStore) Claim(ctx context.Context, limit int, maxAttempts int, lease time.Duration) ([]*Record, error) {
+	now := time.Now().UTC().UnixMilli()
+
+	rows, err := s.db.QueryContext(
+		ctx,
+		s.table.Query(`SELECT o.id, o.topic_id, o.data, o.attributes, o.ordering_key, o.attempts FROM %[1]s o
+			WHERE o.sent_at IS NULL AND o.attempts < ? AND (o.locked_until IS NULL OR o.locked_until <= ?)
+			AND NOT EXISTS (
+				SELECT 1 FROM %[1]s p
+				WHERE p.topic_id = o.topic_id AND p.ordering_key = o.ordering_key AND p.ordering_key <> ''
+				AND p.id < o.id AND p.sent_at IS NULL
+				AND (p.attempts > 0 OR p.locked_until > ?)
+			)
+			ORDER BY o.id LIMIT ?`),
+		maxAttempts,
+		now,
+		now,
+		limit,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("cannot fetch outbox records: %w", err)
+	}
+
+	defer rows.Close()
+
+	var candidates []*Record
+
+	for rows.Next() {
+		var attributes string
+
+		record := &Record{}
+
+		err = rows.Scan(&record.ID, &record.TopicID, &record.Data, &attributes, &record.OrderingKey, &record.Attempts)
+		if err != nil {
+			return nil, fmt.Errorf("cannot scan outbox record: %w", err)
+		}
+
+		err = json.Unmarshal([]byte(attributes), &record.Attributes)
+		if err != nil {
+			return nil, fmt.Errorf("cannot unmarshal outbox record attributes: %w", err)
+		}
+
+		candidates = append(candidates, record)
+	}
+
+	err = rows.Err()
+	if err != nil {
+		return nil, fmt.Errorf("cannot iterate outbox records: %w", err)
+	}
+
+	lockedUntil := time.Now().UTC().Add(lease).UnixMilli()
+	skipped := make(map[string]bool)
+
+	var records []*Record
+
+	for _, record := range candidates {
+		key := orderingKey(record)
+		if key != "" && skipped[key] {
+			continue
+		}
+
+		res, err := s.db.ExecContext(
+			ctx,
+			s.table.Query("UPDATE %s SET locked_until = ? WHERE id = ? AND sent_at IS NULL AND (locked_until IS NULL OR locked_until <= ?)"),
+			lockedUntil,
+			record.ID,
+			now,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("cannot claim outbox record: %w", err)
+		}
+
+		n, err := res.RowsAffected()
+		if err != nil {
+			return nil, fmt.Errorf("cannot claim outbox record: %w", err)
+		}
+
+		// claimed by another relay in the meantime
+		if n == 0 {
+			if key != "" {
+				skipped[key] = true
+			}
+
+			continue
+		}
+
+		records = append(records, record)
+	}
+
+	return records, nil
+}
+
+// MarkSent marks a record as sent, and unlocks it.
+func (s *SQLStore) MarkSent(ctx context.Context, id int64) error {
+	_, err := s.db.ExecContext(
+		ctx,
+		s.table.Query("UPDATE %s SET sent_at = ?, last_error = NULL, locked_until = NULL WHERE id = ?"),
+		time.Now().UTC(),
+		id,
+	)
+	if err != nil {
+		return fmt.Errorf("cannot mark outbox record as sent: %w", err)
+	}
+
+	return nil
+}
+
+// MarkFailed increments a record failed attempts, stores the last error, and unlocks it.
+func (s *SQLStore) MarkFailed(ctx context.Context, id int64, failure error) error {
+	_, err := s.db.ExecContext(
+		ctx,
+		s.table.Query("UPDATE %s SET attempts = attempts + 1, last_error = ?, locked_until = NULL WHERE id = ?"),
+		failure.Error(),
+		id,
+	)
+	if err != nil {
+		return fmt.Errorf("cannot mark outbox record as failed: %w", err)
+	}
+
+	return nil
+}
+
+// Release unlocks a record without accounting a failed attempt, to be claimed again on the next relay runs.
+func (s *SQLStore) Release(ctx context.Context, id int64) error {
+	_, err := s.db.ExecContext(ctx, s.table.Query("UPDATE %s SET locked_until = NULL WHERE id = ?"), id)
+	if err != nil {
+		return fmt.Errorf("cannot release outbox record: %w", err)
+	}
+
+	return nil
+}
+
+// orderingKey returns the record ordering key scoped by topic, or an empty string for unordered records.
+func orderingKey(record *Record) string {
+	if record.OrderingKey == "" {
+		return ""
+	}
+
+	return record.TopicID + "/" + record.OrderingKey
+}
@@ -0,0 +1,202 @@
+package outbox_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ankorstore/yokai-contrib/fxgcppubsub/outbox"
+	"github.com/ankorstore/yokai-contrib/fxgcppubsub/testdata/mysql"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewSQLStore(t *testing.T) {
+	t.Parallel()
+
+	t.Run("with defaults", func(t *testing.T) {
+		t.Parallel()
+
+		store, err := outbox.NewSQLStore(nil, "", "")
+		assert.NoError(t, err)
+		assert.IsType(t, &outbox.SQLStore{}, store)
+		assert.Implements(t, (*outbox.Store)(nil), store)
+	})
+
+	t.Run("with postgres dialect", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := outbox.NewSQLStore(nil, "custom_outbox", outbox.DialectPostgres)
+		assert.NoError(t, err)
+	})
+
+	t.Run("with invalid dialect", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := outbox.NewSQLStore(nil, "", "invalid")
+		assert.Error(t, err)
+		assert.Equal(t, `invalid outbox dialect "invalid"`, err.Error())
+	})
+}
+
+func TestSQLStore(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	db := mysql.StartTestServer(t)
+
+	store, err := outbox.NewSQLStore(db, "", outbox.DialectMySQL)
+	assert.NoError(t, err)
+
+	err = store.CreateTable(ctx)
+	assert.NoError(t, err)
+
+	// idempotent
+	err = store.CreateTable(ctx)
+	assert.NoError(t, err)
+
+	// insert
+	for _, topicID := range []string{"topic-1", "topic-2"} {
+		tx, err := db.BeginTx(ctx, nil)
+		assert.NoError(t, err)
+
+		err = store.Insert(ctx, tx, &outbox.Record{
+			TopicID:     topicID,
+			Data:        []byte("test"),
+			Attributes:  map[string]string{"foo": "bar"},
+			OrderingKey: "key",
+		})
+		assert.NoError(t, err)
+
+		assert.NoError(t, tx.Commit())
+	}
+
+	// claim
+	records, err := store.Claim(ctx, 10, 2, time.Minute)
+	assert.NoError(t, err)
+	assert.Len(t, records, 2)
+
+	assert.Equal(t, "topic-1", records[0].TopicID)
+	assert.Equal(t, []byte("test"), records[0].Data)
+	assert.Equal(t, map[string]string{"foo": "bar"}, records[0].Attributes)
+	assert.Equal(t, "key", records[0].OrderingKey)
+	assert.Equal(t, 0, records[0].Attempts)
+	assert.Equal(t, "topic-2", records[1].TopicID)
+
+	// locked
+	locked, err := store.Claim(ctx, 10, 2, time.Minute)
+	assert.NoError(t, err)
+	assert.Len(t, locked, 0)
+
+	// release
+	for _, record := range records {
+		assert.NoError(t, store.Release(ctx, record.ID))
+	}
+
+	records, err = store.Claim(ctx, 1, 2, time.Minute)
+	assert.NoError(t, err)
+	assert.Len(t, records, 1)
+
+	// mark sent
+	err = store.MarkSent(ctx, records[0].ID)
+	assert.NoError(t, err)
+
+	records, err = store.Claim(ctx, 10, 2, time.Minute)
+	assert.NoError(t, err)
+	assert.Len(t, records, 1)
+	assert.Equal(t, "topic-2", records[0].TopicID)
+
+	// mark failed until max attempts
+	err = store.MarkFailed(ctx, records[0].ID, assert.AnError)
+	assert.NoError(t, err)
+
+	records, err = store.Claim(ctx, 10, 2, time.Minute)
+	assert.NoError(t, err)
+	assert.Len(t, records, 1)
+	assert.Equal(t, 1, records[0].Attempts)
+
+	err = store.MarkFailed(ctx, records[0].ID, assert.AnError)
+	assert.NoError(t, err)
+
+	records, err = store.Claim(ctx, 10, 2, time.Minute)
+	assert.NoError(t, err)
+	assert.Len(t, records, 0)
+}
+
+func TestSQLStoreClaim(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	db := mysql.StartTestServer(t)
+
+	store, err := outbox.NewSQLStore(db, "", outbox.DialectMySQL)
+	assert.NoError(t, err)
+
+	err = store.CreateTable(ctx)
+	assert.NoError(t, err)
+
+	insert := func(topicID string, orderingKey string) {
+		tx, err := db.BeginTx(ctx, nil)
+		assert.NoError(t, err)
+
+		err = store.Insert(ctx, tx, &outbox.Record{TopicID: topicID, Data: []byte("test"), OrderingKey: orderingKey})
+		assert.NoError(t, err)
+
+		assert.NoError(t, tx.Commit())
+	}
+
+	claim := func(limit int, lease time.Duration) []int64 {
+		records, err := store.Claim(ctx, limit, 2, lease)
+		assert.NoError(t, err)
+
+		ids := []int64{}
+		for _, record := range records {
+			ids = append(ids, record.ID)
+		}
+
+		return ids
+	}
+
+	insert("topic", "key-1")
+	insert("topic", "key-1")
+	insert("topic", "key-2")
+	insert("other-topic", "key-1")
+	insert("topic", "")
+
+	t.Run("lease expiration", func(t *testing.T) {
+		assert.Equal(t, []int64{1}, claim(1, time.Millisecond))
+
+		time.Sleep(5 * time.Millisecond)
+
+		assert.Equal(t, []int64{1}, claim(1, time.Minute))
+	})
+
+	t.Run("locked ordering key is not claimed", func(t *testing.T) {
+		// record 2 waits for the locked record 1 of its ordering key
+		assert.Equal(t, []int64{3, 4, 5}, claim(10, time.Minute))
+
+		for _, id := range []int64{1, 3, 4, 5} {
+			assert.NoError(t, store.Release(ctx, id))
+		}
+	})
+
+	t.Run("failing ordering key is not claimed", func(t *testing.T) {
+		assert.NoError(t, store.MarkFailed(ctx, 1, assert.AnError))
+
+		// record 2 waits for the failing record 1 of its ordering key
+		assert.Equal(t, []int64{1, 3, 4, 5}, claim(10, time.Minute))
+
+		// even after max attempts
+		assert.NoError(t, store.MarkFailed(ctx, 1, assert.AnError))
+
+		for _, id := range []int64{3, 4, 5} {
+			assert.NoError(t, store.MarkSent(ctx, id))
+		}
+
+		assert.Equal(t, []int64{}, claim(10, time.Minute))
+
+		// until sent
+		assert.NoError(t, store.MarkSent(ctx, 1))
+
+		assert.Equal(t, []int64{2}, claim(10, time.Minute))
+	})
+}
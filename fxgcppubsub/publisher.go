@@ -2,9 +2,12 @@ package fxgcppubsub
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"time"
 
 	"cloud.google.com/go/pubsub"
+	"github.com/ankorstore/yokai-contrib/fxgcppubsub/delay"
 	"github.com/ankorstore/yokai-contrib/fxgcppubsub/topic"
 )
 
@@ -13,26 +16,155 @@ var _ Publisher = (*DefaultPublisher)(nil)
 // Publisher is the interface for high level publishers.
 type Publisher interface {
 	Publish(ctx context.Context, topicID string, data any, options ...topic.PublishOption) (*pubsub.PublishResult, error)
+	PublishBatch(ctx context.Context, topicID string, items []any, options ...topic.PublishOption) (*topic.BatchPublishResult, error)
+	PublishAt(ctx context.Context, topicID string, data any, at time.Time, options ...topic.PublishOption) (string, error)
+	PublishAfter(ctx context.Context, topicID string, data any, after time.Duration, options ...topic.PublishOption) (string, error)
+	PausedOrderingKeys(ctx context.Context, topicID string) ([]topic.PausedOrderingKey, error)
+	ResumePublish(ctx context.Context, topicID string, orderingKey string) error
 	Stop()
 }
 
 // DefaultPublisher is the default Publisher implementation.
 type DefaultPublisher struct {
-	factory  topic.TopicFactory
-	registry topic.TopicRegistry
+	factory     topic.TopicFactory
+	registry    topic.TopicRegistry
+	middlewares []topic.Middleware
+	delayStore  delay.Store
 }
 
 // NewDefaultPublisher returns a new DefaultPublisher instance.
-func NewDefaultPublisher(factory topic.TopicFactory, registry topic.TopicRegistry) *DefaultPublisher {
+func NewDefaultPublisher(factory topic.TopicFactory, registry topic.TopicRegistry, middlewares ...topic.Middleware) *DefaultPublisher {
 	return &DefaultPublisher{
-		factory:  factory,
-		registry: registry,
+		factory:     factory,
+		registry:    registry,
+		middlewares: middlewares,
 	}
 }
 
+// WithDelayStore sets the delay.Store used to save the delayed messages, and returns the publisher.
+func (p *DefaultPublisher) WithDelayStore(store delay.Store) *DefaultPublisher {
+	p.delayStore = store
+
+	return p
+}
+
 // Publish publishes data, with options, on a given topicID.
+//
+// The registered global middlewares are applied before the ones provided with topic.WithMiddlewares.
 func (p *DefaultPublisher) Publish(ctx context.Context, topicID string, data any, options ...topic.PublishOption) (*pubsub.PublishResult, error) {
 	// retrieve topic
+	top, err := p.Topic(ctx, topicID)
+	if err != nil {
+		return nil, err
+	}
+
+	// publish
+	options = append([]topic.PublishOption{topic.WithMiddlewares(p.middlewares...)}, options...)
+
+	return top.Publish(ctx, data, options...)
+}
+
+// PublishBatch publishes a list of items, with options, on a given topicID.
+//
+// All items are encoded up front, and nothing is published on encoding failure.
+// The returned topic.BatchPublishResult allows to wait for all the publications, and to inspect per item server ids and errors.
+func (p *DefaultPublisher) PublishBatch(ctx context.Context, topicID string, items []any, options ...topic.PublishOption) (*topic.BatchPublishResult, error) {
+	// retrieve topic
+	top, err := p.Topic(ctx, topicID)
+	if err != nil {
+		return nil, err
+	}
+
+	// publish
+	options = append([]topic.PublishOption{topic.WithMiddlewares(p.middlewares...)}, options...)
+
+	return top.PublishBatch(ctx, items, options...)
+}
+
+// PublishAt schedules the publication of data, with options, on a given topicID at a given time, and returns the delayed message id.
+//
+// The data is encoded immediately, and saved with the message attributes and ordering key into the delay.Store, to be
+// published by the delay.Scheduler once due. The per publication middlewares are not saved: only the registered global
+// middlewares are applied on the delayed publication.
+func (p *DefaultPublisher) PublishAt(ctx context.Context, topicID string, data any, at time.Time, options ...topic.PublishOption) (string, error) {
+	if p.delayStore == nil {
+		return "", errors.New("cannot schedule publication: no delay store configured")
+	}
+
+	// retrieve topic
+	top, err := p.Topic(ctx, topicID)
+	if err != nil {
+		return "", err
+	}
+
+	// save
+	message, err := delay.NewMessage(ctx, topicID, top.Codec(), data, at, options...)
+	if err != nil {
+		return "", fmt.Errorf("cannot schedule publication: %w", err)
+	}
+
+	err = p.delayStore.Save(ctx, message)
+	if err != nil {
+		return "", fmt.Errorf("cannot schedule publication: %w", err)
+	}
+
+	return message.ID, nil
+}
+
+// PublishAfter schedules the publication of data, with options, on a given topicID after a given duration, and returns the delayed message id.
+func (p *DefaultPublisher) PublishAfter(ctx context.Context, topicID string, data any, after time.Duration, options ...topic.PublishOption) (string, error) {
+	return p.PublishAt(ctx, topicID, data, time.Now().Add(after), options...)
+}
+
+// PublishEncoded publishes already encoded data, with options, on a given topicID.
+//
+// The data must be encoded with the topic codec, for example from a previous topic.Topic Codec() call.
+func (p *DefaultPublisher) PublishEncoded(ctx context.Context, topicID string, data []byte, options ...topic.PublishOption) (*pubsub.PublishResult, error) {
+	// retrieve topic
+	top, err := p.Topic(ctx, topicID)
+	if err != nil {
+		return nil, err
+	}
+
+	// publish
+	options = append([]topic.PublishOption{topic.WithMiddlewares(p.middlewares...)}, options...)
+
+	return top.PublishEncoded(ctx, data, options...)
+}
+
+// PausedOrderingKeys returns the ordering keys of a given topicID paused after an ordered publication failure.
+func (p *DefaultPublisher) PausedOrderingKeys(ctx context.Context, topicID string) ([]topic.PausedOrderingKey, error) {
+	// retrieve topic
+	top, err := p.Topic(ctx, topicID)
+	if err != nil {
+		return nil, err
+	}
+
+	return top.PausedOrderingKeys(), nil
+}
+
+// ResumePublish resumes the publications for a paused ordering key on a given topicID.
+func (p *DefaultPublisher) ResumePublish(ctx context.Context, topicID string, orderingKey string) error {
+	// retrieve topic
+	top, err := p.Topic(ctx, topicID)
+	if err != nil {
+		return err
+	}
+
+	top.ResumePublish(orderingKey)
+
+	return nil
+}
+
+// Stop stops gracefully all internal publishers.
+func (p *DefaultPublisher) Stop() {
+	for _, top := range p.registry.All() {
+		top.Stop()
+	}
+}
+
+// Topic returns the topic.Topic for a given topicID, created and registered if needed.
+func (p *DefaultPublisher) Topic(ctx context.Context, topicID string) (*topic.Topic, error) {
 	if !p.registry.Has(topicID) {
 		top, err := p.factory.Create(ctx, topicID)
 		if err != nil {
@@ -47,13 +179,5 @@ func (p *DefaultPublisher) Publish(ctx context.Context, topicID string, data any
 		return nil, fmt.Errorf("cannot get topic: %w", err)
 	}
 
-	// publish
-	return top.WithOptions(options...).Publish(ctx, data)
-}
-
-// Stop stops gracefully all internal publishers.
-func (p *DefaultPublisher) Stop() {
-	for _, top := range p.registry.All() {
-		top.BaseTopic().Stop()
-	}
+	return top, nil
 }
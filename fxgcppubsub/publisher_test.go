@@ -2,12 +2,24 @@ package fxgcppubsub_test
 
 import (
 	"context"
+	"fmt"
+	"sync"
 	"testing"
+	"time"
 
+	"cloud.google.com/go/pubsub"
 	"github.com/ankorstore/yokai-contrib/fxgcppubsub"
+	"github.com/ankorstore/yokai-contrib/fxgcppubsub/delay"
+	"github.com/ankorstore/yokai-contrib/fxgcppubsub/message"
+	"github.com/ankorstore/yokai-contrib/fxgcppubsub/reactor/ack"
 	"github.com/ankorstore/yokai-contrib/fxgcppubsub/topic"
+	"github.com/ankorstore/yokai-contrib/fxgcppubsub/topic/middleware"
+	"github.com/ankorstore/yokai/fxconfig"
+	"github.com/ankorstore/yokai/fxlog"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"go.uber.org/fx"
+	"go.uber.org/fx/fxtest"
 )
 
 type topicFactoryMock struct {
@@ -104,4 +116,301 @@ func TestPublisher(t *testing.T) {
 		tfm.AssertExpectations(t)
 		trm.AssertExpectations(t)
 	})
+
+	t.Run("batch topic creation error", func(t *testing.T) {
+		t.Parallel()
+
+		tfm := new(topicFactoryMock)
+		tfm.On("Create", ctx, "test-topic").Return(nil, assert.AnError).Once()
+
+		trm := new(topicRegistryMock)
+		trm.On("Has", "test-topic").Return(false).Once()
+
+		publisher := fxgcppubsub.NewDefaultPublisher(tfm, trm)
+
+		res, err := publisher.PublishBatch(ctx, "test-topic", []any{[]byte("test")})
+		assert.Nil(t, res)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "cannot create topic")
+
+		tfm.AssertExpectations(t)
+		trm.AssertExpectations(t)
+	})
+
+	t.Run("delayed publication without delay store", func(t *testing.T) {
+		t.Parallel()
+
+		tfm := new(topicFactoryMock)
+		tfm.AssertNotCalled(t, "Create")
+
+		trm := new(topicRegistryMock)
+		trm.AssertNotCalled(t, "Has")
+
+		publisher := fxgcppubsub.NewDefaultPublisher(tfm, trm)
+
+		id, err := publisher.PublishAfter(ctx, "test-topic", []byte("test"), time.Second)
+		assert.Empty(t, id)
+		assert.Error(t, err)
+		assert.Equal(t, "cannot schedule publication: no delay store configured", err.Error())
+	})
+
+	t.Run("delayed publication topic creation error", func(t *testing.T) {
+		t.Parallel()
+
+		tfm := new(topicFactoryMock)
+		tfm.On("Create", ctx, "test-topic").Return(nil, assert.AnError).Once()
+
+		trm := new(topicRegistryMock)
+		trm.On("Has", "test-topic").Return(false).Once()
+
+		store := delay.NewMemoryStore()
+
+		publisher := fxgcppubsub.NewDefaultPublisher(tfm, trm).WithDelayStore(store)
+
+		id, err := publisher.PublishAt(ctx, "test-topic", []byte("test"), time.Now())
+		assert.Empty(t, id)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "cannot create topic")
+		assert.Empty(t, store.Messages())
+
+		tfm.AssertExpectations(t)
+		trm.AssertExpectations(t)
+	})
+}
+
+func TestPublisherPublishBatch(t *testing.T) {
+	t.Setenv("APP_ENV", "test")
+	t.Setenv("APP_CONFIG_PATH", "testdata/config")
+	t.Setenv("GCP_PROJECT_ID", "test-project")
+
+	var publisher fxgcppubsub.Publisher
+	var subscriber fxgcppubsub.Subscriber
+
+	ctx := context.Background()
+
+	fxtest.New(
+		t,
+		fx.NopLogger,
+		fxconfig.FxConfigModule,
+		fxlog.FxLogModule,
+		fxgcppubsub.FxGcpPubSubModule,
+		fx.Supply(fx.Annotate(ctx, fx.As(new(context.Context)))),
+		fxgcppubsub.AsPublishMiddleware(func() *middleware.AttributesMiddleware {
+			return middleware.NewStaticAttributesMiddleware(map[string]string{"global": "global"})
+		}),
+		fxgcppubsub.PrepareTopicAndSubscription(fxgcppubsub.PrepareTopicAndSubscriptionParams{
+			TopicID:        "test-topic",
+			SubscriptionID: "test-subscription",
+		}),
+		fx.Populate(&publisher, &subscriber),
+	).RequireStart().RequireStop()
+
+	res, err := publisher.PublishBatch(
+		ctx,
+		"test-topic",
+		[]any{[]byte("test 1"), []byte("test 2")},
+		topic.WithMessageAttributes(map[string]string{"foo": "bar"}),
+	)
+	assert.NoError(t, err)
+
+	err = res.Wait(ctx)
+	assert.NoError(t, err)
+	assert.Len(t, res.ServerIDs(), 2)
+
+	var mutex sync.Mutex
+	out := map[string]map[string]string{}
+
+	subCtx, subCancel := context.WithCancel(ctx)
+	defer subCancel()
+
+	//nolint:errcheck
+	go subscriber.Subscribe(subCtx, "test-subscription", func(ctx context.Context, m *message.Message) {
+		mutex.Lock()
+		defer mutex.Unlock()
+
+		out[string(m.Data())] = m.Attributes()
+
+		m.Ack()
+	})
+
+	assert.Eventually(t, func() bool {
+		mutex.Lock()
+		defer mutex.Unlock()
+
+		return len(out) == 2
+	}, 2*time.Second, 10*time.Millisecond)
+
+	assert.Equal(
+		t,
+		map[string]map[string]string{
+			"test 1": {"foo": "bar", "global": "global"},
+			"test 2": {"foo": "bar", "global": "global"},
+		},
+		out,
+	)
+}
+
+func TestPublisherConcurrentPublications(t *testing.T) {
+	t.Setenv("APP_ENV", "test")
+	t.Setenv("APP_CONFIG_PATH", "testdata/config")
+	t.Setenv("GCP_PROJECT_ID", "test-project")
+
+	var publisher fxgcppubsub.Publisher
+	var subscriber fxgcppubsub.Subscriber
+
+	ctx := context.Background()
+
+	fxtest.New(
+		t,
+		fx.NopLogger,
+		fxconfig.FxConfigModule,
+		fxlog.FxLogModule,
+		fxgcppubsub.FxGcpPubSubModule,
+		fx.Supply(fx.Annotate(ctx, fx.As(new(context.Context)))),
+		fxgcppubsub.PrepareTopicAndSubscription(fxgcppubsub.PrepareTopicAndSubscriptionParams{
+			TopicID:        "test-topic",
+			SubscriptionID: "test-subscription",
+		}),
+		fx.Populate(&publisher, &subscriber),
+	).RequireStart().RequireStop()
+
+	const calls = 20
+
+	var wg sync.WaitGroup
+
+	for i := 0; i < calls; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			options := []topic.PublishOption{
+				topic.WithMessageAttributes(map[string]string{"index": fmt.Sprintf("%d", i)}),
+			}
+
+			if i%2 == 0 {
+				options = append(options, topic.WithMessageOrderingKey(fmt.Sprintf("key-%d", i)))
+			}
+
+			res, err := publisher.Publish(ctx, "test-topic", []byte(fmt.Sprintf("%d", i)), options...)
+			assert.NoError(t, err)
+
+			_, err = res.Get(ctx)
+			assert.NoError(t, err)
+		}()
+	}
+
+	wg.Wait()
+
+	var mutex sync.Mutex
+	received := map[string]*message.Message{}
+
+	subCtx, subCancel := context.WithCancel(ctx)
+	defer subCancel()
+
+	//nolint:errcheck
+	go subscriber.Subscribe(subCtx, "test-subscription", func(ctx context.Context, m *message.Message) {
+		mutex.Lock()
+		defer mutex.Unlock()
+
+		received[string(m.Data())] = m
+
+		m.Ack()
+	})
+
+	assert.Eventually(t, func() bool {
+		mutex.Lock()
+		defer mutex.Unlock()
+
+		return len(received) == calls
+	}, 2*time.Second, 10*time.Millisecond)
+
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	// each message only carries its own publication attributes and ordering key
+	for i := 0; i < calls; i++ {
+		m := received[fmt.Sprintf("%d", i)]
+
+		assert.Equal(t, map[string]string{"index": fmt.Sprintf("%d", i)}, m.Attributes())
+
+		if i%2 == 0 {
+			assert.Equal(t, fmt.Sprintf("key-%d", i), m.OrderingKey())
+		} else {
+			assert.Empty(t, m.OrderingKey())
+		}
+	}
+}
+
+func TestPublisherWithMiddlewares(t *testing.T) {
+	t.Setenv("APP_ENV", "test")
+	t.Setenv("APP_CONFIG_PATH", "testdata/config")
+	t.Setenv("GCP_PROJECT_ID", "test-project")
+
+	var publisher fxgcppubsub.Publisher
+	var subscriber fxgcppubsub.Subscriber
+	var supervisor ack.AckSupervisor
+
+	ctx := context.Background()
+
+	fxtest.New(
+		t,
+		fx.NopLogger,
+		fxconfig.FxConfigModule,
+		fxlog.FxLogModule,
+		fxgcppubsub.FxGcpPubSubModule,
+		fx.Supply(fx.Annotate(ctx, fx.As(new(context.Context)))),
+		fxgcppubsub.AsPublishMiddleware(func() *middleware.AttributesMiddleware {
+			return middleware.NewStaticAttributesMiddleware(map[string]string{"global": "global"})
+		}),
+		fxgcppubsub.PrepareTopicAndSubscription(fxgcppubsub.PrepareTopicAndSubscriptionParams{
+			TopicID:        "test-topic",
+			SubscriptionID: "test-subscription",
+		}),
+		fx.Populate(&publisher, &subscriber, &supervisor),
+	).RequireStart().RequireStop()
+
+	localMiddleware := topic.MiddlewareFunc(func(next topic.PublishFunc) topic.PublishFunc {
+		return func(ctx context.Context, msg *pubsub.Message) (*pubsub.PublishResult, error) {
+			assert.Equal(t, "test-topic", topic.CtxTopicID(ctx))
+			assert.Equal(t, "global", msg.Attributes["global"])
+
+			msg.Attributes["local"] = "local"
+
+			return next(ctx, msg)
+		}
+	})
+
+	attributes := map[string]string{"foo": "bar"}
+
+	res, err := publisher.Publish(
+		ctx,
+		"test-topic",
+		[]byte("test"),
+		topic.WithMessageAttributes(attributes),
+		topic.WithMiddlewares(localMiddleware),
+	)
+	assert.NoError(t, err)
+
+	_, err = res.Get(ctx)
+	assert.NoError(t, err)
+
+	// provided attributes are not modified by middlewares
+	assert.Equal(t, map[string]string{"foo": "bar"}, attributes)
+
+	waiter := supervisor.StartAckWaiter("test-subscription")
+
+	var out map[string]string
+
+	//nolint:errcheck
+	go subscriber.Subscribe(ctx, "test-subscription", func(ctx context.Context, m *message.Message) {
+		out = m.Attributes()
+
+		m.Ack()
+	})
+
+	_, err = waiter.WaitMaxDuration(ctx, 2*time.Second)
+	assert.NoError(t, err)
+
+	assert.Equal(t, map[string]string{"foo": "bar", "global": "global", "local": "local"}, out)
 }
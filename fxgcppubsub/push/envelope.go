@@ -0,0 +1,64 @@
+package push
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"cloud.google.com/go/pubsub"
+)
+
+// Envelope represents the body of the requests sent by a pub/sub push subscription.
+type Envelope struct {
+	Message         EnvelopeMessage `json:"message"`
+	Subscription    string          `json:"subscription"`
+	DeliveryAttempt *int            `json:"deliveryAttempt,omitempty"`
+}
+
+// EnvelopeMessage represents the message of a push Envelope.
+type EnvelopeMessage struct {
+	Data        []byte            `json:"data,omitempty"`
+	Attributes  map[string]string `json:"attributes,omitempty"`
+	MessageID   string            `json:"messageId"`
+	PublishTime time.Time         `json:"publishTime"`
+	OrderingKey string            `json:"orderingKey,omitempty"`
+}
+
+// ParseEnvelope parses a push Envelope from a request body.
+func ParseEnvelope(body []byte) (*Envelope, error) {
+	var envelope Envelope
+
+	err := json.Unmarshal(body, &envelope)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse push envelope: %w", err)
+	}
+
+	if envelope.Subscription == "" {
+		return nil, errors.New("cannot parse push envelope: missing subscription")
+	}
+
+	if envelope.Message.MessageID == "" {
+		return nil, errors.New("cannot parse push envelope: missing message id")
+	}
+
+	return &envelope, nil
+}
+
+// SubscriptionID returns the id of the subscription that pushed the envelope.
+func (e *Envelope) SubscriptionID() string {
+	return e.Subscription[strings.LastIndex(e.Subscription, "/")+1:]
+}
+
+// BaseMessage returns the envelope message as a base pubsub.Message.
+func (e *Envelope) BaseMessage() *pubsub.Message {
+	return &pubsub.Message{
+		ID:              e.Message.MessageID,
+		Data:            e.Message.Data,
+		Attributes:      e.Message.Attributes,
+		PublishTime:     e.Message.PublishTime,
+		OrderingKey:     e.Message.OrderingKey,
+		DeliveryAttempt: e.DeliveryAttempt,
+	}
+}
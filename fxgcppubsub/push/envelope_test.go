@@ -0,0 +1,64 @@
+package push_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ankorstore/yokai-contrib/fxgcppubsub/push"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseEnvelope(t *testing.T) {
+	t.Parallel()
+
+	t.Run("parse valid envelope", func(t *testing.T) {
+		t.Parallel()
+
+		envelope, err := push.ParseEnvelope([]byte(`{
+			"message": {
+				"data": "dGVzdA==",
+				"attributes": {"foo": "bar"},
+				"messageId": "test-id",
+				"publishTime": "2024-01-01T10:00:00Z",
+				"orderingKey": "test-key"
+			},
+			"subscription": "projects/test-project/subscriptions/test-subscription",
+			"deliveryAttempt": 2
+		}`))
+		assert.NoError(t, err)
+
+		assert.Equal(t, "test-subscription", envelope.SubscriptionID())
+
+		msg := envelope.BaseMessage()
+		assert.Equal(t, "test-id", msg.ID)
+		assert.Equal(t, []byte("test"), msg.Data)
+		assert.Equal(t, map[string]string{"foo": "bar"}, msg.Attributes)
+		assert.Equal(t, time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC), msg.PublishTime)
+		assert.Equal(t, "test-key", msg.OrderingKey)
+		assert.Equal(t, 2, *msg.DeliveryAttempt)
+	})
+
+	t.Run("parse invalid json", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := push.ParseEnvelope([]byte(`invalid`))
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "cannot parse push envelope")
+	})
+
+	t.Run("parse envelope without subscription", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := push.ParseEnvelope([]byte(`{"message": {"messageId": "test-id"}}`))
+		assert.Error(t, err)
+		assert.Equal(t, "cannot parse push envelope: missing subscription", err.Error())
+	})
+
+	t.Run("parse envelope without message id", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := push.ParseEnvelope([]byte(`{"message": {}, "subscription": "projects/test-project/subscriptions/test-subscription"}`))
+		assert.Error(t, err)
+		assert.Equal(t, "cannot parse push envelope: missing message id", err.Error())
+	})
+}
@@ -0,0 +1,216 @@
+package push
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ankorstore/yokai-contrib/fxgcppubsub/message"
+	"github.com/ankorstore/yokai-contrib/fxgcppubsub/subscription"
+	"github.com/ankorstore/yokai/log"
+	"github.com/labstack/echo/v4"
+)
+
+var (
+	_ http.Handler       = (*PushHandler)(nil)
+	_ message.AckHandler = (*pushAckHandler)(nil)
+)
+
+// DefaultMaxBodyBytes is the default maximum size of the push requests body: the pub/sub messages are limited to 10MB,
+// and base64 encoded in the push envelope.
+const DefaultMaxBodyBytes int64 = 16 << 20
+
+// SubscriptionResolver is the interface for the components resolving subscriptions, like the fxgcppubsub.DefaultSubscriber.
+type SubscriptionResolver interface {
+	Subscription(ctx context.Context, subscriptionID string) (*subscription.Subscription, error)
+}
+
+// Options represents the PushHandler options.
+type Options struct {
+	Verifier     Verifier
+	Middlewares  []subscription.Middleware
+	MaxBodyBytes int64
+}
+
+// PushOption represents the PushHandler functional options.
+type PushOption func(o *Options)
+
+// WithVerifier sets the Verifier used to authenticate the push requests.
+func WithVerifier(verifier Verifier) PushOption {
+	return func(o *Options) {
+		o.Verifier = verifier
+	}
+}
+
+// WithMaxBodyBytes sets the maximum size of the push requests body.
+func WithMaxBodyBytes(n int64) PushOption {
+	return func(o *Options) {
+		o.MaxBodyBytes = n
+	}
+}
+
+// WithMiddlewares appends subscription.Middleware to the ones wrapping the handler subscription.SubscribeFunc.
+func WithMiddlewares(middlewares ...subscription.Middleware) PushOption {
+	return func(o *Options) {
+		o.Middlewares = append(o.Middlewares, middlewares...)
+	}
+}
+
+// PushHandler is an HTTP handler for the requests sent by pub/sub push subscriptions, to be registered on
+// fxhttpserver (with Handle) or on any net/http server.
+//
+// The handler is bound to a subscription: the pushed messages are decoded with its codec and handled by a
+// subscription.SubscribeFunc, like for pull subscriptions, and the envelopes of other subscriptions (or projects) are
+// rejected with a 403. Acked messages are answered with a 204, nacked or not acknowledged ones with a 500, to be redelivered.
+type PushHandler struct {
+	resolver       SubscriptionResolver
+	subscriptionID string
+	handler        subscription.SubscribeFunc
+	options        Options
+}
+
+// NewPushHandler returns a new PushHandler instance, for a provided subscriptionID.
+func NewPushHandler(resolver SubscriptionResolver, subscriptionID string, f subscription.SubscribeFunc, options ...PushOption) *PushHandler {
+	pushOptions := Options{
+		MaxBodyBytes: DefaultMaxBodyBytes,
+	}
+	for _, applyOpt := range options {
+		applyOpt(&pushOptions)
+	}
+
+	return &PushHandler{
+		resolver:       resolver,
+		subscriptionID: subscriptionID,
+		handler:        subscription.Chain(f, pushOptions.Middlewares...),
+		options:        pushOptions,
+	}
+}
+
+// SubscriptionID returns the id of the subscription the handler is bound to.
+func (h *PushHandler) SubscriptionID() string {
+	return h.subscriptionID
+}
+
+// Options returns the handler options.
+func (h *PushHandler) Options() Options {
+	return h.options
+}
+
+// Handle returns the echo.HandlerFunc, to register the handler on fxhttpserver.
+func (h *PushHandler) Handle() echo.HandlerFunc {
+	return echo.WrapHandler(h)
+}
+
+// ServeHTTP handles a push request.
+func (h *PushHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := log.CtxLogger(ctx)
+
+	if h.options.Verifier != nil {
+		token, found := strings.CutPrefix(r.Header.Get(echo.HeaderAuthorization), "Bearer ")
+		if !found || token == "" {
+			logger.Warn().Msg("pubsub push request without token")
+
+			w.WriteHeader(http.StatusUnauthorized)
+
+			return
+		}
+
+		err := h.options.Verifier.Verify(ctx, token)
+		if err != nil {
+			logger.Warn().Err(err).Msg("pubsub push request token verification error")
+
+			w.WriteHeader(http.StatusUnauthorized)
+
+			return
+		}
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, h.options.MaxBodyBytes))
+	if err != nil {
+		logger.Error().Err(err).Msg("pubsub push request read error")
+
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			w.WriteHeader(http.StatusRequestEntityTooLarge)
+		} else {
+			w.WriteHeader(http.StatusBadRequest)
+		}
+
+		return
+	}
+
+	envelope, err := ParseEnvelope(body)
+	if err != nil {
+		logger.Error().Err(err).Msg("pubsub push request parsing error")
+
+		w.WriteHeader(http.StatusBadRequest)
+
+		return
+	}
+
+	sub, err := h.resolver.Subscription(ctx, h.subscriptionID)
+	if err != nil {
+		logger.Error().Err(err).Str("subscriptionID", h.subscriptionID).Msg("pubsub push subscription error")
+
+		w.WriteHeader(http.StatusInternalServerError)
+
+		return
+	}
+
+	if envelope.Subscription != sub.BaseSubscription().String() {
+		logger.Warn().
+			Str("subscriptionID", h.subscriptionID).
+			Str("subscription", envelope.Subscription).
+			Msg("pubsub push request from unexpected subscription")
+
+		w.WriteHeader(http.StatusForbidden)
+
+		return
+	}
+
+	ackh := &pushAckHandler{}
+
+	h.handler(
+		subscription.ContextWithSubscriptionID(ctx, h.subscriptionID),
+		message.NewMessageWithAckHandler(sub.Codec(), envelope.BaseMessage(), ackh),
+	)
+
+	if ackh.acked() {
+		w.WriteHeader(http.StatusNoContent)
+
+		return
+	}
+
+	w.WriteHeader(http.StatusInternalServerError)
+}
+
+// pushAckHandler records the acknowledgement of a pushed message, answered once handled.
+type pushAckHandler struct {
+	ack   bool
+	mutex sync.Mutex
+}
+
+func (h *pushAckHandler) OnAck() {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	h.ack = true
+}
+
+func (h *pushAckHandler) OnNack() {}
+
+func (h *pushAckHandler) OnModifyAckDeadline(context.Context, time.Duration) error {
+	return errors.New("cannot modify the ack deadline of a pushed message")
+}
+
+func (h *pushAckHandler) acked() bool {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	return h.ack
+}
@@ -0,0 +1,252 @@
+package push_test
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ankorstore/yokai-contrib/fxgcppubsub"
+	"github.com/ankorstore/yokai-contrib/fxgcppubsub/message"
+	"github.com/ankorstore/yokai-contrib/fxgcppubsub/push"
+	"github.com/ankorstore/yokai-contrib/fxgcppubsub/subscription"
+	"github.com/ankorstore/yokai/fxconfig"
+	"github.com/ankorstore/yokai/fxlog"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/fx"
+	"go.uber.org/fx/fxtest"
+)
+
+type testVerifier struct{}
+
+func (v *testVerifier) Verify(ctx context.Context, token string) error {
+	if token != "valid" {
+		return fmt.Errorf("invalid token")
+	}
+
+	return nil
+}
+
+type testResolver struct {
+	factory subscription.SubscriptionFactory
+}
+
+func (r *testResolver) Subscription(ctx context.Context, subscriptionID string) (*subscription.Subscription, error) {
+	return r.factory.Create(ctx, subscriptionID)
+}
+
+type testEvent struct {
+	Name string `json:"name"`
+}
+
+func envelope(subscriptionID string, data string, attributes string) string {
+	return fmt.Sprintf(
+		`{"message":{"data":%q,"attributes":%s,"messageId":"test-id","publishTime":"2024-01-01T10:00:00Z"},"subscription":"projects/test-project/subscriptions/%s"}`,
+		base64.StdEncoding.EncodeToString([]byte(data)),
+		attributes,
+		subscriptionID,
+	)
+}
+
+func TestPushHandler(t *testing.T) {
+	t.Setenv("APP_ENV", "test")
+	t.Setenv("APP_CONFIG_PATH", "../testdata/config")
+	t.Setenv("GCP_PROJECT_ID", "test-project")
+
+	var factory subscription.SubscriptionFactory
+
+	ctx := context.Background()
+
+	fxtest.New(
+		t,
+		fx.NopLogger,
+		fxconfig.FxConfigModule,
+		fxlog.FxLogModule,
+		fxgcppubsub.FxGcpPubSubModule,
+		fx.Supply(fx.Annotate(ctx, fx.As(new(context.Context)))),
+		fxgcppubsub.PrepareTopicAndSubscription(fxgcppubsub.PrepareTopicAndSubscriptionParams{
+			TopicID:        "push-topic",
+			SubscriptionID: "push-subscription",
+		}),
+		fxgcppubsub.PrepareTopicAndSubscription(fxgcppubsub.PrepareTopicAndSubscriptionParams{
+			TopicID:        "codec-topic",
+			SubscriptionID: "codec-subscription",
+		}),
+		fx.Populate(&factory),
+	).RequireStart().RequireStop()
+
+	resolver := &testResolver{factory: factory}
+
+	serve := func(handler *push.PushHandler, body string, token string) int {
+		e := echo.New()
+		e.POST("/push", handler.Handle())
+
+		req := httptest.NewRequest(http.MethodPost, "/push", strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		if token != "" {
+			req.Header.Set(echo.HeaderAuthorization, "Bearer "+token)
+		}
+
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+
+		return rec.Code
+	}
+
+	t.Run("ack pushed message", func(t *testing.T) {
+		var received *message.Message
+		var subscriptionID string
+
+		handler := push.NewPushHandler(resolver, "push-subscription", func(ctx context.Context, m *message.Message) {
+			received = m
+			subscriptionID = subscription.CtxSubscriptionID(ctx)
+
+			m.Ack()
+		})
+
+		code := serve(handler, envelope("push-subscription", "test", `{"foo":"bar"}`), "")
+		assert.Equal(t, http.StatusNoContent, code)
+
+		assert.Equal(t, "push-subscription", subscriptionID)
+		assert.Equal(t, "test-id", received.ID())
+		assert.Equal(t, []byte("test"), received.Data())
+		assert.Equal(t, map[string]string{"foo": "bar"}, received.Attributes())
+	})
+
+	t.Run("nack pushed message", func(t *testing.T) {
+		handler := push.NewPushHandler(resolver, "push-subscription", func(ctx context.Context, m *message.Message) {
+			m.Nack()
+			m.Ack()
+		})
+
+		code := serve(handler, envelope("push-subscription", "test", `{}`), "")
+		assert.Equal(t, http.StatusInternalServerError, code)
+	})
+
+	t.Run("pushed message without acknowledgement", func(t *testing.T) {
+		handler := push.NewPushHandler(resolver, "push-subscription", func(ctx context.Context, m *message.Message) {
+			err := m.ModifyAckDeadline(ctx, 0)
+			assert.Error(t, err)
+			assert.Equal(t, "cannot modify the ack deadline of a pushed message", err.Error())
+		})
+
+		code := serve(handler, envelope("push-subscription", "test", `{}`), "")
+		assert.Equal(t, http.StatusInternalServerError, code)
+	})
+
+	t.Run("decode pushed message with subscription codec", func(t *testing.T) {
+		var event testEvent
+
+		handler := push.NewPushHandler(resolver, "codec-subscription", func(ctx context.Context, m *message.Message) {
+			err := m.Decode(&event)
+			assert.NoError(t, err)
+
+			m.Ack()
+		})
+
+		attributes := `{"ce-id":"test-id","ce-source":"test","ce-specversion":"1.0","ce-type":"test.event","content-type":"application/json"}`
+
+		code := serve(handler, envelope("codec-subscription", `{"name":"test"}`, attributes), "")
+		assert.Equal(t, http.StatusNoContent, code)
+		assert.Equal(t, testEvent{Name: "test"}, event)
+	})
+
+	t.Run("pushed message with middlewares", func(t *testing.T) {
+		var calls []string
+
+		handler := push.NewPushHandler(
+			resolver,
+			"push-subscription",
+			func(ctx context.Context, m *message.Message) {
+				calls = append(calls, "handler")
+
+				m.Ack()
+			},
+			push.WithMiddlewares(subscription.MiddlewareFunc(func(next subscription.SubscribeFunc) subscription.SubscribeFunc {
+				return func(ctx context.Context, m *message.Message) {
+					calls = append(calls, "middleware")
+
+					next(ctx, m)
+				}
+			})),
+		)
+
+		code := serve(handler, envelope("push-subscription", "test", `{}`), "")
+		assert.Equal(t, http.StatusNoContent, code)
+		assert.Equal(t, []string{"middleware", "handler"}, calls)
+	})
+
+	t.Run("push with invalid envelope", func(t *testing.T) {
+		handler := push.NewPushHandler(resolver, "push-subscription", func(ctx context.Context, m *message.Message) {
+			t.Error("should not be called")
+		})
+
+		code := serve(handler, "invalid", "")
+		assert.Equal(t, http.StatusBadRequest, code)
+	})
+
+	t.Run("push with invalid subscription", func(t *testing.T) {
+		handler := push.NewPushHandler(resolver, "invalid-subscription", func(ctx context.Context, m *message.Message) {
+			t.Error("should not be called")
+		})
+
+		code := serve(handler, envelope("invalid-subscription", "test", `{}`), "")
+		assert.Equal(t, http.StatusInternalServerError, code)
+	})
+
+	t.Run("push from another subscription", func(t *testing.T) {
+		handler := push.NewPushHandler(resolver, "push-subscription", func(ctx context.Context, m *message.Message) {
+			t.Error("should not be called")
+		})
+
+		code := serve(handler, envelope("codec-subscription", "test", `{}`), "")
+		assert.Equal(t, http.StatusForbidden, code)
+	})
+
+	t.Run("push from another project", func(t *testing.T) {
+		handler := push.NewPushHandler(resolver, "push-subscription", func(ctx context.Context, m *message.Message) {
+			t.Error("should not be called")
+		})
+
+		body := strings.Replace(envelope("push-subscription", "test", `{}`), "projects/test-project/", "projects/other-project/", 1)
+
+		code := serve(handler, body, "")
+		assert.Equal(t, http.StatusForbidden, code)
+	})
+
+	t.Run("push with too large body", func(t *testing.T) {
+		handler := push.NewPushHandler(
+			resolver,
+			"push-subscription",
+			func(ctx context.Context, m *message.Message) {
+				t.Error("should not be called")
+			},
+			push.WithMaxBodyBytes(10),
+		)
+
+		assert.Equal(t, push.DefaultMaxBodyBytes, push.NewPushHandler(resolver, "push-subscription", nil).Options().MaxBodyBytes)
+		assert.Equal(t, int64(10), handler.Options().MaxBodyBytes)
+
+		code := serve(handler, envelope("push-subscription", "test", `{}`), "")
+		assert.Equal(t, http.StatusRequestEntityTooLarge, code)
+	})
+
+	t.Run("push with token verification", func(t *testing.T) {
+		handler := push.NewPushHandler(
+			resolver,
+			"push-subscription",
+			func(ctx context.Context, m *message.Message) {
+				m.Ack()
+			},
+			push.WithVerifier(&testVerifier{}),
+		)
+
+		assert.Equal(t, http.StatusUnauthorized, serve(handler, envelope("push-subscription", "test", `{}`), ""))
+		assert.Equal(t, http.StatusUnauthorized, serve(handler, envelope("push-subscription", "test", `{}`), "invalid"))
+		assert.Equal(t, http.StatusNoContent, serve(handler, envelope("push-subscription", "test", `{}`), "valid"))
+	})
+}
@@ -0,0 +1,56 @@
+package push
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"google.golang.org/api/idtoken"
+)
+
+var _ Verifier = (*IDTokenVerifier)(nil)
+
+// Verifier is the interface for the push requests authentication token verifiers.
+type Verifier interface {
+	Verify(ctx context.Context, token string) error
+}
+
+// IDTokenVerifier is a Verifier for the OIDC tokens signed by Google, sent by the authenticated push subscriptions.
+type IDTokenVerifier struct {
+	audience string
+	email    string
+}
+
+// NewIDTokenVerifier returns a new IDTokenVerifier instance, expecting the provided audience and, if not empty,
+// the provided service account email.
+func NewIDTokenVerifier(audience string, email string) *IDTokenVerifier {
+	return &IDTokenVerifier{
+		audience: audience,
+		email:    email,
+	}
+}
+
+// Verify verifies the provided token.
+func (v *IDTokenVerifier) Verify(ctx context.Context, token string) error {
+	// an empty audience would not be checked
+	if v.audience == "" {
+		return errors.New("cannot validate push token: missing audience")
+	}
+
+	payload, err := idtoken.Validate(ctx, token, v.audience)
+	if err != nil {
+		return fmt.Errorf("cannot validate push token: %w", err)
+	}
+
+	if v.email != "" {
+		if email, ok := payload.Claims["email"].(string); !ok || email != v.email {
+			return fmt.Errorf("invalid push token email %q", payload.Claims["email"])
+		}
+
+		if verified, ok := payload.Claims["email_verified"].(bool); !ok || !verified {
+			return fmt.Errorf("unverified push token email %q", v.email)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,33 @@
+package push_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ankorstore/yokai-contrib/fxgcppubsub/push"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIDTokenVerifier(t *testing.T) {
+	t.Parallel()
+
+	t.Run("invalid token", func(t *testing.T) {
+		t.Parallel()
+
+		verifier := push.NewIDTokenVerifier("https://example.com/push", "push@test-project.iam.gserviceaccount.com")
+
+		err := verifier.Verify(context.Background(), "invalid")
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "cannot validate push token")
+	})
+
+	t.Run("missing audience", func(t *testing.T) {
+		t.Parallel()
+
+		verifier := push.NewIDTokenVerifier("", "")
+
+		err := verifier.Verify(context.Background(), "invalid")
+		assert.Error(t, err)
+		assert.Equal(t, "cannot validate push token: missing audience", err.Error())
+	})
+}
@@ -19,18 +19,25 @@ func NewAckReactor(supervisor AckSupervisor) *AckReactor {
 // FuncNames returns the list of function names this reactor will react to.
 func (r *AckReactor) FuncNames() []string {
 	return []string{
+		"Publish",
 		"Acknowledge",
 		"ModifyAckDeadline",
 	}
 }
 
 // React is the reactor logic.
+//
+// Publications are recorded to match the waiters on message attributes, and only the ack deadline modifications to 0 are considered as nacks.
 func (r *AckReactor) React(req any) (bool, any, error) {
+	if publishReq, ok := req.(*pubsubpb.PublishRequest); ok {
+		r.supervisor.RecordPublished(publishReq.Messages...)
+	}
+
 	if ackReq, ok := req.(*pubsubpb.AcknowledgeRequest); ok {
 		r.supervisor.StopAckWaiter(ackReq.Subscription, ackReq.AckIds, nil)
 	}
 
-	if modReq, ok := req.(*pubsubpb.ModifyAckDeadlineRequest); ok {
+	if modReq, ok := req.(*pubsubpb.ModifyAckDeadlineRequest); ok && modReq.AckDeadlineSeconds == 0 {
 		r.supervisor.StopNackWaiter(modReq.Subscription, modReq.AckIds, nil)
 	}
 
@@ -7,8 +7,11 @@ import (
 
 	"cloud.google.com/go/pubsub/apiv1/pubsubpb"
 	"github.com/ankorstore/yokai-contrib/fxgcppubsub"
+	"github.com/ankorstore/yokai-contrib/fxgcppubsub/message"
+	"github.com/ankorstore/yokai-contrib/fxgcppubsub/reactor"
 	"github.com/ankorstore/yokai-contrib/fxgcppubsub/reactor/ack"
 	"github.com/ankorstore/yokai-contrib/fxgcppubsub/subscription"
+	"github.com/ankorstore/yokai-contrib/fxgcppubsub/topic"
 	"github.com/ankorstore/yokai/fxconfig"
 	"github.com/ankorstore/yokai/fxlog"
 	"github.com/stretchr/testify/assert"
@@ -41,6 +44,7 @@ func TestAckReactor(t *testing.T) {
 		assert.Equal(
 			t,
 			[]string{
+				"Publish",
 				"Acknowledge",
 				"ModifyAckDeadline",
 			},
@@ -89,4 +93,119 @@ func TestAckReactor(t *testing.T) {
 		assert.NoError(t, err)
 		assert.Equal(t, []string{"test-id"}, data)
 	})
+	t.Run("react to ack deadline extension", func(t *testing.T) {
+		req := &pubsubpb.ModifyAckDeadlineRequest{
+			Subscription:       subscription.NormalizeSubscriptionName("test-project", "test-subscription"),
+			AckIds:             []string{"test-id"},
+			AckDeadlineSeconds: 10,
+		}
+
+		waiter := sup.StartNackWaiter("test-subscription")
+
+		rHandled, rRet, rErr := react.React(req)
+		assert.False(t, rHandled)
+		assert.Nil(t, rRet)
+		assert.NoError(t, rErr)
+
+		_, err := waiter.WaitMaxDuration(context.Background(), 5*time.Millisecond)
+		assert.Error(t, err)
+		assert.Equal(t, "context deadline exceeded", err.Error())
+	})
+
+	t.Run("react to publish", func(t *testing.T) {
+		pubReq := &pubsubpb.PublishRequest{
+			Topic: "projects/test-project/topics/test-topic",
+			Messages: []*pubsubpb.PubsubMessage{
+				{
+					Data:       []byte("test"),
+					Attributes: map[string]string{"foo": "bar"},
+				},
+			},
+		}
+
+		rHandled, rRet, rErr := react.React(pubReq)
+		assert.False(t, rHandled)
+		assert.Nil(t, rRet)
+		assert.NoError(t, rErr)
+
+		// id assigned by the server
+		pubReq.Messages[0].MessageId = "published-id"
+
+		waiter := sup.StartAckWaiter("test-subscription", ack.WithAttributes(map[string]string{"foo": "bar"}))
+
+		//nolint:errcheck
+		react.React(&pubsubpb.AcknowledgeRequest{
+			Subscription: subscription.NormalizeSubscriptionName("test-project", "test-subscription"),
+			AckIds:       []string{"other-id", "published-id"},
+		})
+
+		data, err := waiter.WaitMaxDuration(context.Background(), 5*time.Millisecond)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"published-id"}, data)
+	})
+}
+
+func TestAckReactorWithTestServer(t *testing.T) {
+	t.Setenv("APP_ENV", "test")
+	t.Setenv("APP_CONFIG_PATH", "../../testdata/config")
+	t.Setenv("GCP_PROJECT_ID", "test-project")
+
+	var publisher fxgcppubsub.Publisher
+	var subscriber fxgcppubsub.Subscriber
+	var supervisor ack.AckSupervisor
+
+	ctx := context.Background()
+
+	fxtest.New(
+		t,
+		fx.NopLogger,
+		fxconfig.FxConfigModule,
+		fxlog.FxLogModule,
+		fxgcppubsub.FxGcpPubSubModule,
+		fx.Supply(fx.Annotate(ctx, fx.As(new(context.Context)))),
+		fxgcppubsub.PrepareTopicAndSubscription(fxgcppubsub.PrepareTopicAndSubscriptionParams{
+			TopicID:        "test-topic",
+			SubscriptionID: "test-subscription",
+		}),
+		fx.Populate(&publisher, &subscriber, &supervisor),
+	).RequireStart().RequireStop()
+
+	publish := func(data string, attributes map[string]string) string {
+		res, err := publisher.Publish(ctx, "test-topic", []byte(data), topic.WithMessageAttributes(attributes))
+		assert.NoError(t, err)
+
+		id, err := res.Get(ctx)
+		assert.NoError(t, err)
+
+		return id
+	}
+
+	firstID := publish("first", map[string]string{"name": "first"})
+	secondID := publish("second", map[string]string{"name": "second"})
+	thirdID := publish("third", map[string]string{"name": "third"})
+
+	// several waiters on the same subscription
+	secondWaiter := supervisor.StartAckWaiter("test-subscription", ack.WithMessageIDs(secondID))
+	thirdWaiter := supervisor.StartAckWaiter("test-subscription", ack.WithAttributes(map[string]string{"name": "third"}))
+	allWaiter := supervisor.StartAckWaiter("test-subscription", reactor.WithCount(3))
+
+	subCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	//nolint:errcheck
+	go subscriber.Subscribe(subCtx, "test-subscription", func(ctx context.Context, m *message.Message) {
+		m.Ack()
+	})
+
+	data, err := secondWaiter.WaitMaxDuration(ctx, 5*time.Second)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{secondID}, data)
+
+	data, err = thirdWaiter.WaitMaxDuration(ctx, 5*time.Second)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{thirdID}, data)
+
+	data, err = allWaiter.WaitMaxDuration(ctx, 5*time.Second)
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []string{firstID, secondID, thirdID}, data)
 }
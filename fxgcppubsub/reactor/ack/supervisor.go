@@ -2,7 +2,9 @@ package ack
 
 import (
 	"fmt"
+	"sync"
 
+	"cloud.google.com/go/pubsub/apiv1/pubsubpb"
 	"github.com/ankorstore/yokai-contrib/fxgcppubsub/reactor"
 	"github.com/ankorstore/yokai-contrib/fxgcppubsub/subscription"
 	"github.com/ankorstore/yokai/config"
@@ -13,20 +15,73 @@ const (
 	Nack = "nack"
 )
 
+// DefaultMaxRecordedMessages is the default maximum number of published messages recorded to match the waiters on their attributes.
+const DefaultMaxRecordedMessages = 10000
+
 var _ AckSupervisor = (*DefaultAckSupervisor)(nil)
 
 // AckSupervisor is a reactor supervisor that reacts to acks ans nacks.
 type AckSupervisor interface {
-	StartAckWaiter(subscriptionID string) *reactor.Waiter
+	StartAckWaiter(subscriptionID string, options ...reactor.WaiterOption) *reactor.Waiter
 	StopAckWaiter(subscriptionName string, ackIDs []string, err error)
-	StartNackWaiter(subscriptionID string) *reactor.Waiter
+	StartNackWaiter(subscriptionID string, options ...reactor.WaiterOption) *reactor.Waiter
 	StopNackWaiter(subscriptionName string, ackIDs []string, err error)
+	RecordPublished(messages ...*pubsubpb.PubsubMessage)
+}
+
+// AckEvent represents the ack or nack of a message, the waiters are notified with.
+//
+// With the pub/sub test server, the message ack id is the message id.
+type AckEvent struct {
+	AckID      string
+	Attributes map[string]string
+}
+
+// WithMessageIDs restricts a waiter to the acks or nacks of the provided message ids.
+func WithMessageIDs(ids ...string) reactor.WaiterOption {
+	return reactor.WithMatcher(func(data any) bool {
+		event, ok := data.(*AckEvent)
+		if !ok {
+			return false
+		}
+
+		for _, id := range ids {
+			if event.AckID == id {
+				return true
+			}
+		}
+
+		return false
+	})
+}
+
+// WithAttributes restricts a waiter to the acks or nacks of the messages published with the provided attributes.
+func WithAttributes(attributes map[string]string) reactor.WaiterOption {
+	return reactor.WithMatcher(func(data any) bool {
+		event, ok := data.(*AckEvent)
+		if !ok {
+			return false
+		}
+
+		for k, v := range attributes {
+			if value, found := event.Attributes[k]; !found || value != v {
+				return false
+			}
+		}
+
+		return true
+	})
 }
 
 // DefaultAckSupervisor is the default AckSupervisor implementation.
 type DefaultAckSupervisor struct {
 	supervisor reactor.WaiterSupervisor
 	config     *config.Config
+	pending    []*pubsubpb.PubsubMessage
+	attributes map[string]map[string]string
+	recorded   []string
+	max        int
+	mutex      sync.Mutex
 }
 
 // NewDefaultAckSupervisor returns a new DefaultAckSupervisor instance.
@@ -34,38 +89,137 @@ func NewDefaultAckSupervisor(supervisor reactor.WaiterSupervisor, config *config
 	return &DefaultAckSupervisor{
 		supervisor: supervisor,
 		config:     config,
+		attributes: make(map[string]map[string]string),
+		max:        DefaultMaxRecordedMessages,
 	}
 }
 
-// StartAckWaiter starts an ack waiter on a provided subscriptionID.
-func (s *DefaultAckSupervisor) StartAckWaiter(subscriptionID string) *reactor.Waiter {
-	return s.startWaiter(subscriptionID, Ack)
+// WithMaxRecordedMessages sets the maximum number of recorded published messages, the oldest ones being forgotten
+// first, and returns the supervisor.
+func (s *DefaultAckSupervisor) WithMaxRecordedMessages(max int) *DefaultAckSupervisor {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.max = max
+
+	return s
+}
+
+// Reset forgets all the recorded published messages.
+func (s *DefaultAckSupervisor) Reset() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.pending = nil
+	s.attributes = make(map[string]map[string]string)
+	s.recorded = nil
 }
 
-// StopAckWaiter stop an ack waiter for a provided subscriptionName.
+// StartAckWaiter starts an ack waiter on a provided subscriptionID, with options.
+//
+// By default, the waiter is stopped by the first ack on the subscription, and returns the acked ids.
+func (s *DefaultAckSupervisor) StartAckWaiter(subscriptionID string, options ...reactor.WaiterOption) *reactor.Waiter {
+	return s.startWaiter(subscriptionID, Ack, options...)
+}
+
+// StopAckWaiter notifies the ack waiters for a provided subscriptionName, once per ack id.
 func (s *DefaultAckSupervisor) StopAckWaiter(subscriptionName string, ackIDs []string, err error) {
 	s.stopWaiter(subscriptionName, Ack, ackIDs, err)
 }
 
-// StartNackWaiter starts a nack waiter on a provided subscriptionID.
-func (s *DefaultAckSupervisor) StartNackWaiter(subscriptionID string) *reactor.Waiter {
-	return s.startWaiter(subscriptionID, Nack)
+// StartNackWaiter starts a nack waiter on a provided subscriptionID, with options.
+//
+// By default, the waiter is stopped by the first nack on the subscription, and returns the nacked ids.
+func (s *DefaultAckSupervisor) StartNackWaiter(subscriptionID string, options ...reactor.WaiterOption) *reactor.Waiter {
+	return s.startWaiter(subscriptionID, Nack, options...)
 }
 
-// StopNackWaiter stop a nack waiter for a provided subscriptionName.
+// StopNackWaiter notifies the nack waiters for a provided subscriptionName, once per ack id.
 func (s *DefaultAckSupervisor) StopNackWaiter(subscriptionName string, ackIDs []string, err error) {
 	s.stopWaiter(subscriptionName, Nack, ackIDs, err)
 }
 
-func (s *DefaultAckSupervisor) startWaiter(subscriptionID string, kind string) *reactor.Waiter {
+// RecordPublished records published messages, to match the waiters on their attributes.
+//
+// The messages ids are resolved lazily, since they are assigned by the test server after the publication request is recorded.
+func (s *DefaultAckSupervisor) RecordPublished(messages ...*pubsubpb.PubsubMessage) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.pending = append(s.pending, messages...)
+
+	// messages refused by the test server never get an id
+	if s.max > 0 && len(s.pending) > s.max {
+		s.pending = s.pending[len(s.pending)-s.max:]
+	}
+}
+
+func (s *DefaultAckSupervisor) startWaiter(subscriptionID string, kind string, options ...reactor.WaiterOption) *reactor.Waiter {
 	subscriptionName := subscription.NormalizeSubscriptionName(
 		s.config.GetString("modules.gcppubsub.project.id"),
 		subscriptionID,
 	)
 
-	return s.supervisor.StartWaiter(fmt.Sprintf("%s::%s", kind, subscriptionName))
+	return s.supervisor.StartWaiter(
+		fmt.Sprintf("%s::%s", kind, subscriptionName),
+		append(options, reactor.WithAggregator(ackIDs))...,
+	)
 }
 
 func (s *DefaultAckSupervisor) stopWaiter(subscriptionName string, kind string, ackIDs []string, err error) {
-	s.supervisor.StopWaiter(fmt.Sprintf("%s::%s", kind, subscriptionName), ackIDs, err)
+	for _, ackID := range ackIDs {
+		s.supervisor.StopWaiter(
+			fmt.Sprintf("%s::%s", kind, subscriptionName),
+			&AckEvent{
+				AckID:      ackID,
+				Attributes: s.messageAttributes(ackID),
+			},
+			err,
+		)
+	}
+}
+
+func (s *DefaultAckSupervisor) messageAttributes(messageID string) map[string]string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	var pending []*pubsubpb.PubsubMessage
+
+	for _, m := range s.pending {
+		if m.MessageId == "" {
+			pending = append(pending, m)
+		} else {
+			s.record(m.MessageId, m.Attributes)
+		}
+	}
+
+	s.pending = pending
+
+	return s.attributes[messageID]
+}
+
+func (s *DefaultAckSupervisor) record(messageID string, attributes map[string]string) {
+	if _, ok := s.attributes[messageID]; !ok {
+		s.recorded = append(s.recorded, messageID)
+	}
+
+	s.attributes[messageID] = attributes
+
+	for s.max > 0 && len(s.recorded) > s.max {
+		delete(s.attributes, s.recorded[0])
+
+		s.recorded = s.recorded[1:]
+	}
+}
+
+func ackIDs(data []any) any {
+	ids := make([]string, 0, len(data))
+
+	for _, d := range data {
+		if event, ok := d.(*AckEvent); ok {
+			ids = append(ids, event.AckID)
+		}
+	}
+
+	return ids
 }
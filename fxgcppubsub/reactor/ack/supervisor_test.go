@@ -5,9 +5,12 @@ import (
 	"testing"
 	"time"
 
+	"cloud.google.com/go/pubsub/apiv1/pubsubpb"
 	"github.com/ankorstore/yokai-contrib/fxgcppubsub"
+	"github.com/ankorstore/yokai-contrib/fxgcppubsub/reactor"
 	"github.com/ankorstore/yokai-contrib/fxgcppubsub/reactor/ack"
 	"github.com/ankorstore/yokai-contrib/fxgcppubsub/subscription"
+	"github.com/ankorstore/yokai/config"
 	"github.com/ankorstore/yokai/fxconfig"
 	"github.com/ankorstore/yokai/fxlog"
 	"github.com/stretchr/testify/assert"
@@ -107,4 +110,116 @@ func TestAckSupervisor(t *testing.T) {
 		assert.Equal(t, assert.AnError, err)
 		assert.Equal(t, []string{"test-id"}, data)
 	})
+	t.Run("wait for ack of a message id", func(t *testing.T) {
+		waiter := supervisor.StartAckWaiter("test-subscription", ack.WithMessageIDs("second-id"))
+
+		subscriptionName := subscription.NormalizeSubscriptionName("test-project", "test-subscription")
+
+		supervisor.StopAckWaiter(subscriptionName, []string{"first-id"}, assert.AnError)
+		supervisor.StopAckWaiter(subscriptionName, []string{"third-id", "second-id"}, nil)
+
+		data, err := waiter.WaitMaxDuration(context.Background(), 5*time.Millisecond)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"second-id"}, data)
+	})
+
+	t.Run("wait for ack of a message matching attributes", func(t *testing.T) {
+		supervisor.RecordPublished(
+			&pubsubpb.PubsubMessage{MessageId: "foo-id", Attributes: map[string]string{"foo": "bar", "baz": "qux"}},
+			&pubsubpb.PubsubMessage{MessageId: "bar-id", Attributes: map[string]string{"foo": "baz"}},
+		)
+
+		waiter := supervisor.StartAckWaiter("test-subscription", ack.WithAttributes(map[string]string{"foo": "bar"}))
+
+		subscriptionName := subscription.NormalizeSubscriptionName("test-project", "test-subscription")
+
+		supervisor.StopAckWaiter(subscriptionName, []string{"bar-id", "unknown-id"}, nil)
+		supervisor.StopAckWaiter(subscriptionName, []string{"foo-id"}, nil)
+
+		data, err := waiter.WaitMaxDuration(context.Background(), 5*time.Millisecond)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"foo-id"}, data)
+	})
+
+	t.Run("wait for several nacks", func(t *testing.T) {
+		waiter := supervisor.StartNackWaiter("test-subscription", reactor.WithCount(3))
+
+		subscriptionName := subscription.NormalizeSubscriptionName("test-project", "test-subscription")
+
+		supervisor.StopNackWaiter(subscriptionName, []string{"first-id", "second-id"}, nil)
+
+		_, err := waiter.WaitMaxDuration(context.Background(), 1*time.Millisecond)
+		assert.Error(t, err)
+
+		supervisor.StopNackWaiter(subscriptionName, []string{"third-id"}, nil)
+
+		data, err := waiter.WaitMaxDuration(context.Background(), 5*time.Millisecond)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"first-id", "second-id", "third-id"}, data)
+	})
+
+	t.Run("wait concurrently on the same subscription", func(t *testing.T) {
+		firstWaiter := supervisor.StartAckWaiter("test-subscription", ack.WithMessageIDs("first-id"))
+		secondWaiter := supervisor.StartAckWaiter("test-subscription", ack.WithMessageIDs("second-id"))
+
+		subscriptionName := subscription.NormalizeSubscriptionName("test-project", "test-subscription")
+
+		go supervisor.StopAckWaiter(subscriptionName, []string{"second-id"}, nil)
+		go supervisor.StopAckWaiter(subscriptionName, []string{"first-id"}, nil)
+
+		data, err := firstWaiter.WaitMaxDuration(context.Background(), 50*time.Millisecond)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"first-id"}, data)
+
+		data, err = secondWaiter.WaitMaxDuration(context.Background(), 50*time.Millisecond)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"second-id"}, data)
+	})
+
+	t.Run("forget oldest recorded messages", func(t *testing.T) {
+		cfg, err := config.NewDefaultConfigFactory().Create(config.WithFilePaths("../../testdata/config"))
+		assert.NoError(t, err)
+
+		boundedSupervisor := ack.NewDefaultAckSupervisor(reactor.NewDefaultWaiterSupervisor(), cfg).WithMaxRecordedMessages(1)
+
+		boundedSupervisor.RecordPublished(
+			&pubsubpb.PubsubMessage{MessageId: "old-id", Attributes: map[string]string{"foo": "bar"}},
+			&pubsubpb.PubsubMessage{MessageId: "new-id", Attributes: map[string]string{"foo": "bar"}},
+		)
+
+		subscriptionName := subscription.NormalizeSubscriptionName(cfg.GetString("modules.gcppubsub.project.id"), "test-subscription")
+
+		waiter := boundedSupervisor.StartAckWaiter("test-subscription", ack.WithAttributes(map[string]string{"foo": "bar"}))
+
+		boundedSupervisor.StopAckWaiter(subscriptionName, []string{"old-id"}, nil)
+
+		_, err = waiter.WaitMaxDuration(context.Background(), time.Millisecond)
+		assert.Error(t, err)
+
+		boundedSupervisor.StopAckWaiter(subscriptionName, []string{"new-id"}, nil)
+
+		data, err := waiter.WaitMaxDuration(context.Background(), 5*time.Millisecond)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"new-id"}, data)
+	})
+
+	t.Run("reset recorded messages", func(t *testing.T) {
+		cfg, err := config.NewDefaultConfigFactory().Create(config.WithFilePaths("../../testdata/config"))
+		assert.NoError(t, err)
+
+		resetSupervisor := ack.NewDefaultAckSupervisor(reactor.NewDefaultWaiterSupervisor(), cfg)
+
+		resetSupervisor.RecordPublished(&pubsubpb.PubsubMessage{MessageId: "foo-id", Attributes: map[string]string{"foo": "bar"}})
+		resetSupervisor.Reset()
+
+		subscriptionName := subscription.NormalizeSubscriptionName(cfg.GetString("modules.gcppubsub.project.id"), "test-subscription")
+
+		waiter := resetSupervisor.StartAckWaiter("test-subscription", ack.WithAttributes(map[string]string{"foo": "bar"}))
+		defer waiter.Release()
+
+		resetSupervisor.StopAckWaiter(subscriptionName, []string{"foo-id"}, nil)
+
+		_, err = waiter.WaitMaxDuration(context.Background(), time.Millisecond)
+		assert.Error(t, err)
+	})
 }
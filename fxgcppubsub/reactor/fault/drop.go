@@ -0,0 +1,45 @@
+package fault
+
+// DropAckReactor is a pub/sub test server reactor dropping acks: they are reported as successful to the subscribers,
+// but the messages are not acknowledged, and are redelivered once their ack deadline expires.
+//
+// Since the reactors execution order is not guaranteed, the dropped acks can still be reported to the ack.AckSupervisor waiters.
+type DropAckReactor struct {
+	injector *injector
+}
+
+// NewDropAckReactor returns a new DropAckReactor instance, dropping by default all the Acknowledge calls.
+func NewDropAckReactor(options ...FaultOption) *DropAckReactor {
+	return &DropAckReactor{
+		injector: newInjector([]string{"Acknowledge"}, options...),
+	}
+}
+
+// Options returns the reactor options.
+func (r *DropAckReactor) Options() Options {
+	return r.injector.options
+}
+
+// Faults returns the number of dropped acks calls.
+func (r *DropAckReactor) Faults() int {
+	return r.injector.count()
+}
+
+// Reset resets the number of dropped acks calls, for example between subtests.
+func (r *DropAckReactor) Reset() {
+	r.injector.reset()
+}
+
+// FuncNames returns the list of function names this reactor will react to.
+func (r *DropAckReactor) FuncNames() []string {
+	return r.injector.options.FuncNames
+}
+
+// React is the reactor logic.
+func (r *DropAckReactor) React(req any) (bool, any, error) {
+	if r.injector.inject(req) {
+		return true, nil, nil
+	}
+
+	return false, nil, nil
+}
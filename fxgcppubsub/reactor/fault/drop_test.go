@@ -0,0 +1,143 @@
+package fault_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/pubsub/apiv1/pubsubpb"
+	"cloud.google.com/go/pubsub/pstest"
+	"github.com/ankorstore/yokai-contrib/fxgcppubsub"
+	"github.com/ankorstore/yokai-contrib/fxgcppubsub/reactor/fault"
+	"github.com/ankorstore/yokai/fxconfig"
+	"github.com/ankorstore/yokai/fxlog"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/fx"
+	"go.uber.org/fx/fxtest"
+)
+
+func TestDropAckReactor(t *testing.T) {
+	t.Parallel()
+
+	req := &pubsubpb.AcknowledgeRequest{
+		Subscription: "projects/test-project/subscriptions/test-subscription",
+	}
+
+	t.Run("defaults", func(t *testing.T) {
+		t.Parallel()
+
+		react := fault.NewDropAckReactor()
+
+		assert.Equal(t, []string{"Acknowledge"}, react.FuncNames())
+
+		handled, ret, err := react.React(req)
+		assert.True(t, handled)
+		assert.Nil(t, ret)
+		assert.NoError(t, err)
+
+		assert.Equal(t, 1, react.Faults())
+	})
+
+	t.Run("with resource ids and reset", func(t *testing.T) {
+		t.Parallel()
+
+		react := fault.NewDropAckReactor(fault.WithResourceIDs("other-subscription"))
+
+		handled, _, err := react.React(req)
+		assert.False(t, handled)
+		assert.NoError(t, err)
+
+		assert.Equal(t, 0, react.Faults())
+
+		handled, _, err = react.React(&pubsubpb.AcknowledgeRequest{
+			Subscription: "projects/test-project/subscriptions/other-subscription",
+		})
+		assert.True(t, handled)
+		assert.NoError(t, err)
+
+		assert.Equal(t, 1, react.Faults())
+
+		react.Reset()
+
+		assert.Equal(t, 0, react.Faults())
+	})
+}
+
+func TestDropAckReactorWithTestServer(t *testing.T) {
+	t.Setenv("APP_ENV", "test")
+	t.Setenv("APP_CONFIG_PATH", "../../testdata/config")
+	t.Setenv("GCP_PROJECT_ID", "test-project")
+
+	var publisher fxgcppubsub.Publisher
+	var subscriber fxgcppubsub.Subscriber
+	var server *pstest.Server
+
+	ctx := context.Background()
+
+	react := fault.NewDropAckReactor(fault.WithMaxFaults(1))
+
+	fxtest.New(
+		t,
+		fx.NopLogger,
+		fxconfig.FxConfigModule,
+		fxlog.FxLogModule,
+		fxgcppubsub.FxGcpPubSubModule,
+		fx.Supply(fx.Annotate(ctx, fx.As(new(context.Context)))),
+		fxgcppubsub.AsPubSubTestServerReactor(func() *fault.DropAckReactor {
+			return react
+		}),
+		fxgcppubsub.PrepareTopicAndSubscription(fxgcppubsub.PrepareTopicAndSubscriptionParams{
+			TopicID:        "test-topic",
+			SubscriptionID: "test-subscription",
+		}),
+		fx.Populate(&publisher, &subscriber, &server),
+	).RequireStart().RequireStop()
+
+	res, err := publisher.Publish(ctx, "test-topic", []byte("test"))
+	assert.NoError(t, err)
+
+	id, err := res.Get(ctx)
+	assert.NoError(t, err)
+
+	acks := func() int {
+		for _, m := range server.Messages() {
+			if m.ID == id {
+				return m.Acks
+			}
+		}
+
+		return -1
+	}
+
+	// first ack is dropped
+	messages, err := subscriber.Pull(ctx, "test-subscription", 1, time.Second)
+	assert.NoError(t, err)
+	assert.Len(t, messages, 1)
+
+	messages[0].Ack()
+
+	assert.Eventually(t, func() bool {
+		return react.Faults() == 1
+	}, time.Second, 10*time.Millisecond)
+
+	assert.Equal(t, 0, acks())
+
+	// second ack is accepted
+	err = messages[0].ModifyAckDeadline(ctx, 0)
+	assert.NoError(t, err)
+
+	assert.Eventually(t, func() bool {
+		redelivered, err := subscriber.Pull(ctx, "test-subscription", 1, 100*time.Millisecond)
+		assert.NoError(t, err)
+
+		for _, m := range redelivered {
+			m.Ack()
+		}
+
+		return len(redelivered) == 1
+	}, 2*time.Second, 10*time.Millisecond)
+
+	assert.Eventually(t, func() bool {
+		return acks() == 1
+	}, time.Second, 10*time.Millisecond)
+}
@@ -0,0 +1,52 @@
+package fault
+
+import (
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// DefaultErrorMessage is the default message of the injected errors.
+const DefaultErrorMessage = "injected fault"
+
+// ErrorReactor is a pub/sub test server reactor failing calls with a gRPC status code.
+type ErrorReactor struct {
+	injector *injector
+	code     codes.Code
+}
+
+// NewErrorReactor returns a new ErrorReactor instance, failing by default all the Publish calls with the provided code.
+func NewErrorReactor(code codes.Code, options ...FaultOption) *ErrorReactor {
+	return &ErrorReactor{
+		injector: newInjector([]string{"Publish"}, options...),
+		code:     code,
+	}
+}
+
+// Options returns the reactor options.
+func (r *ErrorReactor) Options() Options {
+	return r.injector.options
+}
+
+// Faults returns the number of injected errors.
+func (r *ErrorReactor) Faults() int {
+	return r.injector.count()
+}
+
+// Reset resets the number of injected errors, for example between subtests.
+func (r *ErrorReactor) Reset() {
+	r.injector.reset()
+}
+
+// FuncNames returns the list of function names this reactor will react to.
+func (r *ErrorReactor) FuncNames() []string {
+	return r.injector.options.FuncNames
+}
+
+// React is the reactor logic.
+func (r *ErrorReactor) React(req any) (bool, any, error) {
+	if r.injector.inject(req) {
+		return true, nil, status.Error(r.code, DefaultErrorMessage)
+	}
+
+	return false, nil, nil
+}
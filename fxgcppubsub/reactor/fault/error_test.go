@@ -0,0 +1,185 @@
+package fault_test
+
+import (
+	"context"
+	"math/rand/v2"
+	"testing"
+
+	"cloud.google.com/go/pubsub/apiv1/pubsubpb"
+	"github.com/ankorstore/yokai-contrib/fxgcppubsub"
+	"github.com/ankorstore/yokai-contrib/fxgcppubsub/reactor/fault"
+	"github.com/ankorstore/yokai/fxconfig"
+	"github.com/ankorstore/yokai/fxlog"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/fx"
+	"go.uber.org/fx/fxtest"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestErrorReactor(t *testing.T) {
+	t.Parallel()
+
+	publishReq := func(topicID string) *pubsubpb.PublishRequest {
+		return &pubsubpb.PublishRequest{
+			Topic: "projects/test-project/topics/" + topicID,
+		}
+	}
+
+	t.Run("defaults", func(t *testing.T) {
+		t.Parallel()
+
+		react := fault.NewErrorReactor(codes.Unavailable)
+
+		assert.Equal(t, []string{"Publish"}, react.FuncNames())
+		assert.Equal(t, fault.DefaultRate, react.Options().Rate)
+		assert.Equal(t, 0, react.Options().MaxFaults)
+
+		for i := 0; i < 3; i++ {
+			handled, ret, err := react.React(publishReq("test-topic"))
+			assert.True(t, handled)
+			assert.Nil(t, ret)
+			assert.Equal(t, codes.Unavailable, status.Code(err))
+			assert.Contains(t, err.Error(), fault.DefaultErrorMessage)
+		}
+
+		assert.Equal(t, 3, react.Faults())
+	})
+
+	t.Run("with func names", func(t *testing.T) {
+		t.Parallel()
+
+		react := fault.NewErrorReactor(codes.Internal, fault.WithFuncNames("Pull", "Acknowledge"))
+
+		assert.Equal(t, []string{"Pull", "Acknowledge"}, react.FuncNames())
+
+		handled, _, err := react.React(&pubsubpb.PullRequest{
+			Subscription: "projects/test-project/subscriptions/test-subscription",
+		})
+		assert.True(t, handled)
+		assert.Equal(t, codes.Internal, status.Code(err))
+	})
+
+	t.Run("with max faults and reset", func(t *testing.T) {
+		t.Parallel()
+
+		react := fault.NewErrorReactor(codes.Unavailable, fault.WithMaxFaults(2))
+
+		for i := 0; i < 2; i++ {
+			handled, _, err := react.React(publishReq("test-topic"))
+			assert.True(t, handled)
+			assert.Error(t, err)
+		}
+
+		handled, _, err := react.React(publishReq("test-topic"))
+		assert.False(t, handled)
+		assert.NoError(t, err)
+
+		assert.Equal(t, 2, react.Faults())
+
+		react.Reset()
+
+		assert.Equal(t, 0, react.Faults())
+
+		handled, _, err = react.React(publishReq("test-topic"))
+		assert.True(t, handled)
+		assert.Error(t, err)
+	})
+
+	t.Run("with resource ids", func(t *testing.T) {
+		t.Parallel()
+
+		react := fault.NewErrorReactor(codes.Unavailable, fault.WithResourceIDs("test-topic"))
+
+		handled, _, err := react.React(publishReq("other-topic"))
+		assert.False(t, handled)
+		assert.NoError(t, err)
+
+		handled, _, err = react.React(publishReq("test-topic"))
+		assert.True(t, handled)
+		assert.Error(t, err)
+
+		assert.Equal(t, 1, react.Faults())
+	})
+
+	t.Run("with rate", func(t *testing.T) {
+		t.Parallel()
+
+		react := fault.NewErrorReactor(
+			codes.Unavailable,
+			fault.WithRate(0.5),
+			fault.WithRand(rand.New(rand.NewPCG(1, 2))),
+		)
+
+		for i := 0; i < 1000; i++ {
+			//nolint:errcheck
+			react.React(publishReq("test-topic"))
+		}
+
+		assert.InDelta(t, 500, react.Faults(), 100)
+	})
+
+	t.Run("with zero and out of range rates", func(t *testing.T) {
+		t.Parallel()
+
+		never := fault.NewErrorReactor(codes.Unavailable, fault.WithRate(-1))
+		always := fault.NewErrorReactor(codes.Unavailable, fault.WithRate(2))
+
+		assert.Equal(t, float64(0), never.Options().Rate)
+		assert.Equal(t, float64(1), always.Options().Rate)
+
+		for i := 0; i < 10; i++ {
+			//nolint:errcheck
+			never.React(publishReq("test-topic"))
+			//nolint:errcheck
+			always.React(publishReq("test-topic"))
+		}
+
+		assert.Equal(t, 0, never.Faults())
+		assert.Equal(t, 10, always.Faults())
+	})
+}
+
+func TestErrorReactorWithTestServer(t *testing.T) {
+	t.Setenv("APP_ENV", "test")
+	t.Setenv("APP_CONFIG_PATH", "../../testdata/config")
+	t.Setenv("GCP_PROJECT_ID", "test-project")
+
+	var publisher fxgcppubsub.Publisher
+
+	ctx := context.Background()
+
+	react := fault.NewErrorReactor(codes.InvalidArgument, fault.WithMaxFaults(1), fault.WithResourceIDs("test-topic"))
+
+	fxtest.New(
+		t,
+		fx.NopLogger,
+		fxconfig.FxConfigModule,
+		fxlog.FxLogModule,
+		fxgcppubsub.FxGcpPubSubModule,
+		fx.Supply(fx.Annotate(ctx, fx.As(new(context.Context)))),
+		fxgcppubsub.AsPubSubTestServerReactor(func() *fault.ErrorReactor {
+			return react
+		}),
+		fxgcppubsub.PrepareTopic(fxgcppubsub.PrepareTopicParams{
+			TopicID: "test-topic",
+		}),
+		fx.Populate(&publisher),
+	).RequireStart().RequireStop()
+
+	res, err := publisher.Publish(ctx, "test-topic", []byte("first"))
+	assert.NoError(t, err)
+
+	_, err = res.Get(ctx)
+	assert.Error(t, err)
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	res, err = publisher.Publish(ctx, "test-topic", []byte("second"))
+	assert.NoError(t, err)
+
+	id, err := res.Get(ctx)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, id)
+
+	assert.Equal(t, 1, react.Faults())
+}
@@ -0,0 +1,93 @@
+package fault
+
+import (
+	"math/rand/v2"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// injector decides, based on the Options, on which calls a fault is injected.
+type injector struct {
+	options Options
+	faults  int
+	mutex   sync.Mutex
+}
+
+func newInjector(defaultFuncNames []string, options ...FaultOption) *injector {
+	faultOptions := DefaultFaultOptions()
+	for _, applyOpt := range options {
+		applyOpt(&faultOptions)
+	}
+
+	if len(faultOptions.FuncNames) == 0 {
+		faultOptions.FuncNames = defaultFuncNames
+	}
+
+	faultOptions.Rate = min(max(faultOptions.Rate, 0), 1)
+
+	return &injector{
+		options: faultOptions,
+	}
+}
+
+// inject returns true if a fault must be injected for the provided request.
+func (i *injector) inject(req any) bool {
+	if len(i.options.ResourceIDs) > 0 && !slices.Contains(i.options.ResourceIDs, resourceID(req)) {
+		return false
+	}
+
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+
+	if i.options.MaxFaults > 0 && i.faults >= i.options.MaxFaults {
+		return false
+	}
+
+	if i.options.Rate < 1 && i.float64() >= i.options.Rate {
+		return false
+	}
+
+	i.faults++
+
+	return true
+}
+
+// count returns the number of injected faults.
+func (i *injector) count() int {
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+
+	return i.faults
+}
+
+// reset resets the number of injected faults.
+func (i *injector) reset() {
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+
+	i.faults = 0
+}
+
+func (i *injector) float64() float64 {
+	if i.options.Rand != nil {
+		return i.options.Rand.Float64()
+	}
+
+	//nolint:gosec
+	return rand.Float64()
+}
+
+// resourceID returns the topic or subscription id targeted by a test server request.
+func resourceID(req any) string {
+	var name string
+
+	switch r := req.(type) {
+	case interface{ GetTopic() string }:
+		name = r.GetTopic()
+	case interface{ GetSubscription() string }:
+		name = r.GetSubscription()
+	}
+
+	return name[strings.LastIndex(name, "/")+1:]
+}
@@ -0,0 +1,50 @@
+package fault
+
+import (
+	"time"
+)
+
+// LatencyReactor is a pub/sub test server reactor adding latency to calls.
+//
+// Since the test server is locked while reacting, the latency also delays the concurrent calls.
+type LatencyReactor struct {
+	injector *injector
+	latency  time.Duration
+}
+
+// NewLatencyReactor returns a new LatencyReactor instance, adding by default the provided latency to all the Publish calls.
+func NewLatencyReactor(latency time.Duration, options ...FaultOption) *LatencyReactor {
+	return &LatencyReactor{
+		injector: newInjector([]string{"Publish"}, options...),
+		latency:  latency,
+	}
+}
+
+// Options returns the reactor options.
+func (r *LatencyReactor) Options() Options {
+	return r.injector.options
+}
+
+// Faults returns the number of delayed calls.
+func (r *LatencyReactor) Faults() int {
+	return r.injector.count()
+}
+
+// Reset resets the number of delayed calls, for example between subtests.
+func (r *LatencyReactor) Reset() {
+	r.injector.reset()
+}
+
+// FuncNames returns the list of function names this reactor will react to.
+func (r *LatencyReactor) FuncNames() []string {
+	return r.injector.options.FuncNames
+}
+
+// React is the reactor logic.
+func (r *LatencyReactor) React(req any) (bool, any, error) {
+	if r.injector.inject(req) {
+		time.Sleep(r.latency)
+	}
+
+	return false, nil, nil
+}
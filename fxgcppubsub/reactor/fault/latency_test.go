@@ -0,0 +1,67 @@
+package fault_test
+
+import (
+	"testing"
+	"time"
+
+	"cloud.google.com/go/pubsub/apiv1/pubsubpb"
+	"github.com/ankorstore/yokai-contrib/fxgcppubsub/reactor/fault"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLatencyReactor(t *testing.T) {
+	t.Parallel()
+
+	req := &pubsubpb.PublishRequest{
+		Topic: "projects/test-project/topics/test-topic",
+	}
+
+	t.Run("defaults", func(t *testing.T) {
+		t.Parallel()
+
+		react := fault.NewLatencyReactor(50 * time.Millisecond)
+
+		assert.Equal(t, []string{"Publish"}, react.FuncNames())
+
+		start := time.Now()
+
+		handled, ret, err := react.React(req)
+		assert.False(t, handled)
+		assert.Nil(t, ret)
+		assert.NoError(t, err)
+
+		assert.GreaterOrEqual(t, time.Since(start), 50*time.Millisecond)
+		assert.Equal(t, 1, react.Faults())
+	})
+
+	t.Run("with max faults and reset", func(t *testing.T) {
+		t.Parallel()
+
+		react := fault.NewLatencyReactor(
+			50*time.Millisecond,
+			fault.WithFuncNames("Pull"),
+			fault.WithMaxFaults(1),
+		)
+
+		assert.Equal(t, []string{"Pull"}, react.FuncNames())
+
+		pullReq := &pubsubpb.PullRequest{
+			Subscription: "projects/test-project/subscriptions/test-subscription",
+		}
+
+		//nolint:errcheck
+		react.React(pullReq)
+
+		start := time.Now()
+
+		//nolint:errcheck
+		react.React(pullReq)
+
+		assert.Less(t, time.Since(start), 50*time.Millisecond)
+		assert.Equal(t, 1, react.Faults())
+
+		react.Reset()
+
+		assert.Equal(t, 0, react.Faults())
+	})
+}
@@ -0,0 +1,62 @@
+package fault
+
+import (
+	"math/rand/v2"
+)
+
+// DefaultRate is the default rate of the calls on which a fault is injected.
+const DefaultRate = 1.0
+
+// Options represents the fault injection reactors options.
+type Options struct {
+	FuncNames   []string
+	ResourceIDs []string
+	MaxFaults   int
+	Rate        float64
+	Rand        *rand.Rand
+}
+
+// DefaultFaultOptions returns the default fault injection reactors options.
+func DefaultFaultOptions() Options {
+	return Options{
+		Rate: DefaultRate,
+	}
+}
+
+// FaultOption represents the fault injection reactors functional options.
+type FaultOption func(o *Options)
+
+// WithFuncNames sets the test server function names the reactor will react to, instead of the reactor default ones.
+func WithFuncNames(names ...string) FaultOption {
+	return func(o *Options) {
+		o.FuncNames = names
+	}
+}
+
+// WithResourceIDs restricts the fault injection to the calls on the provided topic or subscription ids.
+func WithResourceIDs(ids ...string) FaultOption {
+	return func(o *Options) {
+		o.ResourceIDs = ids
+	}
+}
+
+// WithMaxFaults restricts the fault injection to the first n matching calls (0 for no limit).
+func WithMaxFaults(n int) FaultOption {
+	return func(o *Options) {
+		o.MaxFaults = n
+	}
+}
+
+// WithRate sets the rate, between 0 and 1, of the matching calls on which a fault is injected.
+func WithRate(rate float64) FaultOption {
+	return func(o *Options) {
+		o.Rate = rate
+	}
+}
+
+// WithRand sets the random generator used to apply the rate, for example to make it deterministic.
+func WithRand(r *rand.Rand) FaultOption {
+	return func(o *Options) {
+		o.Rand = r
+	}
+}
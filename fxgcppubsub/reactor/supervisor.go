@@ -8,43 +8,87 @@ var _ WaiterSupervisor = (*DefaultWaiterSupervisor)(nil)
 
 // WaiterSupervisor is the interface for waiters supervisors.
 type WaiterSupervisor interface {
-	StartWaiter(target string) *Waiter
+	StartWaiter(target string, options ...WaiterOption) *Waiter
 	StopWaiter(target string, data any, err error)
+	ReleaseWaiter(target string, waiter *Waiter)
 }
 
 // DefaultWaiterSupervisor is the default WaiterSupervisor implementation.
 type DefaultWaiterSupervisor struct {
-	waiters map[string]*Waiter
-	mutex   sync.RWMutex
+	waiters map[string][]*Waiter
+	mutex   sync.Mutex
 }
 
 // NewDefaultWaiterSupervisor returns a new DefaultWaiterSupervisor instance.
 func NewDefaultWaiterSupervisor() *DefaultWaiterSupervisor {
 	return &DefaultWaiterSupervisor{
-		waiters: make(map[string]*Waiter),
+		waiters: make(map[string][]*Waiter),
 	}
 }
 
-// StartWaiter starts a Waiter for a target.
-func (s *DefaultWaiterSupervisor) StartWaiter(target string) *Waiter {
+// StartWaiter starts a Waiter for a target, with options. Several waiters can be started for the same target.
+//
+// The waiters which may never be completed (for example after a wait timeout) must be released with Waiter Release.
+func (s *DefaultWaiterSupervisor) StartWaiter(target string, options ...WaiterOption) *Waiter {
 	s.mutex.Lock()
 	defer s.mutex.Unlock()
 
-	waiter := NewWaiter()
+	waiter := NewWaiter(options...)
+	waiter.release = func() {
+		s.ReleaseWaiter(target, waiter)
+	}
 
-	s.waiters[target] = waiter
+	s.waiters[target] = append(s.waiters[target], waiter)
 
 	return waiter
 }
 
-// StopWaiter stops a Waiter for a target with result.
+// StopWaiter notifies all the waiters of a target with a result, and releases the stopped ones.
 func (s *DefaultWaiterSupervisor) StopWaiter(target string, data any, err error) {
 	s.mutex.Lock()
 	defer s.mutex.Unlock()
 
-	if waiter, found := s.waiters[target]; found {
-		waiter.Stop(data, err)
+	var running []*Waiter
+
+	for _, waiter := range s.waiters[target] {
+		if !waiter.Notify(data, err) {
+			running = append(running, waiter)
+		}
+	}
 
+	if len(running) == 0 {
 		delete(s.waiters, target)
+	} else {
+		s.waiters[target] = running
+	}
+}
+
+// ReleaseWaiter stops a waiter of a target with ErrWaiterReleased, and stops notifying it.
+func (s *DefaultWaiterSupervisor) ReleaseWaiter(target string, waiter *Waiter) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	var running []*Waiter
+
+	for _, w := range s.waiters[target] {
+		if w != waiter {
+			running = append(running, w)
+		}
 	}
+
+	if len(running) == 0 {
+		delete(s.waiters, target)
+	} else {
+		s.waiters[target] = running
+	}
+
+	waiter.Stop(nil, ErrWaiterReleased)
+}
+
+// Waiters returns the number of running waiters of a target.
+func (s *DefaultWaiterSupervisor) Waiters(target string) int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return len(s.waiters[target])
 }
@@ -59,4 +59,72 @@ func TestDefaultWaiterSupervisor(t *testing.T) {
 		assert.Equal(t, "test", data)
 		assert.GreaterOrEqual(t, latency, 1*time.Millisecond)
 	})
+	t.Run("supervise several waiters on the same target", func(t *testing.T) {
+		t.Parallel()
+
+		supervisor := reactor.NewDefaultWaiterSupervisor()
+
+		firstWaiter := supervisor.StartWaiter("target")
+		matchingWaiter := supervisor.StartWaiter("target", reactor.WithMatcher(func(data any) bool {
+			return data == "second"
+		}))
+		countWaiter := supervisor.StartWaiter("target", reactor.WithCount(2))
+		otherWaiter := supervisor.StartWaiter("other")
+
+		supervisor.StopWaiter("target", "first", nil)
+		supervisor.StopWaiter("target", "second", nil)
+
+		data, err := firstWaiter.Wait(context.Background())
+		assert.NoError(t, err)
+		assert.Equal(t, "first", data)
+
+		data, err = matchingWaiter.Wait(context.Background())
+		assert.NoError(t, err)
+		assert.Equal(t, "second", data)
+
+		data, err = countWaiter.Wait(context.Background())
+		assert.NoError(t, err)
+		assert.Equal(t, []any{"first", "second"}, data)
+
+		_, err = otherWaiter.WaitMaxDuration(context.Background(), time.Millisecond)
+		assert.Error(t, err)
+		assert.Equal(t, "context deadline exceeded", err.Error())
+	})
+
+	t.Run("release timed out waiters", func(t *testing.T) {
+		t.Parallel()
+
+		supervisor := reactor.NewDefaultWaiterSupervisor()
+
+		firstWaiter := supervisor.StartWaiter("target", reactor.WithCount(2))
+		secondWaiter := supervisor.StartWaiter("target", reactor.WithCount(2))
+		assert.Equal(t, 2, supervisor.Waiters("target"))
+
+		_, err := firstWaiter.WaitMaxDuration(context.Background(), time.Millisecond)
+		assert.Error(t, err)
+		assert.Equal(t, "context deadline exceeded", err.Error())
+
+		firstWaiter.Release()
+		assert.Equal(t, 1, supervisor.Waiters("target"))
+
+		data, err := firstWaiter.Wait(context.Background())
+		assert.ErrorIs(t, err, reactor.ErrWaiterReleased)
+		assert.Nil(t, data)
+
+		supervisor.StopWaiter("target", "first", nil)
+		supervisor.StopWaiter("target", "second", nil)
+
+		data, err = secondWaiter.Wait(context.Background())
+		assert.NoError(t, err)
+		assert.Equal(t, []any{"first", "second"}, data)
+
+		assert.Equal(t, 0, supervisor.Waiters("target"))
+
+		// releasing a stopped waiter does not change its result
+		secondWaiter.Release()
+
+		data, err = secondWaiter.Wait(context.Background())
+		assert.NoError(t, err)
+		assert.Equal(t, []any{"first", "second"}, data)
+	})
 }
@@ -2,34 +2,154 @@ package reactor
 
 import (
 	"context"
+	"errors"
+	"sync"
 	"time"
 )
 
+// ErrWaiterReleased is the error a Waiter is stopped with when released from its supervisor.
+var ErrWaiterReleased = errors.New("waiter released")
+
+// WaiterMatcher is a function returning true if the data a Waiter is notified with is matching its expectations.
+type WaiterMatcher func(data any) bool
+
+// WaiterAggregator is a function aggregating the data of the matching notifications, once a Waiter is completed.
+type WaiterAggregator func(data []any) any
+
+// WaiterOptions represents the Waiter options.
+type WaiterOptions struct {
+	Matchers   []WaiterMatcher
+	Count      int
+	Aggregator WaiterAggregator
+}
+
+// DefaultWaiterOptions returns the default Waiter options.
+func DefaultWaiterOptions() WaiterOptions {
+	return WaiterOptions{
+		Count:      1,
+		Aggregator: DefaultWaiterAggregator,
+	}
+}
+
+// DefaultWaiterAggregator returns the data of the single matching notification, or the list of data of all the matching notifications.
+func DefaultWaiterAggregator(data []any) any {
+	if len(data) == 1 {
+		return data[0]
+	}
+
+	return data
+}
+
+// WaiterOption represents the Waiter functional options.
+type WaiterOption func(o *WaiterOptions)
+
+// WithMatcher adds a WaiterMatcher: only the notifications matching all the matchers are counted.
+func WithMatcher(matcher WaiterMatcher) WaiterOption {
+	return func(o *WaiterOptions) {
+		o.Matchers = append(o.Matchers, matcher)
+	}
+}
+
+// WithCount sets the number of matching notifications to wait for before completion.
+func WithCount(n int) WaiterOption {
+	return func(o *WaiterOptions) {
+		o.Count = n
+	}
+}
+
+// WithAggregator sets the WaiterAggregator used to aggregate the data of the matching notifications on completion.
+func WithAggregator(aggregator WaiterAggregator) WaiterOption {
+	return func(o *WaiterOptions) {
+		o.Aggregator = aggregator
+	}
+}
+
 // Waiter is a component that can wait (blocking call) until stopped.
 type Waiter struct {
-	done chan struct{}
-	data any
-	err  error
+	options  WaiterOptions
+	done     chan struct{}
+	once     sync.Once
+	mutex    sync.Mutex
+	matching []any
+	data     any
+	err      error
+	release  func()
 }
 
 // NewWaiter returns a new Waiter instance.
-func NewWaiter() *Waiter {
+func NewWaiter(options ...WaiterOption) *Waiter {
+	waiterOptions := DefaultWaiterOptions()
+	for _, applyOpt := range options {
+		applyOpt(&waiterOptions)
+	}
+
+	if waiterOptions.Count < 1 {
+		waiterOptions.Count = 1
+	}
+
+	if waiterOptions.Aggregator == nil {
+		waiterOptions.Aggregator = DefaultWaiterAggregator
+	}
+
 	return &Waiter{
-		done: make(chan struct{}),
+		options: waiterOptions,
+		done:    make(chan struct{}),
 	}
 }
 
+// Options returns the Waiter options.
+func (w *Waiter) Options() WaiterOptions {
+	return w.options
+}
+
 // Done returns the Waiter channel.
 func (w *Waiter) Done() <-chan struct{} {
 	return w.done
 }
 
-// Stop stops the waiter.
+// Stop stops the waiter. Only the first call is effective.
 func (w *Waiter) Stop(data any, err error) {
-	w.data = data
-	w.err = err
+	w.once.Do(func() {
+		w.data = data
+		w.err = err
+
+		close(w.done)
+	})
+}
+
+// Notify notifies the waiter with a result, and returns true if the waiter is stopped.
+//
+// Only the notifications matching all its matchers are considered: the waiter is stopped on the first matching
+// notification with an error, or when the expected count of matching notifications is reached.
+func (w *Waiter) Notify(data any, err error) bool {
+	for _, matcher := range w.options.Matchers {
+		if !matcher(data) {
+			return w.stopped()
+		}
+	}
+
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	if w.stopped() {
+		return true
+	}
+
+	w.matching = append(w.matching, data)
+
+	if err != nil {
+		w.Stop(w.options.Aggregator(w.matching), err)
 
-	close(w.done)
+		return true
+	}
+
+	if len(w.matching) >= w.options.Count {
+		w.Stop(w.options.Aggregator(w.matching), nil)
+
+		return true
+	}
+
+	return false
 }
 
 // Wait is making a blocking call until the Stop func is called, or until the provided context is cancelled.
@@ -51,3 +171,24 @@ func (w *Waiter) WaitMaxDuration(ctx context.Context, duration time.Duration) (a
 
 	return w.Wait(ctx)
 }
+
+// Release releases the waiter from the supervisor which started it, and stops it with ErrWaiterReleased if not already
+// stopped. It must be called when giving up on a waiter which may never be completed, for example after a wait timeout.
+func (w *Waiter) Release() {
+	if w.release != nil {
+		w.release()
+
+		return
+	}
+
+	w.Stop(nil, ErrWaiterReleased)
+}
+
+func (w *Waiter) stopped() bool {
+	select {
+	case <-w.done:
+		return true
+	default:
+		return false
+	}
+}
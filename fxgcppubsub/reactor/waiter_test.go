@@ -123,4 +123,105 @@ func TestWaiter(t *testing.T) {
 		assert.Equal(t, "context deadline exceeded", err.Error())
 		assert.GreaterOrEqual(t, latency, 1*time.Millisecond)
 	})
+	t.Run("release standalone waiter", func(t *testing.T) {
+		t.Parallel()
+
+		waiter := reactor.NewWaiter()
+
+		waiter.Release()
+
+		data, err := waiter.Wait(context.Background())
+		assert.ErrorIs(t, err, reactor.ErrWaiterReleased)
+		assert.Nil(t, data)
+	})
+
+	t.Run("defaults", func(t *testing.T) {
+		t.Parallel()
+
+		waiter := reactor.NewWaiter()
+
+		assert.Empty(t, waiter.Options().Matchers)
+		assert.Equal(t, 1, waiter.Options().Count)
+		assert.NotNil(t, waiter.Options().Aggregator)
+	})
+
+	t.Run("stop only once", func(t *testing.T) {
+		t.Parallel()
+
+		waiter := reactor.NewWaiter()
+
+		waiter.Stop("first", nil)
+		waiter.Stop("second", fmt.Errorf("test error"))
+
+		data, err := waiter.Wait(context.Background())
+		assert.NoError(t, err)
+		assert.Equal(t, "first", data)
+	})
+
+	t.Run("notify until count reached", func(t *testing.T) {
+		t.Parallel()
+
+		waiter := reactor.NewWaiter(reactor.WithCount(2))
+
+		assert.False(t, waiter.Notify("first", nil))
+		assert.True(t, waiter.Notify("second", nil))
+		assert.True(t, waiter.Notify("third", nil))
+
+		data, err := waiter.Wait(context.Background())
+		assert.NoError(t, err)
+		assert.Equal(t, []any{"first", "second"}, data)
+	})
+
+	t.Run("notify with matchers", func(t *testing.T) {
+		t.Parallel()
+
+		waiter := reactor.NewWaiter(
+			reactor.WithMatcher(func(data any) bool {
+				return data != "invalid"
+			}),
+			reactor.WithMatcher(func(data any) bool {
+				return data != "other"
+			}),
+		)
+
+		assert.False(t, waiter.Notify("invalid", nil))
+		assert.False(t, waiter.Notify("other", fmt.Errorf("test error")))
+		assert.True(t, waiter.Notify("valid", nil))
+
+		data, err := waiter.Wait(context.Background())
+		assert.NoError(t, err)
+		assert.Equal(t, "valid", data)
+	})
+
+	t.Run("notify with error", func(t *testing.T) {
+		t.Parallel()
+
+		waiter := reactor.NewWaiter(reactor.WithCount(3))
+
+		assert.False(t, waiter.Notify("first", nil))
+		assert.True(t, waiter.Notify("second", fmt.Errorf("test error")))
+
+		data, err := waiter.Wait(context.Background())
+		assert.Error(t, err)
+		assert.Equal(t, "test error", err.Error())
+		assert.Equal(t, []any{"first", "second"}, data)
+	})
+
+	t.Run("notify with aggregator", func(t *testing.T) {
+		t.Parallel()
+
+		waiter := reactor.NewWaiter(
+			reactor.WithCount(2),
+			reactor.WithAggregator(func(data []any) any {
+				return len(data)
+			}),
+		)
+
+		waiter.Notify("first", nil)
+		waiter.Notify("second", nil)
+
+		data, err := waiter.Wait(context.Background())
+		assert.NoError(t, err)
+		assert.Equal(t, 2, data)
+	})
 }
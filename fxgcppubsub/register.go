@@ -1,7 +1,15 @@
 package fxgcppubsub
 
 import (
+	"context"
+	"fmt"
+	"sync/atomic"
+
 	"cloud.google.com/go/pubsub/pstest"
+	"github.com/ankorstore/yokai-contrib/fxgcppubsub/codec"
+	"github.com/ankorstore/yokai-contrib/fxgcppubsub/subscription"
+	"github.com/ankorstore/yokai-contrib/fxgcppubsub/topic"
+	"github.com/ankorstore/yokai-contrib/fxgcppubsub/worker"
 	"go.uber.org/fx"
 )
 
@@ -32,3 +40,158 @@ func AsPubSubTestServerReactors(constructors ...any) fx.Option {
 
 	return fx.Options(options...)
 }
+
+// AsSubscribeMiddleware registers a [subscription.Middleware] into Fx, applied on all subscriptions.
+func AsSubscribeMiddleware(constructor any) fx.Option {
+	return fx.Provide(
+		fx.Annotate(
+			constructor,
+			fx.As(new(subscription.Middleware)),
+			fx.ResultTags(`group:"gcppubsub-subscribe-middlewares"`),
+		),
+	)
+}
+
+// AsSubscribeMiddlewares registers a list of [subscription.Middleware] into Fx, applied on all subscriptions.
+func AsSubscribeMiddlewares(constructors ...any) fx.Option {
+	options := []fx.Option{}
+
+	for _, constructor := range constructors {
+		options = append(options, AsSubscribeMiddleware(constructor))
+	}
+
+	return fx.Options(options...)
+}
+
+// AsPublishMiddleware registers a [topic.Middleware] into Fx, applied on all publications.
+func AsPublishMiddleware(constructor any) fx.Option {
+	return fx.Provide(
+		fx.Annotate(
+			constructor,
+			fx.As(new(topic.Middleware)),
+			fx.ResultTags(`group:"gcppubsub-publish-middlewares"`),
+		),
+	)
+}
+
+// AsPublishMiddlewares registers a list of [topic.Middleware] into Fx, applied on all publications.
+func AsPublishMiddlewares(constructors ...any) fx.Option {
+	options := []fx.Option{}
+
+	for _, constructor := range constructors {
+		options = append(options, AsPublishMiddleware(constructor))
+	}
+
+	return fx.Options(options...)
+}
+
+// AsTypedPublisher registers a [TypedPublisher] for T, bound to a topicID, into Fx.
+//
+// The compatibility of T with the topic schema is checked on creation, failing the application startup if needed.
+func AsTypedPublisher[T any](topicID string) fx.Option {
+	return fx.Provide(
+		func(ctx context.Context, publisher Publisher, factory topic.TopicFactory, registry topic.TopicRegistry) (*TypedPublisher[T], error) {
+			return NewTypedPublisher[T](ctx, publisher, factory, registry, topicID)
+		},
+	)
+}
+
+// AsTypedSubscriber registers a [TypedSubscriber] for T, bound to a subscriptionID, into Fx.
+//
+// The compatibility of T with the subscription topic schema is checked on creation, failing the application startup if needed.
+func AsTypedSubscriber[T any](subscriptionID string) fx.Option {
+	return fx.Provide(
+		func(ctx context.Context, subscriber Subscriber, factory subscription.SubscriptionFactory, registry subscription.SubscriptionRegistry) (*TypedSubscriber[T], error) {
+			return NewTypedSubscriber[T](ctx, subscriber, factory, registry, subscriptionID)
+		},
+	)
+}
+
+// handlerSequence makes the subscription handlers constructors names unique, when several target the same subscription.
+var handlerSequence atomic.Uint64
+
+// AsSubscriptionHandler registers a handler on a subscriptionID, run in background by the [worker.WorkerSupervisor]
+// when the [FxGcpPubSubWorkerModule] is loaded.
+//
+// The handler can be a [subscription.SubscribeFunc] (with manual acknowledgement), a [subscription.SubscribeErrorFunc]
+// or a [worker.SubscriptionHandler] (with the subscription retry policy), or a constructor returning a [worker.SubscriptionHandler].
+func AsSubscriptionHandler(subscriptionID string, handler any, options ...subscription.SubscribeOption) fx.Option {
+	if worker.IsHandler(handler) {
+		return fx.Supply(
+			fx.Annotated{
+				Group: "gcppubsub-subscription-handlers",
+				Target: worker.SubscriptionHandlerDefinition{
+					SubscriptionID: subscriptionID,
+					Handler:        handler,
+					Options:        options,
+				},
+			},
+		)
+	}
+
+	name := fmt.Sprintf(`name:"gcppubsub-subscription-handler-%s-%d"`, subscriptionID, handlerSequence.Add(1))
+
+	return fx.Options(
+		fx.Provide(
+			fx.Annotate(
+				handler,
+				fx.As(new(worker.SubscriptionHandler)),
+				fx.ResultTags(name),
+			),
+		),
+		fx.Provide(
+			fx.Annotate(
+				func(h worker.SubscriptionHandler) worker.SubscriptionHandlerDefinition {
+					return worker.SubscriptionHandlerDefinition{
+						SubscriptionID: subscriptionID,
+						Handler:        h,
+						Options:        options,
+					}
+				},
+				fx.ParamTags(name),
+				fx.ResultTags(`group:"gcppubsub-subscription-handlers"`),
+			),
+		),
+	)
+}
+
+// AsCodec registers a named [codec.Codec] into the [codec.CodecRegistry], selectable for topics and subscriptions without schema.
+//
+// The codec can be a [codec.Codec] instance, or a constructor returning a [codec.Codec].
+func AsCodec(name string, c any) fx.Option {
+	if instance, ok := c.(codec.Codec); ok {
+		return fx.Supply(
+			fx.Annotated{
+				Group: "gcppubsub-codecs",
+				Target: codec.CodecDefinition{
+					Name:  name,
+					Codec: instance,
+				},
+			},
+		)
+	}
+
+	tag := fmt.Sprintf(`name:"gcppubsub-codec-%s"`, name)
+
+	return fx.Options(
+		fx.Provide(
+			fx.Annotate(
+				c,
+				fx.As(new(codec.Codec)),
+				fx.ResultTags(tag),
+			),
+		),
+		fx.Provide(
+			fx.Annotate(
+				func(c codec.Codec) codec.CodecDefinition {
+					return codec.CodecDefinition{
+						Name:  name,
+						Codec: c,
+					}
+				},
+				fx.ParamTags(tag),
+				fx.ResultTags(`group:"gcppubsub-codecs"`),
+			),
+		),
+	)
+}
@@ -5,8 +5,11 @@ import (
 	"testing"
 
 	"github.com/ankorstore/yokai-contrib/fxgcppubsub"
+	"github.com/ankorstore/yokai-contrib/fxgcppubsub/codec"
 	"github.com/ankorstore/yokai-contrib/fxgcppubsub/reactor/ack"
 	"github.com/ankorstore/yokai-contrib/fxgcppubsub/reactor/log"
+	"github.com/ankorstore/yokai-contrib/fxgcppubsub/subscription/middleware"
+	topicmiddleware "github.com/ankorstore/yokai-contrib/fxgcppubsub/topic/middleware"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -28,3 +31,53 @@ func TestAsPubSubTestServerReactors(t *testing.T) {
 
 	assert.Equal(t, "fx.optionGroup", fmt.Sprintf("%T", result))
 }
+
+func TestAsSubscribeMiddleware(t *testing.T) {
+	t.Parallel()
+
+	result := fxgcppubsub.AsSubscribeMiddleware(middleware.NewRecoveryMiddleware)
+
+	assert.Equal(t, "fx.provideOption", fmt.Sprintf("%T", result))
+}
+
+func TestAsSubscribeMiddlewares(t *testing.T) {
+	t.Parallel()
+
+	result := fxgcppubsub.AsSubscribeMiddlewares(
+		middleware.NewRecoveryMiddleware,
+		middleware.NewLoggerMiddleware,
+	)
+
+	assert.Equal(t, "fx.optionGroup", fmt.Sprintf("%T", result))
+}
+
+func TestAsPublishMiddleware(t *testing.T) {
+	t.Parallel()
+
+	result := fxgcppubsub.AsPublishMiddleware(topicmiddleware.NewLoggerMiddleware)
+
+	assert.Equal(t, "fx.provideOption", fmt.Sprintf("%T", result))
+}
+
+func TestAsPublishMiddlewares(t *testing.T) {
+	t.Parallel()
+
+	result := fxgcppubsub.AsPublishMiddlewares(
+		topicmiddleware.NewLoggerMiddleware,
+		topicmiddleware.NewLoggerMiddleware,
+	)
+
+	assert.Equal(t, "fx.optionGroup", fmt.Sprintf("%T", result))
+}
+
+func TestAsCodec(t *testing.T) {
+	t.Parallel()
+
+	result := fxgcppubsub.AsCodec("json", codec.NewJsonCodec())
+
+	assert.Equal(t, "fx.supplyOption", fmt.Sprintf("%T", result))
+
+	result = fxgcppubsub.AsCodec("json", codec.NewJsonCodec)
+
+	assert.Equal(t, "fx.optionGroup", fmt.Sprintf("%T", result))
+}
@@ -0,0 +1,66 @@
+package schema
+
+import (
+	"fmt"
+
+	"cloud.google.com/go/pubsub"
+	"github.com/hamba/avro/v2"
+)
+
+// Compatibility represents a schema revisions compatibility mode.
+type Compatibility string
+
+const (
+	// CompatibilityNone does not check compatibility.
+	CompatibilityNone Compatibility = "none"
+	// CompatibilityBackward checks that consumers using the new revision can read data produced with the current one.
+	CompatibilityBackward Compatibility = "backward"
+	// CompatibilityForward checks that consumers using the current revision can read data produced with the new one.
+	CompatibilityForward Compatibility = "forward"
+	// CompatibilityFull checks both backward and forward compatibility.
+	CompatibilityFull Compatibility = "full"
+)
+
+// CheckCompatibility checks, for a provided Compatibility mode, if a candidate schema definition is compatible
+// with a current one. Only avro schemas are supported, protocol buffer ones can only be used with CompatibilityNone.
+func CheckCompatibility(schemaType pubsub.SchemaType, currentDefinition string, candidateDefinition string, compatibility Compatibility) error {
+	if compatibility == CompatibilityNone || compatibility == "" {
+		return nil
+	}
+
+	if compatibility != CompatibilityBackward && compatibility != CompatibilityForward && compatibility != CompatibilityFull {
+		return fmt.Errorf("invalid compatibility %q", compatibility)
+	}
+
+	if schemaType != pubsub.SchemaAvro {
+		return fmt.Errorf("compatibility %q check is only supported for avro schemas", compatibility)
+	}
+
+	current, err := avro.ParseBytesWithCache([]byte(currentDefinition), "", &avro.SchemaCache{})
+	if err != nil {
+		return fmt.Errorf("cannot parse current avro schema: %w", err)
+	}
+
+	candidate, err := avro.ParseBytesWithCache([]byte(candidateDefinition), "", &avro.SchemaCache{})
+	if err != nil {
+		return fmt.Errorf("cannot parse candidate avro schema: %w", err)
+	}
+
+	checker := avro.NewSchemaCompatibility()
+
+	if compatibility == CompatibilityBackward || compatibility == CompatibilityFull {
+		err = checker.Compatible(candidate, current)
+		if err != nil {
+			return fmt.Errorf("schema is not backward compatible: %w", err)
+		}
+	}
+
+	if compatibility == CompatibilityForward || compatibility == CompatibilityFull {
+		err = checker.Compatible(current, candidate)
+		if err != nil {
+			return fmt.Errorf("schema is not forward compatible: %w", err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,116 @@
+package schema_test
+
+import (
+	"strings"
+	"testing"
+
+	"cloud.google.com/go/pubsub"
+	"github.com/ankorstore/yokai-contrib/fxgcppubsub/schema"
+	"github.com/ankorstore/yokai-contrib/fxgcppubsub/testdata/avro"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckCompatibility(t *testing.T) {
+	t.Parallel()
+
+	v1 := avro.GetTestAvroSchemaDefinition(t)
+	v2 := avro.GetTestAvroSchemaV2Definition(t)
+	// new field without default
+	v3 := strings.Replace(v2, `"type": "int",
+      "default": 0`, `"type": "int"`, 1)
+
+	tcs := map[string]struct {
+		schemaType    pubsub.SchemaType
+		current       string
+		candidate     string
+		compatibility schema.Compatibility
+		expectedError string
+	}{
+		"none": {
+			schemaType:    pubsub.SchemaProtocolBuffer,
+			current:       "invalid",
+			candidate:     "invalid",
+			compatibility: schema.CompatibilityNone,
+		},
+		"empty": {
+			schemaType: pubsub.SchemaAvro,
+			current:    v1,
+			candidate:  v3,
+		},
+		"invalid compatibility": {
+			schemaType:    pubsub.SchemaAvro,
+			current:       v1,
+			candidate:     v2,
+			compatibility: "invalid",
+			expectedError: `invalid compatibility "invalid"`,
+		},
+		"protocol buffer": {
+			schemaType:    pubsub.SchemaProtocolBuffer,
+			current:       "",
+			candidate:     "",
+			compatibility: schema.CompatibilityBackward,
+			expectedError: `compatibility "backward" check is only supported for avro schemas`,
+		},
+		"invalid current": {
+			schemaType:    pubsub.SchemaAvro,
+			current:       "invalid",
+			candidate:     v2,
+			compatibility: schema.CompatibilityBackward,
+			expectedError: "cannot parse current avro schema",
+		},
+		"invalid candidate": {
+			schemaType:    pubsub.SchemaAvro,
+			current:       v1,
+			candidate:     "invalid",
+			compatibility: schema.CompatibilityBackward,
+			expectedError: "cannot parse candidate avro schema",
+		},
+		"backward compatible": {
+			schemaType:    pubsub.SchemaAvro,
+			current:       v1,
+			candidate:     v2,
+			compatibility: schema.CompatibilityBackward,
+		},
+		"full compatible": {
+			schemaType:    pubsub.SchemaAvro,
+			current:       v1,
+			candidate:     v2,
+			compatibility: schema.CompatibilityFull,
+		},
+		"forward compatible": {
+			schemaType:    pubsub.SchemaAvro,
+			current:       v1,
+			candidate:     v3,
+			compatibility: schema.CompatibilityForward,
+		},
+		"not backward compatible": {
+			schemaType:    pubsub.SchemaAvro,
+			current:       v1,
+			candidate:     v3,
+			compatibility: schema.CompatibilityBackward,
+			expectedError: "schema is not backward compatible",
+		},
+		"not forward compatible": {
+			schemaType:    pubsub.SchemaAvro,
+			current:       v3,
+			candidate:     v1,
+			compatibility: schema.CompatibilityFull,
+			expectedError: "schema is not forward compatible",
+		},
+	}
+
+	for tn, tc := range tcs {
+		tlc := tc
+
+		t.Run(tn, func(t *testing.T) {
+			t.Parallel()
+
+			err := schema.CheckCompatibility(tlc.schemaType, tlc.current, tlc.candidate, tlc.compatibility)
+			if tlc.expectedError == "" {
+				assert.NoError(t, err)
+			} else if assert.Error(t, err) {
+				assert.Contains(t, err.Error(), tlc.expectedError)
+			}
+		})
+	}
+}
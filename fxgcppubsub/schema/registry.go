@@ -4,49 +4,143 @@ import (
 	"context"
 	"fmt"
 	"sync"
+	"time"
 
 	"cloud.google.com/go/pubsub"
+	"golang.org/x/sync/singleflight"
 )
 
 var _ SchemaConfigRegistry = (*DefaultSchemaConfigRegistry)(nil)
 
+// DefaultFetchTimeout is the default timeout of the schema configurations fetches.
+const DefaultFetchTimeout = 10 * time.Second
+
 // SchemaConfigRegistry is the interface for schema config registries.
 type SchemaConfigRegistry interface {
 	Get(ctx context.Context, schemaID string) (*pubsub.SchemaConfig, error)
+	GetRevision(ctx context.Context, schemaID string, revisionID string) (*pubsub.SchemaConfig, error)
+	Invalidate(schemaID string)
 }
 
 // DefaultSchemaConfigRegistry is the default SchemaConfigRegistry implementation.
+//
+// The cache misses are fetched outside the cache lock, once for concurrent callers, and with a timeout independent of
+// the callers contexts cancellation.
 type DefaultSchemaConfigRegistry struct {
-	client  *pubsub.SchemaClient
-	schemas map[string]*pubsub.SchemaConfig
-	mutex   sync.RWMutex
+	client      *pubsub.SchemaClient
+	schemas     map[string]*pubsub.SchemaConfig
+	revisions   map[string]*pubsub.SchemaConfig
+	generations map[string]uint64
+	group       singleflight.Group
+	timeout     time.Duration
+	mutex       sync.RWMutex
 }
 
 // NewDefaultSchemaConfigRegistry returns a new DefaultSchemaConfigRegistry instance.
 func NewDefaultSchemaConfigRegistry(client *pubsub.SchemaClient) *DefaultSchemaConfigRegistry {
 	return &DefaultSchemaConfigRegistry{
-		client:  client,
-		schemas: make(map[string]*pubsub.SchemaConfig),
+		client:      client,
+		schemas:     make(map[string]*pubsub.SchemaConfig),
+		revisions:   make(map[string]*pubsub.SchemaConfig),
+		generations: make(map[string]uint64),
+		timeout:     DefaultFetchTimeout,
 	}
 }
 
-// Get gets a pubsub.SchemaConfig for a provided schemaID.
-func (r *DefaultSchemaConfigRegistry) Get(ctx context.Context, schemaID string) (*pubsub.SchemaConfig, error) {
-	r.mutex.Lock()
-	defer r.mutex.Unlock()
+// WithFetchTimeout sets the timeout of the schema configurations fetches.
+func (r *DefaultSchemaConfigRegistry) WithFetchTimeout(timeout time.Duration) *DefaultSchemaConfigRegistry {
+	r.timeout = timeout
 
+	return r
+}
+
+// Get gets the latest revision pubsub.SchemaConfig for a provided schemaID, cached until Invalidate is called.
+func (r *DefaultSchemaConfigRegistry) Get(ctx context.Context, schemaID string) (*pubsub.SchemaConfig, error) {
 	schemaID = NormalizeSchemaID(schemaID)
 
-	if _, found := r.schemas[schemaID]; found {
-		return r.schemas[schemaID], nil
+	r.mutex.RLock()
+	schema, found := r.schemas[schemaID]
+	generation := r.generations[schemaID]
+	r.mutex.RUnlock()
+
+	if found {
+		return schema, nil
 	}
 
-	schema, err := r.client.Schema(ctx, schemaID, pubsub.SchemaViewFull)
+	schema, err := r.fetch(ctx, fmt.Sprintf("%s#%d", schemaID, generation), schemaID, func(schema *pubsub.SchemaConfig) {
+		// an invalidation during the fetch makes its result stale
+		if r.generations[schemaID] == generation {
+			r.schemas[schemaID] = schema
+		}
+	})
 	if err != nil {
 		return nil, fmt.Errorf("cannot get schema configuration: %w", err)
 	}
 
-	r.schemas[schemaID] = schema
+	return schema, nil
+}
+
+// GetRevision gets the pubsub.SchemaConfig of a provided revisionID for a provided schemaID.
+// Since revisions are immutable, they are cached independently of Invalidate.
+func (r *DefaultSchemaConfigRegistry) GetRevision(ctx context.Context, schemaID string, revisionID string) (*pubsub.SchemaConfig, error) {
+	key := fmt.Sprintf("%s@%s", NormalizeSchemaID(schemaID), revisionID)
+
+	r.mutex.RLock()
+	schema, found := r.revisions[key]
+	r.mutex.RUnlock()
+
+	if found {
+		return schema, nil
+	}
+
+	schema, err := r.fetch(ctx, key, key, func(schema *pubsub.SchemaConfig) {
+		r.revisions[key] = schema
+	})
+	if err != nil {
+		return nil, fmt.Errorf("cannot get schema revision configuration: %w", err)
+	}
 
 	return schema, nil
 }
+
+// Invalidate removes the cached latest revision pubsub.SchemaConfig of a provided schemaID, to be fetched again on next Get.
+func (r *DefaultSchemaConfigRegistry) Invalidate(schemaID string) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	schemaID = NormalizeSchemaID(schemaID)
+
+	delete(r.schemas, schemaID)
+	r.generations[schemaID]++
+}
+
+// fetch fetches the schema configuration of name once for the concurrent callers of the same key, and stores it
+// with store under the cache lock.
+func (r *DefaultSchemaConfigRegistry) fetch(
+	ctx context.Context,
+	key string,
+	name string,
+	store func(schema *pubsub.SchemaConfig),
+) (*pubsub.SchemaConfig, error) {
+	result, err, _ := r.group.Do(key, func() (any, error) {
+		fetchCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), r.timeout)
+		defer cancel()
+
+		schema, err := r.client.Schema(fetchCtx, name, pubsub.SchemaViewFull)
+		if err != nil {
+			return nil, err
+		}
+
+		r.mutex.Lock()
+		store(schema)
+		r.mutex.Unlock()
+
+		return schema, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	//nolint:forcetypeassert
+	return result.(*pubsub.SchemaConfig), nil
+}
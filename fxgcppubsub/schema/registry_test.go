@@ -2,6 +2,7 @@ package schema_test
 
 import (
 	"context"
+	"sync"
 	"testing"
 
 	"cloud.google.com/go/pubsub"
@@ -71,3 +72,118 @@ func TestDefaultSchemaConfigRegistry(t *testing.T) {
 		assert.Equal(t, schemaConfig, schemaConfig2)
 	})
 }
+
+func TestDefaultSchemaConfigRegistryRevisions(t *testing.T) {
+	t.Setenv("APP_ENV", "test")
+	t.Setenv("APP_CONFIG_PATH", "../testdata/config")
+	t.Setenv("GCP_PROJECT_ID", "test-project")
+
+	var client *pubsub.SchemaClient
+	var registry schema.SchemaConfigRegistry
+
+	ctx := context.Background()
+
+	fxtest.New(
+		t,
+		fx.NopLogger,
+		fxconfig.FxConfigModule,
+		fxlog.FxLogModule,
+		fxgcppubsub.FxGcpPubSubModule,
+		fx.Supply(fx.Annotate(ctx, fx.As(new(context.Context)))),
+		fx.Populate(&client, &registry),
+	).RequireStart().RequireStop()
+
+	v1, err := client.CreateSchema(ctx, "test-schema", pubsub.SchemaConfig{
+		Type:       pubsub.SchemaAvro,
+		Definition: "v1",
+	})
+	assert.NoError(t, err)
+
+	latest, err := registry.Get(ctx, "test-schema")
+	assert.NoError(t, err)
+	assert.Equal(t, "v1", latest.Definition)
+
+	v2, err := client.CommitSchema(ctx, "test-schema", pubsub.SchemaConfig{
+		Type:       pubsub.SchemaAvro,
+		Definition: "v2",
+	})
+	assert.NoError(t, err)
+
+	// cached until invalidation
+	latest, err = registry.Get(ctx, "test-schema")
+	assert.NoError(t, err)
+	assert.Equal(t, "v1", latest.Definition)
+
+	registry.Invalidate("projects/test-project/schemas/test-schema")
+
+	latest, err = registry.Get(ctx, "test-schema")
+	assert.NoError(t, err)
+	assert.Equal(t, "v2", latest.Definition)
+	assert.Equal(t, v2.RevisionID, latest.RevisionID)
+
+	// revisions
+	revision, err := registry.GetRevision(ctx, "test-schema", v1.RevisionID)
+	assert.NoError(t, err)
+	assert.Equal(t, "v1", revision.Definition)
+
+	revision, err = registry.GetRevision(ctx, "projects/test-project/schemas/test-schema", v2.RevisionID)
+	assert.NoError(t, err)
+	assert.Equal(t, "v2", revision.Definition)
+
+	_, err = registry.GetRevision(ctx, "test-schema", "invalid")
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "cannot get schema revision configuration")
+}
+
+func TestDefaultSchemaConfigRegistryConcurrency(t *testing.T) {
+	t.Setenv("APP_ENV", "test")
+	t.Setenv("APP_CONFIG_PATH", "../testdata/config")
+	t.Setenv("GCP_PROJECT_ID", "test-project")
+
+	var client *pubsub.SchemaClient
+	var registry schema.SchemaConfigRegistry
+
+	ctx := context.Background()
+
+	fxtest.New(
+		t,
+		fx.NopLogger,
+		fxconfig.FxConfigModule,
+		fxlog.FxLogModule,
+		fxgcppubsub.FxGcpPubSubModule,
+		fx.Supply(fx.Annotate(ctx, fx.As(new(context.Context)))),
+		fx.Populate(&client, &registry),
+	).RequireStart().RequireStop()
+
+	v1, err := client.CreateSchema(ctx, "test-schema", pubsub.SchemaConfig{
+		Type:       pubsub.SchemaAvro,
+		Definition: "v1",
+	})
+	assert.NoError(t, err)
+
+	// a canceled caller context does not cancel the fetch
+	canceledCtx, cancel := context.WithCancel(ctx)
+	cancel()
+
+	var wg sync.WaitGroup
+
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			latest, err := registry.Get(canceledCtx, "test-schema")
+			assert.NoError(t, err)
+			assert.Equal(t, "v1", latest.Definition)
+
+			revision, err := registry.GetRevision(canceledCtx, "test-schema", v1.RevisionID)
+			assert.NoError(t, err)
+			assert.Equal(t, "v1", revision.Definition)
+
+			registry.Invalidate("test-schema")
+		}()
+	}
+
+	wg.Wait()
+}
@@ -0,0 +1,97 @@
+package schema
+
+import (
+	"context"
+	"fmt"
+
+	"cloud.google.com/go/pubsub"
+	"github.com/ankorstore/yokai-contrib/fxgcppubsub/codec"
+)
+
+var _ SchemaRevisionManager = (*DefaultSchemaRevisionManager)(nil)
+
+// SchemaRevisionManager is the interface for schema revisions managers.
+type SchemaRevisionManager interface {
+	Commit(ctx context.Context, schemaID string, definition string, compatibility Compatibility) (*pubsub.SchemaConfig, error)
+	Rollback(ctx context.Context, schemaID string, revisionID string) (*pubsub.SchemaConfig, error)
+}
+
+// DefaultSchemaRevisionManager is the default SchemaRevisionManager implementation.
+type DefaultSchemaRevisionManager struct {
+	client   *pubsub.SchemaClient
+	registry SchemaConfigRegistry
+}
+
+// NewDefaultSchemaRevisionManager returns a new DefaultSchemaRevisionManager instance.
+func NewDefaultSchemaRevisionManager(client *pubsub.SchemaClient, registry SchemaConfigRegistry) *DefaultSchemaRevisionManager {
+	return &DefaultSchemaRevisionManager{
+		client:   client,
+		registry: registry,
+	}
+}
+
+// Commit checks the Compatibility of a definition with the schemaID latest revision, commits it as a new revision,
+// and invalidates the SchemaConfigRegistry cache for the schemaID.
+func (m *DefaultSchemaRevisionManager) Commit(ctx context.Context, schemaID string, definition string, compatibility Compatibility) (*pubsub.SchemaConfig, error) {
+	schemaID = NormalizeSchemaID(schemaID)
+
+	current, err := m.client.Schema(ctx, schemaID, pubsub.SchemaViewFull)
+	if err != nil {
+		return nil, fmt.Errorf("cannot get schema %s: %w", schemaID, err)
+	}
+
+	err = CheckCompatibility(current.Type, current.Definition, definition, compatibility)
+	if err != nil {
+		return nil, fmt.Errorf("cannot commit schema %s: %w", schemaID, err)
+	}
+
+	revision, err := m.client.CommitSchema(ctx, schemaID, pubsub.SchemaConfig{
+		Name:       current.Name,
+		Type:       current.Type,
+		Definition: definition,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("cannot commit schema %s: %w", schemaID, err)
+	}
+
+	m.registry.Invalidate(schemaID)
+
+	return revision, nil
+}
+
+// Rollback creates a new revision of schemaID from a previous revisionID,
+// and invalidates the SchemaConfigRegistry cache for the schemaID.
+func (m *DefaultSchemaRevisionManager) Rollback(ctx context.Context, schemaID string, revisionID string) (*pubsub.SchemaConfig, error) {
+	schemaID = NormalizeSchemaID(schemaID)
+
+	revision, err := m.client.RollbackSchema(ctx, schemaID, revisionID)
+	if err != nil {
+		return nil, fmt.Errorf("cannot rollback schema %s to revision %s: %w", schemaID, revisionID, err)
+	}
+
+	m.registry.Invalidate(schemaID)
+
+	return revision, nil
+}
+
+// RevisionFunc returns a codec.SchemaRevisionFunc resolving the schemaID revisions from a SchemaConfigRegistry.
+func RevisionFunc(ctx context.Context, registry SchemaConfigRegistry, schemaID string) codec.SchemaRevisionFunc {
+	ctx = context.WithoutCancel(ctx)
+
+	return func(revisionID string) (string, string, error) {
+		var schemaConfig *pubsub.SchemaConfig
+		var err error
+
+		if revisionID == "" {
+			schemaConfig, err = registry.Get(ctx, schemaID)
+		} else {
+			schemaConfig, err = registry.GetRevision(ctx, schemaID, revisionID)
+		}
+
+		if err != nil {
+			return "", "", err
+		}
+
+		return schemaConfig.RevisionID, schemaConfig.Definition, nil
+	}
+}
@@ -0,0 +1,114 @@
+package schema_test
+
+import (
+	"context"
+	"testing"
+
+	"cloud.google.com/go/pubsub"
+	"github.com/ankorstore/yokai-contrib/fxgcppubsub"
+	"github.com/ankorstore/yokai-contrib/fxgcppubsub/codec"
+	"github.com/ankorstore/yokai-contrib/fxgcppubsub/schema"
+	"github.com/ankorstore/yokai-contrib/fxgcppubsub/testdata/avro"
+	"github.com/ankorstore/yokai/fxconfig"
+	"github.com/ankorstore/yokai/fxlog"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/fx"
+	"go.uber.org/fx/fxtest"
+)
+
+func TestDefaultSchemaRevisionManager(t *testing.T) {
+	t.Setenv("APP_ENV", "test")
+	t.Setenv("APP_CONFIG_PATH", "../testdata/config")
+	t.Setenv("GCP_PROJECT_ID", "test-project")
+
+	var client *pubsub.SchemaClient
+	var registry schema.SchemaConfigRegistry
+	var manager schema.SchemaRevisionManager
+
+	ctx := context.Background()
+
+	fxtest.New(
+		t,
+		fx.NopLogger,
+		fxconfig.FxConfigModule,
+		fxlog.FxLogModule,
+		fxgcppubsub.FxGcpPubSubModule,
+		fx.Supply(fx.Annotate(ctx, fx.As(new(context.Context)))),
+		fx.Populate(&client, &registry, &manager),
+	).RequireStart().RequireStop()
+
+	v1, err := client.CreateSchema(ctx, "test-schema", pubsub.SchemaConfig{
+		Type:       pubsub.SchemaAvro,
+		Definition: avro.GetTestAvroSchemaDefinition(t),
+	})
+	assert.NoError(t, err)
+
+	revisionFunc := schema.RevisionFunc(ctx, registry, "test-schema")
+
+	revisionID, definition, err := revisionFunc("")
+	assert.NoError(t, err)
+	assert.Equal(t, v1.RevisionID, revisionID)
+	assert.Equal(t, v1.Definition, definition)
+
+	t.Run("commit non existing schema", func(t *testing.T) {
+		_, err := manager.Commit(ctx, "invalid-schema", avro.GetTestAvroSchemaV2Definition(t), schema.CompatibilityBackward)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "cannot get schema invalid-schema")
+	})
+
+	t.Run("commit incompatible revision", func(t *testing.T) {
+		_, err := manager.Commit(ctx, "test-schema", `{"type": "record", "name": "Avro", "fields": [{"name": "Other", "type": "string"}]}`, schema.CompatibilityBackward)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "cannot commit schema test-schema: schema is not backward compatible")
+
+		revisionID, _, err := revisionFunc("")
+		assert.NoError(t, err)
+		assert.Equal(t, v1.RevisionID, revisionID)
+	})
+
+	t.Run("commit compatible revision", func(t *testing.T) {
+		v2, err := manager.Commit(ctx, "projects/test-project/schemas/test-schema", avro.GetTestAvroSchemaV2Definition(t), schema.CompatibilityFull)
+		assert.NoError(t, err)
+		assert.NotEqual(t, v1.RevisionID, v2.RevisionID)
+
+		// registry invalidated
+		revisionID, definition, err := revisionFunc("")
+		assert.NoError(t, err)
+		assert.Equal(t, v2.RevisionID, revisionID)
+		assert.Equal(t, avro.GetTestAvroSchemaV2Definition(t), definition)
+
+		revisionID, definition, err = revisionFunc(v1.RevisionID)
+		assert.NoError(t, err)
+		assert.Equal(t, v1.RevisionID, revisionID)
+		assert.Equal(t, avro.GetTestAvroSchemaDefinition(t), definition)
+	})
+
+	t.Run("rollback revision", func(t *testing.T) {
+		_, err := manager.Rollback(ctx, "test-schema", "invalid")
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "cannot rollback schema test-schema to revision invalid")
+
+		v3, err := manager.Rollback(ctx, "test-schema", v1.RevisionID)
+		assert.NoError(t, err)
+
+		// registry invalidated
+		revisionID, definition, err := revisionFunc("")
+		assert.NoError(t, err)
+		assert.Equal(t, v3.RevisionID, revisionID)
+		assert.Equal(t, avro.GetTestAvroSchemaDefinition(t), definition)
+	})
+
+	t.Run("revision aware codec", func(t *testing.T) {
+		c, err := codec.NewRevisionAwareCodec(codec.NewDefaultCodecFactory(), pubsub.SchemaAvro, pubsub.EncodingBinary, revisionFunc)
+		assert.NoError(t, err)
+
+		enc, err := c.Encode(avro.SimpleRecord{StringField: "test"})
+		assert.NoError(t, err)
+
+		out := avro.SimpleRecord{}
+
+		err = c.DecodeRevision(v1.RevisionID, enc, &out)
+		assert.NoError(t, err)
+		assert.Equal(t, "test", out.StringField)
+	})
+}
@@ -2,9 +2,19 @@ package fxgcppubsub
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strconv"
+	"time"
 
+	"cloud.google.com/go/pubsub"
+	"github.com/ankorstore/yokai-contrib/fxgcppubsub/claimcheck"
+	"github.com/ankorstore/yokai-contrib/fxgcppubsub/message"
+	"github.com/ankorstore/yokai-contrib/fxgcppubsub/push"
 	"github.com/ankorstore/yokai-contrib/fxgcppubsub/subscription"
+	"github.com/ankorstore/yokai-contrib/fxgcppubsub/topic"
+	"github.com/ankorstore/yokai/config"
+	"github.com/ankorstore/yokai/log"
 )
 
 var _ Subscriber = (*DefaultSubscriber)(nil)
@@ -12,29 +22,273 @@ var _ Subscriber = (*DefaultSubscriber)(nil)
 // Subscriber is the interface for high level subscribers.
 type Subscriber interface {
 	Subscribe(ctx context.Context, subscriptionID string, f subscription.SubscribeFunc, options ...subscription.SubscribeOption) error
+	SubscribeWithRetry(ctx context.Context, subscriptionID string, f subscription.SubscribeErrorFunc, options ...subscription.SubscribeOption) error
+	Pull(ctx context.Context, subscriptionID string, maxMessages int, timeout time.Duration) ([]*message.Message, error)
+	PullAndHandle(ctx context.Context, subscriptionID string, maxMessages int, timeout time.Duration, f subscription.SubscribeFunc, options ...subscription.SubscribeOption) (int, error)
+	Flush(ctx context.Context) error
+	PushHandler(subscriptionID string, f subscription.SubscribeFunc, options ...push.PushOption) (*push.PushHandler, error)
 }
 
 // DefaultSubscriber is the default Subscriber implementation.
 type DefaultSubscriber struct {
-	factory  subscription.SubscriptionFactory
-	registry subscription.SubscriptionRegistry
+	factory     subscription.SubscriptionFactory
+	registry    subscription.SubscriptionRegistry
+	publisher   Publisher
+	config      *config.Config
+	middlewares []subscription.Middleware
+	puller      *subscription.Puller
+	blobStore   claimcheck.BlobStore
 }
 
 // NewDefaultSubscriber returns a new DefaultSubscriber instance.
-func NewDefaultSubscriber(factory subscription.SubscriptionFactory, registry subscription.SubscriptionRegistry) *DefaultSubscriber {
+func NewDefaultSubscriber(factory subscription.SubscriptionFactory, registry subscription.SubscriptionRegistry, middlewares ...subscription.Middleware) *DefaultSubscriber {
 	return &DefaultSubscriber{
-		factory:  factory,
-		registry: registry,
+		factory:     factory,
+		registry:    registry,
+		middlewares: middlewares,
 	}
 }
 
+// WithPublisher sets the Publisher used to republish messages on dead-letter topics, and returns the subscriber.
+func (s *DefaultSubscriber) WithPublisher(publisher Publisher) *DefaultSubscriber {
+	s.publisher = publisher
+
+	return s
+}
+
+// WithConfig sets the config.Config used to resolve the subscriptions retry policies and the push authentication,
+// and returns the subscriber.
+func (s *DefaultSubscriber) WithConfig(config *config.Config) *DefaultSubscriber {
+	s.config = config
+
+	return s
+}
+
+// WithBlobStore sets the claimcheck.BlobStore used to fetch the claim-checked payloads, and returns the subscriber.
+//
+// The claim-checked payloads are then fetched before all the other middlewares, and on the messages returned by Pull.
+func (s *DefaultSubscriber) WithBlobStore(store claimcheck.BlobStore) *DefaultSubscriber {
+	s.blobStore = store
+	s.middlewares = append([]subscription.Middleware{claimcheck.NewSubscribeMiddleware(store)}, s.middlewares...)
+
+	return s
+}
+
+// WithPullClient sets the subscription.PullClient used to pull messages, and returns the subscriber.
+func (s *DefaultSubscriber) WithPullClient(client subscription.PullClient) *DefaultSubscriber {
+	s.puller = subscription.NewPuller(client)
+
+	return s
+}
+
 // Subscribe handle received data using a subscription.SubscribeFunc, with options, from a given subscriptionID.
+//
+// The registered global middlewares are applied before the ones provided with subscription.WithMiddlewares.
 func (s *DefaultSubscriber) Subscribe(ctx context.Context, subscriptionID string, f subscription.SubscribeFunc, options ...subscription.SubscribeOption) error {
 	// retrieve subscription
+	sub, err := s.Subscription(ctx, subscriptionID)
+	if err != nil {
+		return err
+	}
+
+	// subscribe
+	options = append([]subscription.SubscribeOption{subscription.WithMiddlewares(s.middlewares...)}, options...)
+
+	return sub.WithOptions(options...).Subscribe(ctx, f)
+}
+
+// SubscribeWithRetry handle received data using a subscription.SubscribeErrorFunc, with options, from a given subscriptionID.
+//
+// Messages are automatically acked on success. On failure, they are retried in process with backoff according to the
+// subscription.RetryPolicy configured for the subscriptionID, and then republished on the dead-letter topic once the
+// max attempts are reached. Without dead-letter topic, they are nacked, and the subscription must have a dead-letter
+// policy to bound their redeliveries: subscription.ErrNoDeadLetter is returned otherwise.
+//
+// The messages lease is extended until the end of their retries, unless overridden with subscription.WithMaxExtension.
+func (s *DefaultSubscriber) SubscribeWithRetry(ctx context.Context, subscriptionID string, f subscription.SubscribeErrorFunc, options ...subscription.SubscribeOption) error {
+	policy := subscription.DefaultRetryPolicy()
+	if s.config != nil {
+		policy = subscription.NewRetryPolicyFromConfig(s.config, subscriptionID)
+	}
+
+	// retrieve subscription
+	sub, err := s.Subscription(ctx, subscriptionID)
+	if err != nil {
+		return err
+	}
+
+	// check dead-letter
+	if policy.DeadLetterTopicID != "" {
+		if s.publisher == nil {
+			return fmt.Errorf("cannot subscribe with retry on subscription %s: no dead-letter publisher configured", subscriptionID)
+		}
+	} else {
+		subscriptionConfig, err := sub.BaseSubscription().Config(ctx)
+		if err != nil {
+			return fmt.Errorf("cannot get subscription %s configuration: %w", subscriptionID, err)
+		}
+
+		if subscriptionConfig.DeadLetterPolicy == nil {
+			return fmt.Errorf(
+				"cannot subscribe with retry on subscription %s: %w, see modules.gcppubsub.subscriptions.%s.retry.dead_letter.topic",
+				subscriptionID,
+				subscription.ErrNoDeadLetter,
+				subscriptionID,
+			)
+		}
+	}
+
+	// extend the lease during the retries
+	if maxExtension := policy.TotalBackoff() + pubsub.DefaultReceiveSettings.MaxExtension; maxExtension > pubsub.DefaultReceiveSettings.MaxExtension {
+		options = append([]subscription.SubscribeOption{subscription.WithMaxExtension(maxExtension)}, options...)
+	}
+
+	return s.Subscribe(ctx, subscriptionID, s.retryFunc(subscriptionID, policy, f), options...)
+}
+
+// Pull synchronously pulls up to maxMessages messages from a given subscriptionID, waiting up to timeout for messages
+// to be available, and returns them decoded with the subscription codec.
+//
+// The returned messages must be explicitly acked, nacked, or have their ack deadline modified: their acks are batched,
+// use Flush to send them before exiting. The subscribe
+// middlewares are not applied: use PullAndHandle to apply them. If a claimcheck.BlobStore is configured, the
+// claim-checked payloads are fetched, and the messages which payload cannot be fetched are nacked and not returned.
+func (s *DefaultSubscriber) Pull(ctx context.Context, subscriptionID string, maxMessages int, timeout time.Duration) ([]*message.Message, error) {
+	if s.puller == nil {
+		return nil, errors.New("cannot pull messages: no pull client configured")
+	}
+
+	// retrieve subscription
+	sub, err := s.Subscription(ctx, subscriptionID)
+	if err != nil {
+		return nil, err
+	}
+
+	// pull
+	messages, err := s.puller.Pull(ctx, sub, maxMessages, timeout)
+	if err != nil || s.blobStore == nil {
+		return messages, err
+	}
+
+	// fetch claim-checked payloads
+	fetched := make([]*message.Message, 0, len(messages))
+
+	for _, m := range messages {
+		err = claimcheck.Fetch(ctx, s.blobStore, m)
+		if err != nil {
+			log.CtxLogger(ctx).
+				Error().
+				Err(err).
+				Str("subscriptionID", subscriptionID).
+				Str("messageID", m.ID()).
+				Msg("pubsub message claim-check error")
+
+			m.Nack()
+
+			continue
+		}
+
+		fetched = append(fetched, m)
+	}
+
+	return fetched, nil
+}
+
+// PullAndHandle synchronously pulls up to maxMessages messages from a given subscriptionID, waiting up to timeout for
+// messages to be available, and handles them one by one, in pull order, using a subscription.SubscribeFunc, with options.
+// It returns the number of handled messages.
+//
+// The registered global middlewares are applied before the ones provided with subscription.WithMiddlewares, as for Subscribe.
+// The acks of the handled messages are sent before returning.
+func (s *DefaultSubscriber) PullAndHandle(
+	ctx context.Context,
+	subscriptionID string,
+	maxMessages int,
+	timeout time.Duration,
+	f subscription.SubscribeFunc,
+	options ...subscription.SubscribeOption,
+) (int, error) {
+	if s.puller == nil {
+		return 0, errors.New("cannot pull messages: no pull client configured")
+	}
+
+	// retrieve subscription
+	sub, err := s.Subscription(ctx, subscriptionID)
+	if err != nil {
+		return 0, err
+	}
+
+	// pull
+	messages, err := s.puller.Pull(ctx, sub, maxMessages, timeout)
+	if err != nil {
+		return 0, err
+	}
+
+	// handle
+	options = append([]subscription.SubscribeOption{subscription.WithMiddlewares(s.middlewares...)}, options...)
+
+	h := subscription.Chain(f, sub.WithOptions(options...).Options().Middlewares...)
+
+	hCtx := subscription.ContextWithSubscriptionID(ctx, subscriptionID)
+
+	for _, m := range messages {
+		h(hCtx, m)
+	}
+
+	// ack
+	err = s.puller.Flush(ctx)
+	if err != nil {
+		return len(messages), fmt.Errorf("cannot acknowledge pulled messages: %w", err)
+	}
+
+	return len(messages), nil
+}
+
+// Flush synchronously sends the pending acks of the messages returned by Pull, which are otherwise batched during
+// subscription.DefaultPullAckDelay. It's called on application shutdown, and should be called before exiting a job.
+func (s *DefaultSubscriber) Flush(ctx context.Context) error {
+	if s.puller == nil {
+		return nil
+	}
+
+	err := s.puller.Flush(ctx)
+	if err != nil {
+		return fmt.Errorf("cannot acknowledge pulled messages: %w", err)
+	}
+
+	return nil
+}
+
+// PushHandler returns a push.PushHandler, handling the messages pushed by a given subscriptionID using a
+// subscription.SubscribeFunc, with options.
+//
+// The registered global middlewares are applied before the ones provided with push.WithMiddlewares. If enabled in
+// config, the push requests OIDC tokens are verified, unless a verifier is provided with push.WithVerifier: the
+// audience is then required.
+func (s *DefaultSubscriber) PushHandler(subscriptionID string, f subscription.SubscribeFunc, options ...push.PushOption) (*push.PushHandler, error) {
+	defaultOptions := []push.PushOption{push.WithMiddlewares(s.middlewares...)}
+
+	if s.config != nil && s.config.GetBool("modules.gcppubsub.push.auth.enabled") {
+		audience := s.config.GetString("modules.gcppubsub.push.auth.audience")
+		if audience == "" {
+			return nil, errors.New("cannot create push handler: push authentication is enabled without modules.gcppubsub.push.auth.audience")
+		}
+
+		defaultOptions = append(defaultOptions, push.WithVerifier(push.NewIDTokenVerifier(
+			audience,
+			s.config.GetString("modules.gcppubsub.push.auth.email"),
+		)))
+	}
+
+	return push.NewPushHandler(s, subscriptionID, f, append(defaultOptions, options...)...), nil
+}
+
+// Subscription returns the subscription.Subscription for a given subscriptionID, created and registered if needed.
+func (s *DefaultSubscriber) Subscription(ctx context.Context, subscriptionID string) (*subscription.Subscription, error) {
 	if !s.registry.Has(subscriptionID) {
 		sub, err := s.factory.Create(ctx, subscriptionID)
 		if err != nil {
-			return fmt.Errorf("cannot create subscription: %w", err)
+			return nil, fmt.Errorf("cannot create subscription: %w", err)
 		}
 
 		s.registry.Add(sub)
@@ -42,9 +296,129 @@ func (s *DefaultSubscriber) Subscribe(ctx context.Context, subscriptionID string
 
 	sub, err := s.registry.Get(subscriptionID)
 	if err != nil {
-		return fmt.Errorf("cannot get subscription: %w", err)
+		return nil, fmt.Errorf("cannot get subscription: %w", err)
 	}
 
-	// subscribe
-	return sub.WithOptions(options...).Subscribe(ctx, f)
+	return sub, nil
+}
+
+func (s *DefaultSubscriber) retryFunc(subscriptionID string, policy subscription.RetryPolicy, f subscription.SubscribeErrorFunc) subscription.SubscribeFunc {
+	return func(ctx context.Context, m *message.Message) {
+		// when provided by the server, the delivery attempt already counts the previous deliveries:
+		// the failures are then retried by redelivery only, to not add up with in process retries
+		delivery := 0
+		if m.DeliveryAttempt() != nil {
+			delivery = *m.DeliveryAttempt()
+		}
+
+		retries := 0
+
+		for {
+			err := f(ctx, m)
+			if err == nil {
+				m.Ack()
+
+				return
+			}
+
+			attempt := max(delivery, 1) + retries
+
+			if attempt >= policy.MaxAttempts {
+				s.deadLetter(ctx, subscriptionID, policy, m, attempt, err)
+
+				return
+			}
+
+			logger := log.CtxLogger(ctx)
+
+			if delivery > 0 {
+				logger.
+					Warn().
+					Err(err).
+					Str("subscriptionID", subscriptionID).
+					Str("messageID", m.ID()).
+					Int("attempt", attempt).
+					Msg("pubsub message processing error, nacked for redelivery")
+
+				m.Nack()
+
+				return
+			}
+
+			logger.
+				Warn().
+				Err(err).
+				Str("subscriptionID", subscriptionID).
+				Str("messageID", m.ID()).
+				Int("attempt", attempt).
+				Msg("pubsub message processing error, retrying")
+
+			select {
+			case <-ctx.Done():
+				m.Nack()
+
+				return
+			case <-time.After(policy.Backoff(attempt)):
+				retries++
+			}
+		}
+	}
+}
+
+func (s *DefaultSubscriber) deadLetter(ctx context.Context, subscriptionID string, policy subscription.RetryPolicy, m *message.Message, attempt int, processingErr error) {
+	logger := log.CtxLogger(ctx)
+
+	if policy.DeadLetterTopicID == "" {
+		logger.
+			Error().
+			Err(processingErr).
+			Str("subscriptionID", subscriptionID).
+			Str("messageID", m.ID()).
+			Int("attempt", attempt).
+			Msg("pubsub message processing error, max attempts reached, nacked for the subscription dead-letter policy")
+
+		m.Nack()
+
+		return
+	}
+
+	attributes := make(map[string]string, len(m.Attributes())+4)
+	for k, v := range m.Attributes() {
+		attributes[k] = v
+	}
+
+	attributes[subscription.DeadLetterErrorAttribute] = processingErr.Error()
+	attributes[subscription.DeadLetterSubscriptionAttribute] = subscriptionID
+	attributes[subscription.DeadLetterMessageIDAttribute] = m.ID()
+	attributes[subscription.DeadLetterDeliveryAttemptAttribute] = strconv.Itoa(attempt)
+
+	res, err := s.publisher.Publish(ctx, policy.DeadLetterTopicID, m.Data(), topic.WithMessageAttributes(attributes))
+	if err == nil {
+		_, err = res.Get(ctx)
+	}
+
+	if err != nil {
+		logger.
+			Error().
+			Err(err).
+			Str("subscriptionID", subscriptionID).
+			Str("messageID", m.ID()).
+			Str("topicID", policy.DeadLetterTopicID).
+			Msg("pubsub message dead-letter publication error")
+
+		m.Nack()
+
+		return
+	}
+
+	logger.
+		Warn().
+		Err(processingErr).
+		Str("subscriptionID", subscriptionID).
+		Str("messageID", m.ID()).
+		Str("topicID", policy.DeadLetterTopicID).
+		Int("attempt", attempt).
+		Msg("pubsub message processing error, max attempts reached, sent to dead-letter topic")
+
+	m.Ack()
 }
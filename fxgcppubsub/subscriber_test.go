@@ -2,13 +2,29 @@ package fxgcppubsub_test
 
 import (
 	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
+	"cloud.google.com/go/pubsub"
+	"cloud.google.com/go/pubsub/pstest"
 	"github.com/ankorstore/yokai-contrib/fxgcppubsub"
 	"github.com/ankorstore/yokai-contrib/fxgcppubsub/message"
+	"github.com/ankorstore/yokai-contrib/fxgcppubsub/push"
+	"github.com/ankorstore/yokai-contrib/fxgcppubsub/reactor/ack"
 	"github.com/ankorstore/yokai-contrib/fxgcppubsub/subscription"
+	"github.com/ankorstore/yokai-contrib/fxgcppubsub/topic"
+	"github.com/ankorstore/yokai/fxconfig"
+	"github.com/ankorstore/yokai/fxlog"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"go.uber.org/fx"
+	"go.uber.org/fx/fxtest"
 )
 
 type subscriptionFactoryMock struct {
@@ -103,4 +119,545 @@ func TestSubscriber(t *testing.T) {
 		sfm.AssertExpectations(t)
 		srm.AssertExpectations(t)
 	})
+
+	t.Run("pull without pull client", func(t *testing.T) {
+		t.Parallel()
+
+		sfm := new(subscriptionFactoryMock)
+		sfm.AssertNotCalled(t, "Create")
+
+		srm := new(subscriptionRegistryMock)
+		srm.AssertNotCalled(t, "Has")
+
+		subscriber := fxgcppubsub.NewDefaultSubscriber(sfm, srm)
+
+		messages, err := subscriber.Pull(ctx, "test-subscription", 10, time.Second)
+		assert.Nil(t, messages)
+		assert.Error(t, err)
+		assert.Equal(t, "cannot pull messages: no pull client configured", err.Error())
+	})
+}
+
+func TestSubscriberWithRetry(t *testing.T) {
+	t.Setenv("APP_ENV", "test")
+	t.Setenv("APP_CONFIG_PATH", "testdata/config")
+	t.Setenv("GCP_PROJECT_ID", "test-project")
+
+	runTest := func(tb testing.TB) (context.Context, fxgcppubsub.Publisher, fxgcppubsub.Subscriber, ack.AckSupervisor) {
+		tb.Helper()
+
+		var publisher fxgcppubsub.Publisher
+		var subscriber fxgcppubsub.Subscriber
+		var supervisor ack.AckSupervisor
+
+		ctx := context.Background()
+
+		fxtest.New(
+			tb,
+			fx.NopLogger,
+			fxconfig.FxConfigModule,
+			fxlog.FxLogModule,
+			fxgcppubsub.FxGcpPubSubModule,
+			fx.Supply(fx.Annotate(ctx, fx.As(new(context.Context)))),
+			fxgcppubsub.PrepareTopicAndSubscription(fxgcppubsub.PrepareTopicAndSubscriptionParams{
+				TopicID:        "retry-topic",
+				SubscriptionID: "retry-subscription",
+			}),
+			fxgcppubsub.PrepareTopicAndSubscription(fxgcppubsub.PrepareTopicAndSubscriptionParams{
+				TopicID:        "dead-letter-topic",
+				SubscriptionID: "dead-letter-subscription",
+			}),
+			fxgcppubsub.PrepareTopicAndSubscription(fxgcppubsub.PrepareTopicAndSubscriptionParams{
+				TopicID:        "no-dead-letter-topic",
+				SubscriptionID: "no-dead-letter-subscription",
+				SubscriptionConfig: pubsub.SubscriptionConfig{
+					DeadLetterPolicy: &pubsub.DeadLetterPolicy{
+						DeadLetterTopic:     "projects/test-project/topics/dead-letter-topic",
+						MaxDeliveryAttempts: 5,
+					},
+				},
+			}),
+			fxgcppubsub.PrepareTopicAndSubscription(fxgcppubsub.PrepareTopicAndSubscriptionParams{
+				TopicID:        "redelivery-topic",
+				SubscriptionID: "redelivery-subscription",
+				SubscriptionConfig: pubsub.SubscriptionConfig{
+					DeadLetterPolicy: &pubsub.DeadLetterPolicy{
+						DeadLetterTopic:     "projects/test-project/topics/dead-letter-topic",
+						MaxDeliveryAttempts: 5,
+					},
+				},
+			}),
+			fxgcppubsub.PrepareTopicAndSubscription(fxgcppubsub.PrepareTopicAndSubscriptionParams{
+				TopicID:        "test-topic",
+				SubscriptionID: "test-subscription",
+			}),
+			fx.Populate(&publisher, &subscriber, &supervisor),
+		).RequireStart().RequireStop()
+
+		return ctx, publisher, subscriber, supervisor
+	}
+
+	t.Run("message ack after retry", func(t *testing.T) {
+		ctx, publisher, subscriber, supervisor := runTest(t)
+
+		_, err := publisher.Publish(ctx, "retry-topic", []byte("test"))
+		assert.NoError(t, err)
+
+		waiter := supervisor.StartAckWaiter("retry-subscription")
+
+		var attempts atomic.Int32
+
+		//nolint:errcheck
+		go subscriber.SubscribeWithRetry(ctx, "retry-subscription", func(ctx context.Context, m *message.Message) error {
+			if attempts.Add(1) < 2 {
+				return fmt.Errorf("test error")
+			}
+
+			assert.Equal(t, []byte("test"), m.Data())
+
+			return nil
+		})
+
+		_, err = waiter.WaitMaxDuration(ctx, 2*time.Second)
+		assert.NoError(t, err)
+
+		assert.Equal(t, int32(2), attempts.Load())
+	})
+
+	t.Run("message sent to dead-letter topic after max attempts", func(t *testing.T) {
+		ctx, publisher, subscriber, supervisor := runTest(t)
+
+		_, err := publisher.Publish(ctx, "retry-topic", []byte("test"), topic.WithMessageAttributes(map[string]string{
+			"foo": "bar",
+		}))
+		assert.NoError(t, err)
+
+		var attempts atomic.Int32
+
+		//nolint:errcheck
+		go subscriber.SubscribeWithRetry(ctx, "retry-subscription", func(ctx context.Context, m *message.Message) error {
+			attempts.Add(1)
+
+			return fmt.Errorf("test error")
+		})
+
+		waiter := supervisor.StartAckWaiter("dead-letter-subscription")
+
+		var deadLetterMessage *message.Message
+
+		//nolint:errcheck
+		go subscriber.Subscribe(ctx, "dead-letter-subscription", func(ctx context.Context, m *message.Message) {
+			deadLetterMessage = m
+
+			m.Ack()
+		})
+
+		_, err = waiter.WaitMaxDuration(ctx, 2*time.Second)
+		assert.NoError(t, err)
+
+		assert.Equal(t, int32(3), attempts.Load())
+		assert.Equal(t, []byte("test"), deadLetterMessage.Data())
+		assert.Equal(t, "bar", deadLetterMessage.Attributes()["foo"])
+		assert.Equal(t, "test error", deadLetterMessage.Attributes()[subscription.DeadLetterErrorAttribute])
+		assert.Equal(t, "retry-subscription", deadLetterMessage.Attributes()[subscription.DeadLetterSubscriptionAttribute])
+		assert.NotEmpty(t, deadLetterMessage.Attributes()[subscription.DeadLetterMessageIDAttribute])
+		assert.Equal(t, "3", deadLetterMessage.Attributes()[subscription.DeadLetterDeliveryAttemptAttribute])
+	})
+
+	t.Run("message nack after max attempts with subscription dead-letter policy", func(t *testing.T) {
+		ctx, publisher, subscriber, supervisor := runTest(t)
+
+		_, err := publisher.Publish(ctx, "no-dead-letter-topic", []byte("test"))
+		assert.NoError(t, err)
+
+		waiter := supervisor.StartNackWaiter("no-dead-letter-subscription")
+
+		//nolint:errcheck
+		go subscriber.SubscribeWithRetry(ctx, "no-dead-letter-subscription", func(ctx context.Context, m *message.Message) error {
+			return fmt.Errorf("test error")
+		})
+
+		_, err = waiter.WaitMaxDuration(ctx, 2*time.Second)
+		assert.NoError(t, err)
+	})
+
+	t.Run("message redelivered with server delivery attempts until max attempts", func(t *testing.T) {
+		ctx, publisher, subscriber, supervisor := runTest(t)
+
+		_, err := publisher.Publish(ctx, "redelivery-topic", []byte("test"))
+		assert.NoError(t, err)
+
+		var mutex sync.Mutex
+		var deliveryAttempts []int
+
+		//nolint:errcheck
+		go subscriber.SubscribeWithRetry(ctx, "redelivery-subscription", func(ctx context.Context, m *message.Message) error {
+			mutex.Lock()
+			defer mutex.Unlock()
+
+			if assert.NotNil(t, m.DeliveryAttempt()) {
+				deliveryAttempts = append(deliveryAttempts, *m.DeliveryAttempt())
+			}
+
+			// let the client send the receipt modack before the nack, as the test server reuses the ack ids
+			time.Sleep(150 * time.Millisecond)
+
+			return fmt.Errorf("test error")
+		})
+
+		waiter := supervisor.StartAckWaiter("dead-letter-subscription")
+
+		var deadLetterMessage *message.Message
+
+		//nolint:errcheck
+		go subscriber.Subscribe(ctx, "dead-letter-subscription", func(ctx context.Context, m *message.Message) {
+			deadLetterMessage = m
+
+			m.Ack()
+		})
+
+		_, err = waiter.WaitMaxDuration(ctx, 5*time.Second)
+		assert.NoError(t, err)
+
+		mutex.Lock()
+		defer mutex.Unlock()
+
+		// redeliveries starting with a delivery attempt greater than 1 are not retried in process
+		assert.Equal(t, []int{1, 2, 3}, deliveryAttempts)
+		assert.Equal(t, "redelivery-subscription", deadLetterMessage.Attributes()[subscription.DeadLetterSubscriptionAttribute])
+		assert.Equal(t, "3", deadLetterMessage.Attributes()[subscription.DeadLetterDeliveryAttemptAttribute])
+	})
+
+	t.Run("error without dead-letter topic nor dead-letter policy", func(t *testing.T) {
+		ctx, _, subscriber, _ := runTest(t)
+
+		err := subscriber.SubscribeWithRetry(ctx, "test-subscription", func(ctx context.Context, m *message.Message) error {
+			return nil
+		})
+		assert.Error(t, err)
+		assert.ErrorIs(t, err, subscription.ErrNoDeadLetter)
+		assert.Contains(t, err.Error(), "modules.gcppubsub.subscriptions.test-subscription.retry.dead_letter.topic")
+	})
+}
+
+type testMiddleware struct {
+	name  string
+	calls *[]string
+}
+
+func (mw *testMiddleware) Wrap(next subscription.SubscribeFunc) subscription.SubscribeFunc {
+	return func(ctx context.Context, m *message.Message) {
+		*mw.calls = append(*mw.calls, mw.name)
+
+		next(ctx, m)
+	}
+}
+
+func TestSubscriberWithMiddlewares(t *testing.T) {
+	t.Setenv("APP_ENV", "test")
+	t.Setenv("APP_CONFIG_PATH", "testdata/config")
+	t.Setenv("GCP_PROJECT_ID", "test-project")
+
+	var publisher fxgcppubsub.Publisher
+	var subscriber fxgcppubsub.Subscriber
+	var supervisor ack.AckSupervisor
+
+	var calls []string
+
+	ctx := context.Background()
+
+	fxtest.New(
+		t,
+		fx.NopLogger,
+		fxconfig.FxConfigModule,
+		fxlog.FxLogModule,
+		fxgcppubsub.FxGcpPubSubModule,
+		fx.Supply(fx.Annotate(ctx, fx.As(new(context.Context)))),
+		fxgcppubsub.AsSubscribeMiddleware(func() *testMiddleware {
+			return &testMiddleware{name: "global", calls: &calls}
+		}),
+		fxgcppubsub.PrepareTopicAndSubscription(fxgcppubsub.PrepareTopicAndSubscriptionParams{
+			TopicID:        "test-topic",
+			SubscriptionID: "test-subscription",
+		}),
+		fx.Populate(&publisher, &subscriber, &supervisor),
+	).RequireStart().RequireStop()
+
+	_, err := publisher.Publish(ctx, "test-topic", []byte("test"))
+	assert.NoError(t, err)
+
+	waiter := supervisor.StartAckWaiter("test-subscription")
+
+	//nolint:errcheck
+	go subscriber.Subscribe(
+		ctx,
+		"test-subscription",
+		func(ctx context.Context, m *message.Message) {
+			assert.Equal(t, "test-subscription", subscription.CtxSubscriptionID(ctx))
+
+			calls = append(calls, "handler")
+
+			m.Ack()
+		},
+		subscription.WithMiddlewares(&testMiddleware{name: "local", calls: &calls}),
+	)
+
+	_, err = waiter.WaitMaxDuration(ctx, 2*time.Second)
+	assert.NoError(t, err)
+
+	assert.Equal(t, []string{"global", "local", "handler"}, calls)
+}
+
+func TestSubscriberPull(t *testing.T) {
+	t.Setenv("APP_ENV", "test")
+	t.Setenv("APP_CONFIG_PATH", "testdata/config")
+	t.Setenv("GCP_PROJECT_ID", "test-project")
+
+	var publisher fxgcppubsub.Publisher
+	var subscriber fxgcppubsub.Subscriber
+	var supervisor ack.AckSupervisor
+	var server *pstest.Server
+
+	ctx := context.Background()
+
+	fxtest.New(
+		t,
+		fx.NopLogger,
+		fxconfig.FxConfigModule,
+		fxlog.FxLogModule,
+		fxgcppubsub.FxGcpPubSubModule,
+		fx.Supply(fx.Annotate(ctx, fx.As(new(context.Context)))),
+		fxgcppubsub.PrepareTopicAndSubscription(fxgcppubsub.PrepareTopicAndSubscriptionParams{
+			TopicID:        "pull-topic",
+			SubscriptionID: "pull-subscription",
+		}),
+		fx.Populate(&publisher, &subscriber, &supervisor, &server),
+	).RequireStart().RequireStop()
+
+	t.Run("pull messages", func(t *testing.T) {
+		for i := 1; i <= 3; i++ {
+			res, err := publisher.Publish(ctx, "pull-topic", []byte(fmt.Sprintf("test-%d", i)))
+			assert.NoError(t, err)
+
+			_, err = res.Get(ctx)
+			assert.NoError(t, err)
+		}
+
+		messages, err := subscriber.Pull(ctx, "pull-subscription", 10, time.Second)
+		assert.NoError(t, err)
+		assert.Len(t, messages, 3)
+
+		// the test server does not guarantee the pull order
+		var pulled []string
+
+		for _, m := range messages {
+			pulled = append(pulled, string(m.Data()))
+
+			waiter := supervisor.StartAckWaiter("pull-subscription")
+
+			m.Ack()
+
+			_, err = waiter.WaitMaxDuration(ctx, time.Second)
+			assert.NoError(t, err)
+		}
+
+		assert.ElementsMatch(t, []string{"test-1", "test-2", "test-3"}, pulled)
+
+		messages, err = subscriber.Pull(ctx, "pull-subscription", 10, 100*time.Millisecond)
+		assert.NoError(t, err)
+		assert.Empty(t, messages)
+	})
+
+	t.Run("pull messages and flush acks", func(t *testing.T) {
+		res, err := publisher.Publish(ctx, "pull-topic", []byte("test"))
+		assert.NoError(t, err)
+
+		id, err := res.Get(ctx)
+		assert.NoError(t, err)
+
+		messages, err := subscriber.Pull(ctx, "pull-subscription", 10, time.Second)
+		assert.NoError(t, err)
+		assert.Len(t, messages, 1)
+
+		messages[0].Ack()
+
+		err = subscriber.Flush(ctx)
+		assert.NoError(t, err)
+
+		assert.Equal(t, 1, server.Message(id).Acks)
+	})
+
+	t.Run("pull from unknown subscription", func(t *testing.T) {
+		_, err := subscriber.Pull(ctx, "unknown-subscription", 10, time.Second)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "cannot create subscription")
+	})
+}
+
+func TestSubscriberPullAndHandle(t *testing.T) {
+	t.Setenv("APP_ENV", "test")
+	t.Setenv("APP_CONFIG_PATH", "testdata/config")
+	t.Setenv("GCP_PROJECT_ID", "test-project")
+
+	var publisher fxgcppubsub.Publisher
+	var subscriber fxgcppubsub.Subscriber
+	var server *pstest.Server
+
+	var calls []string
+
+	ctx := context.Background()
+
+	fxtest.New(
+		t,
+		fx.NopLogger,
+		fxconfig.FxConfigModule,
+		fxlog.FxLogModule,
+		fxgcppubsub.FxGcpPubSubModule,
+		fx.Supply(fx.Annotate(ctx, fx.As(new(context.Context)))),
+		fxgcppubsub.AsSubscribeMiddleware(func() *testMiddleware {
+			return &testMiddleware{name: "global", calls: &calls}
+		}),
+		fxgcppubsub.PrepareTopicAndSubscription(fxgcppubsub.PrepareTopicAndSubscriptionParams{
+			TopicID:        "pull-topic",
+			SubscriptionID: "pull-subscription",
+		}),
+		fx.Populate(&publisher, &subscriber, &server),
+	).RequireStart().RequireStop()
+
+	t.Run("pull and handle messages with middlewares", func(t *testing.T) {
+		var ids []string
+
+		for i := 1; i <= 2; i++ {
+			res, err := publisher.Publish(ctx, "pull-topic", []byte(fmt.Sprintf("test-%d", i)))
+			assert.NoError(t, err)
+
+			id, err := res.Get(ctx)
+			assert.NoError(t, err)
+
+			ids = append(ids, id)
+		}
+
+		var handled []string
+
+		count, err := subscriber.PullAndHandle(
+			ctx,
+			"pull-subscription",
+			10,
+			time.Second,
+			func(ctx context.Context, m *message.Message) {
+				assert.Equal(t, "pull-subscription", subscription.CtxSubscriptionID(ctx))
+
+				calls = append(calls, "handler")
+				handled = append(handled, string(m.Data()))
+
+				m.Ack()
+			},
+			subscription.WithMiddlewares(&testMiddleware{name: "local", calls: &calls}),
+		)
+		assert.NoError(t, err)
+		assert.Equal(t, 2, count)
+
+		assert.ElementsMatch(t, []string{"test-1", "test-2"}, handled)
+		assert.Equal(t, []string{"global", "local", "handler", "global", "local", "handler"}, calls)
+
+		// acks already sent when returning
+		for _, id := range ids {
+			assert.Equal(t, 1, server.Message(id).Acks)
+		}
+	})
+
+	t.Run("pull and handle from unknown subscription", func(t *testing.T) {
+		count, err := subscriber.PullAndHandle(ctx, "unknown-subscription", 10, time.Second, func(context.Context, *message.Message) {})
+		assert.Error(t, err)
+		assert.Equal(t, 0, count)
+		assert.Contains(t, err.Error(), "cannot create subscription")
+	})
+}
+
+func TestSubscriberPushHandler(t *testing.T) {
+	runTest := func(tb testing.TB) fxgcppubsub.Subscriber {
+		tb.Helper()
+
+		var subscriber fxgcppubsub.Subscriber
+
+		ctx := context.Background()
+
+		fxtest.New(
+			tb,
+			fx.NopLogger,
+			fxconfig.FxConfigModule,
+			fxlog.FxLogModule,
+			fxgcppubsub.FxGcpPubSubModule,
+			fx.Supply(fx.Annotate(ctx, fx.As(new(context.Context)))),
+			fxgcppubsub.PrepareTopicAndSubscription(fxgcppubsub.PrepareTopicAndSubscriptionParams{
+				TopicID:        "push-topic",
+				SubscriptionID: "push-subscription",
+			}),
+			fx.Populate(&subscriber),
+		).RequireStart().RequireStop()
+
+		return subscriber
+	}
+
+	t.Run("push handler", func(t *testing.T) {
+		t.Setenv("APP_ENV", "test")
+		t.Setenv("APP_CONFIG_PATH", "testdata/config")
+		t.Setenv("GCP_PROJECT_ID", "test-project")
+
+		subscriber := runTest(t)
+
+		handler, err := subscriber.PushHandler("push-subscription", func(ctx context.Context, m *message.Message) {
+			assert.Equal(t, []byte("test"), m.Data())
+
+			m.Ack()
+		})
+		assert.NoError(t, err)
+
+		assert.Equal(t, "push-subscription", handler.SubscriptionID())
+		assert.Nil(t, handler.Options().Verifier)
+
+		body := `{"message":{"data":"dGVzdA==","messageId":"test-id"},"subscription":"projects/test-project/subscriptions/push-subscription"}`
+
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/push", strings.NewReader(body)))
+
+		assert.Equal(t, http.StatusNoContent, rec.Code)
+	})
+
+	t.Run("push handler with auth enabled", func(t *testing.T) {
+		t.Setenv("APP_ENV", "test")
+		t.Setenv("APP_CONFIG_PATH", "testdata/config")
+		t.Setenv("GCP_PROJECT_ID", "test-project")
+		t.Setenv("PUSH_AUTH_ENABLED", "true")
+		t.Setenv("PUSH_AUTH_AUDIENCE", "https://example.com/push")
+
+		subscriber := runTest(t)
+
+		handler, err := subscriber.PushHandler("push-subscription", func(ctx context.Context, m *message.Message) {
+			m.Ack()
+		})
+		assert.NoError(t, err)
+
+		assert.IsType(t, &push.IDTokenVerifier{}, handler.Options().Verifier)
+
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/push", strings.NewReader("")))
+
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	})
+
+	t.Run("push handler with auth enabled without audience", func(t *testing.T) {
+		t.Setenv("APP_ENV", "test")
+		t.Setenv("APP_CONFIG_PATH", "testdata/config")
+		t.Setenv("GCP_PROJECT_ID", "test-project")
+		t.Setenv("PUSH_AUTH_ENABLED", "true")
+
+		subscriber := runTest(t)
+
+		_, err := subscriber.PushHandler("push-subscription", func(ctx context.Context, m *message.Message) {
+			m.Ack()
+		})
+		assert.Error(t, err)
+		assert.Equal(t, "cannot create push handler: push authentication is enabled without modules.gcppubsub.push.auth.audience", err.Error())
+	})
 }
@@ -0,0 +1,19 @@
+package subscription
+
+import "context"
+
+type ctxSubscriptionIDKey struct{}
+
+// ContextWithSubscriptionID returns a copy of the provided context, carrying the provided subscriptionID.
+func ContextWithSubscriptionID(ctx context.Context, subscriptionID string) context.Context {
+	return context.WithValue(ctx, ctxSubscriptionIDKey{}, subscriptionID)
+}
+
+// CtxSubscriptionID returns the subscriptionID carried by the provided context, or an empty string if missing.
+func CtxSubscriptionID(ctx context.Context) string {
+	if subscriptionID, ok := ctx.Value(ctxSubscriptionIDKey{}).(string); ok {
+		return subscriptionID
+	}
+
+	return ""
+}
@@ -16,20 +16,40 @@ type SubscriptionFactory interface {
 	Create(ctx context.Context, subscriptionID string) (*Subscription, error)
 }
 
+// SubscriptionFactoryOption is a functional option for the DefaultSubscriptionFactory.
+type SubscriptionFactoryOption func(f *DefaultSubscriptionFactory)
+
+// WithCodecRegistry configures the codec.CodecRegistry used for subscriptions on topics without schema: the codec is selected
+// by name from the provided codec.CodecNameFunc, or from the subscription (or its topic) codec.CodecLabel label.
+func WithCodecRegistry(codecs codec.CodecRegistry, codecName codec.CodecNameFunc) SubscriptionFactoryOption {
+	return func(f *DefaultSubscriptionFactory) {
+		f.codecs = codecs
+		f.codecName = codecName
+	}
+}
+
 // DefaultSubscriptionFactory is the default SubscriptionFactory implementation.
 type DefaultSubscriptionFactory struct {
-	client   *pubsub.Client
-	registry schema.SchemaConfigRegistry
-	factory  codec.CodecFactory
+	client    *pubsub.Client
+	registry  schema.SchemaConfigRegistry
+	factory   codec.CodecFactory
+	codecs    codec.CodecRegistry
+	codecName codec.CodecNameFunc
 }
 
 // NewDefaultSubscriptionFactory returns a new DefaultSubscriptionFactory instance.
-func NewDefaultSubscriptionFactory(client *pubsub.Client, registry schema.SchemaConfigRegistry, factory codec.CodecFactory) *DefaultSubscriptionFactory {
-	return &DefaultSubscriptionFactory{
+func NewDefaultSubscriptionFactory(client *pubsub.Client, registry schema.SchemaConfigRegistry, factory codec.CodecFactory, options ...SubscriptionFactoryOption) *DefaultSubscriptionFactory {
+	f := &DefaultSubscriptionFactory{
 		client:   client,
 		registry: registry,
 		factory:  factory,
 	}
+
+	for _, applyOpt := range options {
+		applyOpt(f)
+	}
+
+	return f
 }
 
 // Create creates a new Subscription.
@@ -49,10 +69,8 @@ func (f *DefaultSubscriptionFactory) Create(ctx context.Context, subscriptionID
 		return nil, fmt.Errorf("cannot get subscription %s topic configuration: %w", subscriptionID, err)
 	}
 
-	// subscription topic schema config
-	topicSchemaType := pubsub.SchemaTypeUnspecified
-	topicSchemaEncoding := pubsub.EncodingUnspecified
-	topicSchemaDefinition := ""
+	// subscription codec
+	var subscriptionCodec codec.Codec
 
 	if topicConfig.SchemaSettings != nil {
 		topicSchemaConfig, err := f.registry.Get(ctx, topicConfig.SchemaSettings.Schema)
@@ -60,16 +78,49 @@ func (f *DefaultSubscriptionFactory) Create(ctx context.Context, subscriptionID
 			return nil, fmt.Errorf("cannot get subscription %s topic schema configuration: %w", subscriptionID, err)
 		}
 
-		topicSchemaType = topicSchemaConfig.Type
-		topicSchemaEncoding = topicConfig.SchemaSettings.Encoding
-		topicSchemaDefinition = topicSchemaConfig.Definition
+		subscriptionCodec, err = codec.NewRevisionAwareCodec(
+			f.factory,
+			topicSchemaConfig.Type,
+			topicConfig.SchemaSettings.Encoding,
+			schema.RevisionFunc(ctx, f.registry, topicConfig.SchemaSettings.Schema),
+		)
+		if err != nil {
+			return nil, fmt.Errorf("cannot create subscription %s codec: %w", subscriptionID, err)
+		}
+	} else if codecName := f.resolveCodecName(subscriptionID, subscriptionConfig.Labels, topicConfig.Labels); codecName != "" {
+		subscriptionCodec, err = f.codecs.Get(codecName)
+		if err != nil {
+			return nil, fmt.Errorf("cannot get subscription %s codec: %w", subscriptionID, err)
+		}
+	} else {
+		subscriptionCodec, err = f.factory.Create(pubsub.SchemaTypeUnspecified, pubsub.EncodingUnspecified, "")
+		if err != nil {
+			return nil, fmt.Errorf("cannot create subscription %s codec: %w", subscriptionID, err)
+		}
 	}
 
-	// subscription codec
-	subscriptionCodec, err := f.factory.Create(topicSchemaType, topicSchemaEncoding, topicSchemaDefinition)
-	if err != nil {
-		return nil, fmt.Errorf("cannot create subscription %s codec: %w", subscriptionID, err)
+	return NewSubscription(
+		subscriptionCodec,
+		subscription,
+		WithOrderedHandling(subscriptionConfig.EnableMessageOrdering),
+		WithClient(f.client),
+	), nil
+}
+
+func (f *DefaultSubscriptionFactory) resolveCodecName(subscriptionID string, subscriptionLabels map[string]string, topicLabels map[string]string) string {
+	if f.codecs == nil {
+		return ""
+	}
+
+	if f.codecName != nil {
+		if name := f.codecName(subscriptionID); name != "" {
+			return name
+		}
+	}
+
+	if name, ok := subscriptionLabels[codec.CodecLabel]; ok {
+		return name
 	}
 
-	return NewSubscription(subscriptionCodec, subscription), nil
+	return topicLabels[codec.CodecLabel]
 }
@@ -4,7 +4,9 @@ import (
 	"context"
 	"testing"
 
+	"cloud.google.com/go/pubsub"
 	"github.com/ankorstore/yokai-contrib/fxgcppubsub"
+	"github.com/ankorstore/yokai-contrib/fxgcppubsub/codec"
 	"github.com/ankorstore/yokai-contrib/fxgcppubsub/subscription"
 	"github.com/ankorstore/yokai/fxconfig"
 	"github.com/ankorstore/yokai/fxlog"
@@ -42,6 +44,31 @@ func TestDefaultSubscriptionFactory(t *testing.T) {
 
 		assert.Equal(t, "test-subscription", sub.BaseSubscription().ID())
 		assert.Equal(t, "projects/test-project/subscriptions/test-subscription", sub.BaseSubscription().String())
+		assert.False(t, sub.OrderedHandling())
+	})
+
+	t.Run("subscription creation with message ordering", func(t *testing.T) {
+		fxtest.New(
+			t,
+			fx.NopLogger,
+			fxconfig.FxConfigModule,
+			fxlog.FxLogModule,
+			fxgcppubsub.FxGcpPubSubModule,
+			fx.Supply(fx.Annotate(ctx, fx.As(new(context.Context)))),
+			fxgcppubsub.PrepareTopicAndSubscription(fxgcppubsub.PrepareTopicAndSubscriptionParams{
+				TopicID:        "ordered-topic",
+				SubscriptionID: "ordered-subscription",
+				SubscriptionConfig: pubsub.SubscriptionConfig{
+					EnableMessageOrdering: true,
+				},
+			}),
+			fx.Populate(&factory),
+		).RequireStart().RequireStop()
+
+		sub, err := factory.Create(ctx, "ordered-subscription")
+		assert.NoError(t, err)
+
+		assert.True(t, sub.OrderedHandling())
 	})
 
 	t.Run("subscription creation with schema", func(t *testing.T) {
@@ -67,6 +94,78 @@ func TestDefaultSubscriptionFactory(t *testing.T) {
 		assert.Equal(t, "projects/test-project/subscriptions/test-subscription", sub.BaseSubscription().String())
 	})
 
+	t.Run("subscription creation with codec from topic label", func(t *testing.T) {
+		fxtest.New(
+			t,
+			fx.NopLogger,
+			fxconfig.FxConfigModule,
+			fxlog.FxLogModule,
+			fxgcppubsub.FxGcpPubSubModule,
+			fx.Supply(fx.Annotate(ctx, fx.As(new(context.Context)))),
+			fxgcppubsub.PrepareTopicAndSubscription(fxgcppubsub.PrepareTopicAndSubscriptionParams{
+				TopicID: "test-topic",
+				TopicConfig: pubsub.TopicConfig{
+					Labels: map[string]string{codec.CodecLabel: codec.JsonCodecName},
+				},
+				SubscriptionID: "test-subscription",
+			}),
+			fx.Populate(&factory),
+		).RequireStart().RequireStop()
+
+		sub, err := factory.Create(ctx, "test-subscription")
+		assert.NoError(t, err)
+
+		assert.IsType(t, &codec.JsonCodec{}, sub.Codec())
+	})
+
+	t.Run("subscription creation with codec from subscription label", func(t *testing.T) {
+		fxtest.New(
+			t,
+			fx.NopLogger,
+			fxconfig.FxConfigModule,
+			fxlog.FxLogModule,
+			fxgcppubsub.FxGcpPubSubModule,
+			fx.Supply(fx.Annotate(ctx, fx.As(new(context.Context)))),
+			fxgcppubsub.PrepareTopicAndSubscription(fxgcppubsub.PrepareTopicAndSubscriptionParams{
+				TopicID: "test-topic",
+				TopicConfig: pubsub.TopicConfig{
+					Labels: map[string]string{codec.CodecLabel: codec.RawCodecName},
+				},
+				SubscriptionID: "test-subscription",
+				SubscriptionConfig: pubsub.SubscriptionConfig{
+					Labels: map[string]string{codec.CodecLabel: codec.JsonCodecName},
+				},
+			}),
+			fx.Populate(&factory),
+		).RequireStart().RequireStop()
+
+		sub, err := factory.Create(ctx, "test-subscription")
+		assert.NoError(t, err)
+
+		assert.IsType(t, &codec.JsonCodec{}, sub.Codec())
+	})
+
+	t.Run("subscription creation with codec from config", func(t *testing.T) {
+		fxtest.New(
+			t,
+			fx.NopLogger,
+			fxconfig.FxConfigModule,
+			fxlog.FxLogModule,
+			fxgcppubsub.FxGcpPubSubModule,
+			fx.Supply(fx.Annotate(ctx, fx.As(new(context.Context)))),
+			fxgcppubsub.PrepareTopicAndSubscription(fxgcppubsub.PrepareTopicAndSubscriptionParams{
+				TopicID:        "test-topic",
+				SubscriptionID: "codec-subscription",
+			}),
+			fx.Populate(&factory),
+		).RequireStart().RequireStop()
+
+		sub, err := factory.Create(ctx, "codec-subscription")
+		assert.NoError(t, err)
+
+		assert.IsType(t, &codec.CloudEventsCodec{}, sub.Codec())
+	})
+
 	t.Run("subscription creation error", func(t *testing.T) {
 		fxtest.New(
 			t,
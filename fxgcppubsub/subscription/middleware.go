@@ -0,0 +1,23 @@
+package subscription
+
+// Middleware is the interface for subscription middlewares, wrapping a SubscribeFunc.
+type Middleware interface {
+	Wrap(next SubscribeFunc) SubscribeFunc
+}
+
+// MiddlewareFunc is a func adapter for the Middleware interface.
+type MiddlewareFunc func(next SubscribeFunc) SubscribeFunc
+
+// Wrap wraps the next SubscribeFunc.
+func (f MiddlewareFunc) Wrap(next SubscribeFunc) SubscribeFunc {
+	return f(next)
+}
+
+// Chain wraps a SubscribeFunc with a list of Middleware, the first one being the outermost.
+func Chain(f SubscribeFunc, middlewares ...Middleware) SubscribeFunc {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		f = middlewares[i].Wrap(f)
+	}
+
+	return f
+}
@@ -0,0 +1,46 @@
+package middleware
+
+import (
+	"context"
+	"time"
+
+	"github.com/ankorstore/yokai-contrib/fxgcppubsub/message"
+	"github.com/ankorstore/yokai-contrib/fxgcppubsub/subscription"
+	"github.com/ankorstore/yokai/log"
+)
+
+var _ subscription.Middleware = (*LoggerMiddleware)(nil)
+
+// LoggerMiddleware is a subscription.Middleware enriching the context with a logger carrying
+// the subscription and message ids (to be used with log.CtxLogger), and logging the message processing.
+type LoggerMiddleware struct {
+	logger *log.Logger
+}
+
+// NewLoggerMiddleware returns a new LoggerMiddleware instance.
+func NewLoggerMiddleware(logger *log.Logger) *LoggerMiddleware {
+	return &LoggerMiddleware{
+		logger: logger,
+	}
+}
+
+// Wrap wraps the next subscription.SubscribeFunc.
+func (mw *LoggerMiddleware) Wrap(next subscription.SubscribeFunc) subscription.SubscribeFunc {
+	return func(ctx context.Context, m *message.Message) {
+		logger := mw.logger.
+			With().
+			Str("subscriptionID", subscription.CtxSubscriptionID(ctx)).
+			Str("messageID", m.ID()).
+			Logger()
+
+		ctx = logger.WithContext(ctx)
+
+		start := time.Now()
+
+		logger.Debug().Msg("pubsub message processing start")
+
+		next(ctx, m)
+
+		logger.Debug().Dur("duration", time.Since(start)).Msg("pubsub message processing end")
+	}
+}
@@ -0,0 +1,50 @@
+package middleware
+
+import (
+	"context"
+	"time"
+
+	"github.com/ankorstore/yokai-contrib/fxgcppubsub/message"
+	"github.com/ankorstore/yokai-contrib/fxgcppubsub/metrics"
+	"github.com/ankorstore/yokai-contrib/fxgcppubsub/subscription"
+)
+
+var _ subscription.Middleware = (*MetricsMiddleware)(nil)
+
+// MetricsMiddleware is a subscription.Middleware recording the subscriptions metrics.
+type MetricsMiddleware struct {
+	metrics *metrics.Metrics
+}
+
+// NewMetricsMiddleware returns a new MetricsMiddleware instance.
+func NewMetricsMiddleware(metrics *metrics.Metrics) *MetricsMiddleware {
+	return &MetricsMiddleware{
+		metrics: metrics,
+	}
+}
+
+// Wrap wraps the next subscription.SubscribeFunc.
+func (mw *MetricsMiddleware) Wrap(next subscription.SubscribeFunc) subscription.SubscribeFunc {
+	return func(ctx context.Context, m *message.Message) {
+		subscriptionID := subscription.CtxSubscriptionID(ctx)
+
+		mw.metrics.ReceivedMessages.WithLabelValues(subscriptionID).Inc()
+		mw.metrics.OutstandingMessages.WithLabelValues(subscriptionID).Inc()
+
+		m.OnAcknowledgement(func(ack bool) {
+			mw.metrics.OutstandingMessages.WithLabelValues(subscriptionID).Dec()
+
+			if ack {
+				mw.metrics.AckedMessages.WithLabelValues(subscriptionID).Inc()
+			} else {
+				mw.metrics.NackedMessages.WithLabelValues(subscriptionID).Inc()
+			}
+		})
+
+		start := time.Now()
+
+		next(ctx, m)
+
+		mw.metrics.HandlerDuration.WithLabelValues(subscriptionID).Observe(time.Since(start).Seconds())
+	}
+}
@@ -0,0 +1,53 @@
+package middleware_test
+
+import (
+	"context"
+	"testing"
+
+	"cloud.google.com/go/pubsub"
+	"github.com/ankorstore/yokai-contrib/fxgcppubsub/codec"
+	"github.com/ankorstore/yokai-contrib/fxgcppubsub/message"
+	"github.com/ankorstore/yokai-contrib/fxgcppubsub/metrics"
+	"github.com/ankorstore/yokai-contrib/fxgcppubsub/subscription"
+	"github.com/ankorstore/yokai-contrib/fxgcppubsub/subscription/middleware"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMetricsMiddleware(t *testing.T) {
+	t.Parallel()
+
+	m := metrics.NewMetrics("", "", nil)
+
+	mw := middleware.NewMetricsMiddleware(m)
+
+	var pending *message.Message
+
+	f := mw.Wrap(func(ctx context.Context, msg *message.Message) {
+		switch string(msg.Data()) {
+		case "ack":
+			msg.Ack()
+		case "nack":
+			msg.Nack()
+		default:
+			pending = msg
+		}
+	})
+
+	ctx := subscription.ContextWithSubscriptionID(context.Background(), "test-subscription")
+
+	for _, data := range []string{"ack", "ack", "nack", "pending"} {
+		f(ctx, message.NewMessage(codec.NewRawCodec(), &pubsub.Message{Data: []byte(data)}))
+	}
+
+	assert.Equal(t, float64(4), testutil.ToFloat64(m.ReceivedMessages.WithLabelValues("test-subscription")))
+	assert.Equal(t, float64(2), testutil.ToFloat64(m.AckedMessages.WithLabelValues("test-subscription")))
+	assert.Equal(t, float64(1), testutil.ToFloat64(m.NackedMessages.WithLabelValues("test-subscription")))
+	assert.Equal(t, float64(1), testutil.ToFloat64(m.OutstandingMessages.WithLabelValues("test-subscription")))
+	assert.Equal(t, 1, testutil.CollectAndCount(m.HandlerDuration))
+
+	pending.Ack()
+
+	assert.Equal(t, float64(3), testutil.ToFloat64(m.AckedMessages.WithLabelValues("test-subscription")))
+	assert.Equal(t, float64(0), testutil.ToFloat64(m.OutstandingMessages.WithLabelValues("test-subscription")))
+}
@@ -0,0 +1,44 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ankorstore/yokai-contrib/fxgcppubsub/message"
+	"github.com/ankorstore/yokai-contrib/fxgcppubsub/subscription"
+	"github.com/ankorstore/yokai/log"
+)
+
+var _ subscription.Middleware = (*RecoveryMiddleware)(nil)
+
+// RecoveryMiddleware is a subscription.Middleware recovering from panics, and nacking the message.
+type RecoveryMiddleware struct {
+	logger *log.Logger
+}
+
+// NewRecoveryMiddleware returns a new RecoveryMiddleware instance.
+func NewRecoveryMiddleware(logger *log.Logger) *RecoveryMiddleware {
+	return &RecoveryMiddleware{
+		logger: logger,
+	}
+}
+
+// Wrap wraps the next subscription.SubscribeFunc.
+func (mw *RecoveryMiddleware) Wrap(next subscription.SubscribeFunc) subscription.SubscribeFunc {
+	return func(ctx context.Context, m *message.Message) {
+		defer func() {
+			if r := recover(); r != nil {
+				mw.logger.
+					Error().
+					Err(fmt.Errorf("%v", r)).
+					Str("subscriptionID", subscription.CtxSubscriptionID(ctx)).
+					Str("messageID", m.ID()).
+					Msg("pubsub message processing panic recovered")
+
+				m.Nack()
+			}
+		}()
+
+		next(ctx, m)
+	}
+}
@@ -0,0 +1,46 @@
+package middleware_test
+
+import (
+	"context"
+	"testing"
+
+	"cloud.google.com/go/pubsub"
+	"github.com/ankorstore/yokai-contrib/fxgcppubsub/codec"
+	"github.com/ankorstore/yokai-contrib/fxgcppubsub/message"
+	"github.com/ankorstore/yokai-contrib/fxgcppubsub/subscription"
+	"github.com/ankorstore/yokai-contrib/fxgcppubsub/subscription/middleware"
+	"github.com/ankorstore/yokai/log"
+	"github.com/ankorstore/yokai/log/logtest"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecoveryMiddleware(t *testing.T) {
+	t.Parallel()
+
+	logBuffer := logtest.NewDefaultTestLogBuffer()
+	logger, err := log.NewDefaultLoggerFactory().Create(
+		log.WithLevel(zerolog.DebugLevel),
+		log.WithOutputWriter(logBuffer),
+	)
+	assert.NoError(t, err)
+
+	mw := middleware.NewRecoveryMiddleware(logger)
+
+	f := mw.Wrap(func(ctx context.Context, m *message.Message) {
+		panic("test panic")
+	})
+
+	ctx := subscription.ContextWithSubscriptionID(context.Background(), "test-subscription")
+
+	assert.NotPanics(t, func() {
+		f(ctx, message.NewMessage(codec.NewRawCodec(), &pubsub.Message{ID: "test-id"}))
+	})
+
+	logtest.AssertHasLogRecord(t, logBuffer, map[string]interface{}{
+		"level":          "error",
+		"error":          "test panic",
+		"subscriptionID": "test-subscription",
+		"message":        "pubsub message processing panic recovered",
+	})
+}
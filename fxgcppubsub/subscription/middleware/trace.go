@@ -0,0 +1,77 @@
+package middleware
+
+import (
+	"context"
+
+	"github.com/ankorstore/yokai-contrib/fxgcppubsub/message"
+	"github.com/ankorstore/yokai-contrib/fxgcppubsub/subscription"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	oteltrace "go.opentelemetry.io/otel/trace"
+)
+
+// TracerName is the name of the tracer used for subscriptions.
+const TracerName = "github.com/ankorstore/yokai-contrib/fxgcppubsub/subscription"
+
+var _ subscription.Middleware = (*TraceMiddleware)(nil)
+
+// TraceMiddleware is a subscription.Middleware extracting the trace context from the message attributes,
+// and starting a consumer span linked to the producer span.
+type TraceMiddleware struct {
+	tracer     oteltrace.Tracer
+	propagator propagation.TextMapPropagator
+}
+
+// TraceOption is a functional option for the TraceMiddleware.
+type TraceOption func(mw *TraceMiddleware)
+
+// WithPropagator sets the propagator used to extract the trace context, the global one by default.
+func WithPropagator(propagator propagation.TextMapPropagator) TraceOption {
+	return func(mw *TraceMiddleware) {
+		mw.propagator = propagator
+	}
+}
+
+// NewTraceMiddleware returns a new TraceMiddleware instance, using the global tracer provider if none is provided.
+func NewTraceMiddleware(tracerProvider oteltrace.TracerProvider, options ...TraceOption) *TraceMiddleware {
+	if tracerProvider == nil {
+		tracerProvider = otel.GetTracerProvider()
+	}
+
+	mw := &TraceMiddleware{
+		tracer:     tracerProvider.Tracer(TracerName),
+		propagator: otel.GetTextMapPropagator(),
+	}
+
+	for _, opt := range options {
+		opt(mw)
+	}
+
+	return mw
+}
+
+// Wrap wraps the next subscription.SubscribeFunc.
+func (mw *TraceMiddleware) Wrap(next subscription.SubscribeFunc) subscription.SubscribeFunc {
+	return func(ctx context.Context, m *message.Message) {
+		subscriptionID := subscription.CtxSubscriptionID(ctx)
+
+		ctx = mw.propagator.Extract(ctx, propagation.MapCarrier(m.Attributes()))
+
+		ctx, span := mw.tracer.Start(
+			ctx,
+			"pubsub receive "+subscriptionID,
+			oteltrace.WithSpanKind(oteltrace.SpanKindConsumer),
+			oteltrace.WithLinks(oteltrace.LinkFromContext(ctx)),
+			oteltrace.WithAttributes(
+				attribute.String("messaging.system", "gcp_pubsub"),
+				attribute.String("messaging.operation", "receive"),
+				attribute.String("messaging.destination.name", subscriptionID),
+				attribute.String("messaging.message.id", m.ID()),
+			),
+		)
+		defer span.End()
+
+		next(ctx, m)
+	}
+}
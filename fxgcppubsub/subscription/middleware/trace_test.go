@@ -0,0 +1,96 @@
+package middleware_test
+
+import (
+	"context"
+	"testing"
+
+	"cloud.google.com/go/pubsub"
+	"github.com/ankorstore/yokai-contrib/fxgcppubsub/codec"
+	"github.com/ankorstore/yokai-contrib/fxgcppubsub/message"
+	"github.com/ankorstore/yokai-contrib/fxgcppubsub/subscription"
+	"github.com/ankorstore/yokai-contrib/fxgcppubsub/subscription/middleware"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/baggage"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	oteltrace "go.opentelemetry.io/otel/trace"
+)
+
+func TestTraceMiddleware(t *testing.T) {
+	t.Parallel()
+
+	recorder := tracetest.NewSpanRecorder()
+	tracerProvider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+	mw := middleware.NewTraceMiddleware(tracerProvider, middleware.WithPropagator(propagation.TraceContext{}))
+
+	var handlerSpanContext oteltrace.SpanContext
+
+	f := mw.Wrap(func(ctx context.Context, m *message.Message) {
+		handlerSpanContext = oteltrace.SpanContextFromContext(ctx)
+	})
+
+	ctx := subscription.ContextWithSubscriptionID(context.Background(), "test-subscription")
+
+	f(ctx, message.NewMessage(codec.NewRawCodec(), &pubsub.Message{
+		ID: "test-id",
+		Attributes: map[string]string{
+			"traceparent": "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		},
+	}))
+
+	spans := recorder.Ended()
+	assert.Len(t, spans, 1)
+
+	span := spans[0]
+	assert.Equal(t, "pubsub receive test-subscription", span.Name())
+	assert.Equal(t, oteltrace.SpanKindConsumer, span.SpanKind())
+	assert.Contains(t, span.Attributes(), attribute.String("messaging.destination.name", "test-subscription"))
+	assert.Contains(t, span.Attributes(), attribute.String("messaging.message.id", "test-id"))
+
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", span.SpanContext().TraceID().String())
+	assert.Equal(t, "00f067aa0ba902b7", span.Parent().SpanID().String())
+	assert.Len(t, span.Links(), 1)
+	assert.Equal(t, "00f067aa0ba902b7", span.Links()[0].SpanContext.SpanID().String())
+
+	assert.Equal(t, span.SpanContext(), handlerSpanContext)
+}
+
+func TestTraceMiddlewareWithGlobalPropagator(t *testing.T) {
+	propagator := otel.GetTextMapPropagator()
+	t.Cleanup(func() {
+		otel.SetTextMapPropagator(propagator)
+	})
+
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	recorder := tracetest.NewSpanRecorder()
+	tracerProvider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+	mw := middleware.NewTraceMiddleware(tracerProvider)
+
+	var handlerBaggage baggage.Baggage
+
+	f := mw.Wrap(func(ctx context.Context, m *message.Message) {
+		handlerBaggage = baggage.FromContext(ctx)
+	})
+
+	ctx := subscription.ContextWithSubscriptionID(context.Background(), "test-subscription")
+
+	f(ctx, message.NewMessage(codec.NewRawCodec(), &pubsub.Message{
+		ID: "test-id",
+		Attributes: map[string]string{
+			"traceparent": "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+			"baggage":     "foo=bar",
+		},
+	}))
+
+	spans := recorder.Ended()
+	assert.Len(t, spans, 1)
+
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", spans[0].SpanContext().TraceID().String())
+	assert.Equal(t, "bar", handlerBaggage.Member("foo").Value())
+}
@@ -0,0 +1,62 @@
+package subscription_test
+
+import (
+	"context"
+	"testing"
+
+	"cloud.google.com/go/pubsub"
+	"github.com/ankorstore/yokai-contrib/fxgcppubsub/codec"
+	"github.com/ankorstore/yokai-contrib/fxgcppubsub/message"
+	"github.com/ankorstore/yokai-contrib/fxgcppubsub/subscription"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChain(t *testing.T) {
+	t.Parallel()
+
+	var calls []string
+
+	newMiddleware := func(name string) subscription.Middleware {
+		return subscription.MiddlewareFunc(func(next subscription.SubscribeFunc) subscription.SubscribeFunc {
+			return func(ctx context.Context, m *message.Message) {
+				calls = append(calls, name+" before")
+				next(ctx, m)
+				calls = append(calls, name+" after")
+			}
+		})
+	}
+
+	f := subscription.Chain(
+		func(ctx context.Context, m *message.Message) {
+			calls = append(calls, "handler")
+		},
+		newMiddleware("first"),
+		newMiddleware("second"),
+	)
+
+	f(context.Background(), message.NewMessage(codec.NewRawCodec(), &pubsub.Message{}))
+
+	assert.Equal(
+		t,
+		[]string{
+			"first before",
+			"second before",
+			"handler",
+			"second after",
+			"first after",
+		},
+		calls,
+	)
+}
+
+func TestContextSubscriptionID(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	assert.Equal(t, "", subscription.CtxSubscriptionID(ctx))
+
+	ctx = subscription.ContextWithSubscriptionID(ctx, "test-subscription")
+
+	assert.Equal(t, "test-subscription", subscription.CtxSubscriptionID(ctx))
+}
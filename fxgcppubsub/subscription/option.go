@@ -9,6 +9,7 @@ import (
 // Options represents subscription options.
 type Options struct {
 	ReceiveSettings pubsub.ReceiveSettings
+	Middlewares     []Middleware
 }
 
 // DefaultSubscribeOptions is the default subscription options.
@@ -69,3 +70,10 @@ func WithNumGoroutines(n int) SubscribeOption {
 		o.ReceiveSettings.NumGoroutines = n
 	}
 }
+
+// WithMiddlewares appends middlewares to wrap the SubscribeFunc with.
+func WithMiddlewares(m ...Middleware) SubscribeOption {
+	return func(o *Options) {
+		o.Middlewares = append(o.Middlewares, m...)
+	}
+}
@@ -86,4 +86,17 @@ func TestSubscribeOptions(t *testing.T) {
 
 		assert.Equal(t, value, o.ReceiveSettings.NumGoroutines)
 	})
+
+	t.Run("withMiddlewares", func(t *testing.T) {
+		t.Parallel()
+
+		o := &subscription.Options{}
+		value := subscription.MiddlewareFunc(func(next subscription.SubscribeFunc) subscription.SubscribeFunc {
+			return next
+		})
+		opt := subscription.WithMiddlewares(value, value)
+		opt(o)
+
+		assert.Len(t, o.Middlewares, 2)
+	})
 }
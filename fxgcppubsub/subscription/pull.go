@@ -0,0 +1,264 @@
+package subscription
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"cloud.google.com/go/pubsub"
+	pubsubapi "cloud.google.com/go/pubsub/apiv1"
+	"cloud.google.com/go/pubsub/apiv1/pubsubpb"
+	"github.com/ankorstore/yokai-contrib/fxgcppubsub/message"
+	"github.com/ankorstore/yokai/log"
+	"github.com/googleapis/gax-go/v2"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var (
+	_ PullClient         = (*pubsubapi.SubscriberClient)(nil)
+	_ message.AckHandler = (*pullAckHandler)(nil)
+)
+
+const (
+	// DefaultPullAckDelay is the delay during which the acks of pulled messages are batched, before being sent.
+	DefaultPullAckDelay = 100 * time.Millisecond
+	// maxAckIDs is the max number of ack ids per Acknowledge request.
+	maxAckIDs = 2500
+)
+
+// PullClient is the interface for the clients performing unary pulls, like the pubsub apiv1 SubscriberClient.
+type PullClient interface {
+	Pull(ctx context.Context, req *pubsubpb.PullRequest, opts ...gax.CallOption) (*pubsubpb.PullResponse, error)
+	Acknowledge(ctx context.Context, req *pubsubpb.AcknowledgeRequest, opts ...gax.CallOption) error
+	ModifyAckDeadline(ctx context.Context, req *pubsubpb.ModifyAckDeadlineRequest, opts ...gax.CallOption) error
+}
+
+// Puller performs synchronous consumptions of subscriptions, with the unary Pull RPC.
+type Puller struct {
+	client PullClient
+	ackers map[*pullAcker]struct{}
+	mutex  sync.Mutex
+}
+
+// NewPuller returns a new Puller instance.
+func NewPuller(client PullClient) *Puller {
+	return &Puller{
+		client: client,
+		ackers: make(map[*pullAcker]struct{}),
+	}
+}
+
+// Pull pulls up to maxMessages messages from a subscription, waiting up to timeout (if positive) for messages to be available.
+//
+// The messages are decoded with the subscription codec, and must be explicitly acked, nacked, or have their ack deadline
+// modified: their ack deadline is not extended automatically. No messages are returned if none were available before timeout.
+//
+// The acks of the pulled messages are batched during DefaultPullAckDelay, and sent with a single Acknowledge request:
+// use Flush to send them synchronously, for example before exiting.
+func (p *Puller) Pull(ctx context.Context, sub *Subscription, maxMessages int, timeout time.Duration) ([]*message.Message, error) {
+	if maxMessages <= 0 || maxMessages > math.MaxInt32 {
+		return nil, fmt.Errorf("invalid max messages %d", maxMessages)
+	}
+
+	pullCtx := ctx
+	if timeout > 0 {
+		var cancel context.CancelFunc
+
+		pullCtx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	subscriptionName := sub.BaseSubscription().String()
+
+	res, err := p.client.Pull(pullCtx, &pubsubpb.PullRequest{
+		Subscription: subscriptionName,
+		MaxMessages:  int32(maxMessages),
+	})
+	if err != nil {
+		if ctx.Err() == nil && (errors.Is(err, context.DeadlineExceeded) || status.Code(err) == codes.DeadlineExceeded) {
+			return []*message.Message{}, nil
+		}
+
+		return nil, fmt.Errorf("cannot pull messages: %w", err)
+	}
+
+	ackCtx := ContextWithSubscriptionID(context.WithoutCancel(ctx), sub.BaseSubscription().ID())
+
+	acker := &pullAcker{
+		ctx:          ackCtx,
+		puller:       p,
+		subscription: subscriptionName,
+	}
+
+	messages := make([]*message.Message, len(res.ReceivedMessages))
+
+	for i, received := range res.ReceivedMessages {
+		messages[i] = message.NewMessageWithAckHandler(
+			sub.Codec(),
+			baseMessage(received),
+			&pullAckHandler{
+				ctx:          ackCtx,
+				client:       p.client,
+				acker:        acker,
+				subscription: subscriptionName,
+				ackID:        received.AckId,
+			},
+		)
+	}
+
+	return messages, nil
+}
+
+// Flush synchronously sends the pending acks of the pulled messages, without waiting for DefaultPullAckDelay.
+func (p *Puller) Flush(ctx context.Context) error {
+	p.mutex.Lock()
+	ackers := make([]*pullAcker, 0, len(p.ackers))
+	for acker := range p.ackers {
+		ackers = append(ackers, acker)
+	}
+	p.mutex.Unlock()
+
+	var errs []error
+
+	for _, acker := range ackers {
+		err := acker.flush(ctx)
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func (p *Puller) track(acker *pullAcker, pending bool) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if pending {
+		p.ackers[acker] = struct{}{}
+	} else {
+		delete(p.ackers, acker)
+	}
+}
+
+func baseMessage(received *pubsubpb.ReceivedMessage) *pubsub.Message {
+	msg := &pubsub.Message{
+		ID:          received.GetMessage().GetMessageId(),
+		Data:        received.GetMessage().GetData(),
+		Attributes:  received.GetMessage().GetAttributes(),
+		PublishTime: received.GetMessage().GetPublishTime().AsTime(),
+		OrderingKey: received.GetMessage().GetOrderingKey(),
+	}
+
+	if received.GetDeliveryAttempt() > 0 {
+		deliveryAttempt := int(received.GetDeliveryAttempt())
+		msg.DeliveryAttempt = &deliveryAttempt
+	}
+
+	return msg
+}
+
+// pullAcker batches the acks of pulled messages, sent with the Acknowledge RPC after DefaultPullAckDelay, or on Puller.Flush.
+//
+//nolint:containedctx
+type pullAcker struct {
+	ctx          context.Context
+	puller       *Puller
+	subscription string
+	ackIDs       []string
+	timer        *time.Timer
+	mutex        sync.Mutex
+}
+
+func (a *pullAcker) add(ackID string) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	a.ackIDs = append(a.ackIDs, ackID)
+
+	if a.timer == nil {
+		a.timer = time.AfterFunc(DefaultPullAckDelay, a.flushDelayed)
+		a.puller.track(a, true)
+	}
+}
+
+func (a *pullAcker) flushDelayed() {
+	err := a.flush(a.ctx)
+	if err != nil {
+		log.CtxLogger(a.ctx).
+			Error().
+			Err(err).
+			Str("subscriptionID", CtxSubscriptionID(a.ctx)).
+			Msg("pubsub pulled messages ack error")
+	}
+}
+
+func (a *pullAcker) flush(ctx context.Context) error {
+	a.mutex.Lock()
+	ackIDs := a.ackIDs
+	a.ackIDs = nil
+
+	if a.timer != nil {
+		a.timer.Stop()
+		a.timer = nil
+		a.puller.track(a, false)
+	}
+	a.mutex.Unlock()
+
+	var errs []error
+
+	for len(ackIDs) > 0 {
+		n := min(len(ackIDs), maxAckIDs)
+
+		err := a.puller.client.Acknowledge(ctx, &pubsubpb.AcknowledgeRequest{
+			Subscription: a.subscription,
+			AckIds:       ackIDs[:n],
+		})
+		if err != nil {
+			errs = append(errs, fmt.Errorf("cannot acknowledge %d messages: %w", n, err))
+		}
+
+		ackIDs = ackIDs[n:]
+	}
+
+	return errors.Join(errs...)
+}
+
+// pullAckHandler acknowledges pulled messages with the Acknowledge (batched by the pullAcker) and ModifyAckDeadline RPCs.
+//
+//nolint:containedctx
+type pullAckHandler struct {
+	ctx          context.Context
+	client       PullClient
+	acker        *pullAcker
+	subscription string
+	ackID        string
+}
+
+func (h *pullAckHandler) OnAck() {
+	h.acker.add(h.ackID)
+}
+
+func (h *pullAckHandler) OnNack() {
+	err := h.OnModifyAckDeadline(h.ctx, 0)
+	if err != nil {
+		log.CtxLogger(h.ctx).Error().Err(err).Str("subscriptionID", CtxSubscriptionID(h.ctx)).Msg("pubsub pulled message nack error")
+	}
+}
+
+func (h *pullAckHandler) OnModifyAckDeadline(ctx context.Context, deadline time.Duration) error {
+	err := h.client.ModifyAckDeadline(ctx, &pubsubpb.ModifyAckDeadlineRequest{
+		Subscription:       h.subscription,
+		AckIds:             []string{h.ackID},
+		AckDeadlineSeconds: int32(deadline / time.Second),
+	})
+	if err != nil {
+		return fmt.Errorf("cannot modify ack deadline: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,261 @@
+package subscription_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/pubsub"
+	pubsubapi "cloud.google.com/go/pubsub/apiv1"
+	"cloud.google.com/go/pubsub/apiv1/pubsubpb"
+	"github.com/ankorstore/yokai-contrib/fxgcppubsub"
+	"github.com/ankorstore/yokai-contrib/fxgcppubsub/codec"
+	"github.com/ankorstore/yokai-contrib/fxgcppubsub/reactor/ack"
+	"github.com/ankorstore/yokai-contrib/fxgcppubsub/subscription"
+	"github.com/ankorstore/yokai-contrib/fxgcppubsub/topic"
+	"github.com/ankorstore/yokai/fxconfig"
+	"github.com/ankorstore/yokai/fxlog"
+	"github.com/googleapis/gax-go/v2"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/fx"
+	"go.uber.org/fx/fxtest"
+)
+
+type countingPullClient struct {
+	subscription.PullClient
+	requests int
+	ackIDs   int
+	mutex    sync.Mutex
+}
+
+func (c *countingPullClient) Acknowledge(ctx context.Context, req *pubsubpb.AcknowledgeRequest, opts ...gax.CallOption) error {
+	c.mutex.Lock()
+	c.requests++
+	c.ackIDs += len(req.AckIds)
+	c.mutex.Unlock()
+
+	return c.PullClient.Acknowledge(ctx, req, opts...)
+}
+
+func (c *countingPullClient) Requests() int {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return c.requests
+}
+
+func (c *countingPullClient) AckIDs() int {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return c.ackIDs
+}
+
+func TestPuller(t *testing.T) {
+	t.Setenv("APP_ENV", "test")
+	t.Setenv("APP_CONFIG_PATH", "../testdata/config")
+	t.Setenv("GCP_PROJECT_ID", "test-project")
+
+	var publisher fxgcppubsub.Publisher
+	var supervisor ack.AckSupervisor
+	var client *pubsub.Client
+	var pullClient *pubsubapi.SubscriberClient
+
+	ctx := context.Background()
+
+	fxtest.New(
+		t,
+		fx.NopLogger,
+		fxconfig.FxConfigModule,
+		fxlog.FxLogModule,
+		fxgcppubsub.FxGcpPubSubModule,
+		fx.Supply(fx.Annotate(ctx, fx.As(new(context.Context)))),
+		fxgcppubsub.PrepareTopicAndSubscription(fxgcppubsub.PrepareTopicAndSubscriptionParams{
+			TopicID:        "pull-topic",
+			SubscriptionID: "pull-subscription",
+		}),
+		fxgcppubsub.PrepareTopicAndSubscription(fxgcppubsub.PrepareTopicAndSubscriptionParams{
+			TopicID:        "batch-pull-topic",
+			SubscriptionID: "batch-pull-subscription",
+		}),
+		fxgcppubsub.PrepareTopicAndSubscription(fxgcppubsub.PrepareTopicAndSubscriptionParams{
+			TopicID:        "flush-pull-topic",
+			SubscriptionID: "flush-pull-subscription",
+		}),
+		fxgcppubsub.PrepareTopicAndSubscription(fxgcppubsub.PrepareTopicAndSubscriptionParams{
+			TopicID:        "empty-pull-topic",
+			SubscriptionID: "empty-pull-subscription",
+		}),
+		fx.Populate(&publisher, &client, &pullClient, &supervisor),
+	).RequireStart().RequireStop()
+
+	cod := codec.NewRawCodec()
+	puller := subscription.NewPuller(pullClient)
+
+	t.Run("pull and acknowledge messages", func(t *testing.T) {
+		sub := subscription.NewSubscription(cod, client.Subscription("pull-subscription"))
+
+		for _, data := range []string{"first", "second", "third"} {
+			res, err := publisher.Publish(ctx, "pull-topic", []byte(data), topic.WithMessageAttributes(map[string]string{"foo": "bar"}))
+			assert.NoError(t, err)
+
+			_, err = res.Get(ctx)
+			assert.NoError(t, err)
+		}
+
+		// the test server does not guarantee the pull order
+		messages, err := puller.Pull(ctx, sub, 2, time.Second)
+		assert.NoError(t, err)
+		assert.Len(t, messages, 2)
+
+		var remaining []string
+
+		for _, m := range messages {
+			assert.Equal(t, cod, m.Codec())
+			assert.Equal(t, map[string]string{"foo": "bar"}, m.Attributes())
+			assert.NotEmpty(t, m.ID())
+			assert.False(t, m.BaseMessage().PublishTime.IsZero())
+		}
+
+		for _, data := range []string{"first", "second", "third"} {
+			if data != string(messages[0].Data()) {
+				remaining = append(remaining, data)
+			}
+		}
+
+		assert.Contains(t, []string{"first", "second", "third"}, string(messages[0].Data()))
+		assert.Contains(t, remaining, string(messages[1].Data()))
+
+		// ack
+		ackWaiter := supervisor.StartAckWaiter("pull-subscription")
+
+		messages[0].Ack()
+
+		_, err = ackWaiter.WaitMaxDuration(ctx, time.Second)
+		assert.NoError(t, err)
+
+		// modify ack deadline
+		err = messages[1].ModifyAckDeadline(ctx, time.Minute)
+		assert.NoError(t, err)
+
+		// nack
+		nackWaiter := supervisor.StartNackWaiter("pull-subscription")
+
+		messages[1].Nack()
+
+		_, err = nackWaiter.WaitMaxDuration(ctx, time.Second)
+		assert.NoError(t, err)
+
+		// nacked message is redelivered
+		var pulled []string
+
+		assert.Eventually(t, func() bool {
+			redelivered, err := puller.Pull(ctx, sub, 10, time.Second)
+			assert.NoError(t, err)
+
+			for _, m := range redelivered {
+				pulled = append(pulled, string(m.Data()))
+
+				m.Ack()
+			}
+
+			return len(pulled) >= 2
+		}, 5*time.Second, 10*time.Millisecond)
+
+		assert.ElementsMatch(t, remaining, pulled)
+	})
+
+	t.Run("batch acknowledgements", func(t *testing.T) {
+		sub := subscription.NewSubscription(cod, client.Subscription("batch-pull-subscription"))
+
+		for _, data := range []string{"first", "second", "third"} {
+			res, err := publisher.Publish(ctx, "batch-pull-topic", []byte(data))
+			assert.NoError(t, err)
+
+			_, err = res.Get(ctx)
+			assert.NoError(t, err)
+		}
+
+		countingClient := &countingPullClient{PullClient: pullClient}
+
+		messages, err := subscription.NewPuller(countingClient).Pull(ctx, sub, 10, time.Second)
+		assert.NoError(t, err)
+		assert.Len(t, messages, 3)
+
+		for _, m := range messages {
+			m.Ack()
+		}
+
+		assert.Eventually(t, func() bool {
+			return countingClient.AckIDs() == 3
+		}, time.Second, 10*time.Millisecond)
+
+		assert.Equal(t, 1, countingClient.Requests())
+
+		messages, err = puller.Pull(ctx, sub, 10, 100*time.Millisecond)
+		assert.NoError(t, err)
+		assert.Empty(t, messages)
+	})
+
+	t.Run("flush acknowledgements", func(t *testing.T) {
+		sub := subscription.NewSubscription(cod, client.Subscription("flush-pull-subscription"))
+
+		for _, data := range []string{"first", "second", "third"} {
+			res, err := publisher.Publish(ctx, "flush-pull-topic", []byte(data))
+			assert.NoError(t, err)
+
+			_, err = res.Get(ctx)
+			assert.NoError(t, err)
+		}
+
+		countingClient := &countingPullClient{PullClient: pullClient}
+		flushPuller := subscription.NewPuller(countingClient)
+
+		messages, err := flushPuller.Pull(ctx, sub, 10, time.Second)
+		assert.NoError(t, err)
+		assert.Len(t, messages, 3)
+
+		for _, m := range messages {
+			m.Ack()
+		}
+
+		err = flushPuller.Flush(ctx)
+		assert.NoError(t, err)
+
+		assert.Equal(t, 3, countingClient.AckIDs())
+		assert.Equal(t, 1, countingClient.Requests())
+
+		// nothing left to flush, including after the batching delay
+		err = flushPuller.Flush(ctx)
+		assert.NoError(t, err)
+
+		time.Sleep(2 * subscription.DefaultPullAckDelay)
+
+		assert.Equal(t, 1, countingClient.Requests())
+	})
+
+	t.Run("pull without available messages before timeout", func(t *testing.T) {
+		sub := subscription.NewSubscription(cod, client.Subscription("empty-pull-subscription"))
+
+		messages, err := puller.Pull(ctx, sub, 10, 100*time.Millisecond)
+		assert.NoError(t, err)
+		assert.Empty(t, messages)
+	})
+
+	t.Run("pull with invalid max messages", func(t *testing.T) {
+		sub := subscription.NewSubscription(cod, client.Subscription("pull-subscription"))
+
+		_, err := puller.Pull(ctx, sub, 0, time.Second)
+		assert.Error(t, err)
+		assert.Equal(t, "invalid max messages 0", err.Error())
+	})
+
+	t.Run("pull from invalid subscription", func(t *testing.T) {
+		sub := subscription.NewSubscription(cod, client.Subscription("invalid-subscription"))
+
+		_, err := puller.Pull(ctx, sub, 10, time.Second)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "cannot pull messages")
+	})
+}
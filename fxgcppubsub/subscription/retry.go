@@ -0,0 +1,90 @@
+package subscription
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ankorstore/yokai-contrib/fxgcppubsub/internal/backoff"
+	"github.com/ankorstore/yokai/config"
+)
+
+const (
+	DefaultRetryMaxAttempts       = 5
+	DefaultRetryMinBackoff        = 100 * time.Millisecond
+	DefaultRetryMaxBackoff        = 10 * time.Second
+	DefaultRetryBackoffMultiplier = 2.0
+)
+
+const (
+	DeadLetterErrorAttribute           = "deadletter_error"
+	DeadLetterSubscriptionAttribute    = "deadletter_subscription"
+	DeadLetterMessageIDAttribute       = "deadletter_message_id"
+	DeadLetterDeliveryAttemptAttribute = "deadletter_delivery_attempt"
+)
+
+// ErrNoDeadLetter is returned when retrying the messages of a subscription without dead-letter topic nor dead-letter
+// policy, since their redeliveries would be retried forever.
+var ErrNoDeadLetter = errors.New("no dead-letter topic nor dead-letter policy configured")
+
+// RetryPolicy represents the retry and dead-letter policy of a subscription.
+type RetryPolicy struct {
+	MaxAttempts       int
+	MinBackoff        time.Duration
+	MaxBackoff        time.Duration
+	BackoffMultiplier float64
+	DeadLetterTopicID string
+}
+
+// DefaultRetryPolicy is the default retry policy.
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts:       DefaultRetryMaxAttempts,
+		MinBackoff:        DefaultRetryMinBackoff,
+		MaxBackoff:        DefaultRetryMaxBackoff,
+		BackoffMultiplier: DefaultRetryBackoffMultiplier,
+	}
+}
+
+// NewRetryPolicyFromConfig returns the RetryPolicy configured for a provided subscriptionID,
+// in modules.gcppubsub.subscriptions.{subscriptionID}.retry, falling back on DefaultRetryPolicy.
+func NewRetryPolicyFromConfig(cfg *config.Config, subscriptionID string) RetryPolicy {
+	policy := DefaultRetryPolicy()
+
+	prefix := fmt.Sprintf("modules.gcppubsub.subscriptions.%s.retry", subscriptionID)
+
+	if cfg.IsSet(prefix + ".max_attempts") {
+		policy.MaxAttempts = cfg.GetInt(prefix + ".max_attempts")
+	}
+
+	if cfg.IsSet(prefix + ".backoff.min") {
+		policy.MinBackoff = cfg.GetDuration(prefix + ".backoff.min")
+	}
+
+	if cfg.IsSet(prefix + ".backoff.max") {
+		policy.MaxBackoff = cfg.GetDuration(prefix + ".backoff.max")
+	}
+
+	if cfg.IsSet(prefix + ".backoff.multiplier") {
+		policy.BackoffMultiplier = cfg.GetFloat64(prefix + ".backoff.multiplier")
+	}
+
+	policy.DeadLetterTopicID = cfg.GetString(prefix + ".dead_letter.topic")
+
+	return policy
+}
+
+// Backoff returns the duration to wait after a provided failed attempt, before the next one.
+func (p RetryPolicy) Backoff(attempt int) time.Duration {
+	return backoff.Exponential(attempt, p.MinBackoff, p.MaxBackoff, p.BackoffMultiplier)
+}
+
+// TotalBackoff returns the total duration waited between the MaxAttempts attempts of a message.
+func (p RetryPolicy) TotalBackoff() time.Duration {
+	var total time.Duration
+	for attempt := 1; attempt < p.MaxAttempts; attempt++ {
+		total += p.Backoff(attempt)
+	}
+
+	return total
+}
@@ -0,0 +1,72 @@
+package subscription_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ankorstore/yokai-contrib/fxgcppubsub/subscription"
+	"github.com/ankorstore/yokai/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRetryPolicy(t *testing.T) {
+	t.Setenv("APP_ENV", "test")
+	t.Setenv("GCP_PROJECT_ID", "test-project")
+
+	cfg, err := config.NewDefaultConfigFactory().Create(config.WithFilePaths("../testdata/config"))
+	assert.NoError(t, err)
+
+	t.Run("default policy", func(t *testing.T) {
+		policy := subscription.DefaultRetryPolicy()
+
+		assert.Equal(t, subscription.DefaultRetryMaxAttempts, policy.MaxAttempts)
+		assert.Equal(t, subscription.DefaultRetryMinBackoff, policy.MinBackoff)
+		assert.Equal(t, subscription.DefaultRetryMaxBackoff, policy.MaxBackoff)
+		assert.Equal(t, subscription.DefaultRetryBackoffMultiplier, policy.BackoffMultiplier)
+		assert.Empty(t, policy.DeadLetterTopicID)
+	})
+
+	t.Run("policy from config", func(t *testing.T) {
+		policy := subscription.NewRetryPolicyFromConfig(cfg, "retry-subscription")
+
+		assert.Equal(t, 3, policy.MaxAttempts)
+		assert.Equal(t, 10*time.Millisecond, policy.MinBackoff)
+		assert.Equal(t, 50*time.Millisecond, policy.MaxBackoff)
+		assert.Equal(t, 1.5, policy.BackoffMultiplier)
+		assert.Equal(t, "dead-letter-topic", policy.DeadLetterTopicID)
+	})
+
+	t.Run("policy from config fallback on defaults", func(t *testing.T) {
+		policy := subscription.NewRetryPolicyFromConfig(cfg, "test-subscription")
+
+		assert.Equal(t, subscription.DefaultRetryPolicy(), policy)
+	})
+
+	t.Run("backoff", func(t *testing.T) {
+		policy := subscription.RetryPolicy{
+			MinBackoff:        100 * time.Millisecond,
+			MaxBackoff:        300 * time.Millisecond,
+			BackoffMultiplier: 2,
+		}
+
+		assert.Equal(t, 100*time.Millisecond, policy.Backoff(0))
+		assert.Equal(t, 100*time.Millisecond, policy.Backoff(1))
+		assert.Equal(t, 200*time.Millisecond, policy.Backoff(2))
+		assert.Equal(t, 300*time.Millisecond, policy.Backoff(3))
+		assert.Equal(t, 300*time.Millisecond, policy.Backoff(10))
+	})
+
+	t.Run("total backoff", func(t *testing.T) {
+		policy := subscription.RetryPolicy{
+			MaxAttempts:       4,
+			MinBackoff:        100 * time.Millisecond,
+			MaxBackoff:        300 * time.Millisecond,
+			BackoffMultiplier: 2,
+		}
+
+		assert.Equal(t, 600*time.Millisecond, policy.TotalBackoff())
+
+		policy.MaxAttempts = 1
+		assert.Equal(t, time.Duration(0), policy.TotalBackoff())
+	})
+}
@@ -2,6 +2,7 @@ package subscription
 
 import (
 	"context"
+	"slices"
 
 	"cloud.google.com/go/pubsub"
 	"github.com/ankorstore/yokai-contrib/fxgcppubsub/codec"
@@ -11,20 +12,52 @@ import (
 // SubscribeFunc represents the Subscription execution callback.
 type SubscribeFunc func(ctx context.Context, m *message.Message)
 
+// SubscribeErrorFunc represents the Subscription execution callback returning an error, to be used with a RetryPolicy.
+type SubscribeErrorFunc func(ctx context.Context, m *message.Message) error
+
 // Subscription represents a pub/sub subscription with an associated codec.Codec.
+//
+// The SubscribeOption are resolved per Subscribe call: with a client (see WithClient), a Subscription is safe for
+// concurrent subscriptions.
 type Subscription struct {
 	codec        codec.Codec
 	subscription *pubsub.Subscription
-	options      *Options
+	client       *pubsub.Client
+	options      []SubscribeOption
+	ordered      bool
+}
+
+// SubscriptionOption is a functional option for the Subscription.
+type SubscriptionOption func(s *Subscription)
+
+// WithOrderedHandling records that the subscription has message ordering enabled: the pubsub client then delivers the
+// messages of a same ordering key serially, in order, while messages of different ordering keys are handled concurrently.
+func WithOrderedHandling(enabled bool) SubscriptionOption {
+	return func(s *Subscription) {
+		s.ordered = enabled
+	}
+}
+
+// WithClient configures the client used to create a dedicated base pubsub.Subscription per Subscribe call, since a base
+// pubsub.Subscription cannot receive concurrently. Without client, the base subscription is used.
+func WithClient(client *pubsub.Client) SubscriptionOption {
+	return func(s *Subscription) {
+		s.client = client
+	}
 }
 
 // NewSubscription returns a new Subscription instance.
-func NewSubscription(codec codec.Codec, subscription *pubsub.Subscription) *Subscription {
-	return &Subscription{
+func NewSubscription(codec codec.Codec, subscription *pubsub.Subscription, options ...SubscriptionOption) *Subscription {
+	s := &Subscription{
 		codec:        codec,
 		subscription: subscription,
-		options:      DefaultSubscribeOptions(),
 	}
+
+	for _, applyOpt := range options {
+		applyOpt(s)
+	}
+
+	return s
 }
 
 // Codec returns the subscription associated codec.Codec.
@@ -37,22 +70,47 @@ func (s *Subscription) BaseSubscription() *pubsub.Subscription {
 	return s.subscription
 }
 
-// WithOptions configures the subscription with a list of SubscribeOption.
+// WithOptions returns a copy of the subscription, applying the provided list of SubscribeOption on each of its
+// subscriptions, after the ones already configured. The subscription itself is not modified.
 func (s *Subscription) WithOptions(options ...SubscribeOption) *Subscription {
-	// resolve options
-	for _, applyOpt := range options {
-		applyOpt(s.options)
+	return &Subscription{
+		codec:        s.codec,
+		subscription: s.subscription,
+		client:       s.client,
+		options:      append(slices.Clone(s.options), options...),
+		ordered:      s.ordered,
 	}
+}
 
-	// apply options
-	s.subscription.ReceiveSettings = s.options.ReceiveSettings
+// Options returns the resolved subscription options.
+func (s *Subscription) Options() *Options {
+	options := DefaultSubscribeOptions()
+	for _, applyOpt := range s.options {
+		applyOpt(options)
+	}
 
-	return s
+	return options
 }
 
-// Subscribe starts the subscription and runs the provided SubscribeFunc.
+// OrderedHandling returns true if the messages of a same ordering key are handled serially.
+func (s *Subscription) OrderedHandling() bool {
+	return s.ordered
+}
+
+// Subscribe starts the subscription and runs the provided SubscribeFunc, wrapped by the configured Middleware.
 func (s *Subscription) Subscribe(ctx context.Context, f SubscribeFunc) error {
-	return s.subscription.Receive(ctx, func(fCtx context.Context, msg *pubsub.Message) {
-		f(fCtx, message.NewMessage(s.codec, msg))
+	options := s.Options()
+
+	h := Chain(f, options.Middlewares...)
+
+	base := s.subscription
+	if s.client != nil {
+		base = s.client.Subscription(s.subscription.ID())
+	}
+
+	base.ReceiveSettings = options.ReceiveSettings
+
+	return base.Receive(ctx, func(fCtx context.Context, msg *pubsub.Message) {
+		h(ContextWithSubscriptionID(fCtx, s.subscription.ID()), message.NewMessage(s.codec, msg))
 	})
 }
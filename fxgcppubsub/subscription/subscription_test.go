@@ -76,6 +76,52 @@ func TestSubscription(t *testing.T) {
 
 		assert.Equal(t, cod, sub.Codec())
 		assert.Equal(t, baseSub, sub.BaseSubscription())
+		assert.False(t, sub.OrderedHandling())
+		assert.True(t, subscription.NewSubscription(cod, baseSub, subscription.WithOrderedHandling(true)).OrderedHandling())
+	})
+
+	t.Run("options resolved per call", func(t *testing.T) {
+		sub := subscription.NewSubscription(codec.NewRawCodec(), client.Subscription("raw-subscription"))
+
+		mw := subscription.MiddlewareFunc(func(next subscription.SubscribeFunc) subscription.SubscribeFunc {
+			return next
+		})
+
+		first := sub.WithOptions(subscription.WithMiddlewares(mw), subscription.WithNumGoroutines(1))
+		second := first.WithOptions(subscription.WithMiddlewares(mw))
+
+		assert.Empty(t, sub.Options().Middlewares)
+		assert.Equal(t, pubsub.DefaultReceiveSettings, sub.Options().ReceiveSettings)
+
+		assert.Len(t, first.Options().Middlewares, 1)
+		assert.Equal(t, 1, first.Options().ReceiveSettings.NumGoroutines)
+
+		assert.Len(t, second.Options().Middlewares, 2)
+		assert.Equal(t, 1, second.Options().ReceiveSettings.NumGoroutines)
+	})
+
+	t.Run("concurrent subscriptions with client", func(t *testing.T) {
+		sub := subscription.NewSubscription(
+			codec.NewRawCodec(),
+			client.Subscription("raw-subscription"),
+			subscription.WithClient(client),
+		)
+
+		subscribeCtx, cancel := context.WithTimeout(ctx, 200*time.Millisecond)
+		defer cancel()
+
+		errs := make(chan error, 2)
+
+		for i := 0; i < 2; i++ {
+			go func() {
+				errs <- sub.Subscribe(subscribeCtx, func(ctx context.Context, m *message.Message) {
+					m.Ack()
+				})
+			}()
+		}
+
+		assert.NoError(t, <-errs)
+		assert.NoError(t, <-errs)
 	})
 
 	t.Run("raw message", func(t *testing.T) {
@@ -0,0 +1,32 @@
+package avro
+
+import (
+	"math/big"
+	"time"
+)
+
+type ComplexRecord struct {
+	StringField            string           `avro:"StringField"`
+	OptionalStringField    *string          `avro:"OptionalStringField"`
+	IntField               int32            `avro:"IntField"`
+	LongField              int64            `avro:"LongField"`
+	DoubleField            float64          `avro:"DoubleField"`
+	BytesField             []byte           `avro:"BytesField"`
+	TimestampField         time.Time        `avro:"TimestampField"`
+	OptionalTimestampField *time.Time       `avro:"OptionalTimestampField"`
+	DateField              time.Time        `avro:"DateField"`
+	TimeField              time.Duration    `avro:"TimeField"`
+	DecimalField           *big.Rat         `avro:"DecimalField"`
+	UUIDField              string           `avro:"UUIDField"`
+	EnumField              string           `avro:"EnumField"`
+	FixedField             [4]byte          `avro:"FixedField"`
+	ArrayField             []string         `avro:"ArrayField"`
+	MapField               map[string]int64 `avro:"MapField"`
+	NestedField            ComplexNested    `avro:"NestedField"`
+	OptionalNestedField    *ComplexNested   `avro:"OptionalNestedField"`
+	UnionField             any              `avro:"UnionField"`
+}
+
+type ComplexNested struct {
+	Name string `avro:"Name"`
+}
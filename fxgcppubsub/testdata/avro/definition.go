@@ -10,6 +10,12 @@ import (
 //go:embed simple.avsc
 var contents []byte
 
+//go:embed simple_v2.avsc
+var contentsV2 []byte
+
+//go:embed complex.avsc
+var contentsComplex []byte
+
 func GetTestAvroSchemaDefinition(tb testing.TB) string {
 	tb.Helper()
 
@@ -17,3 +23,19 @@ func GetTestAvroSchemaDefinition(tb testing.TB) string {
 
 	return string(contents)
 }
+
+func GetTestAvroSchemaV2Definition(tb testing.TB) string {
+	tb.Helper()
+
+	assert.NotEmpty(tb, contentsV2)
+
+	return string(contentsV2)
+}
+
+func GetTestAvroComplexSchemaDefinition(tb testing.TB) string {
+	tb.Helper()
+
+	assert.NotEmpty(tb, contentsComplex)
+
+	return string(contentsComplex)
+}
@@ -11,3 +11,10 @@ type InvalidSimpleRecord struct {
 	FloatField   string  `avro:"FloatField" json:"FloatField"`
 	BooleanField float32 `avro:"BooleanField" json:"BooleanField"`
 }
+
+type SimpleRecordV2 struct {
+	StringField  string  `avro:"StringField" json:"StringField"`
+	FloatField   float32 `avro:"FloatField" json:"FloatField"`
+	BooleanField bool    `avro:"BooleanField" json:"BooleanField"`
+	IntField     int32   `avro:"IntField" json:"IntField"`
+}
@@ -0,0 +1,62 @@
+package mysql
+
+import (
+	"database/sql"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/ankorstore/yokai-contrib/fxgomysqlserver/config"
+	"github.com/ankorstore/yokai-contrib/fxgomysqlserver/server"
+	_ "github.com/go-sql-driver/mysql"
+	"github.com/stretchr/testify/assert"
+)
+
+// StartTestServer starts a fxgomysqlserver in memory MySQL server on a free local port, and returns a *sql.DB connected to it.
+func StartTestServer(tb testing.TB) *sql.DB {
+	tb.Helper()
+
+	// free port
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(tb, err)
+
+	//nolint:forcetypeassert
+	port := listener.Addr().(*net.TCPAddr).Port
+	assert.NoError(tb, listener.Close())
+
+	// server
+	serverConfig := config.NewGoMySQLServerConfig(
+		config.WithTransport(config.TCPTransport),
+		config.WithHost("127.0.0.1"),
+		config.WithPort(port),
+	)
+
+	srv, err := server.NewDefaultGoMySQLServerFactory().Create(server.WithConfig(serverConfig))
+	assert.NoError(tb, err)
+
+	//nolint:errcheck
+	go srv.Start()
+
+	tb.Cleanup(func() {
+		//nolint:errcheck
+		srv.Close()
+	})
+
+	// client
+	dsn, err := serverConfig.DSN()
+	assert.NoError(tb, err)
+
+	sqlDB, err := sql.Open("mysql", dsn)
+	assert.NoError(tb, err)
+
+	tb.Cleanup(func() {
+		//nolint:errcheck
+		sqlDB.Close()
+	})
+
+	assert.Eventually(tb, func() bool {
+		return sqlDB.Ping() == nil
+	}, 5*time.Second, 10*time.Millisecond)
+
+	return sqlDB
+}
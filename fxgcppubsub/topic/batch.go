@@ -0,0 +1,84 @@
+package topic
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"cloud.google.com/go/pubsub"
+)
+
+// BatchPublishResult represents the aggregated result of a batch publication.
+type BatchPublishResult struct {
+	results   []*pubsub.PublishResult
+	serverIDs []string
+	errs      []error
+	mutex     sync.Mutex
+}
+
+// NewBatchPublishResult returns a new BatchPublishResult instance, for provided per item results and errors.
+//
+// Items with a non nil error are considered as already failed, their result is ignored.
+func NewBatchPublishResult(results []*pubsub.PublishResult, errs []error) *BatchPublishResult {
+	return &BatchPublishResult{
+		results:   results,
+		serverIDs: make([]string, len(results)),
+		errs:      errs,
+	}
+}
+
+// Len returns the number of items of the batch.
+func (r *BatchPublishResult) Len() int {
+	return len(r.results)
+}
+
+// Results returns the per item pubsub.PublishResult (nil for items failed before publication).
+func (r *BatchPublishResult) Results() []*pubsub.PublishResult {
+	return r.results
+}
+
+// Wait blocks until all the items publications are completed, or until the provided context is done.
+//
+// It returns the joined items errors, which can be inspected individually with Errors.
+func (r *BatchPublishResult) Wait(ctx context.Context) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	for i, res := range r.results {
+		if r.errs[i] != nil || r.serverIDs[i] != "" {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("cannot wait for batch publication: %w", ctx.Err())
+		case <-res.Ready():
+			r.serverIDs[i], r.errs[i] = res.Get(ctx)
+		}
+	}
+
+	return errors.Join(r.errs...)
+}
+
+// ServerIDs returns the per item server ids, empty for failed or not yet completed items.
+func (r *BatchPublishResult) ServerIDs() []string {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	serverIDs := make([]string, len(r.serverIDs))
+	copy(serverIDs, r.serverIDs)
+
+	return serverIDs
+}
+
+// Errors returns the per item errors, nil for succeeded or not yet completed items.
+func (r *BatchPublishResult) Errors() []error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	errs := make([]error, len(r.errs))
+	copy(errs, r.errs)
+
+	return errs
+}
@@ -0,0 +1,151 @@
+package topic_test
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/pubsub"
+	"github.com/ankorstore/yokai-contrib/fxgcppubsub"
+	"github.com/ankorstore/yokai-contrib/fxgcppubsub/codec"
+	"github.com/ankorstore/yokai-contrib/fxgcppubsub/message"
+	"github.com/ankorstore/yokai-contrib/fxgcppubsub/testdata/avro"
+	"github.com/ankorstore/yokai-contrib/fxgcppubsub/topic"
+	"github.com/ankorstore/yokai/fxconfig"
+	"github.com/ankorstore/yokai/fxlog"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/fx"
+	"go.uber.org/fx/fxtest"
+)
+
+func TestTopicPublishBatch(t *testing.T) {
+	t.Setenv("APP_ENV", "test")
+	t.Setenv("APP_CONFIG_PATH", "../testdata/config")
+	t.Setenv("GCP_PROJECT_ID", "test-project")
+
+	var subscriber fxgcppubsub.Subscriber
+	var client *pubsub.Client
+
+	ctx := context.Background()
+	avroSchemaDefinition := avro.GetTestAvroSchemaDefinition(t)
+
+	fxtest.New(
+		t,
+		fx.NopLogger,
+		fxconfig.FxConfigModule,
+		fxlog.FxLogModule,
+		fxgcppubsub.FxGcpPubSubModule,
+		fx.Supply(fx.Annotate(ctx, fx.As(new(context.Context)))),
+		fxgcppubsub.PrepareTopicAndSubscription(fxgcppubsub.PrepareTopicAndSubscriptionParams{
+			TopicID:        "raw-topic",
+			SubscriptionID: "raw-subscription",
+		}),
+		fxgcppubsub.PrepareTopicAndSubscriptionWithSchema(fxgcppubsub.PrepareTopicAndSubscriptionWithSchemaParams{
+			TopicID:        "avro-topic",
+			SubscriptionID: "avro-subscription",
+			SchemaID:       "avro-schema",
+			SchemaConfig: pubsub.SchemaConfig{
+				Name:       "avro-schema",
+				Type:       pubsub.SchemaAvro,
+				Definition: avroSchemaDefinition,
+			},
+			SchemaEncoding: pubsub.EncodingBinary,
+		}),
+		fx.Populate(&subscriber, &client),
+	).RequireStart().RequireStop()
+
+	t.Run("batch success", func(t *testing.T) {
+		top := topic.NewTopic(codec.NewRawCodec(), client.Topic("raw-topic"))
+
+		res, err := top.PublishBatch(ctx, []any{[]byte("data 1"), []byte("data 2"), []byte("data 3")})
+		assert.NoError(t, err)
+		assert.Equal(t, 3, res.Len())
+		assert.Len(t, res.Results(), 3)
+
+		err = res.Wait(ctx)
+		assert.NoError(t, err)
+
+		assert.Equal(t, []error{nil, nil, nil}, res.Errors())
+
+		serverIDs := res.ServerIDs()
+		assert.Len(t, serverIDs, 3)
+		for _, serverID := range serverIDs {
+			assert.NotEmpty(t, serverID)
+		}
+		assert.NotEqual(t, serverIDs[0], serverIDs[1])
+		assert.NotEqual(t, serverIDs[1], serverIDs[2])
+
+		var received atomic.Int32
+
+		subCtx, subCancel := context.WithCancel(ctx)
+		defer subCancel()
+
+		//nolint:errcheck
+		go subscriber.Subscribe(subCtx, "raw-subscription", func(ctx context.Context, m *message.Message) {
+			received.Add(1)
+
+			m.Ack()
+		})
+
+		assert.Eventually(t, func() bool {
+			return received.Load() == 3
+		}, 2*time.Second, 10*time.Millisecond)
+	})
+
+	t.Run("batch encoding failure", func(t *testing.T) {
+		cod, err := codec.NewAvroBinaryCodec(avroSchemaDefinition)
+		assert.NoError(t, err)
+
+		var published atomic.Int32
+
+		top := topic.NewTopic(cod, client.Topic("avro-topic")).WithOptions(
+			topic.WithMiddlewares(topic.MiddlewareFunc(func(next topic.PublishFunc) topic.PublishFunc {
+				return func(ctx context.Context, msg *pubsub.Message) (*pubsub.PublishResult, error) {
+					published.Add(1)
+
+					return next(ctx, msg)
+				}
+			})),
+		)
+
+		res, err := top.PublishBatch(ctx, []any{
+			&avro.SimpleRecord{StringField: "test avro", FloatField: 12.34, BooleanField: true},
+			&avro.InvalidSimpleRecord{StringField: true, FloatField: "test", BooleanField: 12.34},
+		})
+		assert.Nil(t, res)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "cannot encode data at index 1")
+
+		// nothing published
+		assert.Equal(t, int32(0), published.Load())
+	})
+
+	t.Run("batch with per item failures", func(t *testing.T) {
+		top := topic.NewTopic(codec.NewRawCodec(), client.Topic("raw-topic")).WithOptions(
+			topic.WithMiddlewares(topic.MiddlewareFunc(func(next topic.PublishFunc) topic.PublishFunc {
+				return func(ctx context.Context, msg *pubsub.Message) (*pubsub.PublishResult, error) {
+					if string(msg.Data) == "invalid" {
+						return nil, fmt.Errorf("invalid data")
+					}
+
+					return next(ctx, msg)
+				}
+			})),
+		)
+
+		res, err := top.PublishBatch(ctx, []any{[]byte("valid"), []byte("invalid")})
+		assert.NoError(t, err)
+
+		err = res.Wait(ctx)
+		assert.Error(t, err)
+		assert.Equal(t, "invalid data", err.Error())
+
+		assert.Nil(t, res.Results()[1])
+		assert.NotEmpty(t, res.ServerIDs()[0])
+		assert.Empty(t, res.ServerIDs()[1])
+		assert.NoError(t, res.Errors()[0])
+		assert.Error(t, res.Errors()[1])
+	})
+}
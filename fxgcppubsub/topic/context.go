@@ -0,0 +1,36 @@
+package topic
+
+import "context"
+
+type (
+	ctxTopicIDKey     struct{}
+	ctxTopicSchemaKey struct{}
+)
+
+// ContextWithTopicID returns a copy of the provided context, carrying the provided topicID.
+func ContextWithTopicID(ctx context.Context, topicID string) context.Context {
+	return context.WithValue(ctx, ctxTopicIDKey{}, topicID)
+}
+
+// CtxTopicID returns the topicID carried by the provided context, or an empty string if missing.
+func CtxTopicID(ctx context.Context) string {
+	if topicID, ok := ctx.Value(ctxTopicIDKey{}).(string); ok {
+		return topicID
+	}
+
+	return ""
+}
+
+// ContextWithTopicSchema returns a copy of the provided context, carrying the provided topic schema name.
+func ContextWithTopicSchema(ctx context.Context, schema string) context.Context {
+	return context.WithValue(ctx, ctxTopicSchemaKey{}, schema)
+}
+
+// CtxTopicSchema returns the topic schema name carried by the provided context, or an empty string if missing.
+func CtxTopicSchema(ctx context.Context) string {
+	if schema, ok := ctx.Value(ctxTopicSchemaKey{}).(string); ok {
+		return schema
+	}
+
+	return ""
+}
@@ -16,20 +16,56 @@ type TopicFactory interface {
 	Create(ctx context.Context, topicID string) (*Topic, error)
 }
 
+// TopicFactoryOption is a functional option for the DefaultTopicFactory.
+type TopicFactoryOption func(f *DefaultTopicFactory)
+
+// WithCodecRegistry configures the codec.CodecRegistry used for topics without schema: the codec is selected by name
+// from the provided codec.CodecNameFunc, or from the topic codec.CodecLabel label.
+func WithCodecRegistry(codecs codec.CodecRegistry, codecName codec.CodecNameFunc) TopicFactoryOption {
+	return func(f *DefaultTopicFactory) {
+		f.codecs = codecs
+		f.codecName = codecName
+	}
+}
+
+// WithPublishSettingsFunc configures the PublishSettingsFunc providing the publish settings of the created topics.
+func WithPublishSettingsFunc(publishSettings PublishSettingsFunc) TopicFactoryOption {
+	return func(f *DefaultTopicFactory) {
+		f.publishSettings = publishSettings
+	}
+}
+
+// WithResumePolicyFunc configures the ResumePolicyFunc providing the ResumePolicy of the created topics.
+func WithResumePolicyFunc(resumePolicy ResumePolicyFunc) TopicFactoryOption {
+	return func(f *DefaultTopicFactory) {
+		f.resumePolicy = resumePolicy
+	}
+}
+
 // DefaultTopicFactory is the default TopicFactory implementation.
 type DefaultTopicFactory struct {
-	client   *pubsub.Client
-	registry schema.SchemaConfigRegistry
-	factory  codec.CodecFactory
+	client          *pubsub.Client
+	registry        schema.SchemaConfigRegistry
+	factory         codec.CodecFactory
+	codecs          codec.CodecRegistry
+	codecName       codec.CodecNameFunc
+	publishSettings PublishSettingsFunc
+	resumePolicy    ResumePolicyFunc
 }
 
 // NewDefaultTopicFactory returns a new DefaultTopicFactory instance.
-func NewDefaultTopicFactory(client *pubsub.Client, registry schema.SchemaConfigRegistry, factory codec.CodecFactory) *DefaultTopicFactory {
-	return &DefaultTopicFactory{
+func NewDefaultTopicFactory(client *pubsub.Client, registry schema.SchemaConfigRegistry, factory codec.CodecFactory, options ...TopicFactoryOption) *DefaultTopicFactory {
+	f := &DefaultTopicFactory{
 		client:   client,
 		registry: registry,
 		factory:  factory,
 	}
+
+	for _, applyOpt := range options {
+		applyOpt(f)
+	}
+
+	return f
 }
 
 // Create creates a new Topic.
@@ -43,10 +79,8 @@ func (f *DefaultTopicFactory) Create(ctx context.Context, topicID string) (*Topi
 		return nil, fmt.Errorf("cannot get topic %s configuration: %w", topicID, err)
 	}
 
-	// topic schema config
-	topicSchemaType := pubsub.SchemaTypeUnspecified
-	topicSchemaEncoding := pubsub.EncodingUnspecified
-	topicSchemaDefinition := ""
+	// topic codec
+	var topicCodec codec.Codec
 
 	if topicConfig.SchemaSettings != nil {
 		topicSchemaConfig, err := f.registry.Get(ctx, topicConfig.SchemaSettings.Schema)
@@ -54,16 +88,55 @@ func (f *DefaultTopicFactory) Create(ctx context.Context, topicID string) (*Topi
 			return nil, fmt.Errorf("cannot get topic %s schema configuration: %w", topicID, err)
 		}
 
-		topicSchemaType = topicSchemaConfig.Type
-		topicSchemaEncoding = topicConfig.SchemaSettings.Encoding
-		topicSchemaDefinition = topicSchemaConfig.Definition
+		topicCodec, err = codec.NewRevisionAwareCodec(
+			f.factory,
+			topicSchemaConfig.Type,
+			topicConfig.SchemaSettings.Encoding,
+			schema.RevisionFunc(ctx, f.registry, topicConfig.SchemaSettings.Schema),
+		)
+		if err != nil {
+			return nil, fmt.Errorf("cannot create topic %s codec: %w", topicID, err)
+		}
+	} else if codecName := f.resolveCodecName(topicID, topicConfig.Labels); codecName != "" {
+		topicCodec, err = f.codecs.Get(codecName)
+		if err != nil {
+			return nil, fmt.Errorf("cannot get topic %s codec: %w", topicID, err)
+		}
+	} else {
+		topicCodec, err = f.factory.Create(pubsub.SchemaTypeUnspecified, pubsub.EncodingUnspecified, "")
+		if err != nil {
+			return nil, fmt.Errorf("cannot create topic %s codec: %w", topicID, err)
+		}
 	}
 
-	// topic codec
-	topicCodec, err := f.factory.Create(topicSchemaType, topicSchemaEncoding, topicSchemaDefinition)
-	if err != nil {
-		return nil, fmt.Errorf("cannot create topic %s codec: %w", topicID, err)
+	// topic options
+	var topicOptions []TopicOption
+
+	if topicConfig.SchemaSettings != nil {
+		topicOptions = append(topicOptions, WithSchema(topicConfig.SchemaSettings.Schema))
+	}
+
+	if f.publishSettings != nil {
+		topicOptions = append(topicOptions, WithPublishSettings(f.publishSettings(topicID)))
+	}
+
+	if f.resumePolicy != nil {
+		topicOptions = append(topicOptions, WithResumePolicy(f.resumePolicy(topicID)))
+	}
+
+	return NewTopic(topicCodec, topic, topicOptions...), nil
+}
+
+func (f *DefaultTopicFactory) resolveCodecName(topicID string, labels map[string]string) string {
+	if f.codecs == nil {
+		return ""
+	}
+
+	if f.codecName != nil {
+		if name := f.codecName(topicID); name != "" {
+			return name
+		}
 	}
 
-	return NewTopic(topicCodec, topic), nil
+	return labels[codec.CodecLabel]
 }
@@ -4,7 +4,9 @@ import (
 	"context"
 	"testing"
 
+	"cloud.google.com/go/pubsub"
 	"github.com/ankorstore/yokai-contrib/fxgcppubsub"
+	"github.com/ankorstore/yokai-contrib/fxgcppubsub/codec"
 	"github.com/ankorstore/yokai-contrib/fxgcppubsub/topic"
 	"github.com/ankorstore/yokai/fxconfig"
 	"github.com/ankorstore/yokai/fxlog"
@@ -40,6 +42,7 @@ func TestDefaultTopicFactory(t *testing.T) {
 		assert.NoError(t, err)
 
 		assert.Equal(t, "test-topic", top.BaseTopic().ID())
+		assert.Empty(t, top.Schema())
 	})
 
 	t.Run("topic creation with schema", func(t *testing.T) {
@@ -61,6 +64,119 @@ func TestDefaultTopicFactory(t *testing.T) {
 		assert.NoError(t, err)
 
 		assert.Equal(t, "test-topic", sub.BaseTopic().ID())
+		assert.Equal(t, "projects/test-project/schemas/test-schema", sub.Schema())
+	})
+
+	t.Run("topic creation with codec from label", func(t *testing.T) {
+		fxtest.New(
+			t,
+			fx.NopLogger,
+			fxconfig.FxConfigModule,
+			fxlog.FxLogModule,
+			fxgcppubsub.FxGcpPubSubModule,
+			fx.Supply(fx.Annotate(ctx, fx.As(new(context.Context)))),
+			fxgcppubsub.PrepareTopic(fxgcppubsub.PrepareTopicParams{
+				TopicID: "test-topic",
+				TopicConfig: pubsub.TopicConfig{
+					Labels: map[string]string{codec.CodecLabel: codec.JsonCodecName},
+				},
+			}),
+			fx.Populate(&factory),
+		).RequireStart().RequireStop()
+
+		top, err := factory.Create(ctx, "test-topic")
+		assert.NoError(t, err)
+
+		assert.IsType(t, &codec.JsonCodec{}, top.Codec())
+	})
+
+	t.Run("topic creation with codec from config", func(t *testing.T) {
+		fxtest.New(
+			t,
+			fx.NopLogger,
+			fxconfig.FxConfigModule,
+			fxlog.FxLogModule,
+			fxgcppubsub.FxGcpPubSubModule,
+			fx.Supply(fx.Annotate(ctx, fx.As(new(context.Context)))),
+			fxgcppubsub.PrepareTopic(fxgcppubsub.PrepareTopicParams{
+				TopicID: "codec-topic",
+				TopicConfig: pubsub.TopicConfig{
+					Labels: map[string]string{codec.CodecLabel: codec.JsonCodecName},
+				},
+			}),
+			fx.Populate(&factory),
+		).RequireStart().RequireStop()
+
+		top, err := factory.Create(ctx, "codec-topic")
+		assert.NoError(t, err)
+
+		assert.IsType(t, &codec.CloudEventsCodec{}, top.Codec())
+	})
+
+	t.Run("topic creation with publish settings from config", func(t *testing.T) {
+		fxtest.New(
+			t,
+			fx.NopLogger,
+			fxconfig.FxConfigModule,
+			fxlog.FxLogModule,
+			fxgcppubsub.FxGcpPubSubModule,
+			fx.Supply(fx.Annotate(ctx, fx.As(new(context.Context)))),
+			fxgcppubsub.PrepareTopic(fxgcppubsub.PrepareTopicParams{
+				TopicID: "settings-topic",
+			}),
+			fx.Populate(&factory),
+		).RequireStart().RequireStop()
+
+		top, err := factory.Create(ctx, "settings-topic")
+		assert.NoError(t, err)
+
+		assert.Equal(t, 10, top.PublishSettings().CountThreshold)
+		assert.True(t, top.PublishSettings().EnableCompression)
+		assert.Equal(t, top.PublishSettings(), top.BaseTopic().PublishSettings)
+	})
+
+	t.Run("topic creation with resume policy from config", func(t *testing.T) {
+		fxtest.New(
+			t,
+			fx.NopLogger,
+			fxconfig.FxConfigModule,
+			fxlog.FxLogModule,
+			fxgcppubsub.FxGcpPubSubModule,
+			fx.Supply(fx.Annotate(ctx, fx.As(new(context.Context)))),
+			fxgcppubsub.PrepareTopic(fxgcppubsub.PrepareTopicParams{
+				TopicID: "ordered-topic",
+			}),
+			fx.Populate(&factory),
+		).RequireStart().RequireStop()
+
+		top, err := factory.Create(ctx, "ordered-topic")
+		assert.NoError(t, err)
+
+		assert.True(t, top.ResumePolicy().Automatic)
+		assert.Equal(t, 3, top.ResumePolicy().MaxAttempts)
+	})
+
+	t.Run("topic creation with unknown codec", func(t *testing.T) {
+		fxtest.New(
+			t,
+			fx.NopLogger,
+			fxconfig.FxConfigModule,
+			fxlog.FxLogModule,
+			fxgcppubsub.FxGcpPubSubModule,
+			fx.Supply(fx.Annotate(ctx, fx.As(new(context.Context)))),
+			fxgcppubsub.PrepareTopic(fxgcppubsub.PrepareTopicParams{
+				TopicID: "test-topic",
+				TopicConfig: pubsub.TopicConfig{
+					Labels: map[string]string{codec.CodecLabel: "unknown"},
+				},
+			}),
+			fx.Populate(&factory),
+		).RequireStart().RequireStop()
+
+		top, err := factory.Create(ctx, "test-topic")
+		assert.Nil(t, top)
+		assert.Error(t, err)
+		assert.Equal(t, "cannot get topic test-topic codec: codec unknown not found", err.Error())
 	})
 
 	t.Run("topic creation error", func(t *testing.T) {
@@ -0,0 +1,32 @@
+package topic
+
+import (
+	"context"
+
+	"cloud.google.com/go/pubsub"
+)
+
+// PublishFunc represents the Topic publication callback, receiving the encoded message.
+type PublishFunc func(ctx context.Context, msg *pubsub.Message) (*pubsub.PublishResult, error)
+
+// Middleware is the interface for topic middlewares, wrapping a PublishFunc.
+type Middleware interface {
+	Wrap(next PublishFunc) PublishFunc
+}
+
+// MiddlewareFunc is a func adapter for the Middleware interface.
+type MiddlewareFunc func(next PublishFunc) PublishFunc
+
+// Wrap wraps the next PublishFunc.
+func (f MiddlewareFunc) Wrap(next PublishFunc) PublishFunc {
+	return f(next)
+}
+
+// Chain wraps a PublishFunc with a list of Middleware, the first one being the outermost.
+func Chain(f PublishFunc, middlewares ...Middleware) PublishFunc {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		f = middlewares[i].Wrap(f)
+	}
+
+	return f
+}
@@ -0,0 +1,50 @@
+package middleware
+
+import (
+	"context"
+
+	"cloud.google.com/go/pubsub"
+	"github.com/ankorstore/yokai-contrib/fxgcppubsub/topic"
+)
+
+var _ topic.Middleware = (*AttributesMiddleware)(nil)
+
+// AttributesProvider is a func providing message attributes from a context.
+type AttributesProvider func(ctx context.Context) map[string]string
+
+// AttributesMiddleware is a topic.Middleware injecting attributes (correlation id, tenant, schema version, ...)
+// in the published messages. Attributes already provided for the publication are not overridden.
+type AttributesMiddleware struct {
+	provider AttributesProvider
+}
+
+// NewAttributesMiddleware returns a new AttributesMiddleware instance.
+func NewAttributesMiddleware(provider AttributesProvider) *AttributesMiddleware {
+	return &AttributesMiddleware{
+		provider: provider,
+	}
+}
+
+// NewStaticAttributesMiddleware returns a new AttributesMiddleware instance, injecting static attributes.
+func NewStaticAttributesMiddleware(attributes map[string]string) *AttributesMiddleware {
+	return NewAttributesMiddleware(func(context.Context) map[string]string {
+		return attributes
+	})
+}
+
+// Wrap wraps the next topic.PublishFunc.
+func (mw *AttributesMiddleware) Wrap(next topic.PublishFunc) topic.PublishFunc {
+	return func(ctx context.Context, msg *pubsub.Message) (*pubsub.PublishResult, error) {
+		if msg.Attributes == nil {
+			msg.Attributes = make(map[string]string)
+		}
+
+		for k, v := range mw.provider(ctx) {
+			if _, found := msg.Attributes[k]; !found {
+				msg.Attributes[k] = v
+			}
+		}
+
+		return next(ctx, msg)
+	}
+}
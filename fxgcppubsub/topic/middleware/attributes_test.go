@@ -0,0 +1,53 @@
+package middleware_test
+
+import (
+	"context"
+	"testing"
+
+	"cloud.google.com/go/pubsub"
+	"github.com/ankorstore/yokai-contrib/fxgcppubsub/topic/middleware"
+	"github.com/stretchr/testify/assert"
+)
+
+type ctxTenantKey struct{}
+
+func TestAttributesMiddleware(t *testing.T) {
+	t.Parallel()
+
+	var published *pubsub.Message
+
+	next := func(ctx context.Context, msg *pubsub.Message) (*pubsub.PublishResult, error) {
+		published = msg
+
+		return nil, nil
+	}
+
+	t.Run("attributes from context", func(t *testing.T) {
+		mw := middleware.NewAttributesMiddleware(func(ctx context.Context) map[string]string {
+			return map[string]string{
+				"tenant": ctx.Value(ctxTenantKey{}).(string),
+			}
+		})
+
+		ctx := context.WithValue(context.Background(), ctxTenantKey{}, "test-tenant")
+
+		_, err := mw.Wrap(next)(ctx, &pubsub.Message{})
+		assert.NoError(t, err)
+
+		assert.Equal(t, map[string]string{"tenant": "test-tenant"}, published.Attributes)
+	})
+
+	t.Run("static attributes without override", func(t *testing.T) {
+		mw := middleware.NewStaticAttributesMiddleware(map[string]string{
+			"foo":     "foo",
+			"version": "v1",
+		})
+
+		_, err := mw.Wrap(next)(context.Background(), &pubsub.Message{
+			Attributes: map[string]string{"version": "v2"},
+		})
+		assert.NoError(t, err)
+
+		assert.Equal(t, map[string]string{"foo": "foo", "version": "v2"}, published.Attributes)
+	})
+}
@@ -0,0 +1,48 @@
+package middleware
+
+import (
+	"context"
+
+	"cloud.google.com/go/pubsub"
+	"github.com/ankorstore/yokai-contrib/fxgcppubsub/topic"
+	"github.com/ankorstore/yokai/log"
+)
+
+var _ topic.Middleware = (*LoggerMiddleware)(nil)
+
+// LoggerMiddleware is a topic.Middleware logging the message publication.
+type LoggerMiddleware struct {
+	logger *log.Logger
+}
+
+// NewLoggerMiddleware returns a new LoggerMiddleware instance.
+func NewLoggerMiddleware(logger *log.Logger) *LoggerMiddleware {
+	return &LoggerMiddleware{
+		logger: logger,
+	}
+}
+
+// Wrap wraps the next topic.PublishFunc.
+func (mw *LoggerMiddleware) Wrap(next topic.PublishFunc) topic.PublishFunc {
+	return func(ctx context.Context, msg *pubsub.Message) (*pubsub.PublishResult, error) {
+		res, err := next(ctx, msg)
+		if err != nil {
+			mw.logger.
+				Error().
+				Err(err).
+				Str("topicID", topic.CtxTopicID(ctx)).
+				Msg("pubsub message publication error")
+
+			return res, err
+		}
+
+		mw.logger.
+			Debug().
+			Str("topicID", topic.CtxTopicID(ctx)).
+			Interface("attributes", msg.Attributes).
+			Str("orderingKey", msg.OrderingKey).
+			Msg("pubsub message publication")
+
+		return res, nil
+	}
+}
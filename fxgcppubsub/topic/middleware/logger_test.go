@@ -0,0 +1,65 @@
+package middleware_test
+
+import (
+	"context"
+	"testing"
+
+	"cloud.google.com/go/pubsub"
+	"github.com/ankorstore/yokai-contrib/fxgcppubsub/topic"
+	"github.com/ankorstore/yokai-contrib/fxgcppubsub/topic/middleware"
+	"github.com/ankorstore/yokai/log"
+	"github.com/ankorstore/yokai/log/logtest"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoggerMiddleware(t *testing.T) {
+	t.Parallel()
+
+	logBuffer := logtest.NewDefaultTestLogBuffer()
+	logger, err := log.NewDefaultLoggerFactory().Create(
+		log.WithLevel(zerolog.DebugLevel),
+		log.WithOutputWriter(logBuffer),
+	)
+	assert.NoError(t, err)
+
+	mw := middleware.NewLoggerMiddleware(logger)
+
+	ctx := topic.ContextWithTopicID(context.Background(), "test-topic")
+
+	t.Run("publication success", func(t *testing.T) {
+		t.Parallel()
+
+		f := mw.Wrap(func(ctx context.Context, msg *pubsub.Message) (*pubsub.PublishResult, error) {
+			return nil, nil
+		})
+
+		_, err := f(ctx, &pubsub.Message{OrderingKey: "test-key"})
+		assert.NoError(t, err)
+
+		logtest.AssertHasLogRecord(t, logBuffer, map[string]interface{}{
+			"level":       "debug",
+			"topicID":     "test-topic",
+			"orderingKey": "test-key",
+			"message":     "pubsub message publication",
+		})
+	})
+
+	t.Run("publication error", func(t *testing.T) {
+		t.Parallel()
+
+		f := mw.Wrap(func(ctx context.Context, msg *pubsub.Message) (*pubsub.PublishResult, error) {
+			return nil, assert.AnError
+		})
+
+		_, err := f(ctx, &pubsub.Message{})
+		assert.Equal(t, assert.AnError, err)
+
+		logtest.AssertHasLogRecord(t, logBuffer, map[string]interface{}{
+			"level":   "error",
+			"error":   assert.AnError.Error(),
+			"topicID": "test-topic",
+			"message": "pubsub message publication error",
+		})
+	})
+}
@@ -0,0 +1,53 @@
+package middleware
+
+import (
+	"context"
+	"time"
+
+	"cloud.google.com/go/pubsub"
+	"github.com/ankorstore/yokai-contrib/fxgcppubsub/metrics"
+	"github.com/ankorstore/yokai-contrib/fxgcppubsub/topic"
+)
+
+var _ topic.Middleware = (*MetricsMiddleware)(nil)
+
+// MetricsMiddleware is a topic.Middleware recording the publications metrics.
+type MetricsMiddleware struct {
+	metrics *metrics.Metrics
+}
+
+// NewMetricsMiddleware returns a new MetricsMiddleware instance.
+func NewMetricsMiddleware(metrics *metrics.Metrics) *MetricsMiddleware {
+	return &MetricsMiddleware{
+		metrics: metrics,
+	}
+}
+
+// Wrap wraps the next topic.PublishFunc.
+func (mw *MetricsMiddleware) Wrap(next topic.PublishFunc) topic.PublishFunc {
+	return func(ctx context.Context, msg *pubsub.Message) (*pubsub.PublishResult, error) {
+		topicID := topic.CtxTopicID(ctx)
+		start := time.Now()
+
+		res, err := next(ctx, msg)
+		if err != nil {
+			mw.metrics.PublishErrors.WithLabelValues(topicID).Inc()
+
+			return res, err
+		}
+
+		go func() {
+			<-res.Ready()
+
+			mw.metrics.PublishDuration.WithLabelValues(topicID).Observe(time.Since(start).Seconds())
+
+			if _, resErr := res.Get(context.Background()); resErr != nil {
+				mw.metrics.PublishErrors.WithLabelValues(topicID).Inc()
+			} else {
+				mw.metrics.PublishedMessages.WithLabelValues(topicID).Inc()
+			}
+		}()
+
+		return res, nil
+	}
+}
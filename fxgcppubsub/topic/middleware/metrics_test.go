@@ -0,0 +1,33 @@
+package middleware_test
+
+import (
+	"context"
+	"testing"
+
+	"cloud.google.com/go/pubsub"
+	"github.com/ankorstore/yokai-contrib/fxgcppubsub/metrics"
+	"github.com/ankorstore/yokai-contrib/fxgcppubsub/topic"
+	"github.com/ankorstore/yokai-contrib/fxgcppubsub/topic/middleware"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMetricsMiddleware(t *testing.T) {
+	t.Parallel()
+
+	m := metrics.NewMetrics("", "", nil)
+
+	mw := middleware.NewMetricsMiddleware(m)
+
+	f := mw.Wrap(func(ctx context.Context, msg *pubsub.Message) (*pubsub.PublishResult, error) {
+		return nil, assert.AnError
+	})
+
+	ctx := topic.ContextWithTopicID(context.Background(), "test-topic")
+
+	_, err := f(ctx, &pubsub.Message{})
+	assert.Equal(t, assert.AnError, err)
+
+	assert.Equal(t, float64(1), testutil.ToFloat64(m.PublishErrors.WithLabelValues("test-topic")))
+	assert.Equal(t, float64(0), testutil.ToFloat64(m.PublishedMessages.WithLabelValues("test-topic")))
+}
@@ -0,0 +1,86 @@
+package middleware
+
+import (
+	"context"
+
+	"cloud.google.com/go/pubsub"
+	"github.com/ankorstore/yokai-contrib/fxgcppubsub/topic"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	oteltrace "go.opentelemetry.io/otel/trace"
+)
+
+// TracerName is the name of the tracer used for publications.
+const TracerName = "github.com/ankorstore/yokai-contrib/fxgcppubsub/topic"
+
+var _ topic.Middleware = (*TraceMiddleware)(nil)
+
+// TraceMiddleware is a topic.Middleware starting a producer span for the publication,
+// and injecting its trace context in the message attributes.
+type TraceMiddleware struct {
+	tracer     oteltrace.Tracer
+	propagator propagation.TextMapPropagator
+}
+
+// TraceOption is a functional option for the TraceMiddleware.
+type TraceOption func(mw *TraceMiddleware)
+
+// WithPropagator sets the propagator used to inject the trace context, the global one by default.
+func WithPropagator(propagator propagation.TextMapPropagator) TraceOption {
+	return func(mw *TraceMiddleware) {
+		mw.propagator = propagator
+	}
+}
+
+// NewTraceMiddleware returns a new TraceMiddleware instance, using the global tracer provider if none is provided.
+func NewTraceMiddleware(tracerProvider oteltrace.TracerProvider, options ...TraceOption) *TraceMiddleware {
+	if tracerProvider == nil {
+		tracerProvider = otel.GetTracerProvider()
+	}
+
+	mw := &TraceMiddleware{
+		tracer:     tracerProvider.Tracer(TracerName),
+		propagator: otel.GetTextMapPropagator(),
+	}
+
+	for _, opt := range options {
+		opt(mw)
+	}
+
+	return mw
+}
+
+// Wrap wraps the next topic.PublishFunc.
+func (mw *TraceMiddleware) Wrap(next topic.PublishFunc) topic.PublishFunc {
+	return func(ctx context.Context, msg *pubsub.Message) (*pubsub.PublishResult, error) {
+		topicID := topic.CtxTopicID(ctx)
+
+		ctx, span := mw.tracer.Start(
+			ctx,
+			"pubsub publish "+topicID,
+			oteltrace.WithSpanKind(oteltrace.SpanKindProducer),
+			oteltrace.WithAttributes(
+				attribute.String("messaging.system", "gcp_pubsub"),
+				attribute.String("messaging.operation", "publish"),
+				attribute.String("messaging.destination.name", topicID),
+			),
+		)
+		defer span.End()
+
+		if msg.Attributes == nil {
+			msg.Attributes = make(map[string]string)
+		}
+
+		mw.propagator.Inject(ctx, propagation.MapCarrier(msg.Attributes))
+
+		res, err := next(ctx, msg)
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+
+		return res, err
+	}
+}
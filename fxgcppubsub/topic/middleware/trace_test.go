@@ -0,0 +1,115 @@
+package middleware_test
+
+import (
+	"context"
+	"testing"
+
+	"cloud.google.com/go/pubsub"
+	"github.com/ankorstore/yokai-contrib/fxgcppubsub/topic"
+	"github.com/ankorstore/yokai-contrib/fxgcppubsub/topic/middleware"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/baggage"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	oteltrace "go.opentelemetry.io/otel/trace"
+)
+
+func TestTraceMiddleware(t *testing.T) {
+	t.Parallel()
+
+	ctx := topic.ContextWithTopicID(context.Background(), "test-topic")
+
+	t.Run("trace context injection", func(t *testing.T) {
+		t.Parallel()
+
+		recorder := tracetest.NewSpanRecorder()
+		tracerProvider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+		mw := middleware.NewTraceMiddleware(tracerProvider, middleware.WithPropagator(propagation.TraceContext{}))
+
+		var published *pubsub.Message
+
+		f := mw.Wrap(func(ctx context.Context, msg *pubsub.Message) (*pubsub.PublishResult, error) {
+			published = msg
+
+			return nil, nil
+		})
+
+		_, err := f(ctx, &pubsub.Message{})
+		assert.NoError(t, err)
+
+		spans := recorder.Ended()
+		assert.Len(t, spans, 1)
+
+		span := spans[0]
+		assert.Equal(t, "pubsub publish test-topic", span.Name())
+		assert.Equal(t, oteltrace.SpanKindProducer, span.SpanKind())
+		assert.Contains(t, span.Attributes(), attribute.String("messaging.destination.name", "test-topic"))
+
+		assert.Contains(t, published.Attributes["traceparent"], span.SpanContext().TraceID().String())
+		assert.Contains(t, published.Attributes["traceparent"], span.SpanContext().SpanID().String())
+	})
+
+	t.Run("trace publication error", func(t *testing.T) {
+		t.Parallel()
+
+		recorder := tracetest.NewSpanRecorder()
+		tracerProvider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+		mw := middleware.NewTraceMiddleware(tracerProvider, middleware.WithPropagator(propagation.TraceContext{}))
+
+		f := mw.Wrap(func(ctx context.Context, msg *pubsub.Message) (*pubsub.PublishResult, error) {
+			return nil, assert.AnError
+		})
+
+		_, err := f(ctx, &pubsub.Message{})
+		assert.Equal(t, assert.AnError, err)
+
+		spans := recorder.Ended()
+		assert.Len(t, spans, 1)
+		assert.Equal(t, codes.Error, spans[0].Status().Code)
+	})
+}
+
+func TestTraceMiddlewareWithGlobalPropagator(t *testing.T) {
+	propagator := otel.GetTextMapPropagator()
+	t.Cleanup(func() {
+		otel.SetTextMapPropagator(propagator)
+	})
+
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	member, err := baggage.NewMember("foo", "bar")
+	assert.NoError(t, err)
+
+	bag, err := baggage.New(member)
+	assert.NoError(t, err)
+
+	ctx := baggage.ContextWithBaggage(topic.ContextWithTopicID(context.Background(), "test-topic"), bag)
+
+	recorder := tracetest.NewSpanRecorder()
+	tracerProvider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+	mw := middleware.NewTraceMiddleware(tracerProvider)
+
+	var published *pubsub.Message
+
+	f := mw.Wrap(func(ctx context.Context, msg *pubsub.Message) (*pubsub.PublishResult, error) {
+		published = msg
+
+		return nil, nil
+	})
+
+	_, err = f(ctx, &pubsub.Message{})
+	assert.NoError(t, err)
+
+	spans := recorder.Ended()
+	assert.Len(t, spans, 1)
+
+	assert.Contains(t, published.Attributes["traceparent"], spans[0].SpanContext().TraceID().String())
+	assert.Equal(t, "foo=bar", published.Attributes["baggage"])
+}
@@ -0,0 +1,38 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+
+	"cloud.google.com/go/pubsub"
+	"github.com/ankorstore/yokai-contrib/fxgcppubsub/topic"
+)
+
+var _ topic.Middleware = (*ValidatorMiddleware)(nil)
+
+// Validator is a func validating a message before its publication.
+type Validator func(ctx context.Context, msg *pubsub.Message) error
+
+// ValidatorMiddleware is a topic.Middleware preventing the publication of invalid messages.
+type ValidatorMiddleware struct {
+	validator Validator
+}
+
+// NewValidatorMiddleware returns a new ValidatorMiddleware instance.
+func NewValidatorMiddleware(validator Validator) *ValidatorMiddleware {
+	return &ValidatorMiddleware{
+		validator: validator,
+	}
+}
+
+// Wrap wraps the next topic.PublishFunc.
+func (mw *ValidatorMiddleware) Wrap(next topic.PublishFunc) topic.PublishFunc {
+	return func(ctx context.Context, msg *pubsub.Message) (*pubsub.PublishResult, error) {
+		err := mw.validator(ctx, msg)
+		if err != nil {
+			return nil, fmt.Errorf("invalid message: %w", err)
+		}
+
+		return next(ctx, msg)
+	}
+}
@@ -0,0 +1,43 @@
+package middleware_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"cloud.google.com/go/pubsub"
+	"github.com/ankorstore/yokai-contrib/fxgcppubsub/topic/middleware"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidatorMiddleware(t *testing.T) {
+	t.Parallel()
+
+	mw := middleware.NewValidatorMiddleware(func(ctx context.Context, msg *pubsub.Message) error {
+		if len(msg.Data) == 0 {
+			return fmt.Errorf("empty data")
+		}
+
+		return nil
+	})
+
+	f := mw.Wrap(func(ctx context.Context, msg *pubsub.Message) (*pubsub.PublishResult, error) {
+		return nil, nil
+	})
+
+	t.Run("valid message", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := f(context.Background(), &pubsub.Message{Data: []byte("test")})
+		assert.NoError(t, err)
+	})
+
+	t.Run("invalid message", func(t *testing.T) {
+		t.Parallel()
+
+		res, err := f(context.Background(), &pubsub.Message{})
+		assert.Nil(t, res)
+		assert.Error(t, err)
+		assert.Equal(t, "invalid message: empty data", err.Error())
+	})
+}
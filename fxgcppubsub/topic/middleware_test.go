@@ -0,0 +1,77 @@
+package topic_test
+
+import (
+	"context"
+	"testing"
+
+	"cloud.google.com/go/pubsub"
+	"github.com/ankorstore/yokai-contrib/fxgcppubsub/topic"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChain(t *testing.T) {
+	t.Parallel()
+
+	var calls []string
+
+	newMiddleware := func(name string) topic.Middleware {
+		return topic.MiddlewareFunc(func(next topic.PublishFunc) topic.PublishFunc {
+			return func(ctx context.Context, msg *pubsub.Message) (*pubsub.PublishResult, error) {
+				calls = append(calls, name+" before")
+				res, err := next(ctx, msg)
+				calls = append(calls, name+" after")
+
+				return res, err
+			}
+		})
+	}
+
+	f := topic.Chain(
+		func(ctx context.Context, msg *pubsub.Message) (*pubsub.PublishResult, error) {
+			calls = append(calls, "publish")
+
+			return nil, nil
+		},
+		newMiddleware("first"),
+		newMiddleware("second"),
+	)
+
+	_, err := f(context.Background(), &pubsub.Message{})
+	assert.NoError(t, err)
+
+	assert.Equal(
+		t,
+		[]string{
+			"first before",
+			"second before",
+			"publish",
+			"second after",
+			"first after",
+		},
+		calls,
+	)
+}
+
+func TestContextTopicID(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	assert.Equal(t, "", topic.CtxTopicID(ctx))
+
+	ctx = topic.ContextWithTopicID(ctx, "test-topic")
+
+	assert.Equal(t, "test-topic", topic.CtxTopicID(ctx))
+}
+
+func TestContextTopicSchema(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	assert.Equal(t, "", topic.CtxTopicSchema(ctx))
+
+	ctx = topic.ContextWithTopicSchema(ctx, "test-schema")
+
+	assert.Equal(t, "test-schema", topic.CtxTopicSchema(ctx))
+}
@@ -12,10 +12,27 @@ type MessageSettings struct {
 	Attributes  map[string]string
 }
 
+// MergeAttributes returns a new map of the provided encoded attributes (from the codec), overridden by the settings
+// attributes: the returned map can be safely modified.
+func (s MessageSettings) MergeAttributes(encodedAttributes map[string]string) map[string]string {
+	attributes := make(map[string]string, len(encodedAttributes)+len(s.Attributes))
+	for k, v := range encodedAttributes {
+		attributes[k] = v
+	}
+	for k, v := range s.Attributes {
+		attributes[k] = v
+	}
+
+	return attributes
+}
+
 // Options represents publish options.
+//
+// The PublishSettings are the topic ones, fixed per topic (see WithPublishSettings).
 type Options struct {
 	PublishSettings pubsub.PublishSettings
 	MessageSettings MessageSettings
+	Middlewares     []Middleware
 }
 
 // DefaultPublishOptions is the default publish options.
@@ -32,60 +49,68 @@ func DefaultPublishOptions() *Options {
 // PublishOption represents publish functional options.
 type PublishOption func(o *Options)
 
-// WithDelayThreshold sets the delay threshold.
-func WithDelayThreshold(t time.Duration) PublishOption {
-	return func(o *Options) {
-		o.PublishSettings.DelayThreshold = t
-	}
+// WithDelayThreshold is a no-op, kept for compatibility.
+//
+// Deprecated: the publish settings are fixed per topic, configured in modules.gcppubsub.topics.<topic id>.publish
+// (see WithPublishSettings): this option is ignored.
+func WithDelayThreshold(time.Duration) PublishOption {
+	return func(*Options) {}
 }
 
-// WithCountThreshold sets the count threshold.
-func WithCountThreshold(n int) PublishOption {
-	return func(o *Options) {
-		o.PublishSettings.CountThreshold = n
-	}
+// WithCountThreshold is a no-op, kept for compatibility.
+//
+// Deprecated: the publish settings are fixed per topic, configured in modules.gcppubsub.topics.<topic id>.publish
+// (see WithPublishSettings): this option is ignored.
+func WithCountThreshold(int) PublishOption {
+	return func(*Options) {}
 }
 
-// WithByteThreshold sets the byte threshold.
-func WithByteThreshold(n int) PublishOption {
-	return func(o *Options) {
-		o.PublishSettings.ByteThreshold = n
-	}
+// WithByteThreshold is a no-op, kept for compatibility.
+//
+// Deprecated: the publish settings are fixed per topic, configured in modules.gcppubsub.topics.<topic id>.publish
+// (see WithPublishSettings): this option is ignored.
+func WithByteThreshold(int) PublishOption {
+	return func(*Options) {}
 }
 
-// WithNumGoroutines sets the num of goroutines.
-func WithNumGoroutines(n int) PublishOption {
-	return func(o *Options) {
-		o.PublishSettings.NumGoroutines = n
-	}
+// WithNumGoroutines is a no-op, kept for compatibility.
+//
+// Deprecated: the publish settings are fixed per topic, configured in modules.gcppubsub.topics.<topic id>.publish
+// (see WithPublishSettings): this option is ignored.
+func WithNumGoroutines(int) PublishOption {
+	return func(*Options) {}
 }
 
-// WithTimeout sets the timeout.
-func WithTimeout(t time.Duration) PublishOption {
-	return func(o *Options) {
-		o.PublishSettings.Timeout = t
-	}
+// WithTimeout is a no-op, kept for compatibility.
+//
+// Deprecated: the publish settings are fixed per topic, configured in modules.gcppubsub.topics.<topic id>.publish
+// (see WithPublishSettings): this option is ignored.
+func WithTimeout(time.Duration) PublishOption {
+	return func(*Options) {}
 }
 
-// WithFlowControlSettings sets the flow control settings.
-func WithFlowControlSettings(s pubsub.FlowControlSettings) PublishOption {
-	return func(o *Options) {
-		o.PublishSettings.FlowControlSettings = s
-	}
+// WithFlowControlSettings is a no-op, kept for compatibility.
+//
+// Deprecated: the publish settings are fixed per topic, configured in modules.gcppubsub.topics.<topic id>.publish
+// (see WithPublishSettings): this option is ignored.
+func WithFlowControlSettings(pubsub.FlowControlSettings) PublishOption {
+	return func(*Options) {}
 }
 
-// WithCompression sets the compression usage.
-func WithCompression(c bool) PublishOption {
-	return func(o *Options) {
-		o.PublishSettings.EnableCompression = c
-	}
+// WithCompression is a no-op, kept for compatibility.
+//
+// Deprecated: the publish settings are fixed per topic, configured in modules.gcppubsub.topics.<topic id>.publish
+// (see WithPublishSettings): this option is ignored.
+func WithCompression(bool) PublishOption {
+	return func(*Options) {}
 }
 
-// WithCompressionBytesThreshold sets the compression bytes threshold.
-func WithCompressionBytesThreshold(n int) PublishOption {
-	return func(o *Options) {
-		o.PublishSettings.CompressionBytesThreshold = n
-	}
+// WithCompressionBytesThreshold is a no-op, kept for compatibility.
+//
+// Deprecated: the publish settings are fixed per topic, configured in modules.gcppubsub.topics.<topic id>.publish
+// (see WithPublishSettings): this option is ignored.
+func WithCompressionBytesThreshold(int) PublishOption {
+	return func(*Options) {}
 }
 
 // WithMessageOrderingKey sets the message ordering key.
@@ -101,3 +126,10 @@ func WithMessageAttributes(a map[string]string) PublishOption {
 		o.MessageSettings.Attributes = a
 	}
 }
+
+// WithMiddlewares appends middlewares to wrap the publication with.
+func WithMiddlewares(m ...Middleware) PublishOption {
+	return func(o *Options) {
+		o.Middlewares = append(o.Middlewares, m...)
+	}
+}
@@ -12,112 +12,78 @@ import (
 func TestPublishOptions(t *testing.T) {
 	t.Parallel()
 
-	t.Run("withDelayThreshold", func(t *testing.T) {
+	t.Run("deprecated publish settings options", func(t *testing.T) {
 		t.Parallel()
 
-		o := &topic.Options{}
-		value := time.Duration(1)
-		opt := topic.WithDelayThreshold(value)
-		opt(o)
-
-		assert.Equal(t, value, o.PublishSettings.DelayThreshold)
-	})
-
-	t.Run("withCountThreshold", func(t *testing.T) {
-		t.Parallel()
-
-		o := &topic.Options{}
-		value := 1
-		opt := topic.WithCountThreshold(value)
-		opt(o)
-
-		assert.Equal(t, value, o.PublishSettings.CountThreshold)
-	})
-
-	t.Run("withByteThreshold", func(t *testing.T) {
-		t.Parallel()
-
-		o := &topic.Options{}
-		value := 2
-		opt := topic.WithByteThreshold(value)
-		opt(o)
-
-		assert.Equal(t, value, o.PublishSettings.ByteThreshold)
-	})
-
-	t.Run("withNumGoroutines", func(t *testing.T) {
-		t.Parallel()
-
-		o := &topic.Options{}
-		value := 3
-		opt := topic.WithNumGoroutines(value)
-		opt(o)
-
-		assert.Equal(t, value, o.PublishSettings.NumGoroutines)
-	})
-
-	t.Run("withTimeout", func(t *testing.T) {
-		t.Parallel()
-
-		o := &topic.Options{}
-		value := time.Duration(2)
-		opt := topic.WithTimeout(value)
-		opt(o)
-
-		assert.Equal(t, value, o.PublishSettings.Timeout)
+		o := topic.DefaultPublishOptions()
+
+		//nolint:staticcheck
+		for _, opt := range []topic.PublishOption{
+			topic.WithDelayThreshold(time.Duration(1)),
+			topic.WithCountThreshold(1),
+			topic.WithByteThreshold(2),
+			topic.WithNumGoroutines(3),
+			topic.WithTimeout(time.Duration(2)),
+			topic.WithFlowControlSettings(pubsub.FlowControlSettings{}),
+			topic.WithCompression(true),
+			topic.WithCompressionBytesThreshold(4),
+		} {
+			opt(o)
+		}
+
+		assert.Equal(t, pubsub.DefaultPublishSettings, o.PublishSettings)
 	})
 
-	t.Run("withFlowControlSettings", func(t *testing.T) {
+	t.Run("withMessageOrderingKey", func(t *testing.T) {
 		t.Parallel()
 
 		o := &topic.Options{}
-		value := pubsub.FlowControlSettings{}
-		opt := topic.WithFlowControlSettings(value)
+		value := "test"
+		opt := topic.WithMessageOrderingKey(value)
 		opt(o)
 
-		assert.Equal(t, value, o.PublishSettings.FlowControlSettings)
+		assert.Equal(t, value, o.MessageSettings.OrderingKey)
 	})
 
-	t.Run("WithCompression", func(t *testing.T) {
+	t.Run("withMessageAttributes", func(t *testing.T) {
 		t.Parallel()
 
 		o := &topic.Options{}
-		opt := topic.WithCompression(true)
+		value := map[string]string{"test-key": "test-value"}
+		opt := topic.WithMessageAttributes(value)
 		opt(o)
 
-		assert.True(t, o.PublishSettings.EnableCompression)
+		assert.Equal(t, value, o.MessageSettings.Attributes)
 	})
 
-	t.Run("withCompressionBytesThreshold", func(t *testing.T) {
+	t.Run("withMiddlewares", func(t *testing.T) {
 		t.Parallel()
 
 		o := &topic.Options{}
-		value := 4
-		opt := topic.WithCompressionBytesThreshold(value)
+		value := topic.MiddlewareFunc(func(next topic.PublishFunc) topic.PublishFunc {
+			return next
+		})
+		opt := topic.WithMiddlewares(value, value)
 		opt(o)
 
-		assert.Equal(t, value, o.PublishSettings.CompressionBytesThreshold)
+		assert.Len(t, o.Middlewares, 2)
 	})
+}
 
-	t.Run("withMessageOrderingKey", func(t *testing.T) {
-		t.Parallel()
-
-		o := &topic.Options{}
-		value := "test"
-		opt := topic.WithMessageOrderingKey(value)
-		opt(o)
+func TestMessageSettingsMergeAttributes(t *testing.T) {
+	t.Parallel()
 
-		assert.Equal(t, value, o.MessageSettings.OrderingKey)
-	})
+	settings := topic.MessageSettings{
+		Attributes: map[string]string{"foo": "override", "bar": "baz"},
+	}
 
-	t.Run("withMessageAttributes", func(t *testing.T) {
-		t.Parallel()
+	encodedAttributes := map[string]string{"foo": "encoded", "encoding": "avro"}
 
-		o := &topic.Options{}
-		value := map[string]string{"test-key": "test-value"}
-		opt := topic.WithMessageAttributes(value)
-		opt(o)
+	attributes := settings.MergeAttributes(encodedAttributes)
+	assert.Equal(t, map[string]string{"foo": "override", "bar": "baz", "encoding": "avro"}, attributes)
 
-		assert.Equal(t, value, o.MessageSettings.Attributes)
-	})
+	// copied
+	attributes["other"] = "value"
+	assert.Len(t, encodedAttributes, 2)
+	assert.Len(t, settings.Attributes, 2)
 }
@@ -0,0 +1,211 @@
+package topic
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ankorstore/yokai-contrib/fxgcppubsub/internal/backoff"
+	"github.com/ankorstore/yokai/config"
+)
+
+const (
+	DefaultResumeMinBackoff        = 1 * time.Second
+	DefaultResumeMaxBackoff        = 30 * time.Second
+	DefaultResumeBackoffMultiplier = 2.0
+)
+
+// ResumePolicy represents the resume policy of the ordering keys paused after an ordered publication failure.
+//
+// If Automatic is false, paused ordering keys stay paused until Topic ResumePublish is called.
+// If Automatic is true, paused ordering keys are resumed with backoff, up to MaxAttempts consecutive failures (0 for unlimited).
+type ResumePolicy struct {
+	Automatic         bool
+	MaxAttempts       int
+	MinBackoff        time.Duration
+	MaxBackoff        time.Duration
+	BackoffMultiplier float64
+}
+
+// ResumePolicyFunc returns the ResumePolicy of a provided topicID.
+type ResumePolicyFunc func(topicID string) ResumePolicy
+
+// DefaultResumePolicy is the default (manual) resume policy.
+func DefaultResumePolicy() ResumePolicy {
+	return ResumePolicy{
+		Automatic:         false,
+		MinBackoff:        DefaultResumeMinBackoff,
+		MaxBackoff:        DefaultResumeMaxBackoff,
+		BackoffMultiplier: DefaultResumeBackoffMultiplier,
+	}
+}
+
+// NewResumePolicyFromConfig returns the ResumePolicy configured for a provided topicID,
+// in modules.gcppubsub.topics.{topicID}.ordering.resume, falling back on DefaultResumePolicy.
+func NewResumePolicyFromConfig(cfg *config.Config, topicID string) ResumePolicy {
+	policy := DefaultResumePolicy()
+
+	prefix := fmt.Sprintf("modules.gcppubsub.topics.%s.ordering.resume", topicID)
+
+	policy.Automatic = cfg.GetBool(prefix + ".automatic")
+	policy.MaxAttempts = cfg.GetInt(prefix + ".max_attempts")
+
+	if cfg.IsSet(prefix + ".backoff.min") {
+		policy.MinBackoff = cfg.GetDuration(prefix + ".backoff.min")
+	}
+
+	if cfg.IsSet(prefix + ".backoff.max") {
+		policy.MaxBackoff = cfg.GetDuration(prefix + ".backoff.max")
+	}
+
+	if cfg.IsSet(prefix + ".backoff.multiplier") {
+		policy.BackoffMultiplier = cfg.GetFloat64(prefix + ".backoff.multiplier")
+	}
+
+	return policy
+}
+
+// Backoff returns the duration to wait after a provided consecutive failure, before resuming the ordering key.
+func (p ResumePolicy) Backoff(failure int) time.Duration {
+	return backoff.Exponential(failure, p.MinBackoff, p.MaxBackoff, p.BackoffMultiplier)
+}
+
+// PausedOrderingKey represents an ordering key paused after an ordered publication failure.
+type PausedOrderingKey struct {
+	OrderingKey string
+	Error       error
+	PausedAt    time.Time
+	Failures    int
+	ResumeAt    time.Time
+}
+
+type orderingKeyState struct {
+	failures int
+	paused   *PausedOrderingKey
+	timer    *time.Timer
+}
+
+// orderingKeys tracks the ordering keys failures of a topic, and schedules their automatic resume.
+type orderingKeys struct {
+	policy ResumePolicy
+	resume func(orderingKey string)
+	states map[string]*orderingKeyState
+	mutex  sync.Mutex
+}
+
+func newOrderingKeys(policy ResumePolicy, resume func(orderingKey string)) *orderingKeys {
+	return &orderingKeys{
+		policy: policy,
+		resume: resume,
+		states: make(map[string]*orderingKeyState),
+	}
+}
+
+// succeed resets the consecutive failures of an ordering key.
+func (o *orderingKeys) succeed(orderingKey string) {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+
+	if state, ok := o.states[orderingKey]; ok && state.paused == nil {
+		delete(o.states, orderingKey)
+	}
+}
+
+// fail marks an ordering key as paused, and schedules its resume according to the ResumePolicy.
+func (o *orderingKeys) fail(orderingKey string, err error) {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+
+	state, ok := o.states[orderingKey]
+	if !ok {
+		state = &orderingKeyState{}
+		o.states[orderingKey] = state
+	}
+
+	// all the in flight publications of a paused key fail, only the first failure is accounted
+	if state.paused != nil {
+		return
+	}
+
+	now := time.Now()
+
+	state.failures++
+	state.paused = &PausedOrderingKey{
+		OrderingKey: orderingKey,
+		Error:       err,
+		PausedAt:    now,
+		Failures:    state.failures,
+	}
+
+	if o.policy.Automatic && (o.policy.MaxAttempts <= 0 || state.failures <= o.policy.MaxAttempts) {
+		wait := o.policy.Backoff(state.failures)
+
+		paused := state.paused
+		paused.ResumeAt = now.Add(wait)
+
+		state.timer = time.AfterFunc(wait, func() {
+			// the key may have been resumed (and paused again) in the meantime
+			if o.isPaused(orderingKey, paused) {
+				o.resume(orderingKey)
+			}
+		})
+	}
+}
+
+// resumed marks an ordering key as resumed, keeping its consecutive failures until its next successful publication.
+func (o *orderingKeys) resumed(orderingKey string) {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+
+	if state, ok := o.states[orderingKey]; ok {
+		if state.timer != nil {
+			state.timer.Stop()
+			state.timer = nil
+		}
+
+		state.paused = nil
+	}
+}
+
+func (o *orderingKeys) isPaused(orderingKey string, paused *PausedOrderingKey) bool {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+
+	state, ok := o.states[orderingKey]
+
+	return ok && state.paused == paused
+}
+
+// paused returns the paused ordering keys, sorted by key.
+func (o *orderingKeys) paused() []PausedOrderingKey {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+
+	keys := []PausedOrderingKey{}
+
+	for _, state := range o.states {
+		if state.paused != nil {
+			keys = append(keys, *state.paused)
+		}
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i].OrderingKey < keys[j].OrderingKey
+	})
+
+	return keys
+}
+
+// stop cancels all the scheduled resumes.
+func (o *orderingKeys) stop() {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+
+	for _, state := range o.states {
+		if state.timer != nil {
+			state.timer.Stop()
+			state.timer = nil
+		}
+	}
+}
@@ -0,0 +1,193 @@
+package topic_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/pubsub"
+	"github.com/ankorstore/yokai-contrib/fxgcppubsub"
+	"github.com/ankorstore/yokai-contrib/fxgcppubsub/codec"
+	"github.com/ankorstore/yokai-contrib/fxgcppubsub/topic"
+	"github.com/ankorstore/yokai/config"
+	"github.com/ankorstore/yokai/fxconfig"
+	"github.com/ankorstore/yokai/fxlog"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/fx"
+	"go.uber.org/fx/fxtest"
+)
+
+func TestResumePolicy(t *testing.T) {
+	t.Setenv("APP_ENV", "test")
+	t.Setenv("GCP_PROJECT_ID", "test-project")
+
+	cfg, err := config.NewDefaultConfigFactory().Create(config.WithFilePaths("../testdata/config"))
+	assert.NoError(t, err)
+
+	t.Run("default policy", func(t *testing.T) {
+		policy := topic.DefaultResumePolicy()
+
+		assert.False(t, policy.Automatic)
+		assert.Equal(t, 0, policy.MaxAttempts)
+		assert.Equal(t, topic.DefaultResumeMinBackoff, policy.MinBackoff)
+		assert.Equal(t, topic.DefaultResumeMaxBackoff, policy.MaxBackoff)
+		assert.Equal(t, topic.DefaultResumeBackoffMultiplier, policy.BackoffMultiplier)
+	})
+
+	t.Run("policy from config", func(t *testing.T) {
+		policy := topic.NewResumePolicyFromConfig(cfg, "ordered-topic")
+
+		assert.True(t, policy.Automatic)
+		assert.Equal(t, 3, policy.MaxAttempts)
+		assert.Equal(t, 10*time.Millisecond, policy.MinBackoff)
+		assert.Equal(t, 50*time.Millisecond, policy.MaxBackoff)
+		assert.Equal(t, 1.5, policy.BackoffMultiplier)
+	})
+
+	t.Run("policy from config fallback on defaults", func(t *testing.T) {
+		policy := topic.NewResumePolicyFromConfig(cfg, "other-topic")
+
+		assert.Equal(t, topic.DefaultResumePolicy(), policy)
+	})
+
+	t.Run("backoff", func(t *testing.T) {
+		policy := topic.ResumePolicy{
+			MinBackoff:        100 * time.Millisecond,
+			MaxBackoff:        300 * time.Millisecond,
+			BackoffMultiplier: 2,
+		}
+
+		assert.Equal(t, 100*time.Millisecond, policy.Backoff(0))
+		assert.Equal(t, 100*time.Millisecond, policy.Backoff(1))
+		assert.Equal(t, 200*time.Millisecond, policy.Backoff(2))
+		assert.Equal(t, 300*time.Millisecond, policy.Backoff(3))
+		assert.Equal(t, 300*time.Millisecond, policy.Backoff(10))
+	})
+}
+
+func TestTopicOrderingKeys(t *testing.T) {
+	t.Setenv("APP_ENV", "test")
+	t.Setenv("APP_CONFIG_PATH", "../testdata/config")
+	t.Setenv("GCP_PROJECT_ID", "test-project")
+
+	var client *pubsub.Client
+
+	ctx := context.Background()
+
+	fxtest.New(
+		t,
+		fx.NopLogger,
+		fxconfig.FxConfigModule,
+		fxlog.FxLogModule,
+		fxgcppubsub.FxGcpPubSubModule,
+		fx.Supply(fx.Annotate(ctx, fx.As(new(context.Context)))),
+		fxgcppubsub.PrepareTopic(fxgcppubsub.PrepareTopicParams{
+			TopicID: "ordered-topic",
+		}),
+		fx.Populate(&client),
+	).RequireStart().RequireStop()
+
+	t.Run("successful ordered publication", func(t *testing.T) {
+		top := topic.NewTopic(codec.NewRawCodec(), client.Topic("ordered-topic"))
+		defer top.Stop()
+
+		res, err := top.WithOptions(topic.WithMessageOrderingKey("key")).Publish(ctx, []byte("test"))
+		assert.NoError(t, err)
+
+		_, err = res.Get(ctx)
+		assert.NoError(t, err)
+
+		assert.True(t, top.BaseTopic().EnableMessageOrdering)
+		assert.Empty(t, top.PausedOrderingKeys())
+	})
+
+	t.Run("manual resume of a paused ordering key", func(t *testing.T) {
+		top := topic.NewTopic(codec.NewRawCodec(), client.Topic("missing-manual-topic"))
+		defer top.Stop()
+
+		res, err := top.WithOptions(topic.WithMessageOrderingKey("key")).Publish(ctx, []byte("test"))
+		assert.NoError(t, err)
+
+		_, err = res.Get(ctx)
+		assert.Error(t, err)
+
+		assert.Eventually(t, func() bool {
+			return len(top.PausedOrderingKeys()) == 1
+		}, 2*time.Second, 10*time.Millisecond)
+
+		paused := top.PausedOrderingKeys()[0]
+		assert.Equal(t, "key", paused.OrderingKey)
+		assert.Error(t, paused.Error)
+		assert.Equal(t, 1, paused.Failures)
+		assert.False(t, paused.PausedAt.IsZero())
+		assert.True(t, paused.ResumeAt.IsZero())
+
+		// publications are refused while paused, without being accounted as new failures
+		res, err = top.Publish(ctx, []byte("test"), topic.WithMessageOrderingKey("key"))
+		assert.NoError(t, err)
+
+		_, err = res.Get(ctx)
+		assert.ErrorAs(t, err, &pubsub.ErrPublishingPaused{})
+
+		// publications on other ordering keys are not paused
+		res, err = top.Publish(ctx, []byte("test"), topic.WithMessageOrderingKey("other"))
+		assert.NoError(t, err)
+
+		_, err = res.Get(ctx)
+		assert.NotErrorAs(t, err, &pubsub.ErrPublishingPaused{})
+
+		top.ResumePublish("key")
+
+		assert.Eventually(t, func() bool {
+			for _, p := range top.PausedOrderingKeys() {
+				if p.OrderingKey == "key" {
+					return false
+				}
+			}
+
+			return true
+		}, 2*time.Second, 10*time.Millisecond)
+	})
+
+	t.Run("automatic resume of a paused ordering key", func(t *testing.T) {
+		top := topic.NewTopic(
+			codec.NewRawCodec(),
+			client.Topic("missing-automatic-topic"),
+			topic.WithResumePolicy(topic.ResumePolicy{
+				Automatic:         true,
+				MaxAttempts:       2,
+				MinBackoff:        100 * time.Millisecond,
+				MaxBackoff:        100 * time.Millisecond,
+				BackoffMultiplier: 1,
+			}),
+		)
+		defer top.Stop()
+
+		// each failure pauses the key, automatically resumed until the max attempts are exceeded
+		for i := 1; i <= 3; i++ {
+			assert.Eventually(t, func() bool {
+				res, err := top.Publish(ctx, []byte("test"), topic.WithMessageOrderingKey("key"))
+				assert.NoError(t, err)
+
+				_, err = res.Get(ctx)
+
+				return err != nil && !errors.As(err, &pubsub.ErrPublishingPaused{})
+			}, 2*time.Second, 10*time.Millisecond)
+
+			assert.Eventually(t, func() bool {
+				paused := top.PausedOrderingKeys()
+
+				return len(paused) == 1 && paused[0].Failures == i
+			}, 2*time.Second, 5*time.Millisecond)
+		}
+
+		paused := top.PausedOrderingKeys()[0]
+		assert.Equal(t, 3, paused.Failures)
+		assert.True(t, paused.ResumeAt.IsZero())
+
+		time.Sleep(200 * time.Millisecond)
+
+		assert.Len(t, top.PausedOrderingKeys(), 1)
+	})
+}
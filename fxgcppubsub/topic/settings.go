@@ -0,0 +1,75 @@
+package topic
+
+import (
+	"fmt"
+	"strings"
+
+	"cloud.google.com/go/pubsub"
+	"github.com/ankorstore/yokai/config"
+)
+
+// PublishSettingsFunc returns the pubsub.PublishSettings of a provided topicID.
+type PublishSettingsFunc func(topicID string) pubsub.PublishSettings
+
+// NewPublishSettingsFromConfig returns the pubsub.PublishSettings configured for a provided topicID,
+// in modules.gcppubsub.topics.{topicID}.publish, falling back on pubsub.DefaultPublishSettings.
+func NewPublishSettingsFromConfig(cfg *config.Config, topicID string) pubsub.PublishSettings {
+	settings := pubsub.DefaultPublishSettings
+
+	prefix := fmt.Sprintf("modules.gcppubsub.topics.%s.publish", topicID)
+
+	if cfg.IsSet(prefix + ".delay_threshold") {
+		settings.DelayThreshold = cfg.GetDuration(prefix + ".delay_threshold")
+	}
+
+	if cfg.IsSet(prefix + ".count_threshold") {
+		settings.CountThreshold = cfg.GetInt(prefix + ".count_threshold")
+	}
+
+	if cfg.IsSet(prefix + ".byte_threshold") {
+		settings.ByteThreshold = cfg.GetInt(prefix + ".byte_threshold")
+	}
+
+	if cfg.IsSet(prefix + ".num_goroutines") {
+		settings.NumGoroutines = cfg.GetInt(prefix + ".num_goroutines")
+	}
+
+	if cfg.IsSet(prefix + ".timeout") {
+		settings.Timeout = cfg.GetDuration(prefix + ".timeout")
+	}
+
+	if cfg.IsSet(prefix + ".flow_control.max_outstanding_messages") {
+		settings.FlowControlSettings.MaxOutstandingMessages = cfg.GetInt(prefix + ".flow_control.max_outstanding_messages")
+	}
+
+	if cfg.IsSet(prefix + ".flow_control.max_outstanding_bytes") {
+		settings.FlowControlSettings.MaxOutstandingBytes = cfg.GetInt(prefix + ".flow_control.max_outstanding_bytes")
+	}
+
+	if cfg.IsSet(prefix + ".flow_control.limit_exceeded_behavior") {
+		settings.FlowControlSettings.LimitExceededBehavior = limitExceededBehavior(
+			cfg.GetString(prefix + ".flow_control.limit_exceeded_behavior"),
+		)
+	}
+
+	if cfg.IsSet(prefix + ".compression") {
+		settings.EnableCompression = cfg.GetBool(prefix + ".compression")
+	}
+
+	if cfg.IsSet(prefix + ".compression_bytes_threshold") {
+		settings.CompressionBytesThreshold = cfg.GetInt(prefix + ".compression_bytes_threshold")
+	}
+
+	return settings
+}
+
+func limitExceededBehavior(behavior string) pubsub.LimitExceededBehavior {
+	switch strings.ToLower(behavior) {
+	case "block":
+		return pubsub.FlowControlBlock
+	case "signal_error":
+		return pubsub.FlowControlSignalError
+	default:
+		return pubsub.FlowControlIgnore
+	}
+}
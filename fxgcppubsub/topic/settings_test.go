@@ -0,0 +1,45 @@
+package topic_test
+
+import (
+	"testing"
+	"time"
+
+	"cloud.google.com/go/pubsub"
+	"github.com/ankorstore/yokai-contrib/fxgcppubsub/topic"
+	"github.com/ankorstore/yokai/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewPublishSettingsFromConfig(t *testing.T) {
+	t.Setenv("APP_ENV", "test")
+	t.Setenv("GCP_PROJECT_ID", "test-project")
+
+	cfg, err := config.NewDefaultConfigFactory().Create(config.WithFilePaths("../testdata/config"))
+	assert.NoError(t, err)
+
+	t.Run("settings from config", func(t *testing.T) {
+		settings := topic.NewPublishSettingsFromConfig(cfg, "settings-topic")
+
+		expected := pubsub.DefaultPublishSettings
+		expected.DelayThreshold = 5 * time.Millisecond
+		expected.CountThreshold = 10
+		expected.ByteThreshold = 1000
+		expected.NumGoroutines = 2
+		expected.Timeout = 30 * time.Second
+		expected.FlowControlSettings = pubsub.FlowControlSettings{
+			MaxOutstandingMessages: 100,
+			MaxOutstandingBytes:    2048,
+			LimitExceededBehavior:  pubsub.FlowControlBlock,
+		}
+		expected.EnableCompression = true
+		expected.CompressionBytesThreshold = 512
+
+		assert.Equal(t, expected, settings)
+	})
+
+	t.Run("settings from config fallback on defaults", func(t *testing.T) {
+		settings := topic.NewPublishSettingsFromConfig(cfg, "other-topic")
+
+		assert.Equal(t, pubsub.DefaultPublishSettings, settings)
+	})
+}
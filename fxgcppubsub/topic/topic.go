@@ -2,26 +2,72 @@ package topic
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"slices"
 
 	"cloud.google.com/go/pubsub"
 	"github.com/ankorstore/yokai-contrib/fxgcppubsub/codec"
 )
 
 // Topic represents a pub/sub topic with an associated codec.Codec.
+//
+// The topic publish settings are fixed at creation, and the PublishOption are resolved per publication: a Topic is safe
+// for concurrent use.
 type Topic struct {
-	codec   codec.Codec
-	topic   *pubsub.Topic
-	options *Options
+	codec    codec.Codec
+	topic    *pubsub.Topic
+	settings pubsub.PublishSettings
+	schema   string
+	options  []PublishOption
+	ordering *orderingKeys
+}
+
+// TopicOption is a functional option for the Topic.
+type TopicOption func(t *Topic)
+
+// WithPublishSettings configures the topic publish settings.
+func WithPublishSettings(settings pubsub.PublishSettings) TopicOption {
+	return func(t *Topic) {
+		t.settings = settings
+	}
+}
+
+// WithSchema configures the name of the schema the topic is associated to.
+func WithSchema(schema string) TopicOption {
+	return func(t *Topic) {
+		t.schema = schema
+	}
+}
+
+// WithResumePolicy configures the ResumePolicy applied to the ordering keys paused after an ordered publication failure.
+func WithResumePolicy(policy ResumePolicy) TopicOption {
+	return func(t *Topic) {
+		t.ordering.policy = policy
+	}
 }
 
 // NewTopic returns a new Topic instance.
-func NewTopic(codec codec.Codec, topic *pubsub.Topic) *Topic {
-	return &Topic{
-		codec:   codec,
-		topic:   topic,
-		options: DefaultPublishOptions(),
+//
+// The base pubsub.Topic is configured before any publication: with the topic publish settings, and with message
+// ordering enabled, since it only allows messages with an ordering key and has no effect on the others.
+func NewTopic(codec codec.Codec, topic *pubsub.Topic, options ...TopicOption) *Topic {
+	t := &Topic{
+		codec:    codec,
+		topic:    topic,
+		settings: pubsub.DefaultPublishSettings,
 	}
+
+	t.ordering = newOrderingKeys(DefaultResumePolicy(), t.resumePublish)
+
+	for _, applyOpt := range options {
+		applyOpt(t)
+	}
+
+	t.topic.PublishSettings = t.settings
+	t.topic.EnableMessageOrdering = true
+
+	return t
 }
 
 // Codec returns the topic associated codec.Codec.
@@ -34,32 +80,178 @@ func (t *Topic) BaseTopic() *pubsub.Topic {
 	return t.topic
 }
 
-// WithOptions configures the topic with a list of PublishOption.
-func (t *Topic) WithOptions(options ...PublishOption) *Topic {
-	// resolve options
-	for _, applyOpt := range options {
-		applyOpt(t.options)
-	}
+// PublishSettings returns the topic publish settings.
+func (t *Topic) PublishSettings() pubsub.PublishSettings {
+	return t.settings
+}
 
-	// apply options
-	t.topic.PublishSettings = t.options.PublishSettings
-	t.topic.EnableMessageOrdering = t.options.MessageSettings.OrderingKey != ""
+// Schema returns the name of the schema the topic is associated to, or an empty string if none.
+func (t *Topic) Schema() string {
+	return t.schema
+}
 
-	return t
+// ResumePolicy returns the topic ResumePolicy, applied to the ordering keys paused after an ordered publication failure.
+func (t *Topic) ResumePolicy() ResumePolicy {
+	return t.ordering.policy
 }
 
-// Publish publishes the provided data.
-func (t *Topic) Publish(ctx context.Context, data any) (*pubsub.PublishResult, error) {
+// WithOptions returns a copy of the topic, applying the provided list of PublishOption on each of its publications,
+// before the ones provided per call. The topic itself is not modified.
+func (t *Topic) WithOptions(options ...PublishOption) *Topic {
+	return &Topic{
+		codec:    t.codec,
+		topic:    t.topic,
+		settings: t.settings,
+		schema:   t.schema,
+		options:  append(slices.Clone(t.options), options...),
+		ordering: t.ordering,
+	}
+}
+
+// Publish publishes the provided data, with options, through the configured Middleware.
+func (t *Topic) Publish(ctx context.Context, data any, options ...PublishOption) (*pubsub.PublishResult, error) {
+	// options
+	publishOptions := t.resolveOptions(options...)
+
 	// encode
-	encodedData, err := t.codec.Encode(data)
+	encodedData, encodedAttributes, err := codec.EncodeWithAttributesContext(ctx, t.codec, data)
 	if err != nil {
 		return nil, fmt.Errorf("cannot encode data: %w", err)
 	}
 
 	// publish
-	return t.topic.Publish(ctx, &pubsub.Message{
+	h := Chain(t.publish, publishOptions.Middlewares...)
+
+	return h(t.context(ctx), t.message(publishOptions, encodedData, encodedAttributes))
+}
+
+// PublishEncoded publishes the provided already encoded data, with options, through the configured Middleware.
+func (t *Topic) PublishEncoded(ctx context.Context, encodedData []byte, options ...PublishOption) (*pubsub.PublishResult, error) {
+	// options
+	publishOptions := t.resolveOptions(options...)
+
+	// publish
+	h := Chain(t.publish, publishOptions.Middlewares...)
+
+	return h(t.context(ctx), t.message(publishOptions, encodedData, nil))
+}
+
+// PublishBatch publishes the provided list of data, with options, through the configured Middleware.
+//
+// All data are encoded before publishing, and nothing is published if one of them cannot be encoded.
+// The publications are then performed concurrently, and their results are aggregated in a BatchPublishResult.
+func (t *Topic) PublishBatch(ctx context.Context, data []any, options ...PublishOption) (*BatchPublishResult, error) {
+	// options
+	publishOptions := t.resolveOptions(options...)
+
+	// encode
+	encodedData := make([][]byte, len(data))
+	encodedAttributes := make([]map[string]string, len(data))
+
+	for i, d := range data {
+		enc, attributes, err := codec.EncodeWithAttributesContext(ctx, t.codec, d)
+		if err != nil {
+			return nil, fmt.Errorf("cannot encode data at index %d: %w", i, err)
+		}
+
+		encodedData[i] = enc
+		encodedAttributes[i] = attributes
+	}
+
+	// publish
+	h := Chain(t.publish, publishOptions.Middlewares...)
+	hCtx := t.context(ctx)
+
+	results := make([]*pubsub.PublishResult, len(encodedData))
+	errs := make([]error, len(encodedData))
+
+	for i, enc := range encodedData {
+		results[i], errs[i] = h(hCtx, t.message(publishOptions, enc, encodedAttributes[i]))
+	}
+
+	return NewBatchPublishResult(results, errs), nil
+}
+
+// resolveOptions resolves the options of a publication, from the topic ones and the provided ones.
+func (t *Topic) resolveOptions(options ...PublishOption) *Options {
+	publishOptions := DefaultPublishOptions()
+	publishOptions.PublishSettings = t.settings
+
+	for _, applyOpt := range t.options {
+		applyOpt(publishOptions)
+	}
+
+	for _, applyOpt := range options {
+		applyOpt(publishOptions)
+	}
+
+	return publishOptions
+}
+
+// context returns a copy of the provided context, carrying the topic id and schema, for the Middleware.
+func (t *Topic) context(ctx context.Context) context.Context {
+	ctx = ContextWithTopicID(ctx, t.topic.ID())
+
+	if t.schema != "" {
+		ctx = ContextWithTopicSchema(ctx, t.schema)
+	}
+
+	return ctx
+}
+
+func (t *Topic) message(options *Options, encodedData []byte, encodedAttributes map[string]string) *pubsub.Message {
+	// attributes are copied to be safely modified by middlewares
+	return &pubsub.Message{
 		Data:        encodedData,
-		Attributes:  t.options.MessageSettings.Attributes,
-		OrderingKey: t.options.MessageSettings.OrderingKey,
-	}), nil
+		Attributes:  options.MessageSettings.MergeAttributes(encodedAttributes),
+		OrderingKey: options.MessageSettings.OrderingKey,
+	}
+}
+
+// PausedOrderingKeys returns the ordering keys paused after an ordered publication failure, sorted by key.
+func (t *Topic) PausedOrderingKeys() []PausedOrderingKey {
+	return t.ordering.paused()
+}
+
+// ResumePublish resumes the publications for a paused ordering key.
+func (t *Topic) ResumePublish(orderingKey string) {
+	t.resumePublish(orderingKey)
+}
+
+// Stop cancels the scheduled ordering keys resumes, and stops gracefully the base pubsub.Topic.
+func (t *Topic) Stop() {
+	t.ordering.stop()
+	t.topic.Stop()
+}
+
+func (t *Topic) resumePublish(orderingKey string) {
+	t.topic.ResumePublish(orderingKey)
+	t.ordering.resumed(orderingKey)
+}
+
+func (t *Topic) publish(ctx context.Context, msg *pubsub.Message) (*pubsub.PublishResult, error) {
+	res := t.topic.Publish(ctx, msg)
+
+	if msg.OrderingKey != "" {
+		go t.watch(msg.OrderingKey, res)
+	}
+
+	return res, nil
+}
+
+// watch tracks the outcome of an ordered publication, since the base pubsub.Topic pauses the ordering key on failure.
+func (t *Topic) watch(orderingKey string, res *pubsub.PublishResult) {
+	<-res.Ready()
+
+	_, err := res.Get(context.Background())
+	if err == nil {
+		t.ordering.succeed(orderingKey)
+
+		return
+	}
+
+	// publications refused because of an already paused key are not accounted as failures
+	if !errors.As(err, &pubsub.ErrPublishingPaused{}) {
+		t.ordering.fail(orderingKey, err)
+	}
 }
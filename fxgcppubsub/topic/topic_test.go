@@ -2,6 +2,8 @@ package topic_test
 
 import (
 	"context"
+	"fmt"
+	"sync"
 	"testing"
 	"time"
 
@@ -66,6 +68,9 @@ func TestTopic(t *testing.T) {
 			},
 			SchemaEncoding: pubsub.EncodingBinary,
 		}),
+		fxgcppubsub.PrepareTopic(fxgcppubsub.PrepareTopicParams{
+			TopicID: "isolation-topic",
+		}),
 		fx.Populate(&subscriber, &client, &supervisor),
 	).RequireStart().RequireStop()
 
@@ -76,6 +81,35 @@ func TestTopic(t *testing.T) {
 
 		assert.Equal(t, cod, top.Codec())
 		assert.Equal(t, baseTop, top.BaseTopic())
+		assert.Equal(t, pubsub.DefaultPublishSettings, top.PublishSettings())
+		assert.Equal(t, topic.DefaultResumePolicy(), top.ResumePolicy())
+		assert.Empty(t, top.Schema())
+		assert.True(t, baseTop.EnableMessageOrdering)
+
+		assert.Equal(t, "test-schema", topic.NewTopic(cod, baseTop, topic.WithSchema("test-schema")).Schema())
+		assert.Equal(t, "test-schema", topic.NewTopic(cod, baseTop, topic.WithSchema("test-schema")).WithOptions().Schema())
+	})
+
+	t.Run("derived topic schema", func(t *testing.T) {
+		var schema string
+
+		capture := topic.MiddlewareFunc(func(next topic.PublishFunc) topic.PublishFunc {
+			return func(ctx context.Context, msg *pubsub.Message) (*pubsub.PublishResult, error) {
+				schema = topic.CtxTopicSchema(ctx)
+
+				return next(ctx, msg)
+			}
+		})
+
+		top := topic.NewTopic(codec.NewRawCodec(), client.Topic("isolation-topic"), topic.WithSchema("test-schema"))
+
+		res, err := top.WithOptions(topic.WithMiddlewares(capture)).Publish(ctx, []byte("test"))
+		assert.NoError(t, err)
+
+		_, err = res.Get(ctx)
+		assert.NoError(t, err)
+
+		assert.Equal(t, "test-schema", schema)
 	})
 
 	t.Run("raw message", func(t *testing.T) {
@@ -180,4 +214,155 @@ func TestTopic(t *testing.T) {
 		assert.Equal(t, float32(56.78), out.FloatField)
 		assert.False(t, out.BooleanField)
 	})
+
+	t.Run("publish settings", func(t *testing.T) {
+		settings := pubsub.DefaultPublishSettings
+		settings.CountThreshold = 10
+
+		top := topic.NewTopic(codec.NewRawCodec(), client.Topic("isolation-topic"), topic.WithPublishSettings(settings))
+
+		assert.Equal(t, settings, top.PublishSettings())
+		assert.Equal(t, settings, top.BaseTopic().PublishSettings)
+
+		// deprecated per publication settings are ignored
+		//nolint:staticcheck
+		res, err := top.Publish(ctx, []byte("test"), topic.WithCountThreshold(5))
+		assert.NoError(t, err)
+
+		_, err = res.Get(ctx)
+		assert.NoError(t, err)
+
+		assert.Equal(t, settings, top.PublishSettings())
+		assert.Equal(t, settings, top.BaseTopic().PublishSettings)
+	})
+
+	t.Run("per call options isolation", func(t *testing.T) {
+		top := topic.NewTopic(codec.NewRawCodec(), client.Topic("isolation-topic"))
+
+		const calls = 50
+
+		var mutex sync.Mutex
+		published := make(map[int][]*pubsub.Message, calls)
+
+		var wg sync.WaitGroup
+
+		for i := 0; i < calls; i++ {
+			wg.Add(1)
+
+			go func() {
+				defer wg.Done()
+
+				attributes := map[string]string{"index": fmt.Sprintf("%d", i)}
+
+				record := topic.MiddlewareFunc(func(next topic.PublishFunc) topic.PublishFunc {
+					return func(ctx context.Context, msg *pubsub.Message) (*pubsub.PublishResult, error) {
+						mutex.Lock()
+						published[i] = append(published[i], msg)
+						mutex.Unlock()
+
+						// middlewares can safely modify the message attributes
+						msg.Attributes["modified"] = "true"
+
+						return next(ctx, msg)
+					}
+				})
+
+				options := []topic.PublishOption{
+					topic.WithMessageAttributes(attributes),
+					topic.WithMiddlewares(record),
+				}
+
+				if i%2 == 0 {
+					options = append(options, topic.WithMessageOrderingKey(fmt.Sprintf("key-%d", i)))
+				}
+
+				res, err := top.Publish(ctx, []byte(fmt.Sprintf("data-%d", i)), options...)
+				assert.NoError(t, err)
+
+				_, err = res.Get(ctx)
+				assert.NoError(t, err)
+
+				// the provided attributes are not modified
+				assert.Equal(t, map[string]string{"index": fmt.Sprintf("%d", i)}, attributes)
+			}()
+		}
+
+		wg.Wait()
+
+		assert.Len(t, published, calls)
+
+		for i := 0; i < calls; i++ {
+			// each call middleware is applied on its own publication only
+			assert.Len(t, published[i], 1)
+
+			msg := published[i][0]
+			assert.Equal(t, fmt.Sprintf("data-%d", i), string(msg.Data))
+			assert.Equal(t, map[string]string{"index": fmt.Sprintf("%d", i), "modified": "true"}, msg.Attributes)
+
+			if i%2 == 0 {
+				assert.Equal(t, fmt.Sprintf("key-%d", i), msg.OrderingKey)
+			} else {
+				assert.Empty(t, msg.OrderingKey)
+			}
+		}
+	})
+
+	t.Run("derived topic options isolation", func(t *testing.T) {
+		var mutex sync.Mutex
+		var published []*pubsub.Message
+
+		record := topic.MiddlewareFunc(func(next topic.PublishFunc) topic.PublishFunc {
+			return func(ctx context.Context, msg *pubsub.Message) (*pubsub.PublishResult, error) {
+				mutex.Lock()
+				published = append(published, msg)
+				mutex.Unlock()
+
+				return next(ctx, msg)
+			}
+		})
+
+		top := topic.NewTopic(codec.NewRawCodec(), client.Topic("isolation-topic"))
+
+		derived := top.WithOptions(
+			topic.WithMiddlewares(record),
+			topic.WithMessageAttributes(map[string]string{"derived": "true"}),
+			topic.WithMessageOrderingKey("derived"),
+		)
+
+		var wg sync.WaitGroup
+
+		for _, top := range []*topic.Topic{top, derived, top, derived} {
+			wg.Add(1)
+
+			go func() {
+				defer wg.Done()
+
+				res, err := top.Publish(ctx, []byte("test"), topic.WithMiddlewares(record))
+				assert.NoError(t, err)
+
+				_, err = res.Get(ctx)
+				assert.NoError(t, err)
+			}()
+		}
+
+		wg.Wait()
+
+		var derivedMessages, baseMessages int
+
+		for _, msg := range published {
+			if msg.OrderingKey == "derived" {
+				derivedMessages++
+
+				assert.Equal(t, map[string]string{"derived": "true"}, msg.Attributes)
+			} else {
+				baseMessages++
+
+				assert.Empty(t, msg.Attributes)
+			}
+		}
+
+		// the derived topic middlewares are applied in addition to the per call ones
+		assert.Equal(t, 4, derivedMessages)
+		assert.Equal(t, 2, baseMessages)
+	})
 }
@@ -0,0 +1,466 @@
+package topology
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"cloud.google.com/go/pubsub"
+	"github.com/ankorstore/yokai/log"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var _ TopologyReconciler = (*DefaultTopologyReconciler)(nil)
+
+const (
+	KindSchema       = "schema"
+	KindTopic        = "topic"
+	KindSubscription = "subscription"
+)
+
+// TopologyReconciler is the interface for Topology reconcilers.
+type TopologyReconciler interface {
+	Reconcile(ctx context.Context, topology *Topology) (*Report, error)
+}
+
+// Resource represents a reconciled pub/sub resource.
+type Resource struct {
+	Kind string
+	ID   string
+}
+
+// Drift represents a difference between the declared and the actual configuration of a pub/sub resource field.
+type Drift struct {
+	Resource
+	Field    string
+	Expected string
+	Actual   string
+	Updated  bool
+}
+
+// Report represents the result of a Topology reconciliation.
+type Report struct {
+	Created []Resource
+	Drifts  []Drift
+}
+
+// DefaultTopologyReconciler is the default TopologyReconciler implementation.
+//
+// It creates the missing resources, and reports the drifts of the existing ones, only on declared fields.
+// If Topology.Update is enabled, the drifts of mutable fields are updated, the other ones are only reported.
+// It never fails on already existing resources.
+type DefaultTopologyReconciler struct {
+	client       *pubsub.Client
+	schemaClient *pubsub.SchemaClient
+	logger       *log.Logger
+}
+
+// NewDefaultTopologyReconciler returns a new DefaultTopologyReconciler instance.
+func NewDefaultTopologyReconciler(client *pubsub.Client, schemaClient *pubsub.SchemaClient, logger *log.Logger) *DefaultTopologyReconciler {
+	return &DefaultTopologyReconciler{
+		client:       client,
+		schemaClient: schemaClient,
+		logger:       logger,
+	}
+}
+
+// Reconcile reconciles the provided Topology, in order: schemas, topics and subscriptions.
+func (r *DefaultTopologyReconciler) Reconcile(ctx context.Context, topology *Topology) (*Report, error) {
+	report := &Report{}
+
+	for _, schemaConfig := range topology.Schemas {
+		err := r.reconcileSchema(ctx, schemaConfig, report)
+		if err != nil {
+			return report, fmt.Errorf("cannot reconcile schema %s: %w", schemaConfig.ID, err)
+		}
+	}
+
+	for _, topicConfig := range topology.Topics {
+		err := r.reconcileTopic(ctx, topicConfig, topology.Update, report)
+		if err != nil {
+			return report, fmt.Errorf("cannot reconcile topic %s: %w", topicConfig.ID, err)
+		}
+	}
+
+	for _, subscriptionConfig := range topology.Subscriptions {
+		err := r.reconcileSubscription(ctx, subscriptionConfig, topology.Update, report)
+		if err != nil {
+			return report, fmt.Errorf("cannot reconcile subscription %s: %w", subscriptionConfig.ID, err)
+		}
+	}
+
+	return report, nil
+}
+
+func (r *DefaultTopologyReconciler) reconcileSchema(ctx context.Context, schemaConfig SchemaConfig, report *Report) error {
+	resource := Resource{Kind: KindSchema, ID: schemaConfig.ID}
+
+	schemaType, err := schemaConfig.SchemaType()
+	if err != nil {
+		return err
+	}
+
+	actual, err := r.schemaClient.Schema(ctx, schemaConfig.ID, pubsub.SchemaViewFull)
+	if err != nil {
+		if status.Code(err) != codes.NotFound {
+			return fmt.Errorf("cannot get schema: %w", err)
+		}
+
+		_, err = r.schemaClient.CreateSchema(ctx, schemaConfig.ID, pubsub.SchemaConfig{
+			Type:       schemaType,
+			Definition: schemaConfig.Definition,
+		})
+
+		return r.created(resource, err, report)
+	}
+
+	// schemas revisions are not committed, drifts are only reported
+	r.drift(report, Drift{
+		Resource: resource,
+		Field:    "type",
+		Expected: fmt.Sprint(schemaType),
+		Actual:   fmt.Sprint(actual.Type),
+	})
+
+	r.drift(report, Drift{
+		Resource: resource,
+		Field:    "definition",
+		Expected: strings.TrimSpace(schemaConfig.Definition),
+		Actual:   strings.TrimSpace(actual.Definition),
+	})
+
+	return nil
+}
+
+//nolint:cyclop
+func (r *DefaultTopologyReconciler) reconcileTopic(ctx context.Context, topicConfig TopicConfig, update bool, report *Report) error {
+	resource := Resource{Kind: KindTopic, ID: topicConfig.ID}
+
+	encoding, err := topicConfig.Schema.SchemaEncoding()
+	if err != nil {
+		return err
+	}
+
+	var schemaSettings *pubsub.SchemaSettings
+	if topicConfig.Schema.ID != "" {
+		schemaSettings = &pubsub.SchemaSettings{
+			Schema:   r.schemaName(topicConfig.Schema.ID),
+			Encoding: encoding,
+		}
+	}
+
+	topic := r.client.Topic(topicConfig.ID)
+
+	exists, err := topic.Exists(ctx)
+	if err != nil {
+		return fmt.Errorf("cannot check topic existence: %w", err)
+	}
+
+	if !exists {
+		cfg := &pubsub.TopicConfig{
+			Labels:         topicConfig.Labels,
+			SchemaSettings: schemaSettings,
+		}
+
+		if topicConfig.Retention > 0 {
+			cfg.RetentionDuration = topicConfig.Retention
+		}
+
+		_, err = r.client.CreateTopicWithConfig(ctx, topicConfig.ID, cfg)
+
+		return r.created(resource, err, report)
+	}
+
+	actual, err := topic.Config(ctx)
+	if err != nil {
+		return fmt.Errorf("cannot get topic configuration: %w", err)
+	}
+
+	toUpdate := pubsub.TopicConfigToUpdate{}
+	drifts := []Drift{}
+
+	if topicConfig.Retention > 0 {
+		actualRetention, _ := actual.RetentionDuration.(time.Duration)
+		if actualRetention != topicConfig.Retention {
+			toUpdate.RetentionDuration = topicConfig.Retention
+			drifts = append(drifts, Drift{Resource: resource, Field: "retention", Expected: topicConfig.Retention.String(), Actual: actualRetention.String()})
+		}
+	}
+
+	if len(topicConfig.Labels) > 0 && !reflect.DeepEqual(topicConfig.Labels, actual.Labels) {
+		toUpdate.Labels = topicConfig.Labels
+		drifts = append(drifts, Drift{Resource: resource, Field: "labels", Expected: fmt.Sprint(topicConfig.Labels), Actual: fmt.Sprint(actual.Labels)})
+	}
+
+	if schemaSettings != nil {
+		actualSchema, actualEncoding := "", pubsub.EncodingUnspecified
+		if actual.SchemaSettings != nil {
+			actualSchema, actualEncoding = actual.SchemaSettings.Schema, actual.SchemaSettings.Encoding
+		}
+
+		if actualSchema != schemaSettings.Schema || actualEncoding != schemaSettings.Encoding {
+			toUpdate.SchemaSettings = schemaSettings
+			drifts = append(drifts, Drift{
+				Resource: resource,
+				Field:    "schema",
+				Expected: fmt.Sprintf("%s (%v)", schemaSettings.Schema, schemaSettings.Encoding),
+				Actual:   fmt.Sprintf("%s (%v)", actualSchema, actualEncoding),
+			})
+		}
+	}
+
+	if update && len(drifts) > 0 {
+		_, err = topic.Update(ctx, toUpdate)
+		if err != nil {
+			return fmt.Errorf("cannot update topic: %w", err)
+		}
+
+		for i := range drifts {
+			drifts[i].Updated = true
+		}
+	}
+
+	for _, drift := range drifts {
+		r.drift(report, drift)
+	}
+
+	return nil
+}
+
+//nolint:cyclop,funlen
+func (r *DefaultTopologyReconciler) reconcileSubscription(ctx context.Context, subscriptionConfig SubscriptionConfig, update bool, report *Report) error {
+	resource := Resource{Kind: KindSubscription, ID: subscriptionConfig.ID}
+
+	var retryPolicy *pubsub.RetryPolicy
+	if subscriptionConfig.RetryPolicy.MinBackoff > 0 || subscriptionConfig.RetryPolicy.MaxBackoff > 0 {
+		retryPolicy = &pubsub.RetryPolicy{}
+
+		if subscriptionConfig.RetryPolicy.MinBackoff > 0 {
+			retryPolicy.MinimumBackoff = subscriptionConfig.RetryPolicy.MinBackoff
+		}
+
+		if subscriptionConfig.RetryPolicy.MaxBackoff > 0 {
+			retryPolicy.MaximumBackoff = subscriptionConfig.RetryPolicy.MaxBackoff
+		}
+	}
+
+	var deadLetterPolicy *pubsub.DeadLetterPolicy
+	if subscriptionConfig.DeadLetterPolicy.Topic != "" {
+		deadLetterPolicy = &pubsub.DeadLetterPolicy{
+			DeadLetterTopic:     r.client.Topic(subscriptionConfig.DeadLetterPolicy.Topic).String(),
+			MaxDeliveryAttempts: subscriptionConfig.DeadLetterPolicy.MaxDeliveryAttempts,
+		}
+	}
+
+	subscription := r.client.Subscription(subscriptionConfig.ID)
+
+	exists, err := subscription.Exists(ctx)
+	if err != nil {
+		return fmt.Errorf("cannot check subscription existence: %w", err)
+	}
+
+	if !exists {
+		cfg := pubsub.SubscriptionConfig{
+			Topic:             r.client.Topic(subscriptionConfig.Topic),
+			AckDeadline:       subscriptionConfig.AckDeadline,
+			RetentionDuration: subscriptionConfig.Retention,
+			Labels:            subscriptionConfig.Labels,
+			RetryPolicy:       retryPolicy,
+			DeadLetterPolicy:  deadLetterPolicy,
+		}
+
+		if subscriptionConfig.RetainAckedMessages != nil {
+			cfg.RetainAckedMessages = *subscriptionConfig.RetainAckedMessages
+		}
+
+		if subscriptionConfig.EnableMessageOrdering != nil {
+			cfg.EnableMessageOrdering = *subscriptionConfig.EnableMessageOrdering
+		}
+
+		if subscriptionConfig.Filter != nil {
+			cfg.Filter = *subscriptionConfig.Filter
+		}
+
+		_, err = r.client.CreateSubscription(ctx, subscriptionConfig.ID, cfg)
+
+		return r.created(resource, err, report)
+	}
+
+	actual, err := subscription.Config(ctx)
+	if err != nil {
+		return fmt.Errorf("cannot get subscription configuration: %w", err)
+	}
+
+	toUpdate := pubsub.SubscriptionConfigToUpdate{}
+	mutableDrifts := []Drift{}
+
+	// immutable fields, only reported
+	if actual.Topic != nil && actual.Topic.ID() != subscriptionConfig.Topic {
+		r.drift(report, Drift{Resource: resource, Field: "topic", Expected: subscriptionConfig.Topic, Actual: actual.Topic.ID()})
+	}
+
+	if subscriptionConfig.EnableMessageOrdering != nil && actual.EnableMessageOrdering != *subscriptionConfig.EnableMessageOrdering {
+		r.drift(report, Drift{
+			Resource: resource,
+			Field:    "enable_message_ordering",
+			Expected: fmt.Sprint(*subscriptionConfig.EnableMessageOrdering),
+			Actual:   fmt.Sprint(actual.EnableMessageOrdering),
+		})
+	}
+
+	if subscriptionConfig.Filter != nil && actual.Filter != *subscriptionConfig.Filter {
+		r.drift(report, Drift{Resource: resource, Field: "filter", Expected: *subscriptionConfig.Filter, Actual: actual.Filter})
+	}
+
+	// mutable fields
+	if subscriptionConfig.AckDeadline > 0 && actual.AckDeadline != subscriptionConfig.AckDeadline {
+		toUpdate.AckDeadline = subscriptionConfig.AckDeadline
+		mutableDrifts = append(mutableDrifts, Drift{
+			Resource: resource,
+			Field:    "ack_deadline",
+			Expected: subscriptionConfig.AckDeadline.String(),
+			Actual:   actual.AckDeadline.String(),
+		})
+	}
+
+	if subscriptionConfig.Retention > 0 && actual.RetentionDuration != subscriptionConfig.Retention {
+		toUpdate.RetentionDuration = subscriptionConfig.Retention
+		mutableDrifts = append(mutableDrifts, Drift{
+			Resource: resource,
+			Field:    "retention",
+			Expected: subscriptionConfig.Retention.String(),
+			Actual:   actual.RetentionDuration.String(),
+		})
+	}
+
+	if subscriptionConfig.RetainAckedMessages != nil && actual.RetainAckedMessages != *subscriptionConfig.RetainAckedMessages {
+		toUpdate.RetainAckedMessages = *subscriptionConfig.RetainAckedMessages
+		mutableDrifts = append(mutableDrifts, Drift{
+			Resource: resource,
+			Field:    "retain_acked_messages",
+			Expected: fmt.Sprint(*subscriptionConfig.RetainAckedMessages),
+			Actual:   fmt.Sprint(actual.RetainAckedMessages),
+		})
+	}
+
+	if len(subscriptionConfig.Labels) > 0 && !reflect.DeepEqual(subscriptionConfig.Labels, actual.Labels) {
+		toUpdate.Labels = subscriptionConfig.Labels
+		mutableDrifts = append(mutableDrifts, Drift{
+			Resource: resource,
+			Field:    "labels",
+			Expected: fmt.Sprint(subscriptionConfig.Labels),
+			Actual:   fmt.Sprint(actual.Labels),
+		})
+	}
+
+	if retryPolicy != nil && !r.sameRetryPolicy(retryPolicy, actual.RetryPolicy) {
+		toUpdate.RetryPolicy = retryPolicy
+		mutableDrifts = append(mutableDrifts, Drift{
+			Resource: resource,
+			Field:    "retry_policy",
+			Expected: r.formatRetryPolicy(retryPolicy),
+			Actual:   r.formatRetryPolicy(actual.RetryPolicy),
+		})
+	}
+
+	if deadLetterPolicy != nil && !r.sameDeadLetterPolicy(deadLetterPolicy, actual.DeadLetterPolicy) {
+		toUpdate.DeadLetterPolicy = deadLetterPolicy
+		mutableDrifts = append(mutableDrifts, Drift{
+			Resource: resource,
+			Field:    "dead_letter_policy",
+			Expected: fmt.Sprintf("%+v", *deadLetterPolicy),
+			Actual:   fmt.Sprintf("%+v", actual.DeadLetterPolicy),
+		})
+	}
+
+	if update && len(mutableDrifts) > 0 {
+		_, err = subscription.Update(ctx, toUpdate)
+		if err != nil {
+			return fmt.Errorf("cannot update subscription: %w", err)
+		}
+
+		for i := range mutableDrifts {
+			mutableDrifts[i].Updated = true
+		}
+	}
+
+	for _, drift := range mutableDrifts {
+		r.drift(report, drift)
+	}
+
+	return nil
+}
+
+func (r *DefaultTopologyReconciler) created(resource Resource, err error, report *Report) error {
+	if err != nil {
+		// created in the meantime, by another instance for example
+		if status.Code(err) == codes.AlreadyExists {
+			r.logger.Debug().Str("kind", resource.Kind).Str("id", resource.ID).Msg("pubsub topology resource already exists")
+
+			return nil
+		}
+
+		return fmt.Errorf("cannot create %s: %w", resource.Kind, err)
+	}
+
+	r.logger.Info().Str("kind", resource.Kind).Str("id", resource.ID).Msg("pubsub topology resource created")
+
+	report.Created = append(report.Created, resource)
+
+	return nil
+}
+
+func (r *DefaultTopologyReconciler) drift(report *Report, drift Drift) {
+	if drift.Expected == drift.Actual {
+		return
+	}
+
+	event := r.logger.Warn()
+	if drift.Updated {
+		event = r.logger.Info()
+	}
+
+	event.
+		Str("kind", drift.Kind).
+		Str("id", drift.ID).
+		Str("field", drift.Field).
+		Str("expected", drift.Expected).
+		Str("actual", drift.Actual).
+		Bool("updated", drift.Updated).
+		Msg("pubsub topology resource drift")
+
+	report.Drifts = append(report.Drifts, drift)
+}
+
+func (r *DefaultTopologyReconciler) schemaName(schemaID string) string {
+	return fmt.Sprintf("projects/%s/schemas/%s", r.client.Project(), schemaID)
+}
+
+func (r *DefaultTopologyReconciler) sameRetryPolicy(expected *pubsub.RetryPolicy, actual *pubsub.RetryPolicy) bool {
+	if actual == nil {
+		return false
+	}
+
+	return (expected.MinimumBackoff == nil || expected.MinimumBackoff == actual.MinimumBackoff) &&
+		(expected.MaximumBackoff == nil || expected.MaximumBackoff == actual.MaximumBackoff)
+}
+
+func (r *DefaultTopologyReconciler) sameDeadLetterPolicy(expected *pubsub.DeadLetterPolicy, actual *pubsub.DeadLetterPolicy) bool {
+	if actual == nil {
+		return false
+	}
+
+	return expected.DeadLetterTopic == actual.DeadLetterTopic &&
+		(expected.MaxDeliveryAttempts == 0 || expected.MaxDeliveryAttempts == actual.MaxDeliveryAttempts)
+}
+
+func (r *DefaultTopologyReconciler) formatRetryPolicy(policy *pubsub.RetryPolicy) string {
+	if policy == nil {
+		return "<nil>"
+	}
+
+	return fmt.Sprintf("{MinimumBackoff:%v MaximumBackoff:%v}", policy.MinimumBackoff, policy.MaximumBackoff)
+}
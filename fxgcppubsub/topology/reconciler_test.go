@@ -0,0 +1,312 @@
+package topology_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/pubsub"
+	"cloud.google.com/go/pubsub/pstest"
+	"github.com/ankorstore/yokai-contrib/fxgcppubsub/topology"
+	"github.com/ankorstore/yokai/config"
+	"github.com/ankorstore/yokai/log"
+	"github.com/ankorstore/yokai/log/logtest"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/api/option"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+)
+
+type alreadyExistsReactor struct{}
+
+func (r *alreadyExistsReactor) React(req any) (bool, any, error) {
+	return true, nil, status.Error(codes.AlreadyExists, "already exists")
+}
+
+func TestDefaultTopologyReconciler(t *testing.T) {
+	t.Setenv("APP_ENV", "test")
+	t.Setenv("GCP_PROJECT_ID", "test-project")
+
+	cfg, err := config.NewDefaultConfigFactory().Create(config.WithFilePaths("../testdata/topology"))
+	assert.NoError(t, err)
+
+	createReconciler := func(tb testing.TB, options ...pstest.ServerReactorOption) (*topology.DefaultTopologyReconciler, *pubsub.Client, logtest.TestLogBuffer) {
+		tb.Helper()
+
+		ctx := context.Background()
+
+		server := pstest.NewServer(options...)
+		tb.Cleanup(func() {
+			server.Close()
+		})
+
+		clientOptions := []option.ClientOption{
+			option.WithEndpoint(server.Addr),
+			option.WithoutAuthentication(),
+			option.WithGRPCDialOption(grpc.WithTransportCredentials(insecure.NewCredentials())),
+		}
+
+		client, err := pubsub.NewClient(ctx, "test-project", clientOptions...)
+		assert.NoError(tb, err)
+
+		schemaClient, err := pubsub.NewSchemaClient(ctx, "test-project", clientOptions...)
+		assert.NoError(tb, err)
+
+		logBuffer := logtest.NewDefaultTestLogBuffer()
+		logger, err := log.NewDefaultLoggerFactory().Create(
+			log.WithLevel(zerolog.DebugLevel),
+			log.WithOutputWriter(logBuffer),
+		)
+		assert.NoError(tb, err)
+
+		return topology.NewDefaultTopologyReconciler(client, schemaClient, logger), client, logBuffer
+	}
+
+	t.Run("creation and idempotency", func(t *testing.T) {
+		ctx := context.Background()
+
+		top, err := topology.NewTopologyFromConfig(cfg)
+		assert.NoError(t, err)
+
+		reconciler, client, logBuffer := createReconciler(t)
+
+		report, err := reconciler.Reconcile(ctx, top)
+		assert.NoError(t, err)
+
+		assert.Equal(
+			t,
+			[]topology.Resource{
+				{Kind: topology.KindSchema, ID: "topology-schema"},
+				{Kind: topology.KindTopic, ID: "topology-topic"},
+				{Kind: topology.KindTopic, ID: "topology-dead-letter-topic"},
+				{Kind: topology.KindSubscription, ID: "topology-subscription"},
+			},
+			report.Created,
+		)
+		assert.Empty(t, report.Drifts)
+
+		logtest.AssertHasLogRecord(t, logBuffer, map[string]interface{}{
+			"level":   "info",
+			"kind":    "subscription",
+			"id":      "topology-subscription",
+			"message": "pubsub topology resource created",
+		})
+
+		topicConfig, err := client.Topic("topology-topic").Config(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, 24*time.Hour, topicConfig.RetentionDuration)
+		assert.Equal(t, map[string]string{"team": "test"}, topicConfig.Labels)
+		assert.Equal(t, "projects/test-project/schemas/topology-schema", topicConfig.SchemaSettings.Schema)
+		assert.Equal(t, pubsub.EncodingBinary, topicConfig.SchemaSettings.Encoding)
+
+		subscriptionConfig, err := client.Subscription("topology-subscription").Config(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, "topology-topic", subscriptionConfig.Topic.ID())
+		assert.Equal(t, 20*time.Second, subscriptionConfig.AckDeadline)
+		assert.Equal(t, 48*time.Hour, subscriptionConfig.RetentionDuration)
+		assert.Equal(t, `attributes.type = "test"`, subscriptionConfig.Filter)
+		assert.Equal(t, "projects/test-project/topics/topology-dead-letter-topic", subscriptionConfig.DeadLetterPolicy.DeadLetterTopic)
+		assert.Equal(t, 10, subscriptionConfig.DeadLetterPolicy.MaxDeliveryAttempts)
+		assert.Equal(t, 5*time.Second, subscriptionConfig.RetryPolicy.MinimumBackoff)
+		assert.Equal(t, 60*time.Second, subscriptionConfig.RetryPolicy.MaximumBackoff)
+
+		// reconcile again
+		report, err = reconciler.Reconcile(ctx, top)
+		assert.NoError(t, err)
+
+		assert.Empty(t, report.Created)
+		assert.Empty(t, report.Drifts)
+	})
+
+	t.Run("drifts report and update", func(t *testing.T) {
+		ctx := context.Background()
+
+		top, err := topology.NewTopologyFromConfig(cfg)
+		assert.NoError(t, err)
+
+		reconciler, client, logBuffer := createReconciler(t)
+
+		_, err = reconciler.Reconcile(ctx, top)
+		assert.NoError(t, err)
+
+		// drift declared topology
+		top.Schemas[0].Definition = `{"type": "record", "name": "Other", "fields": []}`
+		top.Topics[0].Retention = 12 * time.Hour
+		top.Subscriptions[0].AckDeadline = 30 * time.Second
+		top.Subscriptions[0].Filter = ptr(`attributes.type = "other"`)
+		top.Subscriptions[0].DeadLetterPolicy.MaxDeliveryAttempts = 20
+
+		// report only
+		report, err := reconciler.Reconcile(ctx, top)
+		assert.NoError(t, err)
+
+		assert.Empty(t, report.Created)
+		assert.Len(t, report.Drifts, 5)
+
+		for _, drift := range report.Drifts {
+			assert.False(t, drift.Updated)
+		}
+
+		assert.Equal(t, "definition", report.Drifts[0].Field)
+		assert.Equal(
+			t,
+			topology.Drift{
+				Resource: topology.Resource{Kind: topology.KindTopic, ID: "topology-topic"},
+				Field:    "retention",
+				Expected: "12h0m0s",
+				Actual:   "24h0m0s",
+			},
+			report.Drifts[1],
+		)
+
+		logtest.AssertHasLogRecord(t, logBuffer, map[string]interface{}{
+			"level":    "warn",
+			"kind":     "topic",
+			"id":       "topology-topic",
+			"field":    "retention",
+			"expected": "12h0m0s",
+			"actual":   "24h0m0s",
+			"updated":  false,
+			"message":  "pubsub topology resource drift",
+		})
+
+		subscriptionConfig, err := client.Subscription("topology-subscription").Config(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, 20*time.Second, subscriptionConfig.AckDeadline)
+
+		// update
+		top.Update = true
+
+		report, err = reconciler.Reconcile(ctx, top)
+		assert.NoError(t, err)
+
+		updated := map[string]bool{}
+		for _, drift := range report.Drifts {
+			updated[drift.Kind+"."+drift.Field] = drift.Updated
+		}
+
+		assert.Equal(
+			t,
+			map[string]bool{
+				"schema.definition":               false,
+				"topic.retention":                 true,
+				"subscription.filter":             false,
+				"subscription.ack_deadline":       true,
+				"subscription.dead_letter_policy": true,
+			},
+			updated,
+		)
+
+		topicConfig, err := client.Topic("topology-topic").Config(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, 12*time.Hour, topicConfig.RetentionDuration)
+
+		subscriptionConfig, err = client.Subscription("topology-subscription").Config(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, 30*time.Second, subscriptionConfig.AckDeadline)
+		assert.Equal(t, 20, subscriptionConfig.DeadLetterPolicy.MaxDeliveryAttempts)
+		assert.Equal(t, `attributes.type = "test"`, subscriptionConfig.Filter)
+
+		// only immutable drifts remain
+		report, err = reconciler.Reconcile(ctx, top)
+		assert.NoError(t, err)
+		assert.Len(t, report.Drifts, 2)
+	})
+
+	t.Run("undeclared subscription fields are not managed", func(t *testing.T) {
+		ctx := context.Background()
+
+		reconciler, client, _ := createReconciler(t)
+
+		topic, err := client.CreateTopic(ctx, "test-topic")
+		assert.NoError(t, err)
+
+		_, err = client.CreateSubscription(ctx, "test-subscription", pubsub.SubscriptionConfig{
+			Topic:                 topic,
+			RetainAckedMessages:   true,
+			EnableMessageOrdering: true,
+			Filter:                `attributes.type = "test"`,
+		})
+		assert.NoError(t, err)
+
+		top := &topology.Topology{
+			Update:        true,
+			Subscriptions: []topology.SubscriptionConfig{{ID: "test-subscription", Topic: "test-topic"}},
+		}
+
+		report, err := reconciler.Reconcile(ctx, top)
+		assert.NoError(t, err)
+		assert.Empty(t, report.Created)
+		assert.Empty(t, report.Drifts)
+
+		subscriptionConfig, err := client.Subscription("test-subscription").Config(ctx)
+		assert.NoError(t, err)
+		assert.True(t, subscriptionConfig.RetainAckedMessages)
+		assert.True(t, subscriptionConfig.EnableMessageOrdering)
+		assert.Equal(t, `attributes.type = "test"`, subscriptionConfig.Filter)
+
+		// declared with zero values
+		top.Subscriptions[0].RetainAckedMessages = ptr(false)
+		top.Subscriptions[0].EnableMessageOrdering = ptr(false)
+		top.Subscriptions[0].Filter = ptr("")
+
+		report, err = reconciler.Reconcile(ctx, top)
+		assert.NoError(t, err)
+
+		updated := map[string]bool{}
+		for _, drift := range report.Drifts {
+			updated[drift.Field] = drift.Updated
+		}
+
+		assert.Equal(
+			t,
+			map[string]bool{
+				"enable_message_ordering": false,
+				"filter":                  false,
+				"retain_acked_messages":   true,
+			},
+			updated,
+		)
+
+		subscriptionConfig, err = client.Subscription("test-subscription").Config(ctx)
+		assert.NoError(t, err)
+		assert.False(t, subscriptionConfig.RetainAckedMessages)
+	})
+
+	t.Run("no failure on already existing resources", func(t *testing.T) {
+		ctx := context.Background()
+
+		reconciler, _, logBuffer := createReconciler(t, pstest.ServerReactorOption{
+			FuncName: "CreateTopic",
+			Reactor:  &alreadyExistsReactor{},
+		})
+
+		report, err := reconciler.Reconcile(ctx, &topology.Topology{
+			Topics: []topology.TopicConfig{{ID: "test-topic"}},
+		})
+		assert.NoError(t, err)
+		assert.Empty(t, report.Created)
+
+		logtest.AssertHasLogRecord(t, logBuffer, map[string]interface{}{
+			"level":   "debug",
+			"kind":    "topic",
+			"id":      "test-topic",
+			"message": "pubsub topology resource already exists",
+		})
+	})
+
+	t.Run("failure on creation error", func(t *testing.T) {
+		ctx := context.Background()
+
+		reconciler, _, _ := createReconciler(t)
+
+		_, err := reconciler.Reconcile(ctx, &topology.Topology{
+			Subscriptions: []topology.SubscriptionConfig{{ID: "test-subscription", Topic: "invalid-topic"}},
+		})
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "cannot reconcile subscription test-subscription: cannot create subscription")
+	})
+}
@@ -0,0 +1,123 @@
+package topology
+
+import (
+	"fmt"
+	"time"
+
+	"cloud.google.com/go/pubsub"
+	"github.com/ankorstore/yokai/config"
+)
+
+// ConfigKey is the configuration key of the topology.
+const ConfigKey = "modules.gcppubsub.topology"
+
+// Topology represents the declared pub/sub schemas, topics and subscriptions.
+type Topology struct {
+	Enabled       bool                 `mapstructure:"enabled"`
+	Update        bool                 `mapstructure:"update"`
+	Schemas       []SchemaConfig       `mapstructure:"schemas"`
+	Topics        []TopicConfig        `mapstructure:"topics"`
+	Subscriptions []SubscriptionConfig `mapstructure:"subscriptions"`
+}
+
+// SchemaConfig represents a declared pub/sub schema.
+type SchemaConfig struct {
+	ID         string `mapstructure:"id"`
+	Type       string `mapstructure:"type"`
+	Definition string `mapstructure:"definition"`
+}
+
+// TopicConfig represents a declared pub/sub topic.
+type TopicConfig struct {
+	ID        string            `mapstructure:"id"`
+	Retention time.Duration     `mapstructure:"retention"`
+	Labels    map[string]string `mapstructure:"labels"`
+	Schema    TopicSchemaConfig `mapstructure:"schema"`
+}
+
+// TopicSchemaConfig represents a declared pub/sub topic schema settings.
+type TopicSchemaConfig struct {
+	ID       string `mapstructure:"id"`
+	Encoding string `mapstructure:"encoding"`
+}
+
+// SubscriptionConfig represents a declared pub/sub subscription.
+//
+// RetainAckedMessages, EnableMessageOrdering and Filter are pointers to distinguish the undeclared (nil) fields,
+// which are not managed, from the ones declared with their zero value.
+type SubscriptionConfig struct {
+	ID                    string                 `mapstructure:"id"`
+	Topic                 string                 `mapstructure:"topic"`
+	AckDeadline           time.Duration          `mapstructure:"ack_deadline"`
+	Retention             time.Duration          `mapstructure:"retention"`
+	RetainAckedMessages   *bool                  `mapstructure:"retain_acked_messages"`
+	EnableMessageOrdering *bool                  `mapstructure:"enable_message_ordering"`
+	Filter                *string                `mapstructure:"filter"`
+	Labels                map[string]string      `mapstructure:"labels"`
+	RetryPolicy           RetryPolicyConfig      `mapstructure:"retry_policy"`
+	DeadLetterPolicy      DeadLetterPolicyConfig `mapstructure:"dead_letter_policy"`
+}
+
+// RetryPolicyConfig represents a declared pub/sub subscription retry policy.
+type RetryPolicyConfig struct {
+	MinBackoff time.Duration `mapstructure:"min_backoff"`
+	MaxBackoff time.Duration `mapstructure:"max_backoff"`
+}
+
+// DeadLetterPolicyConfig represents a declared pub/sub subscription dead-letter policy.
+type DeadLetterPolicyConfig struct {
+	Topic               string `mapstructure:"topic"`
+	MaxDeliveryAttempts int    `mapstructure:"max_delivery_attempts"`
+}
+
+// NewTopologyFromConfig returns the Topology declared in modules.gcppubsub.topology.
+func NewTopologyFromConfig(cfg *config.Config) (*Topology, error) {
+	topology := &Topology{}
+
+	err := cfg.UnmarshalKey(ConfigKey, topology)
+	if err != nil {
+		return nil, fmt.Errorf("cannot unmarshal topology configuration: %w", err)
+	}
+
+	for _, schema := range topology.Schemas {
+		if _, err = schema.SchemaType(); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, topic := range topology.Topics {
+		if _, err = topic.Schema.SchemaEncoding(); err != nil {
+			return nil, err
+		}
+	}
+
+	return topology, nil
+}
+
+// SchemaType returns the pubsub.SchemaType of the schema.
+func (c SchemaConfig) SchemaType() (pubsub.SchemaType, error) {
+	switch c.Type {
+	case "avro":
+		return pubsub.SchemaAvro, nil
+	case "protocol_buffer", "proto":
+		return pubsub.SchemaProtocolBuffer, nil
+	default:
+		return pubsub.SchemaTypeUnspecified, fmt.Errorf("invalid type %q for schema %s", c.Type, c.ID)
+	}
+}
+
+// SchemaEncoding returns the pubsub.SchemaEncoding of the topic schema settings.
+func (c TopicSchemaConfig) SchemaEncoding() (pubsub.SchemaEncoding, error) {
+	if c.ID == "" {
+		return pubsub.EncodingUnspecified, nil
+	}
+
+	switch c.Encoding {
+	case "binary", "":
+		return pubsub.EncodingBinary, nil
+	case "json":
+		return pubsub.EncodingJSON, nil
+	default:
+		return pubsub.EncodingUnspecified, fmt.Errorf("invalid encoding %q for schema %s", c.Encoding, c.ID)
+	}
+}
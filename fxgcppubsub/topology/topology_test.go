@@ -0,0 +1,149 @@
+package topology_test
+
+import (
+	"testing"
+	"time"
+
+	"cloud.google.com/go/pubsub"
+	"github.com/ankorstore/yokai-contrib/fxgcppubsub/topology"
+	"github.com/ankorstore/yokai/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewTopologyFromConfig(t *testing.T) {
+	t.Setenv("APP_ENV", "test")
+	t.Setenv("GCP_PROJECT_ID", "test-project")
+
+	cfg, err := config.NewDefaultConfigFactory().Create(config.WithFilePaths("../testdata/topology"))
+	assert.NoError(t, err)
+
+	top, err := topology.NewTopologyFromConfig(cfg)
+	assert.NoError(t, err)
+
+	assert.True(t, top.Enabled)
+	assert.False(t, top.Update)
+
+	assert.Len(t, top.Schemas, 1)
+	assert.Equal(t, "topology-schema", top.Schemas[0].ID)
+	assert.Equal(t, "avro", top.Schemas[0].Type)
+	assert.Contains(t, top.Schemas[0].Definition, `"name": "StringField"`)
+
+	assert.Equal(
+		t,
+		[]topology.TopicConfig{
+			{
+				ID:        "topology-topic",
+				Retention: 24 * time.Hour,
+				Labels:    map[string]string{"team": "test"},
+				Schema: topology.TopicSchemaConfig{
+					ID:       "topology-schema",
+					Encoding: "binary",
+				},
+			},
+			{
+				ID: "topology-dead-letter-topic",
+			},
+		},
+		top.Topics,
+	)
+
+	assert.Equal(
+		t,
+		[]topology.SubscriptionConfig{
+			{
+				ID:          "topology-subscription",
+				Topic:       "topology-topic",
+				AckDeadline: 20 * time.Second,
+				Retention:   48 * time.Hour,
+				Filter:      ptr(`attributes.type = "test"`),
+				Labels:      map[string]string{"team": "test"},
+				RetryPolicy: topology.RetryPolicyConfig{
+					MinBackoff: 5 * time.Second,
+					MaxBackoff: 60 * time.Second,
+				},
+				DeadLetterPolicy: topology.DeadLetterPolicyConfig{
+					Topic:               "topology-dead-letter-topic",
+					MaxDeliveryAttempts: 10,
+				},
+			},
+		},
+		top.Subscriptions,
+	)
+}
+
+func TestNewTopologyFromConfigWithInvalidValues(t *testing.T) {
+	t.Setenv("APP_ENV", "test")
+	t.Setenv("GCP_PROJECT_ID", "test-project")
+
+	t.Run("invalid schema type", func(t *testing.T) {
+		cfg, err := config.NewDefaultConfigFactory().Create(config.WithFilePaths("../testdata/topology"))
+		assert.NoError(t, err)
+
+		cfg.Set("modules.gcppubsub.topology.schemas", []map[string]any{{"id": "test-schema", "type": "invalid"}})
+
+		_, err = topology.NewTopologyFromConfig(cfg)
+		assert.Error(t, err)
+		assert.Equal(t, `invalid type "invalid" for schema test-schema`, err.Error())
+	})
+
+	t.Run("invalid topic schema encoding", func(t *testing.T) {
+		cfg, err := config.NewDefaultConfigFactory().Create(config.WithFilePaths("../testdata/topology"))
+		assert.NoError(t, err)
+
+		cfg.Set("modules.gcppubsub.topology.topics", []map[string]any{{"id": "test-topic", "schema": map[string]any{"id": "test-schema", "encoding": "invalid"}}})
+
+		_, err = topology.NewTopologyFromConfig(cfg)
+		assert.Error(t, err)
+		assert.Equal(t, `invalid encoding "invalid" for schema test-schema`, err.Error())
+	})
+}
+
+func TestSchemaConfig(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		schemaType string
+		expected   pubsub.SchemaType
+		err        bool
+	}{
+		{"avro", pubsub.SchemaAvro, false},
+		{"protocol_buffer", pubsub.SchemaProtocolBuffer, false},
+		{"proto", pubsub.SchemaProtocolBuffer, false},
+		{"invalid", pubsub.SchemaTypeUnspecified, true},
+	}
+
+	for _, test := range tests {
+		schemaType, err := topology.SchemaConfig{ID: "test-schema", Type: test.schemaType}.SchemaType()
+
+		assert.Equal(t, test.expected, schemaType)
+		assert.Equal(t, test.err, err != nil)
+	}
+}
+
+func TestTopicSchemaConfig(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		schemaID string
+		encoding string
+		expected pubsub.SchemaEncoding
+		err      bool
+	}{
+		{"", "", pubsub.EncodingUnspecified, false},
+		{"test-schema", "", pubsub.EncodingBinary, false},
+		{"test-schema", "binary", pubsub.EncodingBinary, false},
+		{"test-schema", "json", pubsub.EncodingJSON, false},
+		{"test-schema", "invalid", pubsub.EncodingUnspecified, true},
+	}
+
+	for _, test := range tests {
+		encoding, err := topology.TopicSchemaConfig{ID: test.schemaID, Encoding: test.encoding}.SchemaEncoding()
+
+		assert.Equal(t, test.expected, encoding)
+		assert.Equal(t, test.err, err != nil)
+	}
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
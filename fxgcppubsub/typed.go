@@ -0,0 +1,248 @@
+package fxgcppubsub
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	"cloud.google.com/go/pubsub"
+	"github.com/ankorstore/yokai-contrib/fxgcppubsub/codec"
+	"github.com/ankorstore/yokai-contrib/fxgcppubsub/message"
+	"github.com/ankorstore/yokai-contrib/fxgcppubsub/subscription"
+	"github.com/ankorstore/yokai-contrib/fxgcppubsub/topic"
+	"github.com/ankorstore/yokai/log"
+)
+
+// TypedSubscribeFunc represents the TypedSubscriber execution callback, receiving the already decoded data.
+type TypedSubscribeFunc[T any] func(ctx context.Context, m *message.Message, data T)
+
+// TypedSubscribeErrorFunc represents the TypedSubscriber execution callback returning an error, to be used with a subscription.RetryPolicy.
+type TypedSubscribeErrorFunc[T any] func(ctx context.Context, m *message.Message, data T) error
+
+// TypedPublisher is a publisher bound to a topic, publishing data of type T.
+type TypedPublisher[T any] struct {
+	publisher Publisher
+	topicID   string
+	codec     codec.Codec
+}
+
+// NewTypedPublisher returns a new TypedPublisher instance, bound to a given topicID.
+//
+// The topic codec.Codec is resolved once, and T is checked to be compatible with the topic schema: from the schema
+// structure if the codec is a codec.TypeCheckerCodec, or by encoding the zero value of T otherwise.
+func NewTypedPublisher[T any](
+	ctx context.Context,
+	publisher Publisher,
+	factory topic.TopicFactory,
+	registry topic.TopicRegistry,
+	topicID string,
+) (*TypedPublisher[T], error) {
+	// retrieve topic
+	if !registry.Has(topicID) {
+		top, err := factory.Create(ctx, topicID)
+		if err != nil {
+			return nil, fmt.Errorf("cannot create topic: %w", err)
+		}
+
+		registry.Add(top)
+	}
+
+	top, err := registry.Get(topicID)
+	if err != nil {
+		return nil, fmt.Errorf("cannot get topic: %w", err)
+	}
+
+	// check compatibility
+	err = checkTypedValue[T](ctx, top.Codec(), false)
+	if err != nil {
+		return nil, fmt.Errorf("type %s is not compatible with topic %s: %w", typeName[T](), topicID, err)
+	}
+
+	return &TypedPublisher[T]{
+		publisher: publisher,
+		topicID:   topicID,
+		codec:     top.Codec(),
+	}, nil
+}
+
+// TopicID returns the topic id the publisher is bound to.
+func (p *TypedPublisher[T]) TopicID() string {
+	return p.topicID
+}
+
+// Codec returns the codec.Codec of the topic the publisher is bound to.
+func (p *TypedPublisher[T]) Codec() codec.Codec {
+	return p.codec
+}
+
+// Publish publishes data, with options, on the bound topic.
+func (p *TypedPublisher[T]) Publish(ctx context.Context, data T, options ...topic.PublishOption) (*pubsub.PublishResult, error) {
+	return p.publisher.Publish(ctx, p.topicID, data, options...)
+}
+
+// PublishBatch publishes a list of data, with options, on the bound topic.
+func (p *TypedPublisher[T]) PublishBatch(ctx context.Context, data []T, options ...topic.PublishOption) (*topic.BatchPublishResult, error) {
+	items := make([]any, len(data))
+	for i, d := range data {
+		items[i] = d
+	}
+
+	return p.publisher.PublishBatch(ctx, p.topicID, items, options...)
+}
+
+// TypedSubscriber is a subscriber bound to a subscription, handling data of type T.
+type TypedSubscriber[T any] struct {
+	subscriber     Subscriber
+	subscriptionID string
+	codec          codec.Codec
+}
+
+// NewTypedSubscriber returns a new TypedSubscriber instance, bound to a given subscriptionID.
+//
+// The subscription codec.Codec is resolved once, and T is checked to be compatible with the subscription topic schema:
+// from the schema structure if the codec is a codec.TypeCheckerCodec, or by encoding and decoding the zero value of T otherwise.
+func NewTypedSubscriber[T any](
+	ctx context.Context,
+	subscriber Subscriber,
+	factory subscription.SubscriptionFactory,
+	registry subscription.SubscriptionRegistry,
+	subscriptionID string,
+) (*TypedSubscriber[T], error) {
+	// retrieve subscription
+	if !registry.Has(subscriptionID) {
+		sub, err := factory.Create(ctx, subscriptionID)
+		if err != nil {
+			return nil, fmt.Errorf("cannot create subscription: %w", err)
+		}
+
+		registry.Add(sub)
+	}
+
+	sub, err := registry.Get(subscriptionID)
+	if err != nil {
+		return nil, fmt.Errorf("cannot get subscription: %w", err)
+	}
+
+	// check compatibility
+	err = checkTypedValue[T](ctx, sub.Codec(), true)
+	if err != nil {
+		return nil, fmt.Errorf("type %s is not compatible with subscription %s: %w", typeName[T](), subscriptionID, err)
+	}
+
+	return &TypedSubscriber[T]{
+		subscriber:     subscriber,
+		subscriptionID: subscriptionID,
+		codec:          sub.Codec(),
+	}, nil
+}
+
+// SubscriptionID returns the subscription id the subscriber is bound to.
+func (s *TypedSubscriber[T]) SubscriptionID() string {
+	return s.subscriptionID
+}
+
+// Codec returns the codec.Codec of the subscription the subscriber is bound to.
+func (s *TypedSubscriber[T]) Codec() codec.Codec {
+	return s.codec
+}
+
+// Subscribe handle received data using a TypedSubscribeFunc, with options, from the bound subscription.
+//
+// Messages that cannot be decoded into T are logged and nacked, without calling the TypedSubscribeFunc.
+func (s *TypedSubscriber[T]) Subscribe(ctx context.Context, f TypedSubscribeFunc[T], options ...subscription.SubscribeOption) error {
+	return s.subscriber.Subscribe(
+		ctx,
+		s.subscriptionID,
+		func(ctx context.Context, m *message.Message) {
+			data, err := decodeTypedValue[T](ctx, s.codec, m.Data(), m.Attributes())
+			if err != nil {
+				log.CtxLogger(ctx).
+					Error().
+					Err(err).
+					Str("subscriptionID", s.subscriptionID).
+					Str("messageID", m.ID()).
+					Msg("pubsub message decoding error")
+
+				m.Nack()
+
+				return
+			}
+
+			f(ctx, m, data)
+		},
+		options...,
+	)
+}
+
+// SubscribeWithRetry handle received data using a TypedSubscribeErrorFunc, with options, from the bound subscription.
+//
+// Messages that cannot be decoded into T are handled as processing errors, according to the subscription.RetryPolicy.
+func (s *TypedSubscriber[T]) SubscribeWithRetry(ctx context.Context, f TypedSubscribeErrorFunc[T], options ...subscription.SubscribeOption) error {
+	return s.subscriber.SubscribeWithRetry(
+		ctx,
+		s.subscriptionID,
+		func(ctx context.Context, m *message.Message) error {
+			data, err := decodeTypedValue[T](ctx, s.codec, m.Data(), m.Attributes())
+			if err != nil {
+				return err
+			}
+
+			return f(ctx, m, data)
+		},
+		options...,
+	)
+}
+
+// checkTypedValue checks that T is compatible with the codec.Codec: structurally if it is a codec.TypeCheckerCodec,
+// or by encoding (and decoding if needed) the zero value of T otherwise.
+func checkTypedValue[T any](ctx context.Context, c codec.Codec, decode bool) (err error) {
+	if checked, checkErr := codec.CheckType(c, reflect.TypeOf((*T)(nil)).Elem()); checked {
+		return checkErr
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("cannot encode zero value of %s: %v", typeName[T](), r)
+		}
+	}()
+
+	enc, attributes, err := codec.EncodeWithAttributesContext(ctx, c, newTypedValue[T]())
+	if err == nil && decode {
+		_, err = decodeTypedValue[T](ctx, c, enc, attributes)
+	}
+
+	return err
+}
+
+// newTypedValue returns a usable zero value of T, allocated if T is a pointer type (like proto messages).
+func newTypedValue[T any]() T {
+	var v T
+
+	if t := reflect.TypeOf(v); t != nil && t.Kind() == reflect.Pointer {
+		//nolint:forcetypeassert
+		return reflect.New(t.Elem()).Interface().(T)
+	}
+
+	return v
+}
+
+// decodeTypedValue decodes the provided encoded data, with its message attributes, into a new T.
+func decodeTypedValue[T any](ctx context.Context, c codec.Codec, enc []byte, attributes map[string]string) (T, error) {
+	v := newTypedValue[T]()
+
+	var out any = &v
+	if t := reflect.TypeOf(v); t != nil && t.Kind() == reflect.Pointer {
+		out = v
+	}
+
+	err := codec.DecodeWithAttributesContext(ctx, c, enc, attributes, out)
+	if err != nil {
+		return v, fmt.Errorf("cannot decode data into %s: %w", typeName[T](), err)
+	}
+
+	return v, nil
+}
+
+func typeName[T any]() string {
+	return reflect.TypeOf((*T)(nil)).Elem().String()
+}
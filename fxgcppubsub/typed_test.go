@@ -0,0 +1,308 @@
+package fxgcppubsub_test
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/pubsub"
+	"github.com/ankorstore/yokai-contrib/fxgcppubsub"
+	"github.com/ankorstore/yokai-contrib/fxgcppubsub/message"
+	"github.com/ankorstore/yokai-contrib/fxgcppubsub/reactor/ack"
+	"github.com/ankorstore/yokai-contrib/fxgcppubsub/testdata/avro"
+	"github.com/ankorstore/yokai-contrib/fxgcppubsub/testdata/proto"
+	"github.com/ankorstore/yokai/fxconfig"
+	"github.com/ankorstore/yokai/fxlog"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/fx"
+	"go.uber.org/fx/fxtest"
+)
+
+func TestTypedPublisherAndSubscriber(t *testing.T) {
+	t.Setenv("APP_ENV", "test")
+	t.Setenv("APP_CONFIG_PATH", "testdata/config")
+	t.Setenv("GCP_PROJECT_ID", "test-project")
+
+	prepareOptions := func(tb testing.TB) fx.Option {
+		tb.Helper()
+
+		return fx.Options(
+			fxconfig.FxConfigModule,
+			fxlog.FxLogModule,
+			fxgcppubsub.FxGcpPubSubModule,
+			fx.Supply(fx.Annotate(context.Background(), fx.As(new(context.Context)))),
+			fxgcppubsub.PrepareTopicAndSubscription(fxgcppubsub.PrepareTopicAndSubscriptionParams{
+				TopicID:        "raw-topic",
+				SubscriptionID: "raw-subscription",
+			}),
+			fxgcppubsub.PrepareTopicAndSubscriptionWithSchema(fxgcppubsub.PrepareTopicAndSubscriptionWithSchemaParams{
+				TopicID:        "avro-topic",
+				SubscriptionID: "avro-subscription",
+				SchemaID:       "avro-schema",
+				SchemaConfig: pubsub.SchemaConfig{
+					Name:       "avro-schema",
+					Type:       pubsub.SchemaAvro,
+					Definition: avro.GetTestAvroSchemaDefinition(tb),
+				},
+				SchemaEncoding: pubsub.EncodingBinary,
+			}),
+			fxgcppubsub.PrepareTopicAndSubscriptionWithSchema(fxgcppubsub.PrepareTopicAndSubscriptionWithSchemaParams{
+				TopicID:        "complex-avro-binary-topic",
+				SubscriptionID: "complex-avro-binary-subscription",
+				SchemaID:       "complex-avro-binary-schema",
+				SchemaConfig: pubsub.SchemaConfig{
+					Name:       "complex-avro-binary-schema",
+					Type:       pubsub.SchemaAvro,
+					Definition: avro.GetTestAvroComplexSchemaDefinition(tb),
+				},
+				SchemaEncoding: pubsub.EncodingBinary,
+			}),
+			fxgcppubsub.PrepareTopicAndSubscriptionWithSchema(fxgcppubsub.PrepareTopicAndSubscriptionWithSchemaParams{
+				TopicID:        "complex-avro-json-topic",
+				SubscriptionID: "complex-avro-json-subscription",
+				SchemaID:       "complex-avro-json-schema",
+				SchemaConfig: pubsub.SchemaConfig{
+					Name:       "complex-avro-json-schema",
+					Type:       pubsub.SchemaAvro,
+					Definition: avro.GetTestAvroComplexSchemaDefinition(tb),
+				},
+				SchemaEncoding: pubsub.EncodingJSON,
+			}),
+			fxgcppubsub.PrepareTopicAndSubscriptionWithSchema(fxgcppubsub.PrepareTopicAndSubscriptionWithSchemaParams{
+				TopicID:        "proto-topic",
+				SubscriptionID: "proto-subscription",
+				SchemaID:       "proto-schema",
+				SchemaConfig: pubsub.SchemaConfig{
+					Name:       "proto-schema",
+					Type:       pubsub.SchemaProtocolBuffer,
+					Definition: proto.GetTestProtoSchemaDefinition(tb),
+				},
+				SchemaEncoding: pubsub.EncodingJSON,
+			}),
+		)
+	}
+
+	t.Run("avro typed message ack", func(t *testing.T) {
+		var publisher *fxgcppubsub.TypedPublisher[avro.SimpleRecord]
+		var subscriber *fxgcppubsub.TypedSubscriber[avro.SimpleRecord]
+		var supervisor ack.AckSupervisor
+
+		ctx := context.Background()
+
+		fxtest.New(
+			t,
+			fx.NopLogger,
+			prepareOptions(t),
+			fxgcppubsub.AsTypedPublisher[avro.SimpleRecord]("avro-topic"),
+			fxgcppubsub.AsTypedSubscriber[avro.SimpleRecord]("avro-subscription"),
+			fx.Populate(&publisher, &subscriber, &supervisor),
+		).RequireStart().RequireStop()
+
+		assert.Equal(t, "avro-topic", publisher.TopicID())
+		assert.Equal(t, "avro-subscription", subscriber.SubscriptionID())
+
+		in := avro.SimpleRecord{
+			StringField:  "test avro",
+			FloatField:   12.34,
+			BooleanField: true,
+		}
+
+		_, err := publisher.Publish(ctx, in)
+		assert.NoError(t, err)
+
+		res, err := publisher.PublishBatch(ctx, []avro.SimpleRecord{in, in})
+		assert.NoError(t, err)
+		assert.NoError(t, res.Wait(ctx))
+		assert.Equal(t, 2, res.Len())
+
+		waiter := supervisor.StartAckWaiter("avro-subscription")
+
+		//nolint:errcheck
+		go subscriber.Subscribe(ctx, func(ctx context.Context, m *message.Message, data avro.SimpleRecord) {
+			assert.Equal(t, in, data)
+
+			m.Ack()
+		})
+
+		_, err = waiter.WaitMaxDuration(ctx, 2*time.Second)
+		assert.NoError(t, err)
+	})
+
+	t.Run("avro typed message ack with decimal and enum", func(t *testing.T) {
+		for _, encoding := range []string{"binary", "json"} {
+			var publisher *fxgcppubsub.TypedPublisher[avro.ComplexRecord]
+			var subscriber *fxgcppubsub.TypedSubscriber[avro.ComplexRecord]
+			var supervisor ack.AckSupervisor
+
+			ctx := context.Background()
+
+			topicID := "complex-avro-" + encoding + "-topic"
+			subscriptionID := "complex-avro-" + encoding + "-subscription"
+
+			// the zero value of avro.ComplexRecord has a nil decimal and an empty enum: the check must not encode it
+			fxtest.New(
+				t,
+				fx.NopLogger,
+				prepareOptions(t),
+				fxgcppubsub.AsTypedPublisher[avro.ComplexRecord](topicID),
+				fxgcppubsub.AsTypedSubscriber[avro.ComplexRecord](subscriptionID),
+				fx.Populate(&publisher, &subscriber, &supervisor),
+			).RequireStart().RequireStop()
+
+			in := avro.ComplexRecord{
+				StringField:    "test avro",
+				TimestampField: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+				DateField:      time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC),
+				DecimalField:   big.NewRat(123456, 100),
+				EnumField:      "GREEN",
+				ArrayField:     []string{},
+				MapField:       map[string]int64{},
+				NestedField:    avro.ComplexNested{Name: "nested"},
+			}
+
+			_, err := publisher.Publish(ctx, in)
+			assert.NoError(t, err)
+
+			waiter := supervisor.StartAckWaiter(subscriptionID)
+
+			//nolint:errcheck
+			go subscriber.Subscribe(ctx, func(ctx context.Context, m *message.Message, data avro.ComplexRecord) {
+				assert.Equal(t, 0, in.DecimalField.Cmp(data.DecimalField))
+				assert.Equal(t, "GREEN", data.EnumField)
+				assert.Equal(t, "nested", data.NestedField.Name)
+
+				m.Ack()
+			})
+
+			_, err = waiter.WaitMaxDuration(ctx, 2*time.Second)
+			assert.NoError(t, err, encoding)
+		}
+	})
+
+	t.Run("proto typed message ack with retry", func(t *testing.T) {
+		var publisher *fxgcppubsub.TypedPublisher[*proto.SimpleRecord]
+		var subscriber *fxgcppubsub.TypedSubscriber[*proto.SimpleRecord]
+		var supervisor ack.AckSupervisor
+
+		ctx := context.Background()
+
+		fxtest.New(
+			t,
+			fx.NopLogger,
+			prepareOptions(t),
+			fxgcppubsub.AsTypedPublisher[*proto.SimpleRecord]("proto-topic"),
+			fxgcppubsub.AsTypedSubscriber[*proto.SimpleRecord]("proto-subscription"),
+			fx.Populate(&publisher, &subscriber, &supervisor),
+		).RequireStart().RequireStop()
+
+		_, err := publisher.Publish(ctx, &proto.SimpleRecord{
+			StringField:  "test proto",
+			FloatField:   56.78,
+			BooleanField: true,
+		})
+		assert.NoError(t, err)
+
+		waiter := supervisor.StartAckWaiter("proto-subscription")
+
+		//nolint:errcheck
+		go subscriber.SubscribeWithRetry(ctx, func(ctx context.Context, m *message.Message, data *proto.SimpleRecord) error {
+			assert.Equal(t, "test proto", data.StringField)
+			assert.Equal(t, float32(56.78), data.FloatField)
+			assert.True(t, data.BooleanField)
+
+			return nil
+		})
+
+		_, err = waiter.WaitMaxDuration(ctx, 2*time.Second)
+		assert.NoError(t, err)
+	})
+
+	t.Run("typed message nack on decoding error", func(t *testing.T) {
+		var client *pubsub.Client
+		var subscriber *fxgcppubsub.TypedSubscriber[avro.SimpleRecord]
+		var supervisor ack.AckSupervisor
+
+		ctx := context.Background()
+
+		fxtest.New(
+			t,
+			fx.NopLogger,
+			prepareOptions(t),
+			fxgcppubsub.AsTypedSubscriber[avro.SimpleRecord]("avro-subscription"),
+			fx.Populate(&client, &subscriber, &supervisor),
+		).RequireStart().RequireStop()
+
+		// publish with the base client, bypassing the codec
+		res := client.Topic("avro-topic").Publish(ctx, &pubsub.Message{Data: []byte("invalid")})
+		_, err := res.Get(ctx)
+		assert.NoError(t, err)
+
+		waiter := supervisor.StartNackWaiter("avro-subscription")
+
+		//nolint:errcheck
+		go subscriber.Subscribe(ctx, func(ctx context.Context, m *message.Message, data avro.SimpleRecord) {
+			t.Error("should not be called")
+		})
+
+		_, err = waiter.WaitMaxDuration(ctx, 2*time.Second)
+		assert.NoError(t, err)
+	})
+
+	t.Run("incompatible types", func(t *testing.T) {
+		tests := []struct {
+			option   fx.Option
+			expected string
+		}{
+			{
+				fx.Options(
+					fxgcppubsub.AsTypedPublisher[avro.InvalidSimpleRecord]("avro-topic"),
+					fx.Invoke(func(*fxgcppubsub.TypedPublisher[avro.InvalidSimpleRecord]) {}),
+				),
+				"type avro.InvalidSimpleRecord is not compatible with topic avro-topic",
+			},
+			{
+				fx.Options(
+					fxgcppubsub.AsTypedPublisher[avro.SimpleRecord]("proto-topic"),
+					fx.Invoke(func(*fxgcppubsub.TypedPublisher[avro.SimpleRecord]) {}),
+				),
+				"type avro.SimpleRecord is not compatible with topic proto-topic: invalid proto message",
+			},
+			{
+				fx.Options(
+					fxgcppubsub.AsTypedPublisher[avro.SimpleRecord]("invalid-topic"),
+					fx.Invoke(func(*fxgcppubsub.TypedPublisher[avro.SimpleRecord]) {}),
+				),
+				"cannot create topic",
+			},
+			{
+				fx.Options(
+					fxgcppubsub.AsTypedSubscriber[avro.SimpleRecord]("raw-subscription"),
+					fx.Invoke(func(*fxgcppubsub.TypedSubscriber[avro.SimpleRecord]) {}),
+				),
+				"type avro.SimpleRecord is not compatible with subscription raw-subscription",
+			},
+			{
+				fx.Options(
+					fxgcppubsub.AsTypedSubscriber[avro.InvalidSimpleRecord]("avro-subscription"),
+					fx.Invoke(func(*fxgcppubsub.TypedSubscriber[avro.InvalidSimpleRecord]) {}),
+				),
+				"type avro.InvalidSimpleRecord is not compatible with subscription avro-subscription",
+			},
+			{
+				fx.Options(
+					fxgcppubsub.AsTypedSubscriber[avro.SimpleRecord]("invalid-subscription"),
+					fx.Invoke(func(*fxgcppubsub.TypedSubscriber[avro.SimpleRecord]) {}),
+				),
+				"cannot create subscription",
+			},
+		}
+
+		for _, test := range tests {
+			err := fx.New(fx.NopLogger, prepareOptions(t), test.option).Err()
+			if assert.Error(t, err) {
+				assert.Contains(t, err.Error(), test.expected)
+			}
+		}
+	})
+}
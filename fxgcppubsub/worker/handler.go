@@ -0,0 +1,160 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/ankorstore/yokai-contrib/fxgcppubsub/message"
+	"github.com/ankorstore/yokai-contrib/fxgcppubsub/subscription"
+)
+
+// ErrMessageNacked is the error observed when a subscription.SubscribeFunc handler nacks a message.
+var ErrMessageNacked = errors.New("message nacked")
+
+// ObserveFunc represents a callback observing the outcome of each handled message.
+type ObserveFunc func(err error)
+
+// Subscriber is the interface for the subscribers used by the workers.
+type Subscriber interface {
+	Subscribe(ctx context.Context, subscriptionID string, f subscription.SubscribeFunc, options ...subscription.SubscribeOption) error
+	SubscribeWithRetry(ctx context.Context, subscriptionID string, f subscription.SubscribeErrorFunc, options ...subscription.SubscribeOption) error
+}
+
+// SubscriptionHandler is the interface for subscription handlers, run with the subscription retry policy:
+// messages are acked on success, and retried (then dead-lettered) on error.
+type SubscriptionHandler interface {
+	Handle(ctx context.Context, m *message.Message) error
+}
+
+// SubscriptionHandlerDefinition represents a handler registered on a subscription, to be run by a Worker.
+//
+// The Handler can be a subscription.SubscribeFunc (with manual acknowledgement), a subscription.SubscribeErrorFunc
+// or a SubscriptionHandler (with the subscription retry policy).
+type SubscriptionHandlerDefinition struct {
+	SubscriptionID string
+	Handler        any
+	Options        []subscription.SubscribeOption
+}
+
+// IsHandler returns true if the provided value can be used as a SubscriptionHandlerDefinition Handler.
+func IsHandler(handler any) bool {
+	switch handler.(type) {
+	case subscription.SubscribeFunc,
+		func(context.Context, *message.Message),
+		subscription.SubscribeErrorFunc,
+		func(context.Context, *message.Message) error,
+		SubscriptionHandler:
+		return true
+	default:
+		return false
+	}
+}
+
+// RunFunc returns the blocking function subscribing the definition handler with a provided Subscriber.
+//
+// The provided observers are notified once of each handled message outcome, on its acknowledgement: the last handler error
+// for a subscription.SubscribeErrorFunc or a SubscriptionHandler (after the in process retries, including when the
+// message is dead-lettered), or ErrMessageNacked on nack for a subscription.SubscribeFunc.
+func (d SubscriptionHandlerDefinition) RunFunc(subscriber Subscriber, observers ...ObserveFunc) (RunFunc, error) {
+	switch h := d.Handler.(type) {
+	case subscription.SubscribeFunc:
+		return d.subscribe(subscriber, h, observers), nil
+	case func(context.Context, *message.Message):
+		return d.subscribe(subscriber, h, observers), nil
+	case subscription.SubscribeErrorFunc:
+		return d.subscribeWithRetry(subscriber, h, observers), nil
+	case func(context.Context, *message.Message) error:
+		return d.subscribeWithRetry(subscriber, h, observers), nil
+	case SubscriptionHandler:
+		return d.subscribeWithRetry(subscriber, h.Handle, observers), nil
+	default:
+		return nil, fmt.Errorf("invalid handler type %T for subscription %s", d.Handler, d.SubscriptionID)
+	}
+}
+
+func (d SubscriptionHandlerDefinition) subscribe(subscriber Subscriber, f subscription.SubscribeFunc, observers []ObserveFunc) RunFunc {
+	if len(observers) > 0 {
+		next := f
+
+		f = func(ctx context.Context, m *message.Message) {
+			m.OnAcknowledgement(func(ack bool) {
+				var err error
+				if !ack {
+					err = ErrMessageNacked
+				}
+
+				for _, observe := range observers {
+					observe(err)
+				}
+			})
+
+			next(ctx, m)
+		}
+	}
+
+	return func(ctx context.Context) error {
+		return subscriber.Subscribe(ctx, d.SubscriptionID, f, d.Options...)
+	}
+}
+
+func (d SubscriptionHandlerDefinition) subscribeWithRetry(subscriber Subscriber, f subscription.SubscribeErrorFunc, observers []ObserveFunc) RunFunc {
+	if len(observers) > 0 {
+		next := f
+
+		// the handler is called once per in process retry: the last error of each message is kept until its
+		// acknowledgement, to observe its final outcome only once
+		var outcomes sync.Map
+
+		f = func(ctx context.Context, m *message.Message) error {
+			value, loaded := outcomes.LoadOrStore(m, &outcome{})
+			//nolint:forcetypeassert
+			o := value.(*outcome)
+
+			if !loaded {
+				m.OnAcknowledgement(func(ack bool) {
+					outcomes.Delete(m)
+
+					err := o.get()
+					if err == nil && !ack {
+						err = ErrMessageNacked
+					}
+
+					for _, observe := range observers {
+						observe(err)
+					}
+				})
+			}
+
+			err := next(ctx, m)
+
+			o.set(err)
+
+			return err
+		}
+	}
+
+	return func(ctx context.Context) error {
+		return subscriber.SubscribeWithRetry(ctx, d.SubscriptionID, f, d.Options...)
+	}
+}
+
+type outcome struct {
+	err   error
+	mutex sync.Mutex
+}
+
+func (o *outcome) get() error {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+
+	return o.err
+}
+
+func (o *outcome) set(err error) {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+
+	o.err = err
+}
@@ -0,0 +1,181 @@
+package worker_test
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+
+	"cloud.google.com/go/pubsub"
+	"github.com/ankorstore/yokai-contrib/fxgcppubsub/codec"
+	"github.com/ankorstore/yokai-contrib/fxgcppubsub/message"
+	"github.com/ankorstore/yokai-contrib/fxgcppubsub/subscription"
+	"github.com/ankorstore/yokai-contrib/fxgcppubsub/worker"
+	"github.com/stretchr/testify/assert"
+)
+
+type subscriberStub struct {
+	subscribed     []string
+	subscribedWith []string
+}
+
+func (s *subscriberStub) Subscribe(_ context.Context, subscriptionID string, _ subscription.SubscribeFunc, _ ...subscription.SubscribeOption) error {
+	s.subscribed = append(s.subscribed, subscriptionID)
+
+	return nil
+}
+
+func (s *subscriberStub) SubscribeWithRetry(_ context.Context, subscriptionID string, _ subscription.SubscribeErrorFunc, _ ...subscription.SubscribeOption) error {
+	s.subscribedWith = append(s.subscribedWith, subscriptionID)
+
+	return nil
+}
+
+type testHandler struct{}
+
+func (h *testHandler) Handle(context.Context, *message.Message) error {
+	return nil
+}
+
+func TestSubscriptionHandlerDefinition(t *testing.T) {
+	t.Parallel()
+
+	subscribeFunc := func(context.Context, *message.Message) {}
+	subscribeErrorFunc := func(context.Context, *message.Message) error { return nil }
+
+	tests := []struct {
+		name      string
+		handler   any
+		withRetry bool
+	}{
+		{"subscribe func", subscribeFunc, false},
+		{"named subscribe func", subscription.SubscribeFunc(subscribeFunc), false},
+		{"subscribe error func", subscribeErrorFunc, true},
+		{"named subscribe error func", subscription.SubscribeErrorFunc(subscribeErrorFunc), true},
+		{"subscription handler", &testHandler{}, true},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.True(t, worker.IsHandler(tt.handler))
+
+			definition := worker.SubscriptionHandlerDefinition{
+				SubscriptionID: "test-subscription",
+				Handler:        tt.handler,
+			}
+
+			subscriber := &subscriberStub{}
+
+			run, err := definition.RunFunc(subscriber)
+			assert.NoError(t, err)
+
+			err = run(context.Background())
+			assert.NoError(t, err)
+
+			if tt.withRetry {
+				assert.Equal(t, []string{"test-subscription"}, subscriber.subscribedWith)
+				assert.Empty(t, subscriber.subscribed)
+			} else {
+				assert.Equal(t, []string{"test-subscription"}, subscriber.subscribed)
+				assert.Empty(t, subscriber.subscribedWith)
+			}
+		})
+	}
+
+	t.Run("invalid handler", func(t *testing.T) {
+		t.Parallel()
+
+		handler := func() *testHandler { return &testHandler{} }
+
+		assert.False(t, worker.IsHandler(handler))
+
+		definition := worker.SubscriptionHandlerDefinition{
+			SubscriptionID: "test-subscription",
+			Handler:        handler,
+		}
+
+		_, err := definition.RunFunc(&subscriberStub{})
+		assert.Error(t, err)
+		assert.Equal(t, "invalid handler type func() *worker_test.testHandler for subscription test-subscription", err.Error())
+	})
+}
+
+type callingSubscriberStub struct{}
+
+func (s *callingSubscriberStub) Subscribe(ctx context.Context, _ string, f subscription.SubscribeFunc, _ ...subscription.SubscribeOption) error {
+	f(ctx, message.NewMessage(codec.NewRawCodec(), &pubsub.Message{ID: "test-id"}))
+
+	return nil
+}
+
+// SubscribeWithRetry retries the handler in process up to 3 attempts, then acks the message like when dead-lettered.
+func (s *callingSubscriberStub) SubscribeWithRetry(ctx context.Context, _ string, f subscription.SubscribeErrorFunc, _ ...subscription.SubscribeOption) error {
+	m := message.NewMessage(codec.NewRawCodec(), &pubsub.Message{ID: "test-id"})
+
+	for attempt := 1; attempt <= 3; attempt++ {
+		if f(ctx, m) == nil {
+			break
+		}
+	}
+
+	m.Ack()
+
+	return nil
+}
+
+func newFailingHandler(failures int) subscription.SubscribeErrorFunc {
+	var attempts atomic.Int32
+
+	return func(context.Context, *message.Message) error {
+		if attempts.Add(1) <= int32(failures) {
+			return assert.AnError
+		}
+
+		return nil
+	}
+}
+
+func TestSubscriptionHandlerDefinitionObservers(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		handler  any
+		expected error
+	}{
+		{"acked message", func(_ context.Context, m *message.Message) { m.Ack() }, nil},
+		{"nacked message", func(_ context.Context, m *message.Message) { m.Nack() }, worker.ErrMessageNacked},
+		{"handler success", func(context.Context, *message.Message) error { return nil }, nil},
+		{"handler error", func(context.Context, *message.Message) error { return assert.AnError }, assert.AnError},
+		{"handler success after retry", newFailingHandler(2), nil},
+		{"handler error after retries", newFailingHandler(3), assert.AnError},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var observed []error
+
+			definition := worker.SubscriptionHandlerDefinition{
+				SubscriptionID: "test-subscription",
+				Handler:        tt.handler,
+			}
+
+			run, err := definition.RunFunc(&callingSubscriberStub{}, func(err error) {
+				observed = append(observed, err)
+			})
+			assert.NoError(t, err)
+
+			//nolint:errcheck
+			run(context.Background())
+
+			assert.Equal(t, []error{tt.expected}, observed)
+		})
+	}
+}
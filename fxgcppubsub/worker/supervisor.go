@@ -0,0 +1,240 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/ankorstore/yokai-contrib/fxgcppubsub/internal/backoff"
+	"github.com/ankorstore/yokai-contrib/fxgcppubsub/subscription"
+	"github.com/ankorstore/yokai/log"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var _ WorkerSupervisor = (*DefaultWorkerSupervisor)(nil)
+
+const (
+	DefaultRestartMinBackoff        = 1 * time.Second
+	DefaultRestartMaxBackoff        = 30 * time.Second
+	DefaultRestartBackoffMultiplier = 2.0
+	DefaultRestartStablePeriod      = 1 * time.Minute
+)
+
+// SupervisorOptions represents the DefaultWorkerSupervisor options.
+//
+// A worker running for at least StablePeriod before an error has its restarts count reset, for the backoff and MaxRestarts.
+type SupervisorOptions struct {
+	MinBackoff        time.Duration
+	MaxBackoff        time.Duration
+	BackoffMultiplier float64
+	MaxRestarts       int
+	StablePeriod      time.Duration
+}
+
+// DefaultSupervisorOptions returns the default SupervisorOptions, restarting workers without limit.
+func DefaultSupervisorOptions() SupervisorOptions {
+	return SupervisorOptions{
+		MinBackoff:        DefaultRestartMinBackoff,
+		MaxBackoff:        DefaultRestartMaxBackoff,
+		BackoffMultiplier: DefaultRestartBackoffMultiplier,
+		StablePeriod:      DefaultRestartStablePeriod,
+	}
+}
+
+// Backoff returns the duration to wait before a provided restart.
+func (o SupervisorOptions) Backoff(restart int) time.Duration {
+	return backoff.Exponential(restart, o.MinBackoff, o.MaxBackoff, o.BackoffMultiplier)
+}
+
+// WorkerSupervisor is the interface for Worker supervisors.
+type WorkerSupervisor interface {
+	Start(ctx context.Context)
+	Stop(ctx context.Context) error
+	Workers() []*Worker
+	Statuses() []WorkerStatus
+}
+
+// DefaultWorkerSupervisor is the default WorkerSupervisor implementation.
+//
+// It runs each Worker in its own goroutine, restarting it with backoff on transient errors, and marks it as failed on
+// permanent errors (like a missing subscription) or once SupervisorOptions.MaxRestarts is reached.
+type DefaultWorkerSupervisor struct {
+	workers []*Worker
+	logger  *log.Logger
+	options SupervisorOptions
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+	mutex   sync.Mutex
+}
+
+// NewDefaultWorkerSupervisor returns a new DefaultWorkerSupervisor instance.
+func NewDefaultWorkerSupervisor(logger *log.Logger, options SupervisorOptions, workers ...*Worker) *DefaultWorkerSupervisor {
+	defaults := DefaultSupervisorOptions()
+
+	if options.MinBackoff <= 0 {
+		options.MinBackoff = defaults.MinBackoff
+	}
+
+	if options.MaxBackoff <= 0 {
+		options.MaxBackoff = defaults.MaxBackoff
+	}
+
+	if options.BackoffMultiplier <= 0 {
+		options.BackoffMultiplier = defaults.BackoffMultiplier
+	}
+
+	if options.StablePeriod <= 0 {
+		options.StablePeriod = defaults.StablePeriod
+	}
+
+	return &DefaultWorkerSupervisor{
+		workers: workers,
+		logger:  logger,
+		options: options,
+	}
+}
+
+// Workers returns the supervised workers.
+func (s *DefaultWorkerSupervisor) Workers() []*Worker {
+	return s.workers
+}
+
+// Statuses returns a snapshot of the supervised workers statuses.
+func (s *DefaultWorkerSupervisor) Statuses() []WorkerStatus {
+	statuses := make([]WorkerStatus, len(s.workers))
+	for i, w := range s.workers {
+		statuses[i] = w.Status()
+	}
+
+	return statuses
+}
+
+// Start starts the supervised workers in background, until Stop is called.
+func (s *DefaultWorkerSupervisor) Start(ctx context.Context) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.cancel != nil {
+		return
+	}
+
+	ctx, s.cancel = context.WithCancel(ctx)
+
+	for _, w := range s.workers {
+		s.wg.Add(1)
+
+		go func(w *Worker) {
+			defer s.wg.Done()
+
+			s.supervise(ctx, w)
+		}(w)
+	}
+}
+
+// Stop stops the supervised workers, and waits for their in flight messages to be drained, until ctx is done.
+func (s *DefaultWorkerSupervisor) Stop(ctx context.Context) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.cancel == nil {
+		return nil
+	}
+
+	s.cancel()
+	s.cancel = nil
+
+	done := make(chan struct{})
+
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("cannot stop workers gracefully: %w", ctx.Err())
+	}
+}
+
+func (s *DefaultWorkerSupervisor) supervise(ctx context.Context, w *Worker) {
+	restarts := 0
+
+	for {
+		s.logger.Info().Str("subscriptionID", w.SubscriptionID()).Int("restarts", restarts).Msg("pubsub worker starting")
+
+		startedAt := time.Now()
+
+		err := w.Run(ctx)
+
+		if ctx.Err() != nil {
+			w.setStatus(func(st *WorkerStatus) {
+				st.Status = StatusStopped
+			})
+
+			s.logger.Info().Str("subscriptionID", w.SubscriptionID()).Msg("pubsub worker stopped")
+
+			return
+		}
+
+		if err == nil {
+			err = errors.New("subscription receiving ended unexpectedly")
+		}
+
+		if time.Since(startedAt) >= s.options.StablePeriod {
+			restarts = 0
+		}
+
+		if !IsTransientError(err) || (s.options.MaxRestarts > 0 && restarts >= s.options.MaxRestarts) {
+			w.setStatus(func(st *WorkerStatus) {
+				st.Status = StatusFailed
+				st.LastError = err
+			})
+
+			s.logger.Error().Err(err).Str("subscriptionID", w.SubscriptionID()).Int("restarts", restarts).Msg("pubsub worker failed")
+
+			return
+		}
+
+		restarts++
+
+		w.setStatus(func(st *WorkerStatus) {
+			st.Status = StatusRestarting
+			st.Restarts = restarts
+			st.LastError = err
+		})
+
+		wait := s.options.Backoff(restarts)
+
+		s.logger.Warn().Err(err).Str("subscriptionID", w.SubscriptionID()).Dur("backoff", wait).Msg("pubsub worker error, restarting")
+
+		select {
+		case <-ctx.Done():
+			w.setStatus(func(st *WorkerStatus) {
+				st.Status = StatusStopped
+			})
+
+			return
+		case <-time.After(wait):
+		}
+	}
+}
+
+// IsTransientError returns false for the errors that a worker restart cannot fix, like a missing subscription,
+// a permission denial or a retry without dead-letter, and true otherwise.
+func IsTransientError(err error) bool {
+	if errors.Is(err, subscription.ErrNoDeadLetter) {
+		return false
+	}
+
+	switch status.Code(err) {
+	case codes.NotFound, codes.PermissionDenied, codes.Unauthenticated, codes.InvalidArgument, codes.Unimplemented:
+		return false
+	default:
+		return true
+	}
+}
@@ -0,0 +1,269 @@
+package worker_test
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ankorstore/yokai-contrib/fxgcppubsub/subscription"
+	"github.com/ankorstore/yokai-contrib/fxgcppubsub/worker"
+	"github.com/ankorstore/yokai/log"
+	"github.com/ankorstore/yokai/log/logtest"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestSupervisorOptionsBackoff(t *testing.T) {
+	t.Parallel()
+
+	options := worker.SupervisorOptions{
+		MinBackoff:        10 * time.Millisecond,
+		MaxBackoff:        50 * time.Millisecond,
+		BackoffMultiplier: 2,
+	}
+
+	assert.Equal(t, 10*time.Millisecond, options.Backoff(0))
+	assert.Equal(t, 10*time.Millisecond, options.Backoff(1))
+	assert.Equal(t, 20*time.Millisecond, options.Backoff(2))
+	assert.Equal(t, 40*time.Millisecond, options.Backoff(3))
+	assert.Equal(t, 50*time.Millisecond, options.Backoff(4))
+}
+
+func TestIsTransientError(t *testing.T) {
+	t.Parallel()
+
+	assert.True(t, worker.IsTransientError(assert.AnError))
+	assert.True(t, worker.IsTransientError(status.Error(codes.Unavailable, "unavailable")))
+	assert.False(t, worker.IsTransientError(status.Error(codes.NotFound, "not found")))
+	assert.False(t, worker.IsTransientError(fmt.Errorf("cannot create subscription: %w", status.Error(codes.PermissionDenied, "denied"))))
+	assert.False(t, worker.IsTransientError(fmt.Errorf("cannot subscribe with retry: %w", subscription.ErrNoDeadLetter)))
+}
+
+func TestDefaultWorkerSupervisor(t *testing.T) {
+	t.Parallel()
+
+	options := worker.SupervisorOptions{
+		MinBackoff: time.Millisecond,
+		MaxBackoff: 5 * time.Millisecond,
+	}
+
+	newLogger := func(t *testing.T) (*log.Logger, logtest.TestLogBuffer) {
+		t.Helper()
+
+		logBuffer := logtest.NewDefaultTestLogBuffer()
+		logger, err := log.NewDefaultLoggerFactory().Create(
+			log.WithLevel(zerolog.DebugLevel),
+			log.WithOutputWriter(logBuffer),
+		)
+		assert.NoError(t, err)
+
+		return logger, logBuffer
+	}
+
+	t.Run("with defaults", func(t *testing.T) {
+		t.Parallel()
+
+		logger, _ := newLogger(t)
+
+		supervisor := worker.NewDefaultWorkerSupervisor(logger, worker.SupervisorOptions{})
+		assert.Implements(t, (*worker.WorkerSupervisor)(nil), supervisor)
+		assert.Empty(t, supervisor.Workers())
+		assert.Empty(t, supervisor.Statuses())
+
+		// stop before start is a no-op
+		assert.NoError(t, supervisor.Stop(context.Background()))
+
+		supervisor.Start(context.Background())
+		supervisor.Start(context.Background())
+
+		assert.NoError(t, supervisor.Stop(context.Background()))
+	})
+
+	t.Run("restart on transient errors and graceful stop", func(t *testing.T) {
+		t.Parallel()
+
+		logger, logBuffer := newLogger(t)
+
+		var runs atomic.Int32
+		var drained atomic.Bool
+
+		w := worker.NewWorker("test-subscription", func(ctx context.Context) error {
+			if runs.Add(1) < 3 {
+				return status.Error(codes.Unavailable, "unavailable")
+			}
+
+			<-ctx.Done()
+
+			// simulates in flight messages draining
+			time.Sleep(10 * time.Millisecond)
+			drained.Store(true)
+
+			return nil
+		})
+
+		supervisor := worker.NewDefaultWorkerSupervisor(logger, options, w)
+		supervisor.Start(context.Background())
+
+		assert.Eventually(t, func() bool {
+			return w.Status().Status == worker.StatusRunning && runs.Load() == 3
+		}, time.Second, time.Millisecond)
+
+		st := supervisor.Statuses()[0]
+		assert.Equal(t, "test-subscription", st.SubscriptionID)
+		assert.Equal(t, 2, st.Restarts)
+		assert.Contains(t, st.LastError.Error(), "unavailable")
+
+		err := supervisor.Stop(context.Background())
+		assert.NoError(t, err)
+		assert.True(t, drained.Load())
+		assert.Equal(t, worker.StatusStopped, w.Status().Status)
+
+		logtest.AssertHasLogRecord(t, logBuffer, map[string]interface{}{
+			"level":          "warn",
+			"subscriptionID": "test-subscription",
+			"message":        "pubsub worker error, restarting",
+		})
+
+		logtest.AssertHasLogRecord(t, logBuffer, map[string]interface{}{
+			"level":          "info",
+			"subscriptionID": "test-subscription",
+			"message":        "pubsub worker stopped",
+		})
+	})
+
+	t.Run("failure on permanent error", func(t *testing.T) {
+		t.Parallel()
+
+		logger, logBuffer := newLogger(t)
+
+		var runs atomic.Int32
+
+		w := worker.NewWorker("test-subscription", func(ctx context.Context) error {
+			runs.Add(1)
+
+			return fmt.Errorf("cannot create subscription: %w", status.Error(codes.NotFound, "not found"))
+		})
+
+		supervisor := worker.NewDefaultWorkerSupervisor(logger, options, w)
+		supervisor.Start(context.Background())
+
+		assert.Eventually(t, func() bool {
+			return w.Status().Status == worker.StatusFailed
+		}, time.Second, time.Millisecond)
+
+		assert.Equal(t, int32(1), runs.Load())
+		assert.Contains(t, w.Status().LastError.Error(), "cannot create subscription")
+
+		assert.NoError(t, supervisor.Stop(context.Background()))
+
+		logtest.AssertHasLogRecord(t, logBuffer, map[string]interface{}{
+			"level":          "error",
+			"subscriptionID": "test-subscription",
+			"message":        "pubsub worker failed",
+		})
+	})
+
+	t.Run("failure on max restarts", func(t *testing.T) {
+		t.Parallel()
+
+		logger, _ := newLogger(t)
+
+		var runs atomic.Int32
+
+		w := worker.NewWorker("test-subscription", func(ctx context.Context) error {
+			runs.Add(1)
+
+			return nil
+		})
+
+		supervisor := worker.NewDefaultWorkerSupervisor(
+			logger,
+			worker.SupervisorOptions{
+				MinBackoff:  time.Millisecond,
+				MaxRestarts: 2,
+			},
+			w,
+		)
+		supervisor.Start(context.Background())
+
+		assert.Eventually(t, func() bool {
+			return w.Status().Status == worker.StatusFailed
+		}, time.Second, time.Millisecond)
+
+		assert.Equal(t, int32(3), runs.Load())
+		assert.Equal(t, 2, w.Status().Restarts)
+		assert.Equal(t, "subscription receiving ended unexpectedly", w.Status().LastError.Error())
+
+		assert.NoError(t, supervisor.Stop(context.Background()))
+	})
+
+	t.Run("restarts reset after stable period", func(t *testing.T) {
+		t.Parallel()
+
+		logger, _ := newLogger(t)
+
+		var runs atomic.Int32
+
+		w := worker.NewWorker("test-subscription", func(ctx context.Context) error {
+			// each run is stable, then fails
+			time.Sleep(5 * time.Millisecond)
+
+			runs.Add(1)
+
+			return nil
+		})
+
+		supervisor := worker.NewDefaultWorkerSupervisor(
+			logger,
+			worker.SupervisorOptions{
+				MinBackoff:   time.Millisecond,
+				MaxRestarts:  1,
+				StablePeriod: time.Millisecond,
+			},
+			w,
+		)
+		supervisor.Start(context.Background())
+
+		assert.Eventually(t, func() bool {
+			return runs.Load() >= 5
+		}, time.Second, time.Millisecond)
+
+		assert.NotEqual(t, worker.StatusFailed, w.Status().Status)
+		assert.Equal(t, 1, w.Status().Restarts)
+
+		assert.NoError(t, supervisor.Stop(context.Background()))
+	})
+
+	t.Run("stop timeout", func(t *testing.T) {
+		t.Parallel()
+
+		logger, _ := newLogger(t)
+
+		release := make(chan struct{})
+		defer close(release)
+
+		w := worker.NewWorker("test-subscription", func(ctx context.Context) error {
+			<-release
+
+			return nil
+		})
+
+		supervisor := worker.NewDefaultWorkerSupervisor(logger, options, w)
+		supervisor.Start(context.Background())
+
+		assert.Eventually(t, func() bool {
+			return w.Status().Status == worker.StatusRunning
+		}, time.Second, time.Millisecond)
+
+		stopCtx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+
+		err := supervisor.Stop(stopCtx)
+		assert.Error(t, err)
+		assert.Equal(t, "cannot stop workers gracefully: context deadline exceeded", err.Error())
+	})
+}
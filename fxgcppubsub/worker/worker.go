@@ -0,0 +1,137 @@
+package worker
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Status represents a Worker status.
+type Status string
+
+const (
+	StatusIdle       Status = "idle"
+	StatusRunning    Status = "running"
+	StatusRestarting Status = "restarting"
+	StatusStopped    Status = "stopped"
+	StatusFailed     Status = "failed"
+)
+
+// DefaultActivityWindow is the default number of latest handled messages used to compute a Worker error rate.
+const DefaultActivityWindow = 100
+
+// RunFunc represents the Worker blocking execution, returning when the context is canceled or on error.
+type RunFunc func(ctx context.Context) error
+
+// WorkerStatus represents a snapshot of a Worker status.
+type WorkerStatus struct {
+	SubscriptionID string
+	Status         Status
+	Restarts       int
+	LastError      error
+	StartedAt      time.Time
+	LastMessageAt  time.Time
+	Processed      int
+	Failed         int
+	ErrorRate      float64
+}
+
+// Worker runs a subscription handler, and keeps track of its status and activity.
+type Worker struct {
+	subscriptionID string
+	run            RunFunc
+	status         WorkerStatus
+	window         []bool
+	windowNext     int
+	windowLen      int
+	windowFailed   int
+	mutex          sync.RWMutex
+}
+
+// NewWorker returns a new Worker instance.
+func NewWorker(subscriptionID string, run RunFunc) *Worker {
+	return &Worker{
+		subscriptionID: subscriptionID,
+		run:            run,
+		status: WorkerStatus{
+			SubscriptionID: subscriptionID,
+			Status:         StatusIdle,
+		},
+		window: make([]bool, DefaultActivityWindow),
+	}
+}
+
+// NewWorkerFromDefinition returns a new Worker instance running a SubscriptionHandlerDefinition, and observing its activity.
+func NewWorkerFromDefinition(subscriber Subscriber, definition SubscriptionHandlerDefinition) (*Worker, error) {
+	w := NewWorker(definition.SubscriptionID, nil)
+
+	run, err := definition.RunFunc(subscriber, w.Observe)
+	if err != nil {
+		return nil, err
+	}
+
+	w.run = run
+
+	return w, nil
+}
+
+// SubscriptionID returns the worker subscription id.
+func (w *Worker) SubscriptionID() string {
+	return w.subscriptionID
+}
+
+// Status returns a snapshot of the worker status.
+func (w *Worker) Status() WorkerStatus {
+	w.mutex.RLock()
+	defer w.mutex.RUnlock()
+
+	return w.status
+}
+
+// Run runs the worker RunFunc once, until the context is canceled or on error.
+func (w *Worker) Run(ctx context.Context) error {
+	w.setStatus(func(s *WorkerStatus) {
+		s.Status = StatusRunning
+		s.StartedAt = time.Now()
+	})
+
+	return w.run(ctx)
+}
+
+// Observe records the outcome of a handled message, to keep track of the worker activity.
+//
+// The error rate is computed on the DefaultActivityWindow latest handled messages.
+func (w *Worker) Observe(err error) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	failed := err != nil
+
+	if w.windowLen == len(w.window) {
+		if w.window[w.windowNext] {
+			w.windowFailed--
+		}
+	} else {
+		w.windowLen++
+	}
+
+	w.window[w.windowNext] = failed
+	w.windowNext = (w.windowNext + 1) % len(w.window)
+
+	w.status.LastMessageAt = time.Now()
+	w.status.Processed++
+
+	if failed {
+		w.status.Failed++
+		w.windowFailed++
+	}
+
+	w.status.ErrorRate = float64(w.windowFailed) / float64(w.windowLen)
+}
+
+func (w *Worker) setStatus(f func(s *WorkerStatus)) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	f(&w.status)
+}
@@ -0,0 +1,59 @@
+package worker_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ankorstore/yokai-contrib/fxgcppubsub/worker"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWorker(t *testing.T) {
+	t.Parallel()
+
+	var w *worker.Worker
+
+	w = worker.NewWorker("test-subscription", func(ctx context.Context) error {
+		assert.Equal(t, worker.StatusRunning, w.Status().Status)
+		assert.False(t, w.Status().StartedAt.IsZero())
+
+		return assert.AnError
+	})
+
+	assert.Equal(t, "test-subscription", w.SubscriptionID())
+	assert.Equal(t, worker.WorkerStatus{SubscriptionID: "test-subscription", Status: worker.StatusIdle}, w.Status())
+
+	err := w.Run(context.Background())
+	assert.Equal(t, assert.AnError, err)
+}
+
+func TestWorkerObserve(t *testing.T) {
+	t.Parallel()
+
+	w := worker.NewWorker("test-subscription", nil)
+
+	w.Observe(nil)
+	w.Observe(assert.AnError)
+
+	st := w.Status()
+	assert.Equal(t, 2, st.Processed)
+	assert.Equal(t, 1, st.Failed)
+	assert.Equal(t, 0.5, st.ErrorRate)
+	assert.False(t, st.LastMessageAt.IsZero())
+
+	// error rate on the latest messages window only
+	for i := 0; i < worker.DefaultActivityWindow; i++ {
+		w.Observe(nil)
+	}
+
+	st = w.Status()
+	assert.Equal(t, worker.DefaultActivityWindow+2, st.Processed)
+	assert.Equal(t, 1, st.Failed)
+	assert.Equal(t, 0.0, st.ErrorRate)
+
+	for i := 0; i < worker.DefaultActivityWindow/4; i++ {
+		w.Observe(assert.AnError)
+	}
+
+	assert.Equal(t, 0.25, w.Status().ErrorRate)
+}